@@ -3,6 +3,7 @@ package api
 import (
 	"FinanceGolang/core/domain"
 	"FinanceGolang/core/services"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -46,6 +47,13 @@ func (h *AccountController) CreateAccount(c *gin.Context) {
 		return
 	}
 	if err := h.accountService.CreateAccount(&account, c.MustGet("userID").(uint)); err != nil {
+		if errors.Is(err, domain.ErrInvalidAccountType) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"status": err.Error(),
 			"error":  "could not create account",
@@ -1,32 +1,49 @@
 package api
 
 import (
+	"FinanceGolang/core/domain"
 	"FinanceGolang/core/services"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AdminController struct {
-	scheduler *services.Scheduler
+	scheduler  *services.Scheduler
+	jobService services.JobService
 }
 
-func CreateAdminController(scheduler *services.Scheduler) *AdminController {
+func CreateAdminController(scheduler *services.Scheduler, jobService services.JobService) *AdminController {
 	return &AdminController{
-		scheduler: scheduler,
+		scheduler:  scheduler,
+		jobService: jobService,
 	}
 }
 
 // CheckPayments запускает проверку платежей вручную
 func (c *AdminController) CheckPayments(ctx *gin.Context) {
-	// Запускаем проверку платежей
-	c.scheduler.CheckPayments()
+	c.runJob(ctx, services.JobCheckPayments, "Проверка платежей выполнена")
+}
 
-	// Возвращаем результат
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Проверка платежей выполнена",
-		"status":  "success",
-	})
+// AccruePenalties запускает начисление неустойки по просроченным платежам вручную
+func (c *AdminController) AccruePenalties(ctx *gin.Context) {
+	c.runJob(ctx, services.JobAccruePenalties, "Начисление неустойки выполнено")
+}
+
+// ProcessCreditLines запускает начисление процентов и формирование выписок по кредитным линиям вручную
+func (c *AdminController) ProcessCreditLines(ctx *gin.Context) {
+	c.runJob(ctx, services.JobProcessCreditLines, "Обработка кредитных линий выполнена")
+}
+
+// ProcessDeposits запускает начисление процентов и обработку окончания срока вкладов вручную
+func (c *AdminController) ProcessDeposits(ctx *gin.Context) {
+	c.runJob(ctx, services.JobProcessDeposits, "Обработка вкладов выполнена")
+}
+
+// ProcessDunning запускает проход по просроченным кредитам и выполнение стадий взыскания вручную
+func (c *AdminController) ProcessDunning(ctx *gin.Context) {
+	c.runJob(ctx, services.JobProcessDunning, "Обработка просроченной задолженности выполнена")
 }
 
 // GetAllCredits возвращает список всех кредитов
@@ -39,3 +56,40 @@ func (c *AdminController) GetAllCredits(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"credits": credits})
 }
+
+// ProcessCards запускает обработку карт вручную
+func (c *AdminController) ProcessCards(ctx *gin.Context) {
+	c.runJob(ctx, services.JobProcessCards, "Обработка карт выполнена")
+}
+
+// ProcessDisputes запускает проверку сроков рассмотрения споров вручную
+func (c *AdminController) ProcessDisputes(ctx *gin.Context) {
+	c.runJob(ctx, services.JobProcessDisputes, "Проверка сроков по спорам выполнена")
+}
+
+// runJob выполняет фоновую задачу через планировщик: запуск попадает в историю
+// и не пересекается с запуском той же задачи по расписанию
+func (c *AdminController) runJob(ctx *gin.Context, name, message string) {
+	var actorID uint
+	if userID, exists := ctx.Get("userID"); exists {
+		actorID = userID.(uint)
+	}
+
+	run, err := c.jobService.Trigger(name, actorID)
+	switch {
+	case errors.Is(err, domain.ErrJobRunning):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	case run.Status == domain.JobRunFailed:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": run.Error})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"status":  "success",
+	})
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarController struct {
+	calendarService services.CalendarService
+}
+
+func CreateCalendarController(calendarService services.CalendarService) *CalendarController {
+	return &CalendarController{calendarService: calendarService}
+}
+
+// GetCalendar возвращает праздники и рабочие выходные за год (только для админа)
+func (cc *CalendarController) GetCalendar(c *gin.Context) {
+	var year int
+	if value := c.Query("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1900 || parsed > 2999 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "invalid year",
+			})
+			return
+		}
+		year = parsed
+	}
+
+	days, err := cc.calendarService.GetYear(year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	dtos := make([]map[string]interface{}, 0, len(days))
+	for i := range days {
+		dtos = append(dtos, days[i].ToDTO())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"days":   dtos,
+	})
+}
+
+// SetDay отмечает дату праздником или рабочим выходным (только для админа)
+func (cc *CalendarController) SetDay(c *gin.Context) {
+	var req payloads.CalendarDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	day, err := cc.calendarService.SetDay(c.Param("date"), &req)
+	if err != nil {
+		c.JSON(calendarErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"day":    day.ToDTO(),
+	})
+}
+
+// DeleteDay удаляет дату из календаря (только для админа)
+func (cc *CalendarController) DeleteDay(c *gin.Context) {
+	if err := cc.calendarService.DeleteDay(c.Param("date")); err != nil {
+		c.JSON(calendarErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "calendar day deleted",
+	})
+}
+
+// Import загружает производственный календарь (только для админа)
+func (cc *CalendarController) Import(c *gin.Context) {
+	var req payloads.ImportCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	imported, err := cc.calendarService.Import(&req)
+	if err != nil {
+		c.JSON(calendarErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"imported": imported,
+	})
+}
+
+// calendarErrorStatus подбирает HTTP-статус для ошибки производственного календаря
+func calendarErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCalendarDay):
+		return http.StatusBadRequest
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
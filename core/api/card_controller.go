@@ -1,10 +1,12 @@
 package api
 
 import (
+	"FinanceGolang/core/dbaccess"
 	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
 	"FinanceGolang/core/services"
+	"errors"
 
-	// "FinanceGolang/core/dbaccess"
 	// "FinanceGolang/core/dbcore"
 	"fmt"
 	"net/http"
@@ -64,6 +66,42 @@ func (cc *CardController) CreateCard(c *gin.Context) {
 	})
 }
 
+// CreateVirtualCard выпускает виртуальную карту
+func (cc *CardController) CreateVirtualCard(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"status": "error", "message": "user not found"})
+		return
+	}
+
+	var req payloads.CreateVirtualCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	unsecureCard, err := cc.cardService.CreateVirtualCard(&req, userID.(uint))
+	if err != nil {
+		status := cardErrorStatus(err)
+		if err.Error() == "account does not belong to the user" {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"message": "virtual card created successfully",
+		"card":    unsecureCard,
+	})
+}
+
 func (cc *CardController) GetCardByID(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -154,3 +192,295 @@ func (cc *CardController) GetAllCards(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// SetPIN устанавливает PIN-код карты
+func (cc *CardController) SetPIN(c *gin.Context) {
+	userID, cardID, ok := cc.cardRequestContext(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.SetPINRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	if err := cc.cardService.SetPIN(cardID, userID, req.PIN); err != nil {
+		c.JSON(cardErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "PIN set successfully",
+	})
+}
+
+// ChangePIN меняет PIN-код карты
+func (cc *CardController) ChangePIN(c *gin.Context) {
+	userID, cardID, ok := cc.cardRequestContext(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.ChangePINRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	if err := cc.cardService.ChangePIN(cardID, userID, req.OldPIN, req.NewPIN); err != nil {
+		c.JSON(cardErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "PIN changed successfully",
+	})
+}
+
+// UnblockCard снимает блокировку карты
+func (cc *CardController) UnblockCard(c *gin.Context) {
+	userID, cardID, ok := cc.cardRequestContext(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.UnblockCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "password is required",
+		})
+		return
+	}
+
+	if err := cc.cardService.UnblockCard(cardID, userID, req.Password, c.ClientIP()); err != nil {
+		c.JSON(cardErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "card unblocked successfully",
+	})
+}
+
+// Authorize проводит операцию по карте (оплата или снятие наличных)
+func (cc *CardController) Authorize(c *gin.Context) {
+	userID, cardID, ok := cc.cardRequestContext(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.CardAuthorizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	card, err := cc.cardService.GetCardByID(cardID)
+	if err != nil {
+		c.JSON(cardErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if card.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  "error",
+			"message": domain.ErrCardNotOwned.Error(),
+		})
+		return
+	}
+
+	transaction, err := cc.cardService.Authorize(cardID, &req)
+	if err != nil {
+		c.JSON(cardErrorStatus(err), gin.H{
+			"status":  "declined",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":      "approved",
+		"transaction": transaction.ToDTO(),
+	})
+}
+
+// GetControls возвращает ограничения по операциям карты
+func (cc *CardController) GetControls(c *gin.Context) {
+	userID, cardID, ok := cc.cardRequestContext(c)
+	if !ok {
+		return
+	}
+
+	card, err := cc.cardService.GetCardByID(cardID)
+	if err != nil {
+		c.JSON(cardErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if card.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  "error",
+			"message": domain.ErrCardNotOwned.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"controls": card.Controls,
+	})
+}
+
+// UpdateControls сохраняет ограничения по операциям карты
+func (cc *CardController) UpdateControls(c *gin.Context) {
+	userID, cardID, ok := cc.cardRequestContext(c)
+	if !ok {
+		return
+	}
+
+	var controls domain.CardControls
+	if err := c.ShouldBindJSON(&controls); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	card, err := cc.cardService.UpdateControls(cardID, userID, &controls)
+	if err != nil {
+		c.JSON(cardErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"message":  "card controls updated successfully",
+		"controls": card.Controls,
+	})
+}
+
+// RevealCard показывает полный номер и срок действия карты после повторного ввода пароля
+func (cc *CardController) RevealCard(c *gin.Context) {
+	userID, cardID, ok := cc.cardRequestContext(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.RevealCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "password is required",
+		})
+		return
+	}
+
+	revealed, err := cc.cardService.RevealCard(cardID, userID, req.Password, c.ClientIP())
+	if err != nil {
+		c.JSON(cardErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Реквизиты выдаются однократно и не должны кэшироваться
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"card":   revealed,
+	})
+}
+
+// cardRequestContext извлекает ID пользователя и ID карты из запроса
+func (cc *CardController) cardRequestContext(c *gin.Context) (uint, uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": "user not found",
+		})
+		return 0, 0, false
+	}
+
+	cardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid card ID",
+		})
+		return 0, 0, false
+	}
+
+	return userID.(uint), uint(cardID), true
+}
+
+// cardErrorStatus подбирает HTTP-статус для ошибки операции с картой
+func cardErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCardNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrCardBlocked), errors.Is(err, domain.ErrCardInactive),
+		errors.Is(err, domain.ErrCardClosed), errors.Is(err, domain.ErrCardExpired),
+		errors.Is(err, domain.ErrCardNotYetValid), errors.Is(err, domain.ErrCardAlreadyUsed):
+		return http.StatusLocked
+	case errors.Is(err, domain.ErrDuplicateTransaction):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrMerchantNotAllowed), errors.Is(err, domain.ErrChannelNotAllowed),
+		errors.Is(err, domain.ErrOnlinePaymentsDisabled), errors.Is(err, domain.ErrForeignTransactionsDisabled),
+		errors.Is(err, domain.ErrMerchantCategoryBlocked), errors.Is(err, domain.ErrOutsideTimeWindow),
+		errors.Is(err, domain.ErrCreditLineBlocked):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidVirtualCard), errors.Is(err, domain.ErrCardProductInactive),
+		errors.Is(err, domain.ErrInvalidCardControls):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrWrongPIN), errors.Is(err, domain.ErrWrongPassword):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrInvalidPIN), errors.Is(err, domain.ErrPINNotSet),
+		errors.Is(err, domain.ErrPINAlreadySet), errors.Is(err, domain.ErrPINRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrTooManyAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domain.ErrCardLimitExceeded), errors.Is(err, domain.ErrCardCapExceeded),
+		errors.Is(err, domain.ErrMCCLimitExceeded),
+		errors.Is(err, domain.ErrInsufficientFunds):
+		return http.StatusPaymentRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CardProductController struct {
+	cardProductService services.CardProductService
+}
+
+func CreateCardProductController(cardProductService services.CardProductService) *CardProductController {
+	return &CardProductController{cardProductService: cardProductService}
+}
+
+// GetProducts возвращает продукты, доступные для выпуска карт
+func (cc *CardProductController) GetProducts(c *gin.Context) {
+	products, err := cc.cardProductService.GetActiveProducts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"products": cardProductDTOs(products),
+	})
+}
+
+// GetAllProducts возвращает все карточные продукты (только для админа)
+func (cc *CardProductController) GetAllProducts(c *gin.Context) {
+	products, err := cc.cardProductService.GetAllProducts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"products": cardProductDTOs(products),
+	})
+}
+
+// CreateProduct создает карточный продукт (только для админа)
+func (cc *CardProductController) CreateProduct(c *gin.Context) {
+	var product domain.CardProduct
+	if err := c.ShouldBindJSON(&product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	if err := cc.cardProductService.CreateProduct(&product); err != nil {
+		c.JSON(cardProductErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"product": product.ToDTO(),
+	})
+}
+
+// UpdateProduct изменяет карточный продукт (только для админа)
+func (cc *CardProductController) UpdateProduct(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid product ID",
+		})
+		return
+	}
+
+	var update domain.CardProduct
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	product, err := cc.cardProductService.UpdateProduct(uint(id), &update)
+	if err != nil {
+		c.JSON(cardProductErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"product": product.ToDTO(),
+	})
+}
+
+// cardProductDTOs преобразует список продуктов в DTO
+func cardProductDTOs(products []domain.CardProduct) []map[string]interface{} {
+	dtos := make([]map[string]interface{}, 0, len(products))
+	for i := range products {
+		dtos = append(dtos, products[i].ToDTO())
+	}
+	return dtos
+}
+
+// cardProductErrorStatus подбирает HTTP-статус для ошибки операции с карточным продуктом
+func cardProductErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCardProduct):
+		return http.StatusBadRequest
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dbaccess.ErrAlreadyExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,24 +1,29 @@
 package api
 
 import (
+	"FinanceGolang/core/domain"
 	"FinanceGolang/core/services"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type CbrController struct {
-	externalService *services.ExternalService
+	keyRateService services.KeyRateService
+	clock          domain.Clock
 }
 
-func CreateCbrController(externalService *services.ExternalService) *CbrController {
-	return &CbrController{externalService: externalService}
+func CreateCbrController(keyRateService services.KeyRateService, clock domain.Clock) *CbrController {
+	return &CbrController{keyRateService: keyRateService, clock: clock}
 }
 
+// GetKeyRate возвращает текущую ключевую ставку с датой и временем обновления
 func (cc *CbrController) GetKeyRate(c *gin.Context) {
-	keyRate, err := cc.externalService.GetKeyRate()
+	quote, err := cc.keyRateService.Current()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(keyRateErrorStatus(err), gin.H{
 			"status":  "error",
 			"message": err.Error(),
 		})
@@ -27,6 +32,68 @@ func (cc *CbrController) GetKeyRate(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"status": "success",
-		"rate":   keyRate,
+		"rate":   quote.Rate,
+		"quote":  quote.ToDTO(),
 	})
 }
+
+// GetKeyRateHistory возвращает историю ключевой ставки за период from..to (ГГГГ-ММ-ДД).
+// По умолчанию - за последний год
+func (cc *CbrController) GetKeyRateHistory(c *gin.Context) {
+	to := cc.clock.Now()
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(domain.KeyRateDateLayout, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "invalid to date",
+			})
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(-1, 0, 0)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(domain.KeyRateDateLayout, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "invalid from date",
+			})
+			return
+		}
+		from = parsed
+	}
+
+	rates, err := cc.keyRateService.History(from, to)
+	if err != nil {
+		c.JSON(keyRateErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	history := make([]map[string]interface{}, 0, len(rates))
+	for i := range rates {
+		history = append(history, rates[i].ToDTO())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"from":    from.Format(domain.KeyRateDateLayout),
+		"to":      to.Format(domain.KeyRateDateLayout),
+		"history": history,
+	})
+}
+
+// keyRateErrorStatus подбирает HTTP-статус для ошибки ключевой ставки
+func keyRateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDateRange):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrKeyRateUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
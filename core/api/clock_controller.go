@@ -0,0 +1,87 @@
+package api
+
+import (
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ClockController struct {
+	clockService services.ClockService
+}
+
+func CreateClockController(clockService services.ClockService) *ClockController {
+	return &ClockController{clockService: clockService}
+}
+
+// GetClock возвращает текущее время и операционный день приложения (только для админа)
+func (cc *ClockController) GetClock(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"clock":  cc.clockService.State().ToDTO(),
+	})
+}
+
+// Advance переводит смоделированные часы вперед и возвращает выполненные запуски задач
+func (cc *ClockController) Advance(c *gin.Context) {
+	var req payloads.AdvanceClockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	state, runs, err := cc.clockService.Advance(&req)
+	if err != nil {
+		c.JSON(clockErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	dtos := make([]map[string]interface{}, 0, len(runs))
+	for i := range runs {
+		dtos = append(dtos, runs[i].ToDTO())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"clock":  state.ToDTO(),
+		"runs":   dtos,
+	})
+}
+
+// Reset возвращает часы к системному времени
+func (cc *ClockController) Reset(c *gin.Context) {
+	state, err := cc.clockService.Reset()
+	if err != nil {
+		c.JSON(clockErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"clock":  state.ToDTO(),
+	})
+}
+
+// clockErrorStatus подбирает HTTP-статус для ошибки управления часами
+func clockErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidClockShift):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrClockSimulationDisabled):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CollectionController struct {
+	collectionService services.CollectionService
+}
+
+func CreateCollectionController(collectionService services.CollectionService) *CollectionController {
+	return &CollectionController{collectionService: collectionService}
+}
+
+// GetWorklist возвращает рабочий список взыскания с фильтрами по корзине просрочки и сумме
+func (cc *CollectionController) GetWorklist(c *gin.Context) {
+	var query payloads.CollectionWorklistQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid query parameters",
+		})
+		return
+	}
+
+	cases, err := cc.collectionService.GetWorklist(&query)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	dtos := make([]map[string]interface{}, 0, len(cases))
+	for i := range cases {
+		dtos = append(dtos, cases[i].ToDTO())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"cases":  dtos,
+	})
+}
+
+// GetCase возвращает дело о просрочке с историей действий
+func (cc *CollectionController) GetCase(c *gin.Context) {
+	caseID, ok := cc.caseID(c)
+	if !ok {
+		return
+	}
+
+	collectionCase, err := cc.collectionService.GetCase(caseID)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"case":   collectionCase.ToDTO(),
+	})
+}
+
+// PromiseToPay фиксирует обещание оплаты и приостанавливает стадии взыскания (оператор)
+func (cc *CollectionController) PromiseToPay(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": "user not found",
+		})
+		return
+	}
+	caseID, ok := cc.caseID(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.PromiseToPayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	collectionCase, err := cc.collectionService.PromiseToPay(caseID, userID.(uint), &req)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"case":   collectionCase.ToDTO(),
+	})
+}
+
+// GetStages возвращает стадии взыскания (только для админа)
+func (cc *CollectionController) GetStages(c *gin.Context) {
+	stages, err := cc.collectionService.GetStages()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	dtos := make([]map[string]interface{}, 0, len(stages))
+	for i := range stages {
+		dtos = append(dtos, stages[i].ToDTO())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"stages": dtos,
+	})
+}
+
+// CreateStage создает стадию взыскания (только для админа)
+func (cc *CollectionController) CreateStage(c *gin.Context) {
+	var stage domain.DunningStage
+	if err := c.ShouldBindJSON(&stage); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	if err := cc.collectionService.CreateStage(&stage); err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status": "success",
+		"stage":  stage.ToDTO(),
+	})
+}
+
+// UpdateStage изменяет стадию взыскания (только для админа)
+func (cc *CollectionController) UpdateStage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid stage ID",
+		})
+		return
+	}
+
+	var update domain.DunningStage
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	stage, err := cc.collectionService.UpdateStage(uint(id), &update)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"stage":  stage.ToDTO(),
+	})
+}
+
+// caseID извлекает ID дела о просрочке из пути
+func (cc *CollectionController) caseID(c *gin.Context) (uint, bool) {
+	caseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid case ID",
+		})
+		return 0, false
+	}
+	return uint(caseID), true
+}
+
+// collectionErrorStatus подбирает HTTP-статус для ошибки взыскания
+func collectionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dbaccess.ErrAlreadyExists), errors.Is(err, domain.ErrCollectionCaseClosed):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidDunningStage), errors.Is(err, domain.ErrInvalidPromiseToPay),
+		errors.Is(err, domain.ErrInvalidDPDBucket):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreditApplicationController struct {
+	applicationService services.CreditApplicationService
+}
+
+func CreateCreditApplicationController(applicationService services.CreditApplicationService) *CreditApplicationController {
+	return &CreditApplicationController{applicationService: applicationService}
+}
+
+// Apply принимает заявку на кредит и возвращает результат автоматического скоринга
+func (ac *CreditApplicationController) Apply(c *gin.Context) {
+	userID, ok := ac.userID(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.CreditApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	application, err := ac.applicationService.Apply(userID, &req)
+	if err != nil {
+		c.JSON(applicationErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":      "success",
+		"application": application.ToDTO(),
+	})
+}
+
+// GetUserApplications возвращает заявки текущего пользователя
+func (ac *CreditApplicationController) GetUserApplications(c *gin.Context) {
+	userID, ok := ac.userID(c)
+	if !ok {
+		return
+	}
+
+	applications, err := ac.applicationService.GetUserApplications(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":       "success",
+		"applications": applicationDTOs(applications),
+	})
+}
+
+// GetUserApplication возвращает заявку текущего пользователя с решениями
+func (ac *CreditApplicationController) GetUserApplication(c *gin.Context) {
+	userID, ok := ac.userID(c)
+	if !ok {
+		return
+	}
+	applicationID, ok := ac.applicationID(c)
+	if !ok {
+		return
+	}
+
+	application, err := ac.applicationService.GetUserApplication(applicationID, userID)
+	if err != nil {
+		c.JSON(applicationErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":      "success",
+		"application": application.ToDTO(),
+	})
+}
+
+// GetApplications возвращает очередь заявок для менеджера (по умолчанию ожидающие решения)
+func (ac *CreditApplicationController) GetApplications(c *gin.Context) {
+	applications, err := ac.applicationService.GetApplications(domain.CreditApplicationStatus(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":       "success",
+		"applications": applicationDTOs(applications),
+	})
+}
+
+// GetApplication возвращает заявку для менеджера
+func (ac *CreditApplicationController) GetApplication(c *gin.Context) {
+	applicationID, ok := ac.applicationID(c)
+	if !ok {
+		return
+	}
+
+	application, err := ac.applicationService.GetApplication(applicationID)
+	if err != nil {
+		c.JSON(applicationErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":      "success",
+		"application": application.ToDTO(),
+	})
+}
+
+// Approve одобряет заявку и выдает кредит
+func (ac *CreditApplicationController) Approve(c *gin.Context) {
+	ac.managerDecision(c, ac.applicationService.Approve)
+}
+
+// Reject отклоняет заявку
+func (ac *CreditApplicationController) Reject(c *gin.Context) {
+	ac.managerDecision(c, ac.applicationService.Reject)
+}
+
+// managerDecision фиксирует решение менеджера с необязательным комментарием
+func (ac *CreditApplicationController) managerDecision(c *gin.Context, decide func(applicationID, managerID uint, comment string) (*domain.CreditApplication, error)) {
+	managerID, ok := ac.userID(c)
+	if !ok {
+		return
+	}
+	applicationID, ok := ac.applicationID(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.CreditApplicationDecisionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "invalid request body",
+			})
+			return
+		}
+	}
+
+	application, err := decide(applicationID, managerID, req.Comment)
+	if err != nil {
+		c.JSON(applicationErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":      "success",
+		"application": application.ToDTO(),
+	})
+}
+
+// userID извлекает ID текущего пользователя
+func (ac *CreditApplicationController) userID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": "user not found",
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// applicationID извлекает ID заявки из пути
+func (ac *CreditApplicationController) applicationID(c *gin.Context) (uint, bool) {
+	applicationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid application ID",
+		})
+		return 0, false
+	}
+	return uint(applicationID), true
+}
+
+// applicationDTOs преобразует список заявок в DTO
+func applicationDTOs(applications []domain.CreditApplication) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(applications))
+	for i := range applications {
+		result = append(result, applications[i].ToDTO())
+	}
+	return result
+}
+
+// applicationErrorStatus подбирает HTTP-статус для ошибки операции с заявкой на кредит
+func applicationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrAccountNotOwned), errors.Is(err, domain.ErrApplicationNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrApplicationNotInReview):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidCreditApplication), errors.Is(err, domain.ErrInvalidRepaymentMethod),
+		errors.Is(err, domain.ErrInvalidCreditAmount), errors.Is(err, domain.ErrInvalidTerm),
+		errors.Is(err, domain.ErrCreditTermsOutOfProduct), errors.Is(err, domain.ErrCreditProductInactive),
+		errors.Is(err, domain.ErrCreditProductNotEligible):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrKeyRateUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
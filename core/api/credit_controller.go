@@ -1,7 +1,11 @@
 package api
 
 import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
 	"FinanceGolang/core/services"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -17,97 +21,48 @@ func CreateCreditController(creditService services.CreditService) *CreditControl
 	return &CreditController{creditService: creditService}
 }
 
-type CreateCreditRequest struct {
-	AccountID   uint    `json:"account_id" binding:"required"`
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
-	TermMonths  int     `json:"term_months" binding:"required,gt=0"`
-	Description string  `json:"description"`
+type CalculateCreditRequest struct {
+	ProductID       uint    `json:"product_id"` // 0 - продукт по умолчанию
+	Amount          float64 `json:"amount" binding:"required,gt=0"`
+	TermMonths      int     `json:"term_months" binding:"required,gt=0"`
+	RepaymentMethod string  `json:"repayment_method" binding:"omitempty,oneof=ANNUITY DIFFERENTIATED"`
 }
 
 type CreditResponse struct {
-	ID             uint      `json:"id"`
-	UserID         uint      `json:"user_id"`
-	AccountID      uint      `json:"account_id"`
-	Amount         float64   `json:"amount"`
-	InterestRate   float64   `json:"interest_rate"`
-	TermMonths     int       `json:"term_months"`
-	MonthlyPayment float64   `json:"monthly_payment"`
-	Status         string    `json:"status"`
-	StartDate      time.Time `json:"start_date"`
-	EndDate        time.Time `json:"end_date"`
-	PaymentDay     int       `json:"payment_day"`
-	NextPayment    time.Time `json:"next_payment"`
-	TotalPaid      float64   `json:"total_paid"`
-	RemainingDebt  float64   `json:"remaining_debt"`
-	OverdueAmount  float64   `json:"overdue_amount"`
-	LastPayment    time.Time `json:"last_payment"`
-	Description    string    `json:"description"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID              uint      `json:"id"`
+	UserID          uint      `json:"user_id"`
+	AccountID       uint      `json:"account_id"`
+	Amount          float64   `json:"amount"`
+	InterestRate    float64   `json:"interest_rate"`
+	TermMonths      int       `json:"term_months"`
+	RepaymentMethod string    `json:"repayment_method"`
+	MonthlyPayment  float64   `json:"monthly_payment"`
+	IssueFee        float64   `json:"issue_fee"`
+	FullCostRate    float64   `json:"full_cost_rate"`
+	FullCostAmount  float64   `json:"full_cost_amount"`
+	Status          string    `json:"status"`
+	StartDate       time.Time `json:"start_date"`
+	EndDate         time.Time `json:"end_date"`
+	PaymentDay      int       `json:"payment_day"`
+	BusinessDayRule string    `json:"business_day_rule"`
+	NextPayment     time.Time `json:"next_payment"`
+	TotalPaid       float64   `json:"total_paid"`
+	RemainingDebt   float64   `json:"remaining_debt"`
+	OverdueAmount   float64   `json:"overdue_amount"`
+	LastPayment     time.Time `json:"last_payment"`
+	Description     string    `json:"description"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
-type ProcessPaymentRequest struct {
-	PaymentNumber int `json:"payment_number" binding:"required,gt=0"`
+type EarlyRepaymentRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+	// Пересчет графика: REDUCE_TERM — сократить срок, REDUCE_PAYMENT — уменьшить платеж
+	Mode string `json:"mode" binding:"required,oneof=REDUCE_TERM REDUCE_PAYMENT"`
 }
 
-func (c *CreditController) CreateCredit(ctx *gin.Context) {
-	userID, exists := ctx.Get("userID")
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
-		return
-	}
-
-	var req CreateCreditRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Дополнительная обработка - убеждаемся, что description всегда строка
-	description := req.Description
-	if description == "" {
-		description = "Потребительский кредит"
-	}
-
-	credit, err := c.creditService.CreateCredit(
-		userID.(uint),
-		req.AccountID,
-		req.Amount,
-		req.TermMonths,
-		description,
-	)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Преобразуем модель в структуру ответа
-	response := CreditResponse{
-		ID:             credit.ID,
-		UserID:         credit.UserID,
-		AccountID:      credit.AccountID,
-		Amount:         credit.Amount,
-		InterestRate:   credit.InterestRate,
-		TermMonths:     credit.Term,
-		MonthlyPayment: credit.CalculateMonthlyPayment(),
-		Status:         string(credit.Status),
-		StartDate:      credit.StartDate,
-		EndDate:        credit.EndDate,
-		PaymentDay:     credit.PaymentDay,
-		NextPayment:    credit.NextPayment,
-		TotalPaid:      credit.TotalPaid,
-		RemainingDebt:  credit.RemainingDebt,
-		OverdueAmount:  credit.OverdueAmount,
-		LastPayment:    credit.LastPayment,
-		Description:    description,
-		CreatedAt:      credit.CreatedAt,
-		UpdatedAt:      credit.UpdatedAt,
-	}
-
-	ctx.JSON(http.StatusCreated, gin.H{
-		"message": "credit created successfully",
-		"credit":  response,
-	})
+type ProcessPaymentRequest struct {
+	PaymentNumber int `json:"payment_number" binding:"required,gt=0"`
 }
 
 func (c *CreditController) GetCreditByID(ctx *gin.Context) {
@@ -129,24 +84,29 @@ func (c *CreditController) GetCreditByID(ctx *gin.Context) {
 	}
 
 	response := CreditResponse{
-		ID:             credit.ID,
-		UserID:         credit.UserID,
-		AccountID:      credit.AccountID,
-		Amount:         credit.Amount,
-		InterestRate:   credit.InterestRate,
-		TermMonths:     credit.Term,
-		MonthlyPayment: credit.CalculateMonthlyPayment(),
-		Status:         string(credit.Status),
-		StartDate:      credit.StartDate,
-		EndDate:        credit.EndDate,
-		PaymentDay:     credit.PaymentDay,
-		NextPayment:    credit.NextPayment,
-		TotalPaid:      credit.TotalPaid,
-		RemainingDebt:  credit.RemainingDebt,
-		OverdueAmount:  credit.OverdueAmount,
-		LastPayment:    credit.LastPayment,
-		CreatedAt:      credit.CreatedAt,
-		UpdatedAt:      credit.UpdatedAt,
+		ID:              credit.ID,
+		UserID:          credit.UserID,
+		AccountID:       credit.AccountID,
+		Amount:          credit.Amount,
+		InterestRate:    credit.InterestRate,
+		TermMonths:      credit.Term,
+		RepaymentMethod: string(credit.Method()),
+		MonthlyPayment:  credit.CalculateMonthlyPayment(),
+		IssueFee:        credit.IssueFee,
+		FullCostRate:    credit.FullCostRate,
+		FullCostAmount:  credit.FullCostAmount,
+		Status:          string(credit.Status),
+		StartDate:       credit.StartDate,
+		EndDate:         credit.EndDate,
+		PaymentDay:      credit.PaymentDay,
+		BusinessDayRule: string(credit.BusinessDayRule.OrDefault()),
+		NextPayment:     credit.NextPayment,
+		TotalPaid:       credit.TotalPaid,
+		RemainingDebt:   credit.RemainingDebt,
+		OverdueAmount:   credit.OverdueAmount,
+		LastPayment:     credit.LastPayment,
+		CreatedAt:       credit.CreatedAt,
+		UpdatedAt:       credit.UpdatedAt,
 	}
 
 	ctx.JSON(http.StatusOK, response)
@@ -173,24 +133,29 @@ func (c *CreditController) GetUserCredits(ctx *gin.Context) {
 		}
 
 		responses[i] = CreditResponse{
-			ID:             credit.ID,
-			UserID:         credit.UserID,
-			AccountID:      credit.AccountID,
-			Amount:         credit.Amount,
-			InterestRate:   credit.InterestRate,
-			TermMonths:     credit.Term,
-			MonthlyPayment: credit.CalculateMonthlyPayment(),
-			Status:         string(credit.Status),
-			StartDate:      credit.StartDate,
-			EndDate:        credit.EndDate,
-			PaymentDay:     credit.PaymentDay,
-			NextPayment:    credit.NextPayment,
-			TotalPaid:      credit.TotalPaid,
-			RemainingDebt:  credit.RemainingDebt,
-			OverdueAmount:  credit.OverdueAmount,
-			LastPayment:    credit.LastPayment,
-			CreatedAt:      credit.CreatedAt,
-			UpdatedAt:      credit.UpdatedAt,
+			ID:              credit.ID,
+			UserID:          credit.UserID,
+			AccountID:       credit.AccountID,
+			Amount:          credit.Amount,
+			InterestRate:    credit.InterestRate,
+			TermMonths:      credit.Term,
+			RepaymentMethod: string(credit.Method()),
+			MonthlyPayment:  credit.CalculateMonthlyPayment(),
+			IssueFee:        credit.IssueFee,
+			FullCostRate:    credit.FullCostRate,
+			FullCostAmount:  credit.FullCostAmount,
+			Status:          string(credit.Status),
+			StartDate:       credit.StartDate,
+			EndDate:         credit.EndDate,
+			PaymentDay:      credit.PaymentDay,
+			BusinessDayRule: string(credit.BusinessDayRule.OrDefault()),
+			NextPayment:     credit.NextPayment,
+			TotalPaid:       credit.TotalPaid,
+			RemainingDebt:   credit.RemainingDebt,
+			OverdueAmount:   credit.OverdueAmount,
+			LastPayment:     credit.LastPayment,
+			CreatedAt:       credit.CreatedAt,
+			UpdatedAt:       credit.UpdatedAt,
 		}
 	}
 
@@ -229,14 +194,61 @@ func (c *CreditController) GetPaymentSchedule(ctx *gin.Context) {
 		scheduleDTOs[i] = schedule[i].ToDTO()
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"schedule": scheduleDTOs})
+	ctx.JSON(http.StatusOK, gin.H{
+		"schedule":         scheduleDTOs,
+		"repayment_method": credit.Method(),
+		"summary":          domain.SummarizeSchedule(credit.Method(), schedule),
+		"comparison":       credit.CompareRepaymentMethods(),
+		"full_cost":        domain.FullCost{Rate: credit.FullCostRate, Amount: credit.FullCostAmount},
+	})
 }
 
-func (c *CreditController) ProcessPayment(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+// CalculateCredit рассчитывает график платежей и переплату без оформления кредита
+func (c *CreditController) CalculateCredit(ctx *gin.Context) {
+	var req CalculateCreditRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	credit, err := c.creditService.CalculateCredit(req.ProductID, req.Amount, req.TermMonths, domain.RepaymentMethod(req.RepaymentMethod))
+	if err != nil {
+		ctx.JSON(creditErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := c.creditService.CalculateSchedule(credit)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid credit ID"})
+		ctx.JSON(creditErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	scheduleDTOs := make([]map[string]interface{}, len(schedule))
+	for i := range schedule {
+		// Расчетный график не сохраняется, поэтому служебные поля записи не нужны
+		scheduleDTOs[i] = schedule[i].ToDTO()
+		for _, key := range []string{"id", "credit_id", "created_at", "updated_at"} {
+			delete(scheduleDTOs[i], key)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"amount":            credit.Amount,
+		"term_months":       credit.Term,
+		"interest_rate":     credit.InterestRate,
+		"repayment_method":  credit.Method(),
+		"issue_fee":         credit.IssueFee,
+		"business_day_rule": credit.BusinessDayRule.OrDefault(),
+		"schedule":          scheduleDTOs,
+		"summary":           domain.SummarizeSchedule(credit.Method(), schedule),
+		"comparison":        credit.CompareRepaymentMethods(),
+		"full_cost":         credit.FullCost(schedule),
+	})
+}
+
+// ProcessPayment списывает платеж по графику кредита текущего пользователя
+func (c *CreditController) ProcessPayment(ctx *gin.Context) {
+	userID, creditID, ok := c.userCredit(ctx)
+	if !ok {
 		return
 	}
 
@@ -246,10 +258,149 @@ func (c *CreditController) ProcessPayment(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.creditService.ProcessPayment(uint(id), req.PaymentNumber); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	allocation, err := c.creditService.ProcessUserPayment(creditID, userID, req.PaymentNumber)
+	if err != nil {
+		response := gin.H{"error": err.Error()}
+		// При нехватке средств часть просроченной задолженности могла быть погашена
+		if allocation != nil && allocation.Amount > 0 {
+			response["allocation"] = allocation
+		}
+		ctx.JSON(creditErrorStatus(err), response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":    "payment processed successfully",
+		"allocation": allocation,
+	})
+}
+
+// EarlyRepayment частично погашает кредит досрочно
+func (c *CreditController) EarlyRepayment(ctx *gin.Context) {
+	userID, creditID, ok := c.userCredit(ctx)
+	if !ok {
+		return
+	}
+
+	var req EarlyRepaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	credit, prepayment, err := c.creditService.EarlyRepayment(creditID, userID, req.Amount, domain.PrepaymentMode(req.Mode))
+	if err != nil {
+		ctx.JSON(creditErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule := make([]map[string]interface{}, len(prepayment.Schedule))
+	for i := range prepayment.Schedule {
+		schedule[i] = prepayment.Schedule[i].ToDTO()
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":            "early repayment processed successfully",
+		"credit":             credit.ToDTO(),
+		"mode":               prepayment.Mode,
+		"amount":             prepayment.Amount,
+		"interest":           prepayment.Interest,
+		"principal":          prepayment.Principal,
+		"remaining_schedule": schedule,
+	})
+}
+
+// FullRepayment полностью погашает кредит досрочно и возвращает справку о закрытии
+func (c *CreditController) FullRepayment(ctx *gin.Context) {
+	userID, creditID, ok := c.userCredit(ctx)
+	if !ok {
+		return
+	}
+
+	credit, certificate, err := c.creditService.FullRepayment(creditID, userID)
+	if err != nil {
+		ctx.JSON(creditErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "credit repaid in full",
+		"credit":      credit.ToDTO(),
+		"certificate": certificate,
+	})
+}
+
+// GetClosingCertificate возвращает справку о полном погашении кредита
+func (c *CreditController) GetClosingCertificate(ctx *gin.Context) {
+	userID, creditID, ok := c.userCredit(ctx)
+	if !ok {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "payment processed successfully"})
+	certificate, err := c.creditService.GetClosingCertificate(creditID, userID)
+	if err != nil {
+		ctx.JSON(creditErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"certificate": certificate})
+}
+
+// GetAgreement отдает кредитный договор с графиком платежей для скачивания
+func (c *CreditController) GetAgreement(ctx *gin.Context) {
+	userID, creditID, ok := c.userCredit(ctx)
+	if !ok {
+		return
+	}
+
+	agreement, err := c.creditService.GetAgreement(creditID, userID)
+	if err != nil {
+		ctx.JSON(creditErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", agreement.FileName()))
+	ctx.Data(http.StatusOK, agreement.ContentType+"; charset=utf-8", []byte(agreement.Content))
+}
+
+// userCredit извлекает ID текущего пользователя и ID кредита из пути
+func (c *CreditController) userCredit(ctx *gin.Context) (uint, uint, bool) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+		return 0, 0, false
+	}
+
+	creditID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid credit id"})
+		return 0, 0, false
+	}
+	return userID.(uint), uint(creditID), true
+}
+
+// creditErrorStatus подбирает HTTP-статус для ошибки операции по кредиту
+func creditErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dbaccess.ErrNotFound), errors.Is(err, domain.ErrPaymentNotFound),
+		errors.Is(err, domain.ErrCertificateNotFound), errors.Is(err, domain.ErrAgreementNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCreditNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrPaymentAlreadyPaid), errors.Is(err, domain.ErrCreditNotActive),
+		errors.Is(err, domain.ErrCreditHasDuePayments), errors.Is(err, domain.ErrPrepaymentOutdated):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInsufficientFunds):
+		return http.StatusPaymentRequired
+	case errors.Is(err, domain.ErrInvalidCreditAmount), errors.Is(err, domain.ErrInvalidTerm),
+		errors.Is(err, domain.ErrInvalidInterestRate), errors.Is(err, domain.ErrInvalidRepaymentMethod),
+		errors.Is(err, domain.ErrInvalidPaymentAmount), errors.Is(err, domain.ErrInvalidPrepaymentMode),
+		errors.Is(err, domain.ErrPrepaymentTooSmall), errors.Is(err, domain.ErrPrepaymentExceedsDebt),
+		errors.Is(err, domain.ErrCreditTermsOutOfProduct), errors.Is(err, domain.ErrCreditProductInactive):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrKeyRateUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
 }
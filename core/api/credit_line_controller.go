@@ -0,0 +1,223 @@
+package api
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreditLineController struct {
+	creditLineService services.CreditLineService
+}
+
+func CreateCreditLineController(creditLineService services.CreditLineService) *CreditLineController {
+	return &CreditLineController{creditLineService: creditLineService}
+}
+
+// GetUserCreditLines возвращает кредитные линии текущего пользователя
+func (lc *CreditLineController) GetUserCreditLines(c *gin.Context) {
+	userID, ok := lc.userID(c)
+	if !ok {
+		return
+	}
+
+	lines, err := lc.creditLineService.GetUserCreditLines(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":       "success",
+		"credit_lines": creditLineDTOs(lines),
+	})
+}
+
+// GetUserCreditLine возвращает кредитную линию текущего пользователя с доступным лимитом
+func (lc *CreditLineController) GetUserCreditLine(c *gin.Context) {
+	userID, ok := lc.userID(c)
+	if !ok {
+		return
+	}
+	lineID, ok := lc.lineID(c)
+	if !ok {
+		return
+	}
+
+	line, account, err := lc.creditLineService.GetUserCreditLine(lineID, userID)
+	if err != nil {
+		c.JSON(creditLineErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	dto := line.ToDTO()
+	dto["balance"] = account.Balance
+	dto["available"] = account.Available()
+	dto["debt"] = account.Debt()
+	c.JSON(http.StatusOK, gin.H{
+		"status":      "success",
+		"credit_line": dto,
+	})
+}
+
+// GetStatements возвращает выписки по кредитной линии текущего пользователя
+func (lc *CreditLineController) GetStatements(c *gin.Context) {
+	userID, ok := lc.userID(c)
+	if !ok {
+		return
+	}
+	lineID, ok := lc.lineID(c)
+	if !ok {
+		return
+	}
+
+	statements, err := lc.creditLineService.GetStatements(lineID, userID)
+	if err != nil {
+		c.JSON(creditLineErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "success",
+		"statements": statements,
+	})
+}
+
+// OpenCreditLine открывает клиенту кредитную линию
+func (lc *CreditLineController) OpenCreditLine(c *gin.Context) {
+	var req payloads.OpenCreditLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	line, account, err := lc.creditLineService.OpenCreditLine(&req)
+	if err != nil {
+		c.JSON(creditLineErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":      "success",
+		"credit_line": line.ToDTO(),
+		"account":     account.ToDTO(),
+	})
+}
+
+// GetCreditLines возвращает все кредитные линии
+func (lc *CreditLineController) GetCreditLines(c *gin.Context) {
+	lines, err := lc.creditLineService.GetCreditLines()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":       "success",
+		"credit_lines": creditLineDTOs(lines),
+	})
+}
+
+// UpdateLimit изменяет лимит кредитной линии
+func (lc *CreditLineController) UpdateLimit(c *gin.Context) {
+	lineID, ok := lc.lineID(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.UpdateCreditLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	line, err := lc.creditLineService.UpdateLimit(lineID, req.CreditLimit)
+	if err != nil {
+		c.JSON(creditLineErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":      "success",
+		"credit_line": line.ToDTO(),
+	})
+}
+
+// userID извлекает ID пользователя из контекста
+func (lc *CreditLineController) userID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": "user not found",
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// lineID извлекает ID кредитной линии из пути
+func (lc *CreditLineController) lineID(c *gin.Context) (uint, bool) {
+	lineID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid credit line ID",
+		})
+		return 0, false
+	}
+	return uint(lineID), true
+}
+
+// creditLineDTOs преобразует список кредитных линий в DTO
+func creditLineDTOs(lines []domain.CreditLine) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(lines))
+	for i := range lines {
+		result = append(result, lines[i].ToDTO())
+	}
+	return result
+}
+
+// creditLineErrorStatus подбирает HTTP-статус для ошибки операции с кредитной линией
+func creditLineErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCreditLineNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidCreditLine), errors.Is(err, domain.ErrCreditLimitBelowDebt):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
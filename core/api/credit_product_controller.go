@@ -0,0 +1,137 @@
+package api
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreditProductController struct {
+	creditProductService services.CreditProductService
+}
+
+func CreateCreditProductController(creditProductService services.CreditProductService) *CreditProductController {
+	return &CreditProductController{creditProductService: creditProductService}
+}
+
+// GetProducts возвращает кредитные продукты, доступные для оформления
+func (pc *CreditProductController) GetProducts(c *gin.Context) {
+	products, err := pc.creditProductService.GetActiveProducts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"products": creditProductDTOs(products),
+	})
+}
+
+// GetAllProducts возвращает все кредитные продукты (только для админа)
+func (pc *CreditProductController) GetAllProducts(c *gin.Context) {
+	products, err := pc.creditProductService.GetAllProducts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"products": creditProductDTOs(products),
+	})
+}
+
+// CreateProduct создает кредитный продукт (только для админа)
+func (pc *CreditProductController) CreateProduct(c *gin.Context) {
+	var product domain.CreditProduct
+	if err := c.ShouldBindJSON(&product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	if err := pc.creditProductService.CreateProduct(&product); err != nil {
+		c.JSON(creditProductErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"product": product.ToDTO(),
+	})
+}
+
+// UpdateProduct изменяет кредитный продукт (только для админа)
+func (pc *CreditProductController) UpdateProduct(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid product ID",
+		})
+		return
+	}
+
+	var update domain.CreditProduct
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	product, err := pc.creditProductService.UpdateProduct(uint(id), &update)
+	if err != nil {
+		c.JSON(creditProductErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"product": product.ToDTO(),
+	})
+}
+
+// creditProductDTOs преобразует список продуктов в DTO
+func creditProductDTOs(products []domain.CreditProduct) []map[string]interface{} {
+	dtos := make([]map[string]interface{}, 0, len(products))
+	for i := range products {
+		dtos = append(dtos, products[i].ToDTO())
+	}
+	return dtos
+}
+
+// creditProductErrorStatus подбирает HTTP-статус для ошибки операции с кредитным продуктом
+func creditProductErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCreditProduct):
+		return http.StatusBadRequest
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dbaccess.ErrAlreadyExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreditRestructuringController struct {
+	restructuringService services.CreditRestructuringService
+}
+
+func CreateCreditRestructuringController(restructuringService services.CreditRestructuringService) *CreditRestructuringController {
+	return &CreditRestructuringController{restructuringService: restructuringService}
+}
+
+// CreateRestructuring оформляет заявку на реструктуризацию кредита (оператор)
+func (rc *CreditRestructuringController) CreateRestructuring(c *gin.Context) {
+	operatorID, ok := rc.userID(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.CreditRestructuringRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	restructuring, plan, err := rc.restructuringService.CreateRestructuring(operatorID, &req)
+	if err != nil {
+		c.JSON(restructuringErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":        "success",
+		"restructuring": restructuring.ToDTO(),
+		"preview":       plan.ToDTO(),
+	})
+}
+
+// GetRestructurings возвращает очередь заявок (по умолчанию ожидающие решения)
+func (rc *CreditRestructuringController) GetRestructurings(c *gin.Context) {
+	restructurings, err := rc.restructuringService.GetRestructurings(domain.RestructuringStatus(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":         "success",
+		"restructurings": restructuringDTOs(restructurings),
+	})
+}
+
+// GetRestructuring возвращает заявку и, если она ждет решения, расчет нового графика
+func (rc *CreditRestructuringController) GetRestructuring(c *gin.Context) {
+	restructuringID, ok := rc.restructuringID(c)
+	if !ok {
+		return
+	}
+
+	restructuring, plan, err := rc.restructuringService.GetRestructuring(restructuringID)
+	if err != nil {
+		c.JSON(restructuringErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"status":        "success",
+		"restructuring": restructuring.ToDTO(),
+	}
+	if plan != nil {
+		response["preview"] = plan.ToDTO()
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCreditHistory возвращает реструктуризации кредита и прежние версии графика
+func (rc *CreditRestructuringController) GetCreditHistory(c *gin.Context) {
+	creditID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid credit ID",
+		})
+		return
+	}
+
+	restructurings, versions, err := rc.restructuringService.GetCreditHistory(uint(creditID))
+	if err != nil {
+		c.JSON(restructuringErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	dtos := make([]map[string]interface{}, 0, len(versions))
+	for i := range versions {
+		dtos = append(dtos, versions[i].ToDTO())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":            "success",
+		"restructurings":    restructuringDTOs(restructurings),
+		"schedule_versions": dtos,
+	})
+}
+
+// Approve одобряет реструктуризацию и перестраивает график (менеджер)
+func (rc *CreditRestructuringController) Approve(c *gin.Context) {
+	rc.managerDecision(c, rc.restructuringService.Approve)
+}
+
+// Reject отклоняет реструктуризацию (менеджер)
+func (rc *CreditRestructuringController) Reject(c *gin.Context) {
+	rc.managerDecision(c, rc.restructuringService.Reject)
+}
+
+// managerDecision фиксирует решение менеджера с необязательным комментарием
+func (rc *CreditRestructuringController) managerDecision(c *gin.Context, decide func(restructuringID, managerID uint, comment string) (*domain.CreditRestructuring, error)) {
+	managerID, ok := rc.userID(c)
+	if !ok {
+		return
+	}
+	restructuringID, ok := rc.restructuringID(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.CreditRestructuringDecisionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "invalid request body",
+			})
+			return
+		}
+	}
+
+	restructuring, err := decide(restructuringID, managerID, req.Comment)
+	if err != nil {
+		c.JSON(restructuringErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":        "success",
+		"restructuring": restructuring.ToDTO(),
+	})
+}
+
+// userID извлекает ID текущего сотрудника
+func (rc *CreditRestructuringController) userID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": "user not found",
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// restructuringID извлекает ID заявки на реструктуризацию из пути
+func (rc *CreditRestructuringController) restructuringID(c *gin.Context) (uint, bool) {
+	restructuringID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid restructuring ID",
+		})
+		return 0, false
+	}
+	return uint(restructuringID), true
+}
+
+// restructuringDTOs преобразует список заявок на реструктуризацию в DTO
+func restructuringDTOs(restructurings []domain.CreditRestructuring) []map[string]interface{} {
+	dtos := make([]map[string]interface{}, 0, len(restructurings))
+	for i := range restructurings {
+		dtos = append(dtos, restructurings[i].ToDTO())
+	}
+	return dtos
+}
+
+// restructuringErrorStatus подбирает HTTP-статус для ошибки реструктуризации
+func restructuringErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrRestructuringSelfApproval):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrRestructuringPending), errors.Is(err, domain.ErrRestructuringNotPending),
+		errors.Is(err, domain.ErrCreditNotActive):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidRestructuring), errors.Is(err, domain.ErrInvalidTerm):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CurrencyRateController struct {
+	currencyRateService services.CurrencyRateService
+	clock               domain.Clock
+}
+
+func CreateCurrencyRateController(currencyRateService services.CurrencyRateService, clock domain.Clock) *CurrencyRateController {
+	return &CurrencyRateController{currencyRateService: currencyRateService, clock: clock}
+}
+
+// GetRates возвращает официальные курсы ЦБ РФ на дату date (ГГГГ-ММ-ДД, по умолчанию
+// сегодня), с currency - курс одной валюты
+func (cc *CurrencyRateController) GetRates(c *gin.Context) {
+	now := cc.clock.Now()
+	date := now
+	if value := c.Query("date"); value != "" {
+		parsed, err := time.Parse(domain.CurrencyRateDateLayout, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "invalid date",
+			})
+			return
+		}
+		date = parsed
+	}
+	if err := domain.ValidateCurrencyRateDate(date, now); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(c.Query("currency")))
+
+	rates, err := cc.currencyRateService.GetRates(date, currency)
+	if err != nil {
+		c.JSON(currencyRateErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	result := make([]map[string]interface{}, 0, len(rates))
+	for i := range rates {
+		result = append(result, rates[i].ToDTO())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"date":    date.Format(domain.CurrencyRateDateLayout),
+		"on_date": rates[0].Date,
+		"rates":   result,
+	})
+}
+
+// currencyRateErrorStatus подбирает HTTP-статус для ошибки курсов валют
+func currencyRateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCurrencyRate):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrCurrencyRateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCurrencyRatesUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DepositController struct {
+	depositService services.DepositService
+}
+
+func CreateDepositController(depositService services.DepositService) *DepositController {
+	return &DepositController{depositService: depositService}
+}
+
+// GetProducts возвращает вклады, доступные для открытия
+func (dc *DepositController) GetProducts(c *gin.Context) {
+	products, err := dc.depositService.GetActiveProducts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"products": depositProductDTOs(products),
+	})
+}
+
+// GetAllProducts возвращает все продукты вкладов (только для админа)
+func (dc *DepositController) GetAllProducts(c *gin.Context) {
+	products, err := dc.depositService.GetAllProducts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"products": depositProductDTOs(products),
+	})
+}
+
+// CreateProduct создает продукт вклада (только для админа)
+func (dc *DepositController) CreateProduct(c *gin.Context) {
+	var product domain.DepositProduct
+	if err := c.ShouldBindJSON(&product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	if err := dc.depositService.CreateProduct(&product); err != nil {
+		c.JSON(depositErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"product": product.ToDTO(),
+	})
+}
+
+// UpdateProduct изменяет продукт вклада (только для админа)
+func (dc *DepositController) UpdateProduct(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid product ID",
+		})
+		return
+	}
+
+	var update domain.DepositProduct
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	product, err := dc.depositService.UpdateProduct(uint(id), &update)
+	if err != nil {
+		c.JSON(depositErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"product": product.ToDTO(),
+	})
+}
+
+// OpenDeposit открывает срочный вклад
+func (dc *DepositController) OpenDeposit(c *gin.Context) {
+	userID, ok := dc.userID(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.OpenTermDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	deposit, err := dc.depositService.OpenDeposit(userID, &req)
+	if err != nil {
+		c.JSON(depositErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"deposit": deposit.ToDTO(),
+	})
+}
+
+// GetUserDeposits возвращает вклады текущего пользователя
+func (dc *DepositController) GetUserDeposits(c *gin.Context) {
+	userID, ok := dc.userID(c)
+	if !ok {
+		return
+	}
+
+	deposits, err := dc.depositService.GetUserDeposits(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	dtos := make([]map[string]interface{}, 0, len(deposits))
+	for i := range deposits {
+		dtos = append(dtos, deposits[i].ToDTO())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"deposits": dtos,
+	})
+}
+
+// GetUserDeposit возвращает вклад текущего пользователя
+func (dc *DepositController) GetUserDeposit(c *gin.Context) {
+	userID, ok := dc.userID(c)
+	if !ok {
+		return
+	}
+	depositID, ok := dc.depositID(c)
+	if !ok {
+		return
+	}
+
+	deposit, err := dc.depositService.GetUserDeposit(depositID, userID)
+	if err != nil {
+		c.JSON(depositErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"deposit": deposit.ToDTO(),
+	})
+}
+
+// CloseDeposit досрочно расторгает вклад текущего пользователя
+func (dc *DepositController) CloseDeposit(c *gin.Context) {
+	userID, ok := dc.userID(c)
+	if !ok {
+		return
+	}
+	depositID, ok := dc.depositID(c)
+	if !ok {
+		return
+	}
+
+	deposit, amount, err := dc.depositService.CloseDeposit(depositID, userID)
+	if err != nil {
+		c.JSON(depositErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":          "success",
+		"deposit":         deposit.ToDTO(),
+		"returned_amount": amount,
+	})
+}
+
+// userID извлекает ID пользователя из контекста
+func (dc *DepositController) userID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": "user not found",
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// depositID извлекает ID вклада из пути
+func (dc *DepositController) depositID(c *gin.Context) (uint, bool) {
+	depositID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid deposit ID",
+		})
+		return 0, false
+	}
+	return uint(depositID), true
+}
+
+// depositProductDTOs преобразует список продуктов вкладов в DTO
+func depositProductDTOs(products []domain.DepositProduct) []map[string]interface{} {
+	dtos := make([]map[string]interface{}, 0, len(products))
+	for i := range products {
+		dtos = append(dtos, products[i].ToDTO())
+	}
+	return dtos
+}
+
+// depositErrorStatus подбирает HTTP-статус для ошибки операции со вкладом
+func depositErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrTermDepositNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrTermDepositClosed), errors.Is(err, dbaccess.ErrAlreadyExists):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInsufficientFunds):
+		return http.StatusPaymentRequired
+	case errors.Is(err, domain.ErrInvalidDepositProduct), errors.Is(err, domain.ErrDepositProductInactive),
+		errors.Is(err, domain.ErrDepositTermsOutOfProduct), errors.Is(err, domain.ErrInvalidTermDeposit),
+		errors.Is(err, domain.ErrTermDepositSourceType):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrKeyRateUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DisputeController struct {
+	disputeService services.DisputeService
+}
+
+func CreateDisputeController(disputeService services.DisputeService) *DisputeController {
+	return &DisputeController{disputeService: disputeService}
+}
+
+// OpenDispute открывает спор по операции по карте
+func (dc *DisputeController) OpenDispute(c *gin.Context) {
+	userID, ok := dc.userID(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.OpenDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	dispute, err := dc.disputeService.OpenDispute(userID, &req)
+	if err != nil {
+		c.JSON(disputeErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"dispute": dispute.ToDTO(),
+	})
+}
+
+// GetUserDisputes возвращает споры текущего пользователя
+func (dc *DisputeController) GetUserDisputes(c *gin.Context) {
+	userID, ok := dc.userID(c)
+	if !ok {
+		return
+	}
+
+	disputes, err := dc.disputeService.GetUserDisputes(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"disputes": disputeDTOs(disputes),
+	})
+}
+
+// GetUserDispute возвращает спор текущего пользователя с историей
+func (dc *DisputeController) GetUserDispute(c *gin.Context) {
+	userID, ok := dc.userID(c)
+	if !ok {
+		return
+	}
+	disputeID, ok := dc.disputeID(c)
+	if !ok {
+		return
+	}
+
+	dispute, err := dc.disputeService.GetUserDispute(disputeID, userID)
+	if err != nil {
+		c.JSON(disputeErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"dispute": dispute.ToDTO(),
+	})
+}
+
+// AddAttachment прикладывает к спору описание файла
+func (dc *DisputeController) AddAttachment(c *gin.Context) {
+	userID, ok := dc.userID(c)
+	if !ok {
+		return
+	}
+	disputeID, ok := dc.disputeID(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.DisputeAttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	dispute, err := dc.disputeService.AddAttachment(disputeID, userID, &req)
+	if err != nil {
+		c.JSON(disputeErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  "success",
+		"dispute": dispute.ToDTO(),
+	})
+}
+
+// GetDisputes возвращает очередь споров для оператора (по умолчанию все нерешенные)
+func (dc *DisputeController) GetDisputes(c *gin.Context) {
+	disputes, err := dc.disputeService.GetDisputes(domain.DisputeStatus(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"disputes": disputeDTOs(disputes),
+	})
+}
+
+// GetDispute возвращает спор для оператора
+func (dc *DisputeController) GetDispute(c *gin.Context) {
+	disputeID, ok := dc.disputeID(c)
+	if !ok {
+		return
+	}
+
+	dispute, err := dc.disputeService.GetDispute(disputeID)
+	if err != nil {
+		c.JSON(disputeErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"dispute": dispute.ToDTO(),
+	})
+}
+
+// StartReview берет спор в работу
+func (dc *DisputeController) StartReview(c *gin.Context) {
+	dc.operatorAction(c, dc.disputeService.StartReview)
+}
+
+// PostProvisionalCredit зачисляет клиенту оспариваемую сумму до решения по спору
+func (dc *DisputeController) PostProvisionalCredit(c *gin.Context) {
+	dc.operatorAction(c, dc.disputeService.PostProvisionalCredit)
+}
+
+// ResolveDispute фиксирует решение по спору
+func (dc *DisputeController) ResolveDispute(c *gin.Context) {
+	operatorID, ok := dc.userID(c)
+	if !ok {
+		return
+	}
+	disputeID, ok := dc.disputeID(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.ResolveDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	dispute, err := dc.disputeService.ResolveDispute(disputeID, operatorID, &req)
+	if err != nil {
+		c.JSON(disputeErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"dispute": dispute.ToDTO(),
+	})
+}
+
+// operatorAction выполняет действие оператора по спору с необязательным комментарием
+func (dc *DisputeController) operatorAction(c *gin.Context, action func(disputeID, operatorID uint, comment string) (*domain.Dispute, error)) {
+	operatorID, ok := dc.userID(c)
+	if !ok {
+		return
+	}
+	disputeID, ok := dc.disputeID(c)
+	if !ok {
+		return
+	}
+
+	var req payloads.DisputeActionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": "invalid request body",
+			})
+			return
+		}
+	}
+
+	dispute, err := action(disputeID, operatorID, req.Comment)
+	if err != nil {
+		c.JSON(disputeErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"dispute": dispute.ToDTO(),
+	})
+}
+
+// userID извлекает ID текущего пользователя
+func (dc *DisputeController) userID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": "user not found",
+		})
+		return 0, false
+	}
+	return userID.(uint), true
+}
+
+// disputeID извлекает ID спора из пути
+func (dc *DisputeController) disputeID(c *gin.Context) (uint, bool) {
+	disputeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid dispute ID",
+		})
+		return 0, false
+	}
+	return uint(disputeID), true
+}
+
+// disputeDTOs преобразует список споров в DTO
+func disputeDTOs(disputes []domain.Dispute) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(disputes))
+	for i := range disputes {
+		result = append(result, disputes[i].ToDTO())
+	}
+	return result
+}
+
+// disputeErrorStatus подбирает HTTP-статус для ошибки операции со спором
+func disputeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCardNotOwned), errors.Is(err, domain.ErrDisputeNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrDisputeExists), errors.Is(err, domain.ErrDisputeClosed),
+		errors.Is(err, domain.ErrProvisionalCreditPosted), errors.Is(err, domain.ErrDisputedTransactionVoided):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidDispute), errors.Is(err, domain.ErrDisputeNotAllowed),
+		errors.Is(err, domain.ErrDisputeWindowExpired), errors.Is(err, domain.ErrInvalidDisputeResolution),
+		errors.Is(err, domain.ErrInvalidDisputeAttachment), errors.Is(err, domain.ErrDisputeAttachmentsExceeded):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
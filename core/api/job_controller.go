@@ -0,0 +1,128 @@
+package api
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobController struct {
+	jobService services.JobService
+}
+
+func CreateJobController(jobService services.JobService) *JobController {
+	return &JobController{jobService: jobService}
+}
+
+// GetJobs возвращает фоновые задачи с расписанием и итогом последнего запуска (только для админа)
+func (jc *JobController) GetJobs(c *gin.Context) {
+	jobs, err := jc.jobService.GetJobs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	dtos := make([]map[string]interface{}, 0, len(jobs))
+	for i := range jobs {
+		dtos = append(dtos, jobs[i].ToDTO())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"jobs":   dtos,
+	})
+}
+
+// GetJob возвращает задачу и историю последних запусков
+func (jc *JobController) GetJob(c *gin.Context) {
+	job, runs, err := jc.jobService.GetJob(c.Param("name"))
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	dtos := make([]map[string]interface{}, 0, len(runs))
+	for i := range runs {
+		dtos = append(dtos, runs[i].ToDTO())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"job":    job.ToDTO(),
+		"runs":   dtos,
+	})
+}
+
+// Pause приостанавливает запуски задачи по расписанию
+func (jc *JobController) Pause(c *gin.Context) {
+	jc.updateJob(c, jc.jobService.Pause)
+}
+
+// Resume возобновляет запуски задачи по расписанию
+func (jc *JobController) Resume(c *gin.Context) {
+	jc.updateJob(c, jc.jobService.Resume)
+}
+
+// Trigger запускает задачу немедленно и возвращает результат запуска
+func (jc *JobController) Trigger(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"status":  "error",
+			"message": "user not found",
+		})
+		return
+	}
+
+	run, err := jc.jobService.Trigger(c.Param("name"), userID.(uint))
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"run":    run.ToDTO(),
+	})
+}
+
+// updateJob меняет состояние задачи и возвращает ее
+func (jc *JobController) updateJob(c *gin.Context, update func(name string) (*domain.Job, error)) {
+	job, err := update(c.Param("name"))
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"job":    job.ToDTO(),
+	})
+}
+
+// jobErrorStatus подбирает HTTP-статус для ошибки управления фоновой задачей
+func jobErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrJobRunning), errors.Is(err, domain.ErrJobAlreadyPaused),
+		errors.Is(err, domain.ErrJobNotPaused):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PenaltyPolicyController struct {
+	penaltyPolicyService services.PenaltyPolicyService
+}
+
+func CreatePenaltyPolicyController(penaltyPolicyService services.PenaltyPolicyService) *PenaltyPolicyController {
+	return &PenaltyPolicyController{penaltyPolicyService: penaltyPolicyService}
+}
+
+// GetPolicies возвращает политики неустойки (только для админа)
+func (pc *PenaltyPolicyController) GetPolicies(c *gin.Context) {
+	policies, err := pc.penaltyPolicyService.GetPolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	dtos := make([]map[string]interface{}, 0, len(policies))
+	for i := range policies {
+		dtos = append(dtos, policies[i].ToDTO())
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "success",
+		"policies": dtos,
+	})
+}
+
+// CreatePolicy создает политику неустойки (только для админа)
+func (pc *PenaltyPolicyController) CreatePolicy(c *gin.Context) {
+	var policy domain.PenaltyPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	if err := pc.penaltyPolicyService.CreatePolicy(&policy); err != nil {
+		c.JSON(penaltyPolicyErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status": "success",
+		"policy": policy.ToDTO(),
+	})
+}
+
+// UpdatePolicy изменяет политику неустойки (только для админа)
+func (pc *PenaltyPolicyController) UpdatePolicy(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid policy ID",
+		})
+		return
+	}
+
+	var update domain.PenaltyPolicy
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": "invalid request body",
+		})
+		return
+	}
+
+	policy, err := pc.penaltyPolicyService.UpdatePolicy(uint(id), &update)
+	if err != nil {
+		c.JSON(penaltyPolicyErrorStatus(err), gin.H{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"policy": policy.ToDTO(),
+	})
+}
+
+// penaltyPolicyErrorStatus подбирает HTTP-статус для ошибки операции с политикой неустойки
+func penaltyPolicyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPenaltyPolicy):
+		return http.StatusBadRequest
+	case errors.Is(err, dbaccess.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dbaccess.ErrAlreadyExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
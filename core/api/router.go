@@ -3,8 +3,12 @@ package api
 import (
 	"FinanceGolang/core/dbaccess"
 	"FinanceGolang/core/dbcore"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/iso8583"
 	"FinanceGolang/core/security"
 	"FinanceGolang/core/services"
+	"FinanceGolang/core/settings"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"time"
@@ -31,12 +35,42 @@ const (
 	APIPathTransfer     = "/transfer"
 	APIPathTransactions = "/transactions"
 	APIPathCards        = "/cards"
+	APIPathPIN          = "/pin"
+	APIPathUnblock      = "/unblock"
+	APIPathAuthorize    = "/authorize"
+	APIPathReveal       = "/reveal"
+	APIPathVirtual      = "/virtual"
+	APIPathProducts     = "/products"
+	APIPathControls     = "/controls"
 	APIPathCredits      = "/credits"
 	APIPathSchedule     = "/schedule"
 	APIPathPayment      = "/payment"
+	APIPathCalculate    = "/calculate"
+	APIPathEarlyRepay   = "/early-repayment"
+	APIPathFullRepay    = "/full-repayment"
+	APIPathCertificate  = "/closing-certificate"
+	APIPathAgreement    = "/agreement"
 	APIPathAnalytics    = "/analytics"
 	APIPathForecast     = "/forecast"
 	APIPathKeyRate      = "/keyrate"
+	APIPathDisputes     = "/disputes"
+	APIPathAttachments  = "/attachments"
+	APIPathApplications = "/credit-applications"
+	APIPathCreditProds  = "/credit-products"
+	APIPathCreditLines  = "/credit-lines"
+	APIPathStatements   = "/statements"
+	APIPathLimit        = "/limit"
+	APIPathDeposits     = "/deposits"
+	APIPathDepositProds = "/deposit-products"
+	APIPathClose        = "/close"
+	APIPathRestructure  = "/restructurings"
+	APIPathCollections  = "/collections"
+	APIPathPromise      = "/promise"
+	APIPathJobs         = "/jobs"
+	APIPathClock        = "/clock"
+	APIPathCalendar     = "/calendar"
+	APIPathHistory      = "/history"
+	APIPathCurrencyRate = "/currency-rates"
 )
 
 // Константы для сообщений об ошибках
@@ -45,11 +79,49 @@ const (
 	ErrInternalServer = "Внутренняя ошибка сервера"
 )
 
-type Router struct{}
+type Router struct {
+	clock         domain.Clock
+	simulated     *domain.SimulatedClock       // nil, если моделирование времени выключено
+	keyRates      services.KeyRateService      // общий кэш ключевой ставки
+	currencyRates services.CurrencyRateService // общий, чтобы курсы на прошедшие даты запрашивались у ЦБ РФ один раз
+}
 
-// NewRouter создает новый экземпляр маршрутизатора
+// NewRouter создает новый экземпляр маршрутизатора. Все сервисы получают
+// общие часы: системные или, вне production, смоделированные для тестирования
 func NewRouter() *Router {
-	return &Router{}
+	cfg := settings.Get()
+	router := &Router{clock: domain.SystemClock}
+	if cfg.ClockSimulation {
+		if cfg.AppEnv == "production" {
+			logrus.Warn("Моделирование времени недоступно в production, используется системное время")
+		} else {
+			simulated := domain.NewSimulatedClock()
+			dbcore.UseClock(simulated)
+			logrus.Warn("Включено моделирование времени: часы можно переводить через /api/admin/clock")
+			router.clock = simulated
+			router.simulated = simulated
+		}
+	}
+	router.keyRates = newKeyRateService(cfg, router.clock)
+	router.currencyRates = services.CurrencyRateServiceInstance(
+		services.NewCBRCurrencyRateProvider(cfg.CurrencyRatesCBRURL, cfg.CurrencyRatesTimeout),
+		dbaccess.CurrencyRateRepositoryInstance(dbcore.DB),
+		router.clock,
+	)
+	return router
+}
+
+// newKeyRateService создает сервис ключевой ставки с источником из настроек.
+// Сервис один на приложение, чтобы кэш ставки был общим для всех потребителей
+func newKeyRateService(cfg *settings.Config, clock domain.Clock) services.KeyRateService {
+	provider := services.NewKeyRateProvider(cfg.KeyRateProvider, cfg.KeyRateCBRURL, cfg.KeyRateStubFile, cfg.KeyRateTimeout)
+	if provider.Source() == domain.KeyRateSourceStub {
+		logrus.Warn("Ключевая ставка берется из локального файла, а не от ЦБ РФ")
+	}
+	return services.KeyRateServiceInstance(provider, dbaccess.KeyRateRepositoryInstance(dbcore.DB), services.KeyRateOptions{
+		CacheTTL:     cfg.KeyRateCacheTTL,
+		MaxStaleness: cfg.KeyRateMaxStaleness,
+	}, clock)
 }
 
 // createAuthService создает сервис аутентификации
@@ -68,38 +140,169 @@ func (r *Router) createUserService() services.UserService {
 func (r *Router) createAccountService() services.AccountService {
 	accountRepo := dbaccess.AccountRepositoryInstance(dbcore.DB)
 	transactionRepo := dbaccess.TransactionRepositoryInstance(dbcore.DB)
-	return services.AccountServiceInstance(accountRepo, transactionRepo)
+	creditLineRepo := dbaccess.CreditLineRepositoryInstance(dbcore.DB)
+	return services.AccountServiceInstance(accountRepo, transactionRepo, creditLineRepo)
 }
 
 // createCardService создает сервис карт
 func (r *Router) createCardService() services.CardService {
 	cardRepo := dbaccess.CardRepositoryInstance(dbcore.DB)
 	accountRepo := dbaccess.AccountRepositoryInstance(dbcore.DB)
+	transactionRepo := dbaccess.TransactionRepositoryInstance(dbcore.DB)
+	userRepo := dbaccess.UserRepositoryInstance(dbcore.DB)
+	auditRepo := dbaccess.AuditRepositoryInstance(dbcore.DB)
+	cardProductRepo := dbaccess.CardProductRepositoryInstance(dbcore.DB)
+	creditLineRepo := dbaccess.CreditLineRepositoryInstance(dbcore.DB)
 
 	// Читаем публичный ключ из файла
-	publicKeyBytes, err := ioutil.ReadFile("public_key.asc")
+	publicKeyBytes, err := ioutil.ReadFile(settings.Get().PGPPublicKeyPath)
 	if err != nil {
 		logrus.WithError(err).Error("Ошибка чтения публичного ключа")
 		publicKeyBytes = []byte("card_public_key_" + time.Now().Format("20060102150405"))
 	}
 
-	// Читаем HMAC секрет из файла
-	hmacSecretBytes, err := ioutil.ReadFile("private_key.asc")
+	// Ключ HMAC для отпечатков номеров карт задается в конфигурации
+	hmacSecret := []byte(settings.Get().CardHMACSecret)
+
+	return services.CardServiceInstance(cardRepo, accountRepo, transactionRepo, userRepo, auditRepo, cardProductRepo, creditLineRepo, string(publicKeyBytes), hmacSecret, r.clock)
+}
+
+// CreateISO8583Server создает TCP-шлюз ISO 8583 для операций по картам
+func (r *Router) CreateISO8583Server() (*iso8583.Server, error) {
+	cfg := settings.Get()
+	spec, err := iso8583.LoadSpec(cfg.ISO8583SpecPath)
 	if err != nil {
-		logrus.WithError(err).Error("Ошибка чтения приватного ключа")
-		hmacSecretBytes = []byte("card_hmac_secret_" + time.Now().Format("20060102150405"))
+		return nil, err
 	}
+	addr := fmt.Sprintf("%s:%d", cfg.ISO8583Host, cfg.ISO8583Port)
+	return iso8583.NewServer(addr, spec, services.CardGatewayInstance(r.createCardService())), nil
+}
 
-	return services.CardServiceInstance(cardRepo, accountRepo, string(publicKeyBytes), hmacSecretBytes)
+// createDisputeService создает сервис споров по операциям
+func (r *Router) createDisputeService() services.DisputeService {
+	disputeRepo := dbaccess.DisputeRepositoryInstance(dbcore.DB)
+	transactionRepo := dbaccess.TransactionRepositoryInstance(dbcore.DB)
+	cardRepo := dbaccess.CardRepositoryInstance(dbcore.DB)
+	return services.DisputeServiceInstance(disputeRepo, transactionRepo, cardRepo, r.clock)
+}
+
+// createCardProductService создает сервис карточных продуктов
+func (r *Router) createCardProductService() services.CardProductService {
+	cardProductRepo := dbaccess.CardProductRepositoryInstance(dbcore.DB)
+	return services.CardProductServiceInstance(cardProductRepo)
 }
 
 // createCreditService создает сервис кредитов
 func (r *Router) createCreditService() services.CreditService {
 	return services.CreditServiceInstance(
+		dbaccess.CreditRepositoryInstance(dbcore.DB),
+		dbaccess.AccountRepositoryInstance(dbcore.DB),
+		dbaccess.TransactionRepositoryInstance(dbcore.DB),
+		dbaccess.CreditProductRepositoryInstance(dbcore.DB),
+		dbaccess.PenaltyPolicyRepositoryInstance(dbcore.DB),
+		dbaccess.UserRepositoryInstance(dbcore.DB),
+		r.keyRates,
+		dbaccess.CalendarRepositoryInstance(dbcore.DB),
+		r.clock,
+	)
+}
+
+// createCreditProductService создает сервис кредитных продуктов
+func (r *Router) createCreditProductService() services.CreditProductService {
+	return services.CreditProductServiceInstance(
+		dbaccess.CreditProductRepositoryInstance(dbcore.DB),
+		dbaccess.PenaltyPolicyRepositoryInstance(dbcore.DB),
+	)
+}
+
+// createCreditApplicationService создает сервис заявок на кредит
+func (r *Router) createCreditApplicationService() services.CreditApplicationService {
+	return services.CreditApplicationServiceInstance(
+		dbaccess.CreditApplicationRepositoryInstance(dbcore.DB),
+		dbaccess.CreditRepositoryInstance(dbcore.DB),
+		dbaccess.AccountRepositoryInstance(dbcore.DB),
+		dbaccess.TransactionRepositoryInstance(dbcore.DB),
+		r.createCreditService(),
+		r.clock,
+	)
+}
+
+// createCreditRestructuringService создает сервис реструктуризации кредитов
+func (r *Router) createCreditRestructuringService() services.CreditRestructuringService {
+	return services.CreditRestructuringServiceInstance(
+		dbaccess.CreditRestructuringRepositoryInstance(dbcore.DB),
+		dbaccess.CreditRepositoryInstance(dbcore.DB),
+		r.createCreditService(),
+		dbaccess.CalendarRepositoryInstance(dbcore.DB),
+		r.clock,
+	)
+}
+
+// createCollectionService создает сервис взыскания просроченной задолженности
+func (r *Router) createCollectionService() services.CollectionService {
+	return services.CollectionServiceInstance(
+		dbaccess.DunningStageRepositoryInstance(dbcore.DB),
+		dbaccess.CollectionCaseRepositoryInstance(dbcore.DB),
+		dbaccess.CreditRepositoryInstance(dbcore.DB),
+		dbaccess.UserRepositoryInstance(dbcore.DB),
+		r.createCreditService(),
+		services.NewExternalService("", 0, "", "", ""),
+		r.clock,
+	)
+}
+
+// createScheduler создает шедулер с операциями фоновых задач
+func (r *Router) createScheduler(disputeService services.DisputeService) *services.Scheduler {
+	return services.NewScheduler(
 		dbaccess.CreditRepositoryInstance(dbcore.DB),
 		dbaccess.AccountRepositoryInstance(dbcore.DB),
 		dbaccess.TransactionRepositoryInstance(dbcore.DB),
 		services.NewExternalService("", 0, "", "", ""),
+		r.keyRates,
+		r.currencyRates,
+		r.createCardService(),
+		disputeService,
+		r.clock,
+	)
+}
+
+// createJobService создает сервис фоновых задач шедулера
+func (r *Router) createJobService(scheduler *services.Scheduler) services.JobService {
+	cfg := settings.Get()
+	return services.JobServiceInstance(dbaccess.JobRepositoryInstance(dbcore.DB), scheduler.Jobs(), services.JobOptions{
+		Instance:     cfg.JobsInstanceID,
+		PollInterval: cfg.JobsPollInterval,
+		LeaseTTL:     cfg.JobsLeaseTTL,
+		Schedules:    cfg.JobSchedules,
+		Clock:        r.clock,
+	})
+}
+
+// CreateJobService создает сервис фоновых задач для запуска задач по расписанию
+func (r *Router) CreateJobService() services.JobService {
+	return r.createJobService(r.createScheduler(r.createDisputeService()))
+}
+
+// createCreditLineService создает сервис кредитных линий
+func (r *Router) createCreditLineService() services.CreditLineService {
+	return services.CreditLineServiceInstance(
+		dbaccess.CreditLineRepositoryInstance(dbcore.DB),
+		dbaccess.AccountRepositoryInstance(dbcore.DB),
+		dbaccess.TransactionRepositoryInstance(dbcore.DB),
+		dbaccess.UserRepositoryInstance(dbcore.DB),
+		r.clock,
+	)
+}
+
+// createDepositService создает сервис срочных вкладов
+func (r *Router) createDepositService() services.DepositService {
+	return services.DepositServiceInstance(
+		dbaccess.DepositProductRepositoryInstance(dbcore.DB),
+		dbaccess.TermDepositRepositoryInstance(dbcore.DB),
+		dbaccess.AccountRepositoryInstance(dbcore.DB),
+		r.keyRates,
+		dbaccess.CalendarRepositoryInstance(dbcore.DB),
+		r.clock,
 	)
 }
 
@@ -108,7 +311,7 @@ func (r *Router) createAnalyticsService() *services.AnalyticsService {
 	accountRepo := dbaccess.AccountRepositoryInstance(dbcore.DB)
 	transactionRepo := dbaccess.TransactionRepositoryInstance(dbcore.DB)
 	creditRepo := dbaccess.CreditRepositoryInstance(dbcore.DB)
-	return services.NewAnalyticsService(transactionRepo, accountRepo, creditRepo)
+	return services.NewAnalyticsService(transactionRepo, accountRepo, creditRepo, r.clock)
 }
 
 // LoggerMiddleware логирует информацию о запросах
@@ -269,6 +472,7 @@ func (r *Router) RegisterCardRoutes(g *gin.RouterGroup) {
 	authService := r.createAuthService()
 	cardService := r.createCardService()
 	cardController := CreateCardController(cardService)
+	cardProductController := CreateCardProductController(r.createCardProductService())
 
 	g.POST(APIPathCards, security.AuthMiddleware(security.AuthMiddlewareDeps{
 		ValidateUserFromToken: authService.ValidateUserFromToken,
@@ -276,22 +480,71 @@ func (r *Router) RegisterCardRoutes(g *gin.RouterGroup) {
 	g.GET(APIPathCards, security.AuthMiddleware(security.AuthMiddlewareDeps{
 		ValidateUserFromToken: authService.ValidateUserFromToken,
 	}), cardController.GetAllCards)
+	g.POST(APIPathCards+APIPathVirtual, security.AuthMiddleware(security.AuthMiddlewareDeps{
+		ValidateUserFromToken: authService.ValidateUserFromToken,
+	}), cardController.CreateVirtualCard)
+	g.GET(APIPathCards+APIPathProducts, security.AuthMiddleware(security.AuthMiddlewareDeps{
+		ValidateUserFromToken: authService.ValidateUserFromToken,
+	}), cardProductController.GetProducts)
 
 	// Маршрут для получения информации о конкретной карте
 	g.GET(APIPathCards+"/:id", security.AuthMiddleware(security.AuthMiddlewareDeps{
 		ValidateUserFromToken: authService.ValidateUserFromToken,
 	}), cardController.GetCardByID)
+
+	// Маршруты управления PIN-кодом, показа реквизитов и операций по карте
+	cardGroup := g.Group(APIPathCards + "/:id")
+	cardGroup.Use(security.AuthMiddleware(security.AuthMiddlewareDeps{
+		ValidateUserFromToken: authService.ValidateUserFromToken,
+	}))
+	{
+		cardGroup.POST(APIPathPIN, cardController.SetPIN)
+		cardGroup.PUT(APIPathPIN, cardController.ChangePIN)
+		cardGroup.POST(APIPathUnblock, cardController.UnblockCard)
+		cardGroup.POST(APIPathAuthorize, cardController.Authorize)
+		cardGroup.POST(APIPathReveal, cardController.RevealCard)
+		cardGroup.GET(APIPathControls, cardController.GetControls)
+		cardGroup.PUT(APIPathControls, cardController.UpdateControls)
+	}
+}
+
+// RegisterDisputeRoutes регистрирует маршруты споров клиента по операциям
+func (r *Router) RegisterDisputeRoutes(g *gin.RouterGroup) {
+	authService := r.createAuthService()
+	disputeController := CreateDisputeController(r.createDisputeService())
+
+	disputes := g.Group(APIPathDisputes)
+	disputes.Use(security.AuthMiddleware(security.AuthMiddlewareDeps{
+		ValidateUserFromToken: authService.ValidateUserFromToken,
+	}))
+	{
+		disputes.POST("", disputeController.OpenDispute)
+		disputes.GET("", disputeController.GetUserDisputes)
+		disputes.GET("/:id", disputeController.GetUserDispute)
+		disputes.POST("/:id"+APIPathAttachments, disputeController.AddAttachment)
+	}
 }
 
 // RegisterKeyRateRoutes регистрирует маршруты ключевой ставки
 func (r *Router) RegisterKeyRateRoutes(g *gin.RouterGroup) {
 	authService := r.createAuthService()
-	externalService := services.NewExternalService("", 0, "", "", "")
-	cbrController := CreateCbrController(externalService)
+	cbrController := CreateCbrController(r.keyRates, r.clock)
+	authMiddleware := security.AuthMiddleware(security.AuthMiddlewareDeps{
+		ValidateUserFromToken: authService.ValidateUserFromToken,
+	})
+
+	g.GET("", authMiddleware, cbrController.GetKeyRate)
+	g.GET(APIPathHistory, authMiddleware, cbrController.GetKeyRateHistory)
+}
+
+// RegisterCurrencyRateRoutes регистрирует маршруты курсов валют
+func (r *Router) RegisterCurrencyRateRoutes(g *gin.RouterGroup) {
+	authService := r.createAuthService()
+	currencyRateController := CreateCurrencyRateController(r.currencyRates, r.clock)
 
 	g.GET("", security.AuthMiddleware(security.AuthMiddlewareDeps{
 		ValidateUserFromToken: authService.ValidateUserFromToken,
-	}), cbrController.GetKeyRate)
+	}), currencyRateController.GetRates)
 }
 
 // RegisterCreditRoutes регистрирует маршруты кредитов
@@ -300,16 +553,77 @@ func (r *Router) RegisterCreditRoutes(g *gin.RouterGroup) {
 	creditService := r.createCreditService()
 	creditController := CreateCreditController(creditService)
 
+	g.GET(APIPathCreditProds, security.AuthMiddleware(security.AuthMiddlewareDeps{
+		ValidateUserFromToken: authService.ValidateUserFromToken,
+	}), CreateCreditProductController(r.createCreditProductService()).GetProducts)
+
 	credits := g.Group(APIPathCredits)
 	credits.Use(security.AuthMiddleware(security.AuthMiddlewareDeps{
 		ValidateUserFromToken: authService.ValidateUserFromToken,
 	}))
 	{
-		credits.POST("", creditController.CreateCredit)
 		credits.GET("", creditController.GetUserCredits)
+		credits.POST(APIPathCalculate, creditController.CalculateCredit)
 		credits.GET("/:id", creditController.GetCreditByID)
 		credits.GET("/:id"+APIPathSchedule, creditController.GetPaymentSchedule)
 		credits.POST("/:id"+APIPathPayment, creditController.ProcessPayment)
+		credits.POST("/:id"+APIPathEarlyRepay, creditController.EarlyRepayment)
+		credits.POST("/:id"+APIPathFullRepay, creditController.FullRepayment)
+		credits.GET("/:id"+APIPathCertificate, creditController.GetClosingCertificate)
+		credits.GET("/:id"+APIPathAgreement, creditController.GetAgreement)
+	}
+}
+
+// RegisterCreditApplicationRoutes регистрирует маршруты заявок на кредит
+func (r *Router) RegisterCreditApplicationRoutes(g *gin.RouterGroup) {
+	authService := r.createAuthService()
+	applicationController := CreateCreditApplicationController(r.createCreditApplicationService())
+
+	applications := g.Group(APIPathApplications)
+	applications.Use(security.AuthMiddleware(security.AuthMiddlewareDeps{
+		ValidateUserFromToken: authService.ValidateUserFromToken,
+	}))
+	{
+		applications.POST("", applicationController.Apply)
+		applications.GET("", applicationController.GetUserApplications)
+		applications.GET("/:id", applicationController.GetUserApplication)
+	}
+}
+
+// RegisterCreditLineRoutes регистрирует маршруты кредитных линий
+func (r *Router) RegisterCreditLineRoutes(g *gin.RouterGroup) {
+	authService := r.createAuthService()
+	creditLineController := CreateCreditLineController(r.createCreditLineService())
+
+	creditLines := g.Group(APIPathCreditLines)
+	creditLines.Use(security.AuthMiddleware(security.AuthMiddlewareDeps{
+		ValidateUserFromToken: authService.ValidateUserFromToken,
+	}))
+	{
+		creditLines.GET("", creditLineController.GetUserCreditLines)
+		creditLines.GET("/:id", creditLineController.GetUserCreditLine)
+		creditLines.GET("/:id"+APIPathStatements, creditLineController.GetStatements)
+	}
+}
+
+// RegisterDepositRoutes регистрирует маршруты срочных вкладов
+func (r *Router) RegisterDepositRoutes(g *gin.RouterGroup) {
+	authService := r.createAuthService()
+	depositController := CreateDepositController(r.createDepositService())
+
+	g.GET(APIPathDepositProds, security.AuthMiddleware(security.AuthMiddlewareDeps{
+		ValidateUserFromToken: authService.ValidateUserFromToken,
+	}), depositController.GetProducts)
+
+	deposits := g.Group(APIPathDeposits)
+	deposits.Use(security.AuthMiddleware(security.AuthMiddlewareDeps{
+		ValidateUserFromToken: authService.ValidateUserFromToken,
+	}))
+	{
+		deposits.POST("", depositController.OpenDeposit)
+		deposits.GET("", depositController.GetUserDeposits)
+		deposits.GET("/:id", depositController.GetUserDeposit)
+		deposits.POST("/:id"+APIPathClose, depositController.CloseDeposit)
 	}
 }
 
@@ -334,13 +648,24 @@ func (r *Router) RegisterAnalyticsRoutes(g *gin.RouterGroup) {
 // RegisterAdminRoutes регистрирует маршруты админской части
 func (r *Router) RegisterAdminRoutes(g *gin.RouterGroup) {
 	authService := r.createAuthService()
-	scheduler := services.NewScheduler(
-		dbaccess.CreditRepositoryInstance(dbcore.DB),
-		dbaccess.AccountRepositoryInstance(dbcore.DB),
-		dbaccess.TransactionRepositoryInstance(dbcore.DB),
-		services.NewExternalService("", 0, "", "", ""),
-	)
-	adminController := CreateAdminController(scheduler)
+	disputeService := r.createDisputeService()
+	scheduler := r.createScheduler(disputeService)
+	jobService := r.createJobService(scheduler)
+	adminController := CreateAdminController(scheduler, jobService)
+	jobController := CreateJobController(jobService)
+	clockController := CreateClockController(services.ClockServiceInstance(r.clock, r.simulated, jobService))
+	calendarController := CreateCalendarController(
+		services.CalendarServiceInstance(dbaccess.CalendarRepositoryInstance(dbcore.DB), r.clock))
+	cardProductController := CreateCardProductController(r.createCardProductService())
+	penaltyPolicyController := CreatePenaltyPolicyController(
+		services.PenaltyPolicyServiceInstance(dbaccess.PenaltyPolicyRepositoryInstance(dbcore.DB)))
+	disputeController := CreateDisputeController(disputeService)
+	creditProductController := CreateCreditProductController(r.createCreditProductService())
+	applicationController := CreateCreditApplicationController(r.createCreditApplicationService())
+	creditLineController := CreateCreditLineController(r.createCreditLineService())
+	depositController := CreateDepositController(r.createDepositService())
+	restructuringController := CreateCreditRestructuringController(r.createCreditRestructuringService())
+	collectionController := CreateCollectionController(r.createCollectionService())
 
 	admin := g.Group("/admin")
 	admin.Use(security.AuthMiddleware(security.AuthMiddlewareDeps{
@@ -348,7 +673,149 @@ func (r *Router) RegisterAdminRoutes(g *gin.RouterGroup) {
 	}))
 	{
 		admin.GET("/credits", adminController.GetAllCredits)
-		admin.POST("/scheduler/check-payments", adminController.CheckPayments)
+	}
+
+	// Ручной запуск фоновых операций двигает деньги, поэтому доступен только администраторам
+	triggers := admin.Group("/scheduler")
+	triggers.Use(security.AdminMiddleware())
+	{
+		triggers.POST("/check-payments", adminController.CheckPayments)
+		triggers.POST("/process-cards", adminController.ProcessCards)
+		triggers.POST("/accrue-penalties", adminController.AccruePenalties)
+		triggers.POST("/process-disputes", adminController.ProcessDisputes)
+		triggers.POST("/process-credit-lines", adminController.ProcessCreditLines)
+		triggers.POST("/process-deposits", adminController.ProcessDeposits)
+		triggers.POST("/process-dunning", adminController.ProcessDunning)
+	}
+
+	// Рассмотрение споров доступно операторам и администраторам
+	disputes := admin.Group(APIPathDisputes)
+	disputes.Use(security.RoleMiddleware(domain.RoleOperator, domain.RoleAdmin))
+	{
+		disputes.GET("", disputeController.GetDisputes)
+		disputes.GET("/:id", disputeController.GetDispute)
+		disputes.POST("/:id/review", disputeController.StartReview)
+		disputes.POST("/:id/provisional-credit", disputeController.PostProvisionalCredit)
+		disputes.POST("/:id/resolve", disputeController.ResolveDispute)
+	}
+
+	// Рассмотрение заявок на кредит доступно менеджерам и администраторам
+	applications := admin.Group(APIPathApplications)
+	applications.Use(security.RoleMiddleware(domain.RoleManager, domain.RoleAdmin))
+	{
+		applications.GET("", applicationController.GetApplications)
+		applications.GET("/:id", applicationController.GetApplication)
+		applications.POST("/:id/approve", applicationController.Approve)
+		applications.POST("/:id/reject", applicationController.Reject)
+	}
+
+	// Реструктуризацию оформляют операторы, а одобряют менеджеры;
+	// заявки и история графиков доступны обеим ролям
+	restructurings := admin.Group(APIPathRestructure)
+	restructurings.Use(security.RoleMiddleware(domain.RoleOperator, domain.RoleManager, domain.RoleAdmin))
+	{
+		restructurings.GET("", restructuringController.GetRestructurings)
+		restructurings.GET("/:id", restructuringController.GetRestructuring)
+		restructurings.GET(APIPathCredits+"/:id", restructuringController.GetCreditHistory)
+		restructurings.POST("", security.RoleMiddleware(domain.RoleOperator, domain.RoleAdmin),
+			restructuringController.CreateRestructuring)
+		restructurings.POST("/:id/approve", security.RoleMiddleware(domain.RoleManager, domain.RoleAdmin),
+			restructuringController.Approve)
+		restructurings.POST("/:id/reject", security.RoleMiddleware(domain.RoleManager, domain.RoleAdmin),
+			restructuringController.Reject)
+	}
+
+	// Открытие кредитных линий и изменение лимитов доступно менеджерам и администраторам
+	creditLines := admin.Group(APIPathCreditLines)
+	creditLines.Use(security.RoleMiddleware(domain.RoleManager, domain.RoleAdmin))
+	{
+		creditLines.GET("", creditLineController.GetCreditLines)
+		creditLines.POST("", creditLineController.OpenCreditLine)
+		creditLines.PUT("/:id"+APIPathLimit, creditLineController.UpdateLimit)
+	}
+
+	// Управление карточными продуктами доступно только администраторам
+	cardProducts := admin.Group("/card-products")
+	cardProducts.Use(security.AdminMiddleware())
+	{
+		cardProducts.GET("", cardProductController.GetAllProducts)
+		cardProducts.POST("", cardProductController.CreateProduct)
+		cardProducts.PUT("/:id", cardProductController.UpdateProduct)
+	}
+
+	// Управление кредитными продуктами доступно только администраторам
+	creditProducts := admin.Group(APIPathCreditProds)
+	creditProducts.Use(security.AdminMiddleware())
+	{
+		creditProducts.GET("", creditProductController.GetAllProducts)
+		creditProducts.POST("", creditProductController.CreateProduct)
+		creditProducts.PUT("/:id", creditProductController.UpdateProduct)
+	}
+
+	// Управление продуктами вкладов доступно только администраторам
+	depositProducts := admin.Group(APIPathDepositProds)
+	depositProducts.Use(security.AdminMiddleware())
+	{
+		depositProducts.GET("", depositController.GetAllProducts)
+		depositProducts.POST("", depositController.CreateProduct)
+		depositProducts.PUT("/:id", depositController.UpdateProduct)
+	}
+
+	// Управление фоновыми задачами доступно только администраторам
+	jobs := admin.Group(APIPathJobs)
+	jobs.Use(security.AdminMiddleware())
+	{
+		jobs.GET("", jobController.GetJobs)
+		jobs.GET("/:name", jobController.GetJob)
+		jobs.POST("/:name/pause", jobController.Pause)
+		jobs.POST("/:name/resume", jobController.Resume)
+		jobs.POST("/:name/trigger", jobController.Trigger)
+	}
+
+	// Моделирование времени доступно только администраторам и только вне production
+	clock := admin.Group(APIPathClock)
+	clock.Use(security.AdminMiddleware())
+	{
+		clock.GET("", clockController.GetClock)
+		clock.POST("/advance", clockController.Advance)
+		clock.POST("/reset", clockController.Reset)
+	}
+
+	// Ведение производственного календаря доступно только администраторам
+	calendar := admin.Group(APIPathCalendar)
+	calendar.Use(security.AdminMiddleware())
+	{
+		calendar.GET("", calendarController.GetCalendar)
+		calendar.POST("/import", calendarController.Import)
+		calendar.PUT("/:date", calendarController.SetDay)
+		calendar.DELETE("/:date", calendarController.DeleteDay)
+	}
+
+	// Рабочий список взыскания доступен операторам, менеджерам и администраторам
+	collections := admin.Group(APIPathCollections)
+	collections.Use(security.RoleMiddleware(domain.RoleOperator, domain.RoleManager, domain.RoleAdmin))
+	{
+		collections.GET("", collectionController.GetWorklist)
+		collections.GET("/:id", collectionController.GetCase)
+		collections.POST("/:id"+APIPathPromise, collectionController.PromiseToPay)
+	}
+
+	// Управление политиками неустойки по кредитам доступно только администраторам
+	penaltyPolicies := admin.Group("/penalty-policies")
+	penaltyPolicies.Use(security.AdminMiddleware())
+	{
+		penaltyPolicies.GET("", penaltyPolicyController.GetPolicies)
+		penaltyPolicies.POST("", penaltyPolicyController.CreatePolicy)
+		penaltyPolicies.PUT("/:id", penaltyPolicyController.UpdatePolicy)
+	}
+
+	// Настройка стадий взыскания доступна только администраторам
+	dunningStages := admin.Group("/dunning-stages")
+	dunningStages.Use(security.AdminMiddleware())
+	{
+		dunningStages.GET("", collectionController.GetStages)
+		dunningStages.POST("", collectionController.CreateStage)
+		dunningStages.PUT("/:id", collectionController.UpdateStage)
 	}
 }
 
@@ -394,10 +861,15 @@ func (r *Router) InitRoutes() *gin.Engine {
 		r.RegisterUserRoutes(api)
 		r.RegisterAccountRoutes(api)
 		r.RegisterCardRoutes(api)
+		r.RegisterDisputeRoutes(api)
 		r.RegisterCreditRoutes(api)
+		r.RegisterCreditApplicationRoutes(api)
+		r.RegisterCreditLineRoutes(api)
+		r.RegisterDepositRoutes(api)
 		r.RegisterAnalyticsRoutes(api)
 		r.RegisterAdminRoutes(api)
 		r.RegisterKeyRateRoutes(api.Group(APIPathKeyRate))
+		r.RegisterCurrencyRateRoutes(api.Group(APIPathCurrencyRate))
 	}
 
 	return router
@@ -3,7 +3,9 @@ package main
 import (
 	"FinanceGolang/core/api"
 	"FinanceGolang/core/dbcore"
+	"FinanceGolang/core/security"
 	"FinanceGolang/core/settings"
+	"context"
 	"fmt"
 	"log"
 )
@@ -15,6 +17,9 @@ func main() {
 	}
 	cfg := settings.Get()
 
+	// Настройка ключей PGP для шифрования данных карт
+	security.ConfigurePGP(cfg.PGPPublicKeyPath, cfg.PGPPrivateKeyPath, cfg.PGPPassphrase)
+
 	// Инициализация базы данных
 	db, err := dbcore.InitDB()
 	if err != nil {
@@ -37,6 +42,30 @@ func main() {
 	// Настройка Gin и middleware
 	r := router.InitRoutes()
 
+	// Регистрация фоновых задач и запуск по расписанию. Блокировка в базе не дает
+	// нескольким экземплярам приложения выполнить одну задачу одновременно
+	jobs := router.CreateJobService()
+	if err := jobs.Register(); err != nil {
+		log.Fatalf("Ошибка регистрации фоновых задач: %v", err)
+	}
+	if cfg.JobsEnabled {
+		go jobs.Start(context.Background())
+	}
+
+	// Запуск шлюза ISO 8583 рядом с HTTP-сервером
+	if cfg.ISO8583Enabled {
+		gateway, err := router.CreateISO8583Server()
+		if err != nil {
+			log.Fatalf("Ошибка настройки шлюза ISO 8583: %v", err)
+		}
+		defer gateway.Close()
+		go func() {
+			if err := gateway.ListenAndServe(); err != nil {
+				log.Fatalf("Ошибка запуска шлюза ISO 8583: %v", err)
+			}
+		}()
+	}
+
 	// Запуск сервера
 	addr := fmt.Sprintf("%s:%d", cfg.ServerHost, cfg.ServerPort)
 	log.Printf("Сервер запускается на %s", addr)
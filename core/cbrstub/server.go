@@ -0,0 +1,221 @@
+// Package cbrstub - локальная заглушка веб-сервиса DailyInfo Банка России для работы
+// без доступа к cbr.ru. Отвечает на методы KeyRateXML и GetCursOnDateXML по SOAP 1.2
+// ответами из фикстур (core/cbrstub/fixtures):
+//
+//   - KeyRateXML.xml - ответ KeyRateXML, из него отдаются строки за запрошенный период;
+//   - GetCursOnDateXML_ГГГГММДД.xml - ответ GetCursOnDateXML с курсами на дату. На запрос
+//     отдается фикстура с последней датой не позже запрошенной, как курсы в выходные.
+package cbrstub
+
+import (
+	"bytes"
+	"embed"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Path путь веб-сервиса, как у DailyInfo
+const Path = "/DailyInfoWebServ/DailyInfo.asmx"
+
+// fixtureFiles фикстуры, поставляемые с приложением
+//
+//go:embed fixtures/*.xml
+var fixtureFiles embed.FS
+
+const (
+	keyRateFixture    = "KeyRateXML.xml"
+	cursFixturePrefix = "GetCursOnDateXML_"
+	fixtureDateLayout = "20060102"
+	requestDateLayout = "2006-01-02"
+)
+
+const (
+	envelopeStart = `<?xml version="1.0" encoding="utf-8"?><soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:xsd="http://www.w3.org/2001/XMLSchema"><soap:Body>`
+	envelopeEnd   = `</soap:Body></soap:Envelope>`
+)
+
+// soapRequest запрос к DailyInfo: заполняется поле вызванного метода
+type soapRequest struct {
+	Body struct {
+		KeyRate *struct {
+			FromDate string `xml:"fromDate"`
+			ToDate   string `xml:"ToDate"`
+		} `xml:"KeyRateXML"`
+		CursOnDate *struct {
+			OnDate string `xml:"On_date"`
+		} `xml:"GetCursOnDateXML"`
+	} `xml:"Body"`
+}
+
+// keyRateFixtureData строки фикстуры KeyRateXML
+type keyRateFixtureData struct {
+	Rows []struct {
+		Date string `xml:"DT"`
+		Rate string `xml:"Rate"`
+	} `xml:"Body>KeyRateXMLResponse>KeyRateXMLResult>KeyRate>KR"`
+}
+
+// Handler HTTP-обработчик заглушки DailyInfo
+type Handler struct {
+	fixtures fs.FS
+}
+
+// NewHandler создает заглушку по фикстурам из каталога dir, без каталога - по
+// фикстурам, поставляемым с приложением. Фикстуры читаются при каждом запросе,
+// поэтому записанные ответы можно добавлять без перезапуска
+func NewHandler(dir string) (*Handler, error) {
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, err
+		}
+		return &Handler{fixtures: os.DirFS(dir)}, nil
+	}
+	fixtures, err := fs.Sub(fixtureFiles, "fixtures")
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{fixtures: fixtures}, nil
+}
+
+// ServeHTTP отвечает на SOAP-запрос
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.fault(w, http.StatusBadRequest, "cannot read request")
+		return
+	}
+	var request soapRequest
+	if err := xml.Unmarshal(body, &request); err != nil {
+		h.fault(w, http.StatusBadRequest, "invalid SOAP request")
+		return
+	}
+
+	var response []byte
+	switch {
+	case request.Body.KeyRate != nil:
+		response, err = h.keyRate(request.Body.KeyRate.FromDate, request.Body.KeyRate.ToDate)
+	case request.Body.CursOnDate != nil:
+		response, err = h.cursOnDate(request.Body.CursOnDate.OnDate)
+	default:
+		h.fault(w, http.StatusBadRequest, "unsupported method")
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).Warn("Заглушка ЦБ РФ не смогла подготовить ответ")
+		h.fault(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+	w.Write(response)
+}
+
+// keyRate отдает строки фикстуры KeyRateXML за период from..to
+func (h *Handler) keyRate(from, to string) ([]byte, error) {
+	start, err := requestDate(from)
+	if err != nil {
+		return nil, err
+	}
+	end, err := requestDate(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var data keyRateFixtureData
+	recorded, err := fs.ReadFile(h.fixtures, keyRateFixture)
+	switch {
+	case err == nil:
+		if err := xml.Unmarshal(recorded, &data); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %v", keyRateFixture, err)
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	var response bytes.Buffer
+	response.WriteString(envelopeStart)
+	response.WriteString(`<KeyRateXMLResponse xmlns="http://web.cbr.ru/"><KeyRateXMLResult><KeyRate xmlns="">`)
+	for _, row := range data.Rows {
+		if len(row.Date) < len(requestDateLayout) {
+			continue
+		}
+		if date := row.Date[:len(requestDateLayout)]; date < start || date > end {
+			continue
+		}
+		fmt.Fprintf(&response, "<KR><DT>%s</DT><Rate>%s</Rate></KR>", row.Date, row.Rate)
+	}
+	response.WriteString(`</KeyRate></KeyRateXMLResult></KeyRateXMLResponse>`)
+	response.WriteString(envelopeEnd)
+	return response.Bytes(), nil
+}
+
+// cursOnDate отдает фикстуру GetCursOnDateXML с последней датой не позже onDate.
+// Если такой нет, отдается пустой ответ, как у DailyInfo для дат без курсов
+func (h *Handler) cursOnDate(onDate string) ([]byte, error) {
+	date, err := requestDate(onDate)
+	if err != nil {
+		return nil, err
+	}
+	requested, _ := time.Parse(requestDateLayout, date)
+
+	names, err := fs.Glob(h.fixtures, cursFixturePrefix+"*.xml")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	var fixture string
+	for _, name := range names {
+		fixtureDate := strings.TrimSuffix(strings.TrimPrefix(name, cursFixturePrefix), ".xml")
+		if _, err := time.Parse(fixtureDateLayout, fixtureDate); err != nil {
+			continue
+		}
+		if fixtureDate <= requested.Format(fixtureDateLayout) {
+			fixture = name
+		}
+	}
+	if fixture != "" {
+		return fs.ReadFile(h.fixtures, fixture)
+	}
+
+	return []byte(envelopeStart +
+		`<GetCursOnDateXMLResponse xmlns="http://web.cbr.ru/"><GetCursOnDateXMLResult>` +
+		`<ValuteData xmlns="" OnDate="` + requested.Format(fixtureDateLayout) + `" />` +
+		`</GetCursOnDateXMLResult></GetCursOnDateXMLResponse>` + envelopeEnd), nil
+}
+
+// fault отвечает ошибкой SOAP 1.2
+func (h *Handler) fault(w http.ResponseWriter, status int, reason string) {
+	var text bytes.Buffer
+	xml.EscapeText(&text, []byte(reason))
+	w.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "%s<soap:Fault><soap:Code><soap:Value>soap:Sender</soap:Value></soap:Code>"+
+		"<soap:Reason><soap:Text xml:lang=\"en\">%s</soap:Text></soap:Reason></soap:Fault>%s",
+		envelopeStart, text.String(), envelopeEnd)
+}
+
+// requestDate приводит дату запроса (xs:dateTime) к ГГГГ-ММ-ДД
+func requestDate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < len(requestDateLayout) {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	if _, err := time.Parse(requestDateLayout, value[:len(requestDateLayout)]); err != nil {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	return value[:len(requestDateLayout)], nil
+}
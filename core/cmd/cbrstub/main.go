@@ -0,0 +1,39 @@
+// Заглушка веб-сервиса DailyInfo Банка России: отвечает на KeyRateXML и
+// GetCursOnDateXML из фикстур (core/cbrstub/fixtures), чтобы ключевую ставку
+// и курсы валют можно было проверить без доступа к cbr.ru.
+//
+// Пример:
+//
+//	go run ./core/cmd/cbrstub -addr localhost:8091
+//
+// и в .env приложения:
+//
+//	KEY_RATE_CBR_URL=http://localhost:8091/DailyInfoWebServ/DailyInfo.asmx
+//	CURRENCY_RATES_CBR_URL=http://localhost:8091/DailyInfoWebServ/DailyInfo.asmx
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"FinanceGolang/core/cbrstub"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8091", "адрес заглушки")
+	fixtures := flag.String("fixtures", "", "каталог с записанными ответами (по умолчанию встроенные фикстуры)")
+	flag.Parse()
+
+	handler, err := cbrstub.NewHandler(*fixtures)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки фикстур: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cbrstub.Path, handler)
+	log.Printf("Заглушка ЦБ РФ запускается на http://%s%s", *addr, cbrstub.Path)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		log.Fatalf("Ошибка запуска заглушки: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+// Тестовый клиент шлюза ISO 8583: отправляет сообщение из JSON-фикстуры
+// (core/iso8583/fixtures) и выводит ответ.
+//
+// Пример:
+//
+//	go run ./core/cmd/iso8583client -fixture core/iso8583/fixtures/auth_hold_0100.json \
+//		-pan 4276001234567890 -expiry 3012 -pin 1234
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"strconv"
+	"time"
+
+	"FinanceGolang/core/iso8583"
+)
+
+// fixture сообщение в JSON: номера полей задаются строками
+type fixture struct {
+	Description string            `json:"description"`
+	MTI         string            `json:"mti"`
+	Fields      map[string]string `json:"fields"`
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8583", "адрес шлюза ISO 8583")
+	specPath := flag.String("spec", "", "файл спецификации полей (по умолчанию встроенная)")
+	fixturePath := flag.String("fixture", "", "JSON-фикстура сообщения")
+	pan := flag.String("pan", "", "номер карты (поле 2)")
+	pin := flag.String("pin", "", "PIN, передается PIN-блоком ISO 9564 формата 0 (поле 52)")
+	amount := flag.String("amount", "", "сумма в рублях, например 150.00 (поле 4)")
+	rrn := flag.String("rrn", "", "номер ссылки RRN (поле 37), по умолчанию генерируется")
+	authCode := flag.String("auth", "", "код авторизации холда для подтверждения (поле 38)")
+	expiry := flag.String("expiry", "", "срок действия карты YYMM (поле 14)")
+	timeout := flag.Duration("timeout", 10*time.Second, "таймаут ответа")
+	flag.Parse()
+
+	if *fixturePath == "" {
+		log.Fatal("Не указана фикстура сообщения (-fixture)")
+	}
+
+	spec, err := iso8583.LoadSpec(*specPath)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки спецификации: %v", err)
+	}
+
+	request, err := loadFixture(*fixturePath)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки фикстуры: %v", err)
+	}
+
+	if *pan != "" {
+		request.Set(iso8583.FieldPAN, *pan)
+	}
+	if *expiry != "" {
+		request.Set(iso8583.FieldExpiryDate, *expiry)
+	}
+	if *amount != "" {
+		minor, err := parseAmount(*amount)
+		if err != nil {
+			log.Fatalf("Неверная сумма: %v", err)
+		}
+		request.Set(iso8583.FieldAmount, minor)
+	}
+	if *authCode != "" {
+		request.Set(iso8583.FieldAuthCode, *authCode)
+	}
+	if *pin != "" {
+		pinBlock, err := iso8583.EncodePINBlock(*pin, request.Get(iso8583.FieldPAN))
+		if err != nil {
+			log.Fatalf("Ошибка формирования PIN-блока: %v", err)
+		}
+		request.Set(iso8583.FieldPINData, pinBlock)
+	}
+	if *rrn != "" {
+		request.Set(iso8583.FieldRRN, *rrn)
+	}
+	if err := fillDefaults(request); err != nil {
+		log.Fatalf("Ошибка заполнения полей: %v", err)
+	}
+
+	client, err := iso8583.Dial(*addr, spec, *timeout)
+	if err != nil {
+		log.Fatalf("Ошибка подключения к %s: %v", *addr, err)
+	}
+	defer client.Close()
+
+	fmt.Println(">>", request)
+	response, err := client.Send(request)
+	if err != nil {
+		log.Fatalf("Ошибка обмена сообщениями: %v", err)
+	}
+	fmt.Println("<<", response)
+}
+
+// loadFixture читает сообщение из JSON-фикстуры
+func loadFixture(path string) (*iso8583.Message, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+
+	message := iso8583.NewMessage(f.MTI)
+	for key, value := range f.Fields {
+		number, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field number %q", key)
+		}
+		message.Set(number, value)
+	}
+	return message, nil
+}
+
+// fillDefaults заполняет дату и время передачи, номер трассировки и RRN, если их нет в фикстуре
+func fillDefaults(message *iso8583.Message) error {
+	now := time.Now()
+	if !message.Has(iso8583.FieldTransmissionTime) {
+		message.Set(iso8583.FieldTransmissionTime, now.UTC().Format("0102150405"))
+	}
+	if !message.Has(iso8583.FieldLocalTime) {
+		message.Set(iso8583.FieldLocalTime, now.Format("150405"))
+	}
+	if !message.Has(iso8583.FieldLocalDate) {
+		message.Set(iso8583.FieldLocalDate, now.Format("0102"))
+	}
+	if !message.Has(iso8583.FieldSTAN) {
+		stan, err := randomDigits(6)
+		if err != nil {
+			return err
+		}
+		message.Set(iso8583.FieldSTAN, stan)
+	}
+	if !message.Has(iso8583.FieldRRN) {
+		// RRN: последняя цифра года, день года, час и шесть случайных цифр
+		suffix, err := randomDigits(6)
+		if err != nil {
+			return err
+		}
+		message.Set(iso8583.FieldRRN, fmt.Sprintf("%d%03d%02d%s", now.Year()%10, now.YearDay(), now.Hour(), suffix))
+	}
+	return nil
+}
+
+// parseAmount переводит сумму в рублях в копейки
+func parseAmount(value string) (string, error) {
+	rubles, err := strconv.ParseFloat(value, 64)
+	if err != nil || rubles <= 0 {
+		return "", fmt.Errorf("amount must be a positive number")
+	}
+	return strconv.FormatInt(int64(rubles*100+0.5), 10), nil
+}
+
+// randomDigits генерирует строку из n случайных цифр
+func randomDigits(n int) (string, error) {
+	digits := make([]byte, n)
+	for i := range digits {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + d.Int64())
+	}
+	return string(digits), nil
+}
@@ -17,7 +17,7 @@ type AnalyticsRepository interface {
 	GetUserStats(ctx context.Context) (*domain.UserStats, error)
 	GetAccountStats(ctx context.Context) (*domain.AccountStats, error)
 	GetCreditStats(ctx context.Context) (*domain.CreditStats, error)
-	GetCardStats(ctx context.Context) (*domain.CardStats, error)
+	GetCardStats(ctx context.Context, now time.Time) (*domain.CardStats, error)
 	GetRoleStats(ctx context.Context) (*domain.RoleStats, error)
 }
 
@@ -192,8 +192,8 @@ func (r *analyticsRepository) GetCreditStats(ctx context.Context) (*domain.Credi
 	return &stats, nil
 }
 
-// GetCardStats получает статистику по картам
-func (r *analyticsRepository) GetCardStats(ctx context.Context) (*domain.CardStats, error) {
+// GetCardStats получает статистику по картам. Просроченными считаются карты со сроком до месяца now
+func (r *analyticsRepository) GetCardStats(ctx context.Context, now time.Time) (*domain.CardStats, error) {
 	var stats domain.CardStats
 
 	// Общее количество карт
@@ -208,10 +208,9 @@ func (r *analyticsRepository) GetCardStats(ctx context.Context) (*domain.CardSta
 		return nil, err
 	}
 
-	// Количество просроченных карт
-	now := time.Now().Format("01/06")
+	// Количество просроченных карт: срок MM/YY сравнивается как YYMM
 	if err := r.db.Model(&domain.Card{}).
-		Where("expiry_date < ?", now).
+		Where("substr(expiry_date, 4, 2) || substr(expiry_date, 1, 2) < ?", now.Format("0601")).
 		Count(&stats.ExpiredCards).Error; err != nil {
 		return nil, err
 	}
@@ -0,0 +1,117 @@
+package dbaccess
+
+import (
+	"context"
+	"time"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// AuditRepository интерфейс репозитория журнала аудита
+type AuditRepository interface {
+	Repository[domain.AuditLog]
+	GetByEntity(ctx context.Context, entityType string, entityID uint) ([]domain.AuditLog, error)
+	GetByUserID(ctx context.Context, userID uint) ([]domain.AuditLog, error)
+	CountSince(ctx context.Context, userID uint, actions []domain.AuditAction, since time.Time) (int64, error)
+}
+
+// auditRepository реализация репозитория журнала аудита
+type auditRepository struct {
+	BaseRepository[domain.AuditLog]
+}
+
+// AuditRepositoryInstance создает новый репозиторий журнала аудита
+func AuditRepositoryInstance(db *gorm.DB) AuditRepository {
+	return &auditRepository{
+		BaseRepository: *NewBaseRepository[domain.AuditLog](db),
+	}
+}
+
+// Create создает новую запись журнала аудита
+func (r *auditRepository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает запись журнала по ID
+func (r *auditRepository) GetByID(ctx context.Context, id uint) (*domain.AuditLog, error) {
+	var entry domain.AuditLog
+	if err := r.db.First(&entry, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &entry, nil
+}
+
+// GetByEntity получает записи журнала по объекту
+func (r *auditRepository) GetByEntity(ctx context.Context, entityType string, entityID uint) ([]domain.AuditLog, error) {
+	var entries []domain.AuditLog
+	if err := r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at DESC").Find(&entries).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return entries, nil
+}
+
+// GetByUserID получает записи журнала по пользователю
+func (r *auditRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.AuditLog, error) {
+	var entries []domain.AuditLog
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&entries).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return entries, nil
+}
+
+// CountSince считает записи пользователя с указанными действиями начиная с since
+func (r *auditRepository) CountSince(ctx context.Context, userID uint, actions []domain.AuditAction, since time.Time) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.AuditLog{}).
+		Where("user_id = ? AND action IN ? AND created_at >= ?", userID, actions, since).
+		Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
+
+// Update обновляет запись журнала
+func (r *auditRepository) Update(ctx context.Context, entry *domain.AuditLog) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(entry).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет запись журнала
+func (r *auditRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.AuditLog{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список записей журнала
+func (r *auditRepository) List(ctx context.Context, offset, limit int) ([]domain.AuditLog, error) {
+	var entries []domain.AuditLog
+	if err := r.db.Order("created_at DESC").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return entries, nil
+}
+
+// Count возвращает количество записей журнала
+func (r *auditRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.AuditLog{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,158 @@
+package dbaccess
+
+import (
+	"context"
+	"errors"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// CalendarRepository интерфейс репозитория производственного календаря
+type CalendarRepository interface {
+	Repository[domain.CalendarDay]
+	GetByDate(ctx context.Context, date string) (*domain.CalendarDay, error)
+	GetByYear(ctx context.Context, year int) ([]domain.CalendarDay, error)
+	Upsert(ctx context.Context, day *domain.CalendarDay) error
+	DeleteByDate(ctx context.Context, date string) error
+	GetCalendar(ctx context.Context) (*domain.BusinessCalendar, error)
+}
+
+// calendarRepository реализация репозитория производственного календаря
+type calendarRepository struct {
+	BaseRepository[domain.CalendarDay]
+}
+
+// CalendarRepositoryInstance создает новый репозиторий производственного календаря
+func CalendarRepositoryInstance(db *gorm.DB) CalendarRepository {
+	return &calendarRepository{
+		BaseRepository: *NewBaseRepository[domain.CalendarDay](db),
+	}
+}
+
+// Create создает новый день календаря
+func (r *calendarRepository) Create(ctx context.Context, day *domain.CalendarDay) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(day).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает день календаря по ID
+func (r *calendarRepository) GetByID(ctx context.Context, id uint) (*domain.CalendarDay, error) {
+	var day domain.CalendarDay
+	if err := r.db.First(&day, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &day, nil
+}
+
+// GetByDate получает день календаря по дате ГГГГ-ММ-ДД
+func (r *calendarRepository) GetByDate(ctx context.Context, date string) (*domain.CalendarDay, error) {
+	var day domain.CalendarDay
+	if err := r.db.Where("date = ?", date).First(&day).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &day, nil
+}
+
+// GetByYear получает дни календаря за год в порядке дат
+func (r *calendarRepository) GetByYear(ctx context.Context, year int) ([]domain.CalendarDay, error) {
+	var days []domain.CalendarDay
+	from := domain.CalendarDate(year, 1, 1)
+	to := domain.CalendarDate(year, 12, 31)
+	if err := r.db.Where("date BETWEEN ? AND ?", from, to).Order("date").Find(&days).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return days, nil
+}
+
+// Upsert создает день календаря или обновляет вид и описание существующего
+func (r *calendarRepository) Upsert(ctx context.Context, day *domain.CalendarDay) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var existing domain.CalendarDay
+		err := tx.Where("date = ?", day.Date).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Create(day).Error; err != nil {
+				return r.HandleError(err)
+			}
+			return nil
+		case err != nil:
+			return r.HandleError(err)
+		}
+		existing.Type = day.Type
+		existing.Description = day.Description
+		if err := tx.Save(&existing).Error; err != nil {
+			return r.HandleError(err)
+		}
+		*day = existing
+		return nil
+	})
+}
+
+// Update обновляет день календаря
+func (r *calendarRepository) Update(ctx context.Context, day *domain.CalendarDay) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(day).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет день календаря. Запись удаляется физически, чтобы дату
+// можно было добавить снова без конфликта уникального индекса
+func (r *calendarRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&domain.CalendarDay{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// DeleteByDate удаляет день календаря по дате, после чего день считается
+// обычным: рабочим с понедельника по пятницу и выходным в субботу и воскресенье
+func (r *calendarRepository) DeleteByDate(ctx context.Context, date string) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("date = ?", date).Delete(&domain.CalendarDay{})
+		if result.Error != nil {
+			return r.HandleError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+// List получает список дней календаря в порядке дат
+func (r *calendarRepository) List(ctx context.Context, offset, limit int) ([]domain.CalendarDay, error) {
+	var days []domain.CalendarDay
+	if err := r.db.Order("date").Offset(offset).Limit(limit).Find(&days).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return days, nil
+}
+
+// Count возвращает количество дней календаря
+func (r *calendarRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.CalendarDay{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
+
+// GetCalendar загружает производственный календарь целиком
+func (r *calendarRepository) GetCalendar(ctx context.Context) (*domain.BusinessCalendar, error) {
+	var days []domain.CalendarDay
+	if err := r.db.Find(&days).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return domain.NewBusinessCalendar(days), nil
+}
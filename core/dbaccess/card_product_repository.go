@@ -0,0 +1,125 @@
+package dbaccess
+
+import (
+	"context"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// CardProductRepository интерфейс репозитория карточных продуктов
+type CardProductRepository interface {
+	Repository[domain.CardProduct]
+	GetByCode(ctx context.Context, code string) (*domain.CardProduct, error)
+	GetDefault(ctx context.Context) (*domain.CardProduct, error)
+	GetActive(ctx context.Context) ([]domain.CardProduct, error)
+	ClearDefault(ctx context.Context, exceptID uint) error
+}
+
+// cardProductRepository реализация репозитория карточных продуктов
+type cardProductRepository struct {
+	BaseRepository[domain.CardProduct]
+}
+
+// CardProductRepositoryInstance создает новый репозиторий карточных продуктов
+func CardProductRepositoryInstance(db *gorm.DB) CardProductRepository {
+	return &cardProductRepository{
+		BaseRepository: *NewBaseRepository[domain.CardProduct](db),
+	}
+}
+
+// Create создает новый продукт
+func (r *cardProductRepository) Create(ctx context.Context, product *domain.CardProduct) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает продукт по ID
+func (r *cardProductRepository) GetByID(ctx context.Context, id uint) (*domain.CardProduct, error) {
+	var product domain.CardProduct
+	if err := r.db.First(&product, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &product, nil
+}
+
+// GetByCode получает продукт по коду
+func (r *cardProductRepository) GetByCode(ctx context.Context, code string) (*domain.CardProduct, error) {
+	var product domain.CardProduct
+	if err := r.db.Where("code = ?", code).First(&product).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &product, nil
+}
+
+// GetDefault получает продукт, используемый при выпуске карты без указания продукта
+func (r *cardProductRepository) GetDefault(ctx context.Context) (*domain.CardProduct, error) {
+	var product domain.CardProduct
+	if err := r.db.Where("is_default = ? AND is_active = ?", true, true).First(&product).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &product, nil
+}
+
+// GetActive получает продукты, доступные для выпуска
+func (r *cardProductRepository) GetActive(ctx context.Context) ([]domain.CardProduct, error) {
+	var products []domain.CardProduct
+	if err := r.db.Where("is_active = ?", true).Order("id").Find(&products).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return products, nil
+}
+
+// ClearDefault снимает признак продукта по умолчанию со всех продуктов, кроме указанного
+func (r *cardProductRepository) ClearDefault(ctx context.Context, exceptID uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.CardProduct{}).Where("id <> ? AND is_default = ?", exceptID, true).
+			Update("is_default", false).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Update обновляет продукт
+func (r *cardProductRepository) Update(ctx context.Context, product *domain.CardProduct) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(product).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет продукт
+func (r *cardProductRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.CardProduct{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список продуктов
+func (r *cardProductRepository) List(ctx context.Context, offset, limit int) ([]domain.CardProduct, error) {
+	var products []domain.CardProduct
+	if err := r.db.Order("id").Offset(offset).Limit(limit).Find(&products).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return products, nil
+}
+
+// Count возвращает количество продуктов
+func (r *cardProductRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.CardProduct{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
@@ -13,14 +13,41 @@ import (
 // CardRepository интерфейс репозитория карт
 type CardRepository interface {
 	Repository[domain.Card]
-	GetByNumber(ctx context.Context, number string) (*domain.Card, error)
+	GetByNumberHash(ctx context.Context, numberHash string) (*domain.Card, error)
+	ExistsByNumberHash(ctx context.Context, numberHash string) (bool, error)
 	GetByUserID(ctx context.Context, userID uint) ([]domain.Card, error)
 	GetByAccountID(ctx context.Context, accountID uint) ([]domain.Card, error)
-	GetExpiredCards(ctx context.Context) ([]domain.Card, error)
+	GetExpiredCards(ctx context.Context, now time.Time) ([]domain.Card, error)
+	GetCardsDueForRenewal(ctx context.Context, expiresBefore time.Time) ([]domain.Card, error)
+	GetCardsWithoutExpiry(ctx context.Context) ([]domain.Card, error)
+	UpdateExpiresAt(ctx context.Context, id uint, expiresAt time.Time) error
+	UpdateDisplayData(ctx context.Context, id uint, lastFour, brand, numberHash string) error
+	SetReplacedBy(ctx context.Context, id, replacedByID uint) error
+	SetPendingCVV(ctx context.Context, id uint, encryptedCVV string) error
+	ClearPendingCVV(ctx context.Context, id uint) (bool, error)
 	GetActiveCards(ctx context.Context) ([]domain.Card, error)
 	UpdateStatus(ctx context.Context, id uint, isActive bool) error
+	UpdatePIN(ctx context.Context, id uint, pinHash string) error
+	UpdatePINAttempts(ctx context.Context, id uint, attempts int, isBlocked bool) error
+	RegisterWrongPIN(ctx context.Context, id uint, limit int) (attempts int, isBlocked bool, err error)
+	ResetPINAttempts(ctx context.Context, id uint) (bool, error)
+	UpdateLastUsed(ctx context.Context, id uint, lastUsed time.Time) error
+	Debit(ctx context.Context, card *domain.Card, transaction *domain.Transaction, usedAt time.Time) error
 	GetDailyUsage(ctx context.Context, id uint, date time.Time) (float64, error)
 	GetMonthlyUsage(ctx context.Context, id uint, year int, month time.Month) (float64, error)
+	GetTotalUsage(ctx context.Context, id uint) (float64, error)
+	GetMCCUsage(ctx context.Context, id uint, mcc string, from, to time.Time) (float64, error)
+	UpdateControls(ctx context.Context, id uint, controls domain.CardControls) error
+	GetExpiredVirtualCards(ctx context.Context, now time.Time) ([]domain.Card, error)
+	Close(ctx context.Context, id uint, reason string, closedAt time.Time) error
+	UpdateMerchantLock(ctx context.Context, id uint, merchant string) error
+}
+
+// cardUsageStatuses статусы транзакций, учитываемые в лимитах карты
+var cardUsageStatuses = []domain.TransactionStatus{
+	domain.TransactionStatusPending,
+	domain.TransactionStatusCompleted,
+	domain.TransactionStatusChargedBack,
 }
 
 // cardRepository реализация репозитория карт
@@ -68,15 +95,24 @@ func (r *cardRepository) GetByID(ctx context.Context, id uint) (*domain.Card, er
 	return &card, nil
 }
 
-// GetByNumber получает карту по номеру
-func (r *cardRepository) GetByNumber(ctx context.Context, number string) (*domain.Card, error) {
+// GetByNumberHash получает карту по отпечатку номера (см. security.FingerprintPAN)
+func (r *cardRepository) GetByNumberHash(ctx context.Context, numberHash string) (*domain.Card, error) {
 	var card domain.Card
-	if err := r.db.Where("number = ?", number).First(&card).Error; err != nil {
+	if err := r.db.Where("number_hash = ?", numberHash).First(&card).Error; err != nil {
 		return nil, r.HandleError(err)
 	}
 	return &card, nil
 }
 
+// ExistsByNumberHash проверяет, выпущена ли уже карта с таким отпечатком номера
+func (r *cardRepository) ExistsByNumberHash(ctx context.Context, numberHash string) (bool, error) {
+	var count int64
+	if err := r.db.Unscoped().Model(&domain.Card{}).Where("number_hash = ?", numberHash).Count(&count).Error; err != nil {
+		return false, r.HandleError(err)
+	}
+	return count > 0, nil
+}
+
 // GetByUserID получает карты пользователя
 func (r *cardRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.Card, error) {
 	var cards []domain.Card
@@ -95,11 +131,41 @@ func (r *cardRepository) GetByAccountID(ctx context.Context, accountID uint) ([]
 	return cards, nil
 }
 
-// GetExpiredCards получает просроченные карты
-func (r *cardRepository) GetExpiredCards(ctx context.Context) ([]domain.Card, error) {
+// GetExpiredCards получает незакрытые карты с истекшим сроком действия.
+// Срок действия в expiry_date зашифрован, поэтому используется expires_at
+func (r *cardRepository) GetExpiredCards(ctx context.Context, now time.Time) ([]domain.Card, error) {
 	var cards []domain.Card
-	now := time.Now().Format("01/06")
-	if err := r.db.Where("expiry_date < ?", now).Find(&cards).Error; err != nil {
+	if err := r.db.Where("closed_at IS NULL AND expires_at <= ?", now).Find(&cards).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return cards, nil
+}
+
+// GetCardsDueForRenewal получает действующие пластиковые карты, срок действия
+// которых заканчивается до expiresBefore и которые еще не перевыпущены
+func (r *cardRepository) GetCardsDueForRenewal(ctx context.Context, expiresBefore time.Time) ([]domain.Card, error) {
+	var cards []domain.Card
+	if err := r.db.Where("is_active = ? AND closed_at IS NULL AND replaced_by_id IS NULL AND kind <> ? AND expires_at <= ?",
+		true, domain.CardKindVirtual, expiresBefore).Find(&cards).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return cards, nil
+}
+
+// GetCardsWithoutExpiry получает незакрытые карты, выпущенные до появления поля expires_at
+func (r *cardRepository) GetCardsWithoutExpiry(ctx context.Context) ([]domain.Card, error) {
+	var cards []domain.Card
+	if err := r.db.Where("closed_at IS NULL AND expires_at IS NULL").Find(&cards).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return cards, nil
+}
+
+// GetExpiredVirtualCards получает действующие виртуальные карты с истекшим сроком действия
+func (r *cardRepository) GetExpiredVirtualCards(ctx context.Context, now time.Time) ([]domain.Card, error) {
+	var cards []domain.Card
+	if err := r.db.Where("kind = ? AND closed_at IS NULL AND valid_until <= ?", domain.CardKindVirtual, now).
+		Find(&cards).Error; err != nil {
 		return nil, r.HandleError(err)
 	}
 	return cards, nil
@@ -115,12 +181,10 @@ func (r *cardRepository) GetActiveCards(ctx context.Context) ([]domain.Card, err
 }
 
 // Update обновляет карту
+// Номер и срок действия хранятся в зашифрованном виде, поэтому
+// валидация данных здесь не выполняется (см. Create)
 func (r *cardRepository) Update(ctx context.Context, card *domain.Card) error {
 	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
-		if err := card.Validate(); err != nil {
-			return ErrInvalidData
-		}
-
 		if err := tx.Save(card).Error; err != nil {
 			return r.HandleError(err)
 		}
@@ -138,6 +202,222 @@ func (r *cardRepository) UpdateStatus(ctx context.Context, id uint, isActive boo
 	})
 }
 
+// UpdatePIN сохраняет хеш нового PIN-кода и сбрасывает счетчик неверных попыток
+func (r *cardRepository) UpdatePIN(ctx context.Context, id uint, pinHash string) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Card{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"pin_hash":     pinHash,
+			"pin_attempts": 0,
+		}).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// UpdatePINAttempts обновляет счетчик неверных вводов PIN и признак блокировки карты
+func (r *cardRepository) UpdatePINAttempts(ctx context.Context, id uint, attempts int, isBlocked bool) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Card{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"pin_attempts": attempts,
+			"is_blocked":   isBlocked,
+		}).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// RegisterWrongPIN атомарно увеличивает счетчик неверных вводов PIN и блокирует карту,
+// когда он достигает limit. Карта, уже заблокированная параллельным запросом,
+// не меняется и возвращается как заблокированная
+func (r *cardRepository) RegisterWrongPIN(ctx context.Context, id uint, limit int) (int, bool, error) {
+	var result struct {
+		PinAttempts int
+		IsBlocked   bool
+	}
+	err := r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Raw(`UPDATE cards
+			SET pin_attempts = pin_attempts + 1,
+				is_blocked = (pin_attempts + 1 >= ?),
+				updated_at = ?
+			WHERE id = ? AND is_blocked = ? AND deleted_at IS NULL
+			RETURNING pin_attempts, is_blocked`,
+			limit, tx.NowFunc(), id, false).Scan(&result)
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			result.IsBlocked = true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return result.PinAttempts, result.IsBlocked, nil
+}
+
+// ResetPINAttempts сбрасывает счетчик неверных вводов PIN, если карта не заблокирована.
+// Возвращает false, если карта успела заблокироваться
+func (r *cardRepository) ResetPINAttempts(ctx context.Context, id uint) (bool, error) {
+	var reset bool
+	err := r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Model(&domain.Card{}).
+			Where("id = ? AND is_blocked = ?", id, false).
+			Update("pin_attempts", 0)
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		reset = res.RowsAffected > 0
+		return nil
+	})
+	return reset, err
+}
+
+// Close закрывает карту: деактивирует ее и сохраняет дату и причину закрытия
+func (r *cardRepository) Close(ctx context.Context, id uint, reason string, closedAt time.Time) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Card{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"is_active":    false,
+			"closed_at":    closedAt,
+			"close_reason": reason,
+		}).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// UpdateExpiresAt сохраняет срок действия карты в открытом виде
+func (r *cardRepository) UpdateExpiresAt(ctx context.Context, id uint, expiresAt time.Time) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Card{}).Where("id = ?", id).Update("expires_at", expiresAt).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// UpdateDisplayData сохраняет последние 4 цифры, платежную систему и отпечаток номера карты
+func (r *cardRepository) UpdateDisplayData(ctx context.Context, id uint, lastFour, brand, numberHash string) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Card{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"last_four":   lastFour,
+			"brand":       brand,
+			"number_hash": numberHash,
+		}).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// SetPendingCVV сохраняет зашифрованный CVV перевыпущенной карты до первого показа реквизитов
+func (r *cardRepository) SetPendingCVV(ctx context.Context, id uint, encryptedCVV string) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Card{}).Where("id = ?", id).Update("pending_cvv", encryptedCVV).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// ClearPendingCVV стирает сохраненный CVV. Возвращает false, если его уже стер
+// параллельный запрос: CVV выдается только тому, кто его стер
+func (r *cardRepository) ClearPendingCVV(ctx context.Context, id uint) (bool, error) {
+	var cleared bool
+	err := r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Model(&domain.Card{}).Where("id = ? AND pending_cvv <> ?", id, "").Update("pending_cvv", "")
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		cleared = res.RowsAffected > 0
+		return nil
+	})
+	return cleared, err
+}
+
+// SetReplacedBy связывает карту с перевыпущенной картой
+func (r *cardRepository) SetReplacedBy(ctx context.Context, id, replacedByID uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Card{}).Where("id = ?", id).Update("replaced_by_id", replacedByID).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// UpdateControls сохраняет ограничения по операциям карты
+func (r *cardRepository) UpdateControls(ctx context.Context, id uint, controls domain.CardControls) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		card := domain.Card{Model: gorm.Model{ID: id}, Controls: controls}
+		if err := tx.Model(&card).Select("controls").Updates(&card).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// UpdateMerchantLock закрепляет виртуальную карту за продавцом
+func (r *cardRepository) UpdateMerchantLock(ctx context.Context, id uint, merchant string) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Card{}).Where("id = ?", id).Update("merchant_lock", merchant).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// UpdateLastUsed обновляет дату последнего использования карты
+func (r *cardRepository) UpdateLastUsed(ctx context.Context, id uint, lastUsed time.Time) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Card{}).Where("id = ?", id).Update("last_used", lastUsed).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Debit списывает сумму операции по карте со счета и сохраняет операцию в одной транзакции.
+// Строка карты блокируется обновлением last_used до подсчета списанных средств, поэтому
+// параллельные операции по одной виртуальной карте проверяют ее лимит по очереди.
+// Списание проходит, только если сумма не превышает остаток с кредитным лимитом
+func (r *cardRepository) Debit(ctx context.Context, card *domain.Card, transaction *domain.Transaction, usedAt time.Time) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Card{}).Where("id = ?", card.ID).Update("last_used", usedAt).Error; err != nil {
+			return r.HandleError(err)
+		}
+
+		if card.IsVirtual() {
+			var spent float64
+			if err := tx.Model(&domain.Transaction{}).
+				Where("card_id = ? AND status IN ?", card.ID, cardUsageStatuses).
+				Select("COALESCE(SUM(amount), 0)").
+				Scan(&spent).Error; err != nil {
+				return r.HandleError(err)
+			}
+			if err := card.CheckVirtualLimit(transaction.Amount, spent); err != nil {
+				return err
+			}
+		}
+
+		res := tx.Model(&domain.Account{}).
+			Where("id = ? AND balance + credit_limit >= ?", transaction.FromAccountID, transaction.Amount).
+			Update("balance", gorm.Expr("balance - ?", transaction.Amount))
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrInsufficientFunds
+		}
+		if err := tx.Create(transaction).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
 // Delete удаляет карту
 func (r *cardRepository) Delete(ctx context.Context, id uint) error {
 	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
@@ -173,7 +453,7 @@ func (r *cardRepository) GetDailyUsage(ctx context.Context, id uint, date time.T
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
 	if err := r.db.Model(&domain.Transaction{}).
-		Where("card_id = ? AND created_at BETWEEN ? AND ?", id, startOfDay, endOfDay).
+		Where("card_id = ? AND status IN ? AND created_at BETWEEN ? AND ?", id, cardUsageStatuses, startOfDay, endOfDay).
 		Select("COALESCE(SUM(amount), 0)").
 		Scan(&total).Error; err != nil {
 		return 0, r.HandleError(err)
@@ -188,7 +468,31 @@ func (r *cardRepository) GetMonthlyUsage(ctx context.Context, id uint, year int,
 	endOfMonth := startOfMonth.AddDate(0, 1, 0)
 
 	if err := r.db.Model(&domain.Transaction{}).
-		Where("card_id = ? AND created_at BETWEEN ? AND ?", id, startOfMonth, endOfMonth).
+		Where("card_id = ? AND status IN ? AND created_at BETWEEN ? AND ?", id, cardUsageStatuses, startOfMonth, endOfMonth).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return total, nil
+}
+
+// GetTotalUsage получает сумму всех операций по карте за весь срок ее действия
+func (r *cardRepository) GetTotalUsage(ctx context.Context, id uint) (float64, error) {
+	var total float64
+	if err := r.db.Model(&domain.Transaction{}).
+		Where("card_id = ? AND status IN ?", id, cardUsageStatuses).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return total, nil
+}
+
+// GetMCCUsage получает сумму операций по карте с указанным кодом категории продавца за период
+func (r *cardRepository) GetMCCUsage(ctx context.Context, id uint, mcc string, from, to time.Time) (float64, error) {
+	var total float64
+	if err := r.db.Model(&domain.Transaction{}).
+		Where("card_id = ? AND mcc = ? AND status IN ? AND created_at BETWEEN ? AND ?", id, mcc, cardUsageStatuses, from, to).
 		Select("COALESCE(SUM(amount), 0)").
 		Scan(&total).Error; err != nil {
 		return 0, r.HandleError(err)
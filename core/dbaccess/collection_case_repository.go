@@ -0,0 +1,139 @@
+package dbaccess
+
+import (
+	"context"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// CollectionCaseRepository интерфейс репозитория дел о просроченной задолженности
+type CollectionCaseRepository interface {
+	Repository[domain.CollectionCase]
+	GetOpen(ctx context.Context) ([]domain.CollectionCase, error)
+	GetWorklist(ctx context.Context, filter domain.CollectionFilter) ([]domain.CollectionCase, error)
+	UpdateWithActions(ctx context.Context, collectionCase *domain.CollectionCase, actions []domain.DunningAction) error
+}
+
+// collectionCaseRepository реализация репозитория дел о просроченной задолженности
+type collectionCaseRepository struct {
+	BaseRepository[domain.CollectionCase]
+}
+
+// CollectionCaseRepositoryInstance создает новый репозиторий дел о просроченной задолженности
+func CollectionCaseRepositoryInstance(db *gorm.DB) CollectionCaseRepository {
+	return &collectionCaseRepository{
+		BaseRepository: *NewBaseRepository[domain.CollectionCase](db),
+	}
+}
+
+// Create создает дело
+func (r *collectionCaseRepository) Create(ctx context.Context, collectionCase *domain.CollectionCase) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Actions").Create(collectionCase).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает дело по ID вместе с историей действий
+func (r *collectionCaseRepository) GetByID(ctx context.Context, id uint) (*domain.CollectionCase, error) {
+	var collectionCase domain.CollectionCase
+	if err := r.db.Preload("Actions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	}).First(&collectionCase, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &collectionCase, nil
+}
+
+// GetOpen получает незакрытые дела
+func (r *collectionCaseRepository) GetOpen(ctx context.Context) ([]domain.CollectionCase, error) {
+	var cases []domain.CollectionCase
+	if err := r.db.Where("status <> ?", domain.CollectionStatusClosed).Order("id").Find(&cases).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return cases, nil
+}
+
+// GetWorklist получает дела по фильтру: сначала с наибольшей просрочкой и суммой
+func (r *collectionCaseRepository) GetWorklist(ctx context.Context, filter domain.CollectionFilter) ([]domain.CollectionCase, error) {
+	query := r.db.Model(&domain.CollectionCase{})
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.MinDPD > 0 {
+		query = query.Where("days_past_due >= ?", filter.MinDPD)
+	}
+	if filter.MaxDPD > 0 {
+		query = query.Where("days_past_due <= ?", filter.MaxDPD)
+	}
+	if filter.MinAmount > 0 {
+		query = query.Where("overdue_amount >= ?", filter.MinAmount)
+	}
+	if filter.MaxAmount > 0 {
+		query = query.Where("overdue_amount <= ?", filter.MaxAmount)
+	}
+
+	var cases []domain.CollectionCase
+	if err := query.Order("days_past_due DESC, overdue_amount DESC, id").Find(&cases).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return cases, nil
+}
+
+// Update обновляет дело
+func (r *collectionCaseRepository) Update(ctx context.Context, collectionCase *domain.CollectionCase) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Actions").Save(collectionCase).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// UpdateWithActions обновляет дело и добавляет записи в историю действий в одной транзакции
+func (r *collectionCaseRepository) UpdateWithActions(ctx context.Context, collectionCase *domain.CollectionCase, actions []domain.DunningAction) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Actions").Save(collectionCase).Error; err != nil {
+			return r.HandleError(err)
+		}
+		for i := range actions {
+			actions[i].CaseID = collectionCase.ID
+			if err := tx.Create(&actions[i]).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		return nil
+	})
+}
+
+// Delete удаляет дело
+func (r *collectionCaseRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.CollectionCase{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список дел
+func (r *collectionCaseRepository) List(ctx context.Context, offset, limit int) ([]domain.CollectionCase, error) {
+	var cases []domain.CollectionCase
+	if err := r.db.Order("id").Offset(offset).Limit(limit).Find(&cases).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return cases, nil
+}
+
+// Count возвращает количество дел
+func (r *collectionCaseRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.CollectionCase{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,159 @@
+package dbaccess
+
+import (
+	"context"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// CreditApplicationRepository интерфейс репозитория заявок на кредит
+type CreditApplicationRepository interface {
+	Repository[domain.CreditApplication]
+	CreateWithDecision(ctx context.Context, application *domain.CreditApplication, decision *domain.CreditDecision) error
+	UpdateWithDecision(ctx context.Context, application *domain.CreditApplication, decision *domain.CreditDecision) error
+	Decide(ctx context.Context, application *domain.CreditApplication, decision *domain.CreditDecision) error
+	GetByUserID(ctx context.Context, userID uint) ([]domain.CreditApplication, error)
+	GetByStatus(ctx context.Context, status domain.CreditApplicationStatus) ([]domain.CreditApplication, error)
+}
+
+// creditApplicationRepository реализация репозитория заявок на кредит
+type creditApplicationRepository struct {
+	BaseRepository[domain.CreditApplication]
+}
+
+// CreditApplicationRepositoryInstance создает новый репозиторий заявок на кредит
+func CreditApplicationRepositoryInstance(db *gorm.DB) CreditApplicationRepository {
+	return &creditApplicationRepository{
+		BaseRepository: *NewBaseRepository[domain.CreditApplication](db),
+	}
+}
+
+// Create создает заявку
+func (r *creditApplicationRepository) Create(ctx context.Context, application *domain.CreditApplication) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Decisions").Create(application).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// CreateWithDecision создает заявку и решение по ней в одной транзакции
+func (r *creditApplicationRepository) CreateWithDecision(ctx context.Context, application *domain.CreditApplication, decision *domain.CreditDecision) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Decisions").Create(application).Error; err != nil {
+			return r.HandleError(err)
+		}
+		decision.ApplicationID = application.ID
+		if err := tx.Create(decision).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает заявку по ID вместе с историей решений
+func (r *creditApplicationRepository) GetByID(ctx context.Context, id uint) (*domain.CreditApplication, error) {
+	var application domain.CreditApplication
+	if err := r.db.Preload("Decisions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	}).First(&application, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &application, nil
+}
+
+// GetByUserID получает заявки пользователя
+func (r *creditApplicationRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.CreditApplication, error) {
+	var applications []domain.CreditApplication
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&applications).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return applications, nil
+}
+
+// GetByStatus получает заявки в указанном статусе, начиная с самых ранних
+func (r *creditApplicationRepository) GetByStatus(ctx context.Context, status domain.CreditApplicationStatus) ([]domain.CreditApplication, error) {
+	var applications []domain.CreditApplication
+	if err := r.db.Where("status = ?", status).Order("created_at").Find(&applications).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return applications, nil
+}
+
+// Update обновляет заявку
+func (r *creditApplicationRepository) Update(ctx context.Context, application *domain.CreditApplication) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Decisions").Save(application).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// UpdateWithDecision обновляет заявку и добавляет решение по ней в одной транзакции
+func (r *creditApplicationRepository) UpdateWithDecision(ctx context.Context, application *domain.CreditApplication, decision *domain.CreditDecision) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Decisions").Save(application).Error; err != nil {
+			return r.HandleError(err)
+		}
+		if err := tx.Create(decision).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Decide сохраняет решение менеджера и новый статус заявки в одной транзакции. Статус
+// меняется, только если заявка еще ожидает ручного рассмотрения; иначе (например, решение
+// уже принял другой менеджер) ничего не сохраняется и возвращается ErrApplicationNotInReview
+func (r *creditApplicationRepository) Decide(ctx context.Context, application *domain.CreditApplication, decision *domain.CreditDecision) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Model(&domain.CreditApplication{}).
+			Where("id = ? AND status = ?", application.ID, domain.ApplicationStatusManualReview).
+			Updates(map[string]interface{}{
+				"status":     application.Status,
+				"decided_at": application.DecidedAt,
+			})
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrApplicationNotInReview
+		}
+		if err := tx.Create(decision).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет заявку
+func (r *creditApplicationRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.CreditApplication{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список заявок
+func (r *creditApplicationRepository) List(ctx context.Context, offset, limit int) ([]domain.CreditApplication, error) {
+	var applications []domain.CreditApplication
+	if err := r.db.Order("created_at DESC").Offset(offset).Limit(limit).Find(&applications).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return applications, nil
+}
+
+// Count возвращает количество заявок
+func (r *creditApplicationRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.CreditApplication{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,206 @@
+package dbaccess
+
+import (
+	"context"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// CreditLineRepository интерфейс репозитория кредитных линий
+type CreditLineRepository interface {
+	Repository[domain.CreditLine]
+	CreateWithAccount(ctx context.Context, account *domain.Account, line *domain.CreditLine) error
+	GetByAccountID(ctx context.Context, accountID uint) (*domain.CreditLine, error)
+	GetByUserID(ctx context.Context, userID uint) ([]domain.CreditLine, error)
+	UpdateLimit(ctx context.Context, line *domain.CreditLine) error
+	SaveProcessing(ctx context.Context, line *domain.CreditLine, statements []domain.CreditLineStatement, statement *domain.CreditLineStatement, charges []domain.Transaction) error
+	GetStatements(ctx context.Context, lineID uint) ([]domain.CreditLineStatement, error)
+	GetLastStatement(ctx context.Context, lineID uint) (*domain.CreditLineStatement, error)
+	GetUnsettledStatements(ctx context.Context, lineID uint) ([]domain.CreditLineStatement, error)
+}
+
+// creditLineRepository реализация репозитория кредитных линий
+type creditLineRepository struct {
+	BaseRepository[domain.CreditLine]
+}
+
+// CreditLineRepositoryInstance создает новый репозиторий кредитных линий
+func CreditLineRepositoryInstance(db *gorm.DB) CreditLineRepository {
+	return &creditLineRepository{
+		BaseRepository: *NewBaseRepository[domain.CreditLine](db),
+	}
+}
+
+// Create создает новую кредитную линию
+func (r *creditLineRepository) Create(ctx context.Context, line *domain.CreditLine) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(line).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// CreateWithAccount открывает кредитный счет и кредитную линию на нем в одной транзакции
+func (r *creditLineRepository) CreateWithAccount(ctx context.Context, account *domain.Account, line *domain.CreditLine) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(account).Error; err != nil {
+			return r.HandleError(err)
+		}
+		line.AccountID = account.ID
+		if err := tx.Create(line).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает кредитную линию по ID
+func (r *creditLineRepository) GetByID(ctx context.Context, id uint) (*domain.CreditLine, error) {
+	var line domain.CreditLine
+	if err := r.db.First(&line, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &line, nil
+}
+
+// GetByAccountID получает кредитную линию кредитного счета
+func (r *creditLineRepository) GetByAccountID(ctx context.Context, accountID uint) (*domain.CreditLine, error) {
+	var line domain.CreditLine
+	if err := r.db.Where("account_id = ?", accountID).First(&line).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &line, nil
+}
+
+// GetByUserID получает кредитные линии пользователя
+func (r *creditLineRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.CreditLine, error) {
+	var lines []domain.CreditLine
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&lines).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return lines, nil
+}
+
+// Update обновляет кредитную линию
+func (r *creditLineRepository) Update(ctx context.Context, line *domain.CreditLine) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(line).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// UpdateLimit сохраняет новый лимит кредитной линии вместе с лимитом кредитного счета
+func (r *creditLineRepository) UpdateLimit(ctx context.Context, line *domain.CreditLine) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(line).Update("credit_limit", line.CreditLimit).Error; err != nil {
+			return r.HandleError(err)
+		}
+		if err := tx.Model(&domain.Account{}).Where("id = ?", line.AccountID).
+			Update("credit_limit", line.CreditLimit).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// creditLineProcessingColumns поля кредитной линии, которые меняет ежедневная обработка
+var creditLineProcessingColumns = []string{
+	"status", "grace_active", "grace_since", "accrued_interest", "deferred_interest",
+	"accrued_through", "cycle_start", "next_statement_date", "cycle_interest", "cycle_fees",
+}
+
+// SaveProcessing сохраняет результат ежедневной обработки кредитной линии в одной транзакции:
+// списания процентов и штрафов, подведенные и новую выписку и начисления по линии.
+// Лимит и условия линии не перезаписываются, чтобы не затереть их параллельное изменение
+func (r *creditLineRepository) SaveProcessing(ctx context.Context, line *domain.CreditLine, statements []domain.CreditLineStatement, statement *domain.CreditLineStatement, charges []domain.Transaction) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		for i := range charges {
+			if err := tx.Model(&domain.Account{}).Where("id = ?", charges[i].FromAccountID).
+				Update("balance", gorm.Expr("balance - ?", charges[i].Amount)).Error; err != nil {
+				return r.HandleError(err)
+			}
+			if err := tx.Create(&charges[i]).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		for i := range statements {
+			if err := tx.Save(&statements[i]).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		if statement != nil {
+			if err := tx.Create(statement).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		if err := tx.Model(line).Select(creditLineProcessingColumns).Updates(line).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет кредитную линию
+func (r *creditLineRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.CreditLine{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список кредитных линий
+func (r *creditLineRepository) List(ctx context.Context, offset, limit int) ([]domain.CreditLine, error) {
+	var lines []domain.CreditLine
+	if err := r.db.Order("id").Offset(offset).Limit(limit).Find(&lines).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return lines, nil
+}
+
+// Count возвращает количество кредитных линий
+func (r *creditLineRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.CreditLine{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
+
+// GetStatements получает выписки по кредитной линии, начиная с последней
+func (r *creditLineRepository) GetStatements(ctx context.Context, lineID uint) ([]domain.CreditLineStatement, error) {
+	var statements []domain.CreditLineStatement
+	if err := r.db.Where("credit_line_id = ?", lineID).Order("period_end DESC").
+		Find(&statements).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return statements, nil
+}
+
+// GetLastStatement получает последнюю выписку по кредитной линии
+func (r *creditLineRepository) GetLastStatement(ctx context.Context, lineID uint) (*domain.CreditLineStatement, error) {
+	var statement domain.CreditLineStatement
+	if err := r.db.Where("credit_line_id = ?", lineID).Order("period_end DESC").
+		First(&statement).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &statement, nil
+}
+
+// GetUnsettledStatements получает выписки, по которым не наступила дата платежа
+// или не внесен минимальный платеж
+func (r *creditLineRepository) GetUnsettledStatements(ctx context.Context, lineID uint) ([]domain.CreditLineStatement, error) {
+	var statements []domain.CreditLineStatement
+	if err := r.db.Where("credit_line_id = ? AND status IN ?", lineID,
+		[]domain.StatementStatus{domain.StatementStatusIssued, domain.StatementStatusOverdue}).
+		Order("period_end").Find(&statements).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return statements, nil
+}
@@ -0,0 +1,125 @@
+package dbaccess
+
+import (
+	"context"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// CreditProductRepository интерфейс репозитория кредитных продуктов
+type CreditProductRepository interface {
+	Repository[domain.CreditProduct]
+	GetByCode(ctx context.Context, code string) (*domain.CreditProduct, error)
+	GetDefault(ctx context.Context) (*domain.CreditProduct, error)
+	GetActive(ctx context.Context) ([]domain.CreditProduct, error)
+	ClearDefault(ctx context.Context, exceptID uint) error
+}
+
+// creditProductRepository реализация репозитория кредитных продуктов
+type creditProductRepository struct {
+	BaseRepository[domain.CreditProduct]
+}
+
+// CreditProductRepositoryInstance создает новый репозиторий кредитных продуктов
+func CreditProductRepositoryInstance(db *gorm.DB) CreditProductRepository {
+	return &creditProductRepository{
+		BaseRepository: *NewBaseRepository[domain.CreditProduct](db),
+	}
+}
+
+// Create создает новый продукт
+func (r *creditProductRepository) Create(ctx context.Context, product *domain.CreditProduct) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает продукт по ID
+func (r *creditProductRepository) GetByID(ctx context.Context, id uint) (*domain.CreditProduct, error) {
+	var product domain.CreditProduct
+	if err := r.db.First(&product, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &product, nil
+}
+
+// GetByCode получает продукт по коду
+func (r *creditProductRepository) GetByCode(ctx context.Context, code string) (*domain.CreditProduct, error) {
+	var product domain.CreditProduct
+	if err := r.db.Where("code = ?", code).First(&product).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &product, nil
+}
+
+// GetDefault получает продукт, используемый кредитным калькулятором без указания продукта
+func (r *creditProductRepository) GetDefault(ctx context.Context) (*domain.CreditProduct, error) {
+	var product domain.CreditProduct
+	if err := r.db.Where("is_default = ? AND is_active = ?", true, true).First(&product).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &product, nil
+}
+
+// GetActive получает продукты, доступные для оформления
+func (r *creditProductRepository) GetActive(ctx context.Context) ([]domain.CreditProduct, error) {
+	var products []domain.CreditProduct
+	if err := r.db.Where("is_active = ?", true).Order("id").Find(&products).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return products, nil
+}
+
+// ClearDefault снимает признак продукта по умолчанию со всех продуктов, кроме указанного
+func (r *creditProductRepository) ClearDefault(ctx context.Context, exceptID uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.CreditProduct{}).Where("id <> ? AND is_default = ?", exceptID, true).
+			Update("is_default", false).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Update обновляет продукт
+func (r *creditProductRepository) Update(ctx context.Context, product *domain.CreditProduct) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(product).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет продукт
+func (r *creditProductRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.CreditProduct{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список продуктов
+func (r *creditProductRepository) List(ctx context.Context, offset, limit int) ([]domain.CreditProduct, error) {
+	var products []domain.CreditProduct
+	if err := r.db.Order("id").Offset(offset).Limit(limit).Find(&products).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return products, nil
+}
+
+// Count возвращает количество продуктов
+func (r *creditProductRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.CreditProduct{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
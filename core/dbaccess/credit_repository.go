@@ -14,15 +14,25 @@ type CreditRepository interface {
 	Repository[domain.Credit]
 	GetByAccountID(ctx context.Context, accountID uint) (*domain.Credit, error)
 	GetActiveCredits(ctx context.Context) ([]domain.Credit, error)
-	GetOverdueCredits(ctx context.Context) ([]domain.Credit, error)
+	GetOverdueCredits(ctx context.Context, now time.Time) ([]domain.Credit, error)
 	GetCreditsByUserID(ctx context.Context, userID uint) ([]domain.Credit, error)
 	UpdateStatus(ctx context.Context, id uint, status domain.CreditStatus) error
 	UpdateNextPayment(ctx context.Context, id uint, nextPayment time.Time) error
 	UpdateTotalPaid(ctx context.Context, id uint, amount float64) error
 	GetCreditsByStatus(ctx context.Context, status domain.CreditStatus) ([]domain.Credit, error)
 	GetCreditsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]domain.Credit, error)
+	Issue(ctx context.Context, credit *domain.Credit, schedule []domain.PaymentSchedule, documents func(*domain.Credit) (*domain.CreditAgreement, []domain.Transaction, error)) error
+	CreatePaymentSchedule(ctx context.Context, schedule []domain.PaymentSchedule) error
 	GetPaymentSchedule(ctx context.Context, creditID uint) ([]domain.PaymentSchedule, error)
+	GetPayment(ctx context.Context, creditID uint, paymentNumber int) (*domain.PaymentSchedule, error)
+	GetDuePayments(ctx context.Context, now time.Time) ([]domain.PaymentSchedule, error)
 	UpdatePaymentSchedule(ctx context.Context, payment *domain.PaymentSchedule) error
+	UpdateWithSchedule(ctx context.Context, credit *domain.Credit, payments []domain.PaymentSchedule) error
+	ApplyPayment(ctx context.Context, credit *domain.Credit, payments []domain.PaymentSchedule, transaction *domain.Transaction) error
+	ApplyPrepayment(ctx context.Context, credit *domain.Credit, prepayment *domain.Prepayment, transaction *domain.Transaction, certificate *domain.CreditClosingCertificate) error
+	GetClosingCertificate(ctx context.Context, creditID uint) (*domain.CreditClosingCertificate, error)
+	GetAgreement(ctx context.Context, creditID uint) (*domain.CreditAgreement, error)
+	CountOverduePayments(ctx context.Context, userID uint) (int64, error)
 }
 
 // creditRepository реализация репозитория кредитов
@@ -78,10 +88,9 @@ func (r *creditRepository) GetActiveCredits(ctx context.Context) ([]domain.Credi
 	return credits, nil
 }
 
-// GetOverdueCredits получает просроченные кредиты
-func (r *creditRepository) GetOverdueCredits(ctx context.Context) ([]domain.Credit, error) {
+// GetOverdueCredits получает кредиты, просроченные на момент now
+func (r *creditRepository) GetOverdueCredits(ctx context.Context, now time.Time) ([]domain.Credit, error) {
 	var credits []domain.Credit
-	now := time.Now()
 	if err := r.db.Where("status = ? AND next_payment < ?", domain.CreditStatusActive, now).Find(&credits).Error; err != nil {
 		return nil, r.HandleError(err)
 	}
@@ -190,21 +199,265 @@ func (r *creditRepository) GetCreditsByDateRange(ctx context.Context, startDate,
 	return credits, nil
 }
 
+// Issue сохраняет выдачу кредита в одной транзакции: кредит с графиком платежей, кредитный
+// договор, зачисление суммы кредита за вычетом комиссии на счет и операции выдачи. Договор
+// и операции ссылаются на номер кредита, поэтому формируются функцией documents после
+// сохранения кредита. При любой ошибке ничего не сохраняется
+func (r *creditRepository) Issue(ctx context.Context, credit *domain.Credit, schedule []domain.PaymentSchedule, documents func(*domain.Credit) (*domain.CreditAgreement, []domain.Transaction, error)) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := credit.Validate(); err != nil {
+			return ErrInvalidData
+		}
+
+		if err := tx.Create(credit).Error; err != nil {
+			return r.HandleError(err)
+		}
+		for i := range schedule {
+			schedule[i].CreditID = credit.ID
+		}
+		if err := tx.Omit("Credit").Create(&schedule).Error; err != nil {
+			return r.HandleError(err)
+		}
+
+		agreement, transactions, err := documents(credit)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(agreement).Error; err != nil {
+			return r.HandleError(err)
+		}
+		if err := tx.Model(&domain.Account{}).Where("id = ?", credit.AccountID).
+			Update("balance", gorm.Expr("balance + ?", credit.Amount-credit.IssueFee)).Error; err != nil {
+			return r.HandleError(err)
+		}
+		for i := range transactions {
+			if err := tx.Create(&transactions[i]).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		return nil
+	})
+}
+
+// CreatePaymentSchedule сохраняет график платежей по уже существующему кредиту
+func (r *creditRepository) CreatePaymentSchedule(ctx context.Context, schedule []domain.PaymentSchedule) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Credit").Create(&schedule).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
 // GetPaymentSchedule получает график платежей по кредиту
 func (r *creditRepository) GetPaymentSchedule(ctx context.Context, creditID uint) ([]domain.PaymentSchedule, error) {
 	var schedule []domain.PaymentSchedule
-	if err := r.db.Where("credit_id = ?", creditID).Find(&schedule).Error; err != nil {
+	if err := r.db.Where("credit_id = ?", creditID).Order("payment_number").Find(&schedule).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return schedule, nil
+}
+
+// GetPayment получает платеж графика по номеру
+func (r *creditRepository) GetPayment(ctx context.Context, creditID uint, paymentNumber int) (*domain.PaymentSchedule, error) {
+	var payment domain.PaymentSchedule
+	if err := r.db.Where("credit_id = ? AND payment_number = ?", creditID, paymentNumber).First(&payment).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &payment, nil
+}
+
+// GetDuePayments получает неоплаченные платежи действующих кредитов, срок которых наступил
+func (r *creditRepository) GetDuePayments(ctx context.Context, now time.Time) ([]domain.PaymentSchedule, error) {
+	var schedule []domain.PaymentSchedule
+	if err := r.db.Joins("JOIN credits ON credits.id = payment_schedules.credit_id AND credits.deleted_at IS NULL").
+		Where("credits.status IN ?", []domain.CreditStatus{domain.CreditStatusActive, domain.CreditStatusOverdue}).
+		Where("payment_schedules.status <> ? AND payment_schedules.due_date <= ?", domain.PaymentStatusPaid, now).
+		Order("payment_schedules.credit_id, payment_schedules.payment_number").
+		Find(&schedule).Error; err != nil {
 		return nil, r.HandleError(err)
 	}
 	return schedule, nil
 }
 
-// UpdatePaymentSchedule обновляет график платежей
+// UpdatePaymentSchedule обновляет платеж графика
 func (r *creditRepository) UpdatePaymentSchedule(ctx context.Context, payment *domain.PaymentSchedule) error {
 	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
-		if err := tx.Save(payment).Error; err != nil {
+		if err := tx.Omit("Credit").Save(payment).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// UpdateWithSchedule обновляет кредит и измененные платежи графика в одной транзакции
+func (r *creditRepository) UpdateWithSchedule(ctx context.Context, credit *domain.Credit, payments []domain.PaymentSchedule) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := credit.Validate(); err != nil {
+			return ErrInvalidData
+		}
+
+		for i := range payments {
+			if err := tx.Omit("Credit").Save(&payments[i]).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		if err := tx.Save(credit).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// ApplyPayment сохраняет платеж по графику в одной транзакции: списывает сумму со счета,
+// добавляет операцию списания, сохраняет измененные платежи и обновляет кредит. Без
+// transaction сохраняются только начисленная неустойка и итоги кредита. Если кредит уже
+// закрыт или один из измененных платежей уже оплачен (например, параллельным списанием
+// того же платежа), ничего не сохраняется
+func (r *creditRepository) ApplyPayment(ctx context.Context, credit *domain.Credit, payments []domain.PaymentSchedule, transaction *domain.Transaction) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := credit.Validate(); err != nil {
+			return ErrInvalidData
+		}
+
+		// Блокируем строку кредита, чтобы параллельные списания проверяли график по очереди
+		res := tx.Model(credit).
+			Where("status IN ?", []domain.CreditStatus{domain.CreditStatusActive, domain.CreditStatusOverdue}).
+			Update("updated_at", tx.NowFunc())
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrCreditNotActive
+		}
+		for i := range payments {
+			res := tx.Model(&payments[i]).Where("status <> ?", domain.PaymentStatusPaid).
+				Select("*").Omit("Credit", "created_at").Updates(&payments[i])
+			if res.Error != nil {
+				return r.HandleError(res.Error)
+			}
+			if res.RowsAffected == 0 {
+				return domain.ErrPaymentAlreadyPaid
+			}
+		}
+
+		if transaction != nil {
+			res := tx.Model(&domain.Account{}).
+				Where("id = ? AND balance >= ?", transaction.FromAccountID, transaction.Amount).
+				Update("balance", gorm.Expr("balance - ?", transaction.Amount))
+			if res.Error != nil {
+				return r.HandleError(res.Error)
+			}
+			if res.RowsAffected == 0 {
+				return domain.ErrInsufficientFunds
+			}
+			if err := tx.Create(transaction).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		if err := tx.Save(credit).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// ApplyPrepayment сохраняет досрочное погашение в одной транзакции: списывает сумму со счета,
+// добавляет операцию списания, заменяет неоплаченную часть графика новой, добавляет запись
+// о платеже, обновляет кредит и, если кредит погашен полностью, сохраняет справку о закрытии.
+// Если после расчета погашения кредит закрыт или по нему прошел платеж (например, запрос
+// отправлен повторно), ничего не сохраняется
+func (r *creditRepository) ApplyPrepayment(ctx context.Context, credit *domain.Credit, prepayment *domain.Prepayment, transaction *domain.Transaction, certificate *domain.CreditClosingCertificate) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := credit.Validate(); err != nil {
+			return ErrInvalidData
+		}
+
+		// Блокируем строку кредита, чтобы параллельные погашения проверяли график по очереди
+		res := tx.Model(credit).
+			Where("status IN ?", []domain.CreditStatus{domain.CreditStatusActive, domain.CreditStatusOverdue}).
+			Update("updated_at", tx.NowFunc())
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrCreditNotActive
+		}
+		var paidLater int64
+		if err := tx.Model(&domain.PaymentSchedule{}).
+			Where("credit_id = ? AND status = ? AND payment_number >= ?",
+				credit.ID, domain.PaymentStatusPaid, prepayment.Payment.PaymentNumber).
+			Count(&paidLater).Error; err != nil {
+			return r.HandleError(err)
+		}
+		if paidLater > 0 {
+			return domain.ErrPrepaymentOutdated
+		}
+
+		res = tx.Model(&domain.Account{}).
+			Where("id = ? AND balance >= ?", transaction.FromAccountID, transaction.Amount).
+			Update("balance", gorm.Expr("balance - ?", transaction.Amount))
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrInsufficientFunds
+		}
+		if err := tx.Create(transaction).Error; err != nil {
+			return r.HandleError(err)
+		}
+
+		if err := tx.Where("credit_id = ? AND status <> ?", credit.ID, domain.PaymentStatusPaid).
+			Delete(&domain.PaymentSchedule{}).Error; err != nil {
 			return r.HandleError(err)
 		}
+		if err := tx.Omit("Credit").Create(&prepayment.Payment).Error; err != nil {
+			return r.HandleError(err)
+		}
+		if len(prepayment.Schedule) > 0 {
+			if err := tx.Omit("Credit").Create(&prepayment.Schedule).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		if err := tx.Save(credit).Error; err != nil {
+			return r.HandleError(err)
+		}
+		if certificate != nil {
+			if err := tx.Create(certificate).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
 		return nil
 	})
 }
+
+// GetClosingCertificate получает справку о полном погашении кредита
+func (r *creditRepository) GetClosingCertificate(ctx context.Context, creditID uint) (*domain.CreditClosingCertificate, error) {
+	var certificate domain.CreditClosingCertificate
+	if err := r.db.Where("credit_id = ?", creditID).First(&certificate).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &certificate, nil
+}
+
+// GetAgreement получает кредитный договор
+func (r *creditRepository) GetAgreement(ctx context.Context, creditID uint) (*domain.CreditAgreement, error) {
+	var agreement domain.CreditAgreement
+	if err := r.db.Where("credit_id = ?", creditID).First(&agreement).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &agreement, nil
+}
+
+// CountOverduePayments возвращает количество платежей пользователя, которые когда-либо были просрочены
+func (r *creditRepository) CountOverduePayments(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.PaymentSchedule{}).
+		Joins("JOIN credits ON credits.id = payment_schedules.credit_id").
+		Where("credits.user_id = ?", userID).
+		Where("payment_schedules.status = ? OR payment_schedules.overdue_days > 0", domain.PaymentStatusOverdue).
+		Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,183 @@
+package dbaccess
+
+import (
+	"context"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// CreditRestructuringRepository интерфейс репозитория реструктуризаций кредитов
+type CreditRestructuringRepository interface {
+	Repository[domain.CreditRestructuring]
+	GetByStatus(ctx context.Context, status domain.RestructuringStatus) ([]domain.CreditRestructuring, error)
+	GetByCreditID(ctx context.Context, creditID uint) ([]domain.CreditRestructuring, error)
+	HasPending(ctx context.Context, creditID uint) (bool, error)
+	Apply(ctx context.Context, restructuring *domain.CreditRestructuring, credit *domain.Credit, version *domain.CreditScheduleVersion, plan *domain.RestructuringPlan) error
+	Reject(ctx context.Context, restructuring *domain.CreditRestructuring) error
+	GetScheduleVersions(ctx context.Context, creditID uint) ([]domain.CreditScheduleVersion, error)
+}
+
+// creditRestructuringRepository реализация репозитория реструктуризаций кредитов
+type creditRestructuringRepository struct {
+	BaseRepository[domain.CreditRestructuring]
+}
+
+// CreditRestructuringRepositoryInstance создает новый репозиторий реструктуризаций кредитов
+func CreditRestructuringRepositoryInstance(db *gorm.DB) CreditRestructuringRepository {
+	return &creditRestructuringRepository{
+		BaseRepository: *NewBaseRepository[domain.CreditRestructuring](db),
+	}
+}
+
+// Create создает заявку на реструктуризацию
+func (r *creditRestructuringRepository) Create(ctx context.Context, restructuring *domain.CreditRestructuring) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(restructuring).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает заявку на реструктуризацию по ID
+func (r *creditRestructuringRepository) GetByID(ctx context.Context, id uint) (*domain.CreditRestructuring, error) {
+	var restructuring domain.CreditRestructuring
+	if err := r.db.First(&restructuring, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &restructuring, nil
+}
+
+// GetByStatus получает заявки в указанном статусе, начиная с самых ранних
+func (r *creditRestructuringRepository) GetByStatus(ctx context.Context, status domain.RestructuringStatus) ([]domain.CreditRestructuring, error) {
+	var restructurings []domain.CreditRestructuring
+	if err := r.db.Where("status = ?", status).Order("created_at, id").Find(&restructurings).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return restructurings, nil
+}
+
+// GetByCreditID получает реструктуризации кредита
+func (r *creditRestructuringRepository) GetByCreditID(ctx context.Context, creditID uint) ([]domain.CreditRestructuring, error) {
+	var restructurings []domain.CreditRestructuring
+	if err := r.db.Where("credit_id = ?", creditID).Order("created_at, id").Find(&restructurings).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return restructurings, nil
+}
+
+// HasPending проверяет, есть ли по кредиту заявка, ожидающая решения
+func (r *creditRestructuringRepository) HasPending(ctx context.Context, creditID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&domain.CreditRestructuring{}).
+		Where("credit_id = ? AND status = ?", creditID, domain.RestructuringStatusPending).
+		Count(&count).Error; err != nil {
+		return false, r.HandleError(err)
+	}
+	return count > 0, nil
+}
+
+// Apply сохраняет одобренную реструктуризацию в одной транзакции: снимок прежнего графика,
+// закрытые переносом просроченные платежи, новую неоплаченную часть графика,
+// новые условия кредита и решение по заявке. Если заявка уже не ожидает решения
+// (например, ее одобрил другой менеджер), ничего не сохраняется
+func (r *creditRestructuringRepository) Apply(ctx context.Context, restructuring *domain.CreditRestructuring, credit *domain.Credit, version *domain.CreditScheduleVersion, plan *domain.RestructuringPlan) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := credit.Validate(); err != nil {
+			return ErrInvalidData
+		}
+
+		if err := r.saveDecision(tx, restructuring); err != nil {
+			return err
+		}
+		if err := tx.Create(version).Error; err != nil {
+			return r.HandleError(err)
+		}
+		for i := range plan.Settled {
+			if err := tx.Omit("Credit").Save(&plan.Settled[i]).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		if err := tx.Where("credit_id = ? AND status <> ?", credit.ID, domain.PaymentStatusPaid).
+			Delete(&domain.PaymentSchedule{}).Error; err != nil {
+			return r.HandleError(err)
+		}
+		if err := tx.Omit("Credit").Create(&plan.Schedule).Error; err != nil {
+			return r.HandleError(err)
+		}
+		if err := tx.Save(credit).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Reject сохраняет отказ по заявке, если она еще ожидает решения
+func (r *creditRestructuringRepository) Reject(ctx context.Context, restructuring *domain.CreditRestructuring) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		return r.saveDecision(tx, restructuring)
+	})
+}
+
+// saveDecision сохраняет заявку с принятым решением условным обновлением: строка
+// меняется, только если в базе заявка еще в статусе RestructuringStatusPending
+func (r *creditRestructuringRepository) saveDecision(tx *gorm.DB, restructuring *domain.CreditRestructuring) error {
+	res := tx.Model(restructuring).Where("status = ?", domain.RestructuringStatusPending).
+		Select("*").Omit("created_at").Updates(restructuring)
+	if res.Error != nil {
+		return r.HandleError(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrRestructuringNotPending
+	}
+	return nil
+}
+
+// GetScheduleVersions получает снимки прежних графиков кредита
+func (r *creditRestructuringRepository) GetScheduleVersions(ctx context.Context, creditID uint) ([]domain.CreditScheduleVersion, error) {
+	var versions []domain.CreditScheduleVersion
+	if err := r.db.Where("credit_id = ?", creditID).Order("version").Find(&versions).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return versions, nil
+}
+
+// Update обновляет заявку на реструктуризацию
+func (r *creditRestructuringRepository) Update(ctx context.Context, restructuring *domain.CreditRestructuring) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(restructuring).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет заявку на реструктуризацию
+func (r *creditRestructuringRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.CreditRestructuring{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список заявок на реструктуризацию
+func (r *creditRestructuringRepository) List(ctx context.Context, offset, limit int) ([]domain.CreditRestructuring, error) {
+	var restructurings []domain.CreditRestructuring
+	if err := r.db.Order("id").Offset(offset).Limit(limit).Find(&restructurings).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return restructurings, nil
+}
+
+// Count возвращает количество заявок на реструктуризацию
+func (r *creditRestructuringRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.CreditRestructuring{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
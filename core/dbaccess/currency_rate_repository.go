@@ -0,0 +1,141 @@
+package dbaccess
+
+import (
+	"context"
+	"errors"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// CurrencyRateRepository интерфейс репозитория курсов валют
+type CurrencyRateRepository interface {
+	Repository[domain.CurrencyRate]
+	GetOnDate(ctx context.Context, date, charCode string) ([]domain.CurrencyRate, error)
+	SaveRates(ctx context.Context, rates []domain.CurrencyRate) error
+}
+
+// currencyRateRepository реализация репозитория курсов валют
+type currencyRateRepository struct {
+	BaseRepository[domain.CurrencyRate]
+}
+
+// CurrencyRateRepositoryInstance создает новый репозиторий курсов валют
+func CurrencyRateRepositoryInstance(db *gorm.DB) CurrencyRateRepository {
+	return &currencyRateRepository{
+		BaseRepository: *NewBaseRepository[domain.CurrencyRate](db),
+	}
+}
+
+// Create создает курс валюты
+func (r *currencyRateRepository) Create(ctx context.Context, rate *domain.CurrencyRate) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(rate).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает курс валюты по ID
+func (r *currencyRateRepository) GetByID(ctx context.Context, id uint) (*domain.CurrencyRate, error) {
+	var rate domain.CurrencyRate
+	if err := r.db.First(&rate, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &rate, nil
+}
+
+// GetOnDate получает курсы, действующие на дату date: установленные на эту дату
+// или, если на нее курсы не устанавливались, на последнюю дату перед ней.
+// С charCode возвращается курс одной валюты
+func (r *currencyRateRepository) GetOnDate(ctx context.Context, date, charCode string) ([]domain.CurrencyRate, error) {
+	var latest domain.CurrencyRate
+	err := r.db.Where("date <= ?", date).Order("date DESC").First(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.HandleError(err)
+	}
+
+	query := r.db.Where("date = ?", latest.Date)
+	if charCode != "" {
+		query = query.Where("char_code = ?", charCode)
+	}
+	var rates []domain.CurrencyRate
+	if err := query.Order("char_code").Find(&rates).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return rates, nil
+}
+
+// SaveRates сохраняет курсы: новые добавляются, у существующих на ту же дату
+// обновляются номинал, курс и наименование
+func (r *currencyRateRepository) SaveRates(ctx context.Context, rates []domain.CurrencyRate) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		for i := range rates {
+			var existing domain.CurrencyRate
+			err := tx.Where("date = ? AND char_code = ?", rates[i].Date, rates[i].CharCode).First(&existing).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := tx.Create(&rates[i]).Error; err != nil {
+					return r.HandleError(err)
+				}
+				continue
+			case err != nil:
+				return r.HandleError(err)
+			}
+			existing.NumCode = rates[i].NumCode
+			existing.Name = rates[i].Name
+			existing.Nominal = rates[i].Nominal
+			existing.Value = rates[i].Value
+			existing.UnitRate = rates[i].UnitRate
+			if err := tx.Save(&existing).Error; err != nil {
+				return r.HandleError(err)
+			}
+			rates[i] = existing
+		}
+		return nil
+	})
+}
+
+// Update обновляет курс валюты
+func (r *currencyRateRepository) Update(ctx context.Context, rate *domain.CurrencyRate) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(rate).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет курс валюты. Запись удаляется физически, чтобы курс на ту же
+// дату можно было загрузить снова без конфликта уникального индекса
+func (r *currencyRateRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&domain.CurrencyRate{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает курсы валют в порядке дат
+func (r *currencyRateRepository) List(ctx context.Context, offset, limit int) ([]domain.CurrencyRate, error) {
+	var rates []domain.CurrencyRate
+	if err := r.db.Order("date, char_code").Offset(offset).Limit(limit).Find(&rates).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return rates, nil
+}
+
+// Count возвращает количество курсов валют
+func (r *currencyRateRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.CurrencyRate{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,93 @@
+package dbaccess
+
+import (
+	"context"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// DepositProductRepository интерфейс репозитория продуктов вкладов
+type DepositProductRepository interface {
+	Repository[domain.DepositProduct]
+	GetActive(ctx context.Context) ([]domain.DepositProduct, error)
+}
+
+// depositProductRepository реализация репозитория продуктов вкладов
+type depositProductRepository struct {
+	BaseRepository[domain.DepositProduct]
+}
+
+// DepositProductRepositoryInstance создает новый репозиторий продуктов вкладов
+func DepositProductRepositoryInstance(db *gorm.DB) DepositProductRepository {
+	return &depositProductRepository{
+		BaseRepository: *NewBaseRepository[domain.DepositProduct](db),
+	}
+}
+
+// Create создает новый продукт
+func (r *depositProductRepository) Create(ctx context.Context, product *domain.DepositProduct) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает продукт по ID
+func (r *depositProductRepository) GetByID(ctx context.Context, id uint) (*domain.DepositProduct, error) {
+	var product domain.DepositProduct
+	if err := r.db.First(&product, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &product, nil
+}
+
+// GetActive получает продукты, доступные для открытия вклада
+func (r *depositProductRepository) GetActive(ctx context.Context) ([]domain.DepositProduct, error) {
+	var products []domain.DepositProduct
+	if err := r.db.Where("is_active = ?", true).Order("id").Find(&products).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return products, nil
+}
+
+// Update обновляет продукт
+func (r *depositProductRepository) Update(ctx context.Context, product *domain.DepositProduct) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(product).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет продукт
+func (r *depositProductRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.DepositProduct{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список продуктов
+func (r *depositProductRepository) List(ctx context.Context, offset, limit int) ([]domain.DepositProduct, error) {
+	var products []domain.DepositProduct
+	if err := r.db.Order("id").Offset(offset).Limit(limit).Find(&products).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return products, nil
+}
+
+// Count возвращает количество продуктов
+func (r *depositProductRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.DepositProduct{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,315 @@
+package dbaccess
+
+import (
+	"context"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// DisputeRepository интерфейс репозитория споров по операциям
+type DisputeRepository interface {
+	Repository[domain.Dispute]
+	CreateWithEvent(ctx context.Context, dispute *domain.Dispute, event *domain.DisputeEvent) error
+	UpdateWithEvent(ctx context.Context, dispute *domain.Dispute, event *domain.DisputeEvent) error
+	GetByUserID(ctx context.Context, userID uint) ([]domain.Dispute, error)
+	GetByStatus(ctx context.Context, status domain.DisputeStatus) ([]domain.Dispute, error)
+	GetByTransactionID(ctx context.Context, transactionID uint) (*domain.Dispute, error)
+	GetUnresolved(ctx context.Context) ([]domain.Dispute, error)
+	AddEvent(ctx context.Context, event *domain.DisputeEvent) error
+	AddAttachment(ctx context.Context, attachment *domain.DisputeAttachment, event *domain.DisputeEvent) error
+	CountAttachments(ctx context.Context, disputeID uint) (int64, error)
+	PostProvisionalCredit(ctx context.Context, dispute *domain.Dispute, credit *domain.Transaction, event *domain.DisputeEvent) error
+	Resolve(ctx context.Context, dispute *domain.Dispute, posting *domain.Transaction, events ...*domain.DisputeEvent) error
+}
+
+// unresolvedDisputeStatuses статусы споров, по которым еще не принято решение
+var unresolvedDisputeStatuses = []domain.DisputeStatus{
+	domain.DisputeStatusOpen,
+	domain.DisputeStatusUnderReview,
+}
+
+// disputeRepository реализация репозитория споров
+type disputeRepository struct {
+	BaseRepository[domain.Dispute]
+}
+
+// DisputeRepositoryInstance создает новый репозиторий споров
+func DisputeRepositoryInstance(db *gorm.DB) DisputeRepository {
+	return &disputeRepository{
+		BaseRepository: *NewBaseRepository[domain.Dispute](db),
+	}
+}
+
+// Create создает спор
+func (r *disputeRepository) Create(ctx context.Context, dispute *domain.Dispute) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Events", "Attachments").Create(dispute).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// CreateWithEvent создает спор и первую запись его истории в одной транзакции
+func (r *disputeRepository) CreateWithEvent(ctx context.Context, dispute *domain.Dispute, event *domain.DisputeEvent) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Events", "Attachments").Create(dispute).Error; err != nil {
+			return r.HandleError(err)
+		}
+		event.DisputeID = dispute.ID
+		if err := tx.Create(event).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает спор по ID вместе с историей и вложениями
+func (r *disputeRepository) GetByID(ctx context.Context, id uint) (*domain.Dispute, error) {
+	var dispute domain.Dispute
+	if err := r.db.Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	}).Preload("Attachments").First(&dispute, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &dispute, nil
+}
+
+// GetByUserID получает споры пользователя
+func (r *disputeRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.Dispute, error) {
+	var disputes []domain.Dispute
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&disputes).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return disputes, nil
+}
+
+// GetByStatus получает споры в указанном статусе, начиная с ближайшего срока решения
+func (r *disputeRepository) GetByStatus(ctx context.Context, status domain.DisputeStatus) ([]domain.Dispute, error) {
+	var disputes []domain.Dispute
+	if err := r.db.Where("status = ?", status).Order("resolution_due_at").Find(&disputes).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return disputes, nil
+}
+
+// GetByTransactionID получает спор по операции
+func (r *disputeRepository) GetByTransactionID(ctx context.Context, transactionID uint) (*domain.Dispute, error) {
+	var dispute domain.Dispute
+	if err := r.db.Where("transaction_id = ?", transactionID).First(&dispute).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &dispute, nil
+}
+
+// GetUnresolved получает споры, по которым не принято решение, начиная с ближайшего срока
+func (r *disputeRepository) GetUnresolved(ctx context.Context) ([]domain.Dispute, error) {
+	var disputes []domain.Dispute
+	if err := r.db.Where("status IN ?", unresolvedDisputeStatuses).
+		Order("resolution_due_at").Find(&disputes).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return disputes, nil
+}
+
+// Update обновляет спор
+func (r *disputeRepository) Update(ctx context.Context, dispute *domain.Dispute) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Events", "Attachments").Save(dispute).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// UpdateWithEvent обновляет спор и добавляет запись в его историю в одной транзакции
+func (r *disputeRepository) UpdateWithEvent(ctx context.Context, dispute *domain.Dispute, event *domain.DisputeEvent) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Omit("Events", "Attachments").Save(dispute).Error; err != nil {
+			return r.HandleError(err)
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// PostProvisionalCredit зачисляет оспариваемую сумму, помечает операцию возвращенной по спору
+// и сохраняет ссылку на зачисление в одной транзакции. Спор обновляется, только если по нему
+// еще нет ни решения, ни предварительного зачисления
+func (r *disputeRepository) PostProvisionalCredit(ctx context.Context, dispute *domain.Dispute, credit *domain.Transaction, event *domain.DisputeEvent) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Model(&domain.Dispute{}).
+			Where("id = ? AND status IN ? AND provisional_credit_tx_id IS NULL", dispute.ID, unresolvedDisputeStatuses).
+			Update("updated_at", tx.NowFunc())
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return r.conflict(tx, dispute.ID)
+		}
+		if err := r.post(tx, dispute, credit); err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Dispute{}).Where("id = ?", dispute.ID).
+			Update("provisional_credit_tx_id", credit.ID).Error; err != nil {
+			return r.HandleError(err)
+		}
+		dispute.ProvisionalCreditTxID = &credit.ID
+		if err := tx.Create(event).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Resolve сохраняет решение по спору вместе с проводкой по нему в одной транзакции.
+// Спор обновляется, только если решение по нему еще не принято, а предварительное
+// зачисление не менялось с момента чтения. posting - зачисление суммы спора
+// (TransactionTypeDeposit) или списание предварительного зачисления, может быть nil
+func (r *disputeRepository) Resolve(ctx context.Context, dispute *domain.Dispute, posting *domain.Transaction, events ...*domain.DisputeEvent) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		query := tx.Model(&domain.Dispute{}).Where("id = ? AND status IN ?", dispute.ID, unresolvedDisputeStatuses)
+		if dispute.ProvisionalCreditTxID == nil {
+			query = query.Where("provisional_credit_tx_id IS NULL")
+		} else {
+			query = query.Where("provisional_credit_tx_id = ?", *dispute.ProvisionalCreditTxID)
+		}
+		res := query.Updates(map[string]interface{}{
+			"status":      dispute.Status,
+			"operator_id": dispute.OperatorID,
+			"resolution":  dispute.Resolution,
+			"resolved_at": dispute.ResolvedAt,
+		})
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return r.conflict(tx, dispute.ID)
+		}
+		if posting != nil {
+			if err := r.post(tx, dispute, posting); err != nil {
+				return err
+			}
+		}
+		for _, event := range events {
+			if err := tx.Create(event).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		return nil
+	})
+}
+
+// post проводит зачисление или списание по спору. Зачисление переводит оспоренную
+// операцию в TransactionStatusChargedBack, чтобы ее последующая отмена не вернула
+// сумму повторно; списание предварительного зачисления возвращает операции статус
+// TransactionStatusCompleted
+func (r *disputeRepository) post(tx *gorm.DB, dispute *domain.Dispute, posting *domain.Transaction) error {
+	var original domain.Transaction
+	if err := tx.First(&original, dispute.TransactionID).Error; err != nil {
+		return r.HandleError(err)
+	}
+
+	amount := posting.Amount
+	if posting.Type == domain.TransactionTypeDeposit {
+		res := tx.Model(&original).Where("status = ?", domain.TransactionStatusCompleted).
+			Update("status", domain.TransactionStatusChargedBack)
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrDisputedTransactionVoided
+		}
+	} else {
+		amount = -amount
+		if err := tx.Model(&original).Where("status = ?", domain.TransactionStatusChargedBack).
+			Update("status", domain.TransactionStatusCompleted).Error; err != nil {
+			return r.HandleError(err)
+		}
+	}
+
+	if err := tx.Model(&domain.Account{}).Where("id = ?", dispute.AccountID).
+		Update("balance", gorm.Expr("balance + ?", amount)).Error; err != nil {
+		return r.HandleError(err)
+	}
+	if err := tx.Create(posting).Error; err != nil {
+		return r.HandleError(err)
+	}
+	return nil
+}
+
+// conflict возвращает причину, по которой условное обновление спора не затронуло строк
+func (r *disputeRepository) conflict(tx *gorm.DB, id uint) error {
+	var current domain.Dispute
+	if err := tx.First(&current, id).Error; err != nil {
+		return r.HandleError(err)
+	}
+	if current.IsResolved() {
+		return domain.ErrDisputeClosed
+	}
+	return domain.ErrProvisionalCreditPosted
+}
+
+// AddEvent добавляет запись в историю спора
+func (r *disputeRepository) AddEvent(ctx context.Context, event *domain.DisputeEvent) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// AddAttachment сохраняет описание вложения и запись об этом в истории спора
+func (r *disputeRepository) AddAttachment(ctx context.Context, attachment *domain.DisputeAttachment, event *domain.DisputeEvent) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(attachment).Error; err != nil {
+			return r.HandleError(err)
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// CountAttachments возвращает количество вложений спора
+func (r *disputeRepository) CountAttachments(ctx context.Context, disputeID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.DisputeAttachment{}).Where("dispute_id = ?", disputeID).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
+
+// Delete удаляет спор
+func (r *disputeRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.Dispute{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список споров
+func (r *disputeRepository) List(ctx context.Context, offset, limit int) ([]domain.Dispute, error) {
+	var disputes []domain.Dispute
+	if err := r.db.Order("created_at DESC").Offset(offset).Limit(limit).Find(&disputes).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return disputes, nil
+}
+
+// Count возвращает количество споров
+func (r *disputeRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.Dispute{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
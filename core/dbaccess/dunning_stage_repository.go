@@ -0,0 +1,93 @@
+package dbaccess
+
+import (
+	"context"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// DunningStageRepository интерфейс репозитория стадий взыскания
+type DunningStageRepository interface {
+	Repository[domain.DunningStage]
+	GetActive(ctx context.Context) ([]domain.DunningStage, error)
+}
+
+// dunningStageRepository реализация репозитория стадий взыскания
+type dunningStageRepository struct {
+	BaseRepository[domain.DunningStage]
+}
+
+// DunningStageRepositoryInstance создает новый репозиторий стадий взыскания
+func DunningStageRepositoryInstance(db *gorm.DB) DunningStageRepository {
+	return &dunningStageRepository{
+		BaseRepository: *NewBaseRepository[domain.DunningStage](db),
+	}
+}
+
+// Create создает новую стадию
+func (r *dunningStageRepository) Create(ctx context.Context, stage *domain.DunningStage) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(stage).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает стадию по ID
+func (r *dunningStageRepository) GetByID(ctx context.Context, id uint) (*domain.DunningStage, error) {
+	var stage domain.DunningStage
+	if err := r.db.First(&stage, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &stage, nil
+}
+
+// GetActive получает действующие стадии в порядке нарастания просрочки
+func (r *dunningStageRepository) GetActive(ctx context.Context) ([]domain.DunningStage, error) {
+	var stages []domain.DunningStage
+	if err := r.db.Where("is_active = ?", true).Order("days_past_due, id").Find(&stages).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return stages, nil
+}
+
+// Update обновляет стадию
+func (r *dunningStageRepository) Update(ctx context.Context, stage *domain.DunningStage) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(stage).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет стадию
+func (r *dunningStageRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.DunningStage{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список стадий в порядке нарастания просрочки
+func (r *dunningStageRepository) List(ctx context.Context, offset, limit int) ([]domain.DunningStage, error) {
+	var stages []domain.DunningStage
+	if err := r.db.Order("days_past_due, id").Offset(offset).Limit(limit).Find(&stages).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return stages, nil
+}
+
+// Count возвращает количество стадий
+func (r *dunningStageRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.DunningStage{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,223 @@
+package dbaccess
+
+import (
+	"context"
+	"time"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// JobRepository интерфейс репозитория фоновых задач и истории их запусков
+type JobRepository interface {
+	Repository[domain.Job]
+	GetByName(ctx context.Context, name string) (*domain.Job, error)
+	UpdateSchedule(ctx context.Context, job *domain.Job) error
+
+	AcquireLease(ctx context.Context, job *domain.Job, owner, token string, until, now, dueBy time.Time) (bool, error)
+	RenewLease(ctx context.Context, jobID uint, token string, until time.Time) error
+	AbandonRuns(ctx context.Context, jobID uint, now time.Time) error
+	CreateRun(ctx context.Context, run *domain.JobRun) error
+	FinishRun(ctx context.Context, job *domain.Job, run *domain.JobRun) error
+	GetRuns(ctx context.Context, jobID uint, limit int) ([]domain.JobRun, error)
+}
+
+// jobRepository реализация репозитория фоновых задач
+type jobRepository struct {
+	BaseRepository[domain.Job]
+}
+
+// JobRepositoryInstance создает новый репозиторий фоновых задач
+func JobRepositoryInstance(db *gorm.DB) JobRepository {
+	return &jobRepository{
+		BaseRepository: *NewBaseRepository[domain.Job](db),
+	}
+}
+
+// Create создает задачу
+func (r *jobRepository) Create(ctx context.Context, job *domain.Job) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает задачу по ID
+func (r *jobRepository) GetByID(ctx context.Context, id uint) (*domain.Job, error) {
+	var job domain.Job
+	if err := r.db.First(&job, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &job, nil
+}
+
+// GetByName получает задачу по имени
+func (r *jobRepository) GetByName(ctx context.Context, name string) (*domain.Job, error) {
+	var job domain.Job
+	if err := r.db.Where("name = ?", name).First(&job).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &job, nil
+}
+
+// UpdateSchedule сохраняет описание, расписание и состояние задачи, не затрагивая
+// блокировку и итоги запусков, которые в это время может менять другой экземпляр
+func (r *jobRepository) UpdateSchedule(ctx context.Context, job *domain.Job) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(job).Select("description", "schedule", "status", "next_run_at").
+			Updates(job).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// AcquireLease захватывает задачу до момента until, если она свободна или срок
+// прежней блокировки истек к моменту now. Для запуска по расписанию передается dueBy:
+// задача должна быть активна, а срок запуска не позже dueBy, так один срок не выполнится
+// дважды, если другой экземпляр уже успел его обработать. Для ручного запуска dueBy
+// нулевой. Возвращает false, если задача занята
+func (r *jobRepository) AcquireLease(ctx context.Context, job *domain.Job, owner, token string, until, now, dueBy time.Time) (bool, error) {
+	query := r.db.Model(&domain.Job{}).
+		Where("id = ?", job.ID).
+		Where("lease_until IS NULL OR lease_until < ?", now)
+	if !dueBy.IsZero() {
+		query = query.Where("status = ? AND next_run_at <= ?", domain.JobStatusActive, dueBy)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"lease_owner": owner,
+		"lease_token": token,
+		"lease_until": until,
+	})
+	if result.Error != nil {
+		return false, r.HandleError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	job.LeaseOwner = owner
+	job.LeaseToken = token
+	job.LeaseUntil = &until
+	return true, nil
+}
+
+// RenewLease продлевает блокировку, пока задача выполняется
+func (r *jobRepository) RenewLease(ctx context.Context, jobID uint, token string, until time.Time) error {
+	if err := r.db.Model(&domain.Job{}).
+		Where("id = ? AND lease_token = ?", jobID, token).
+		Update("lease_until", until).Error; err != nil {
+		return r.HandleError(err)
+	}
+	return nil
+}
+
+// AbandonRuns отмечает как неуспешные запуски, прерванные остановкой экземпляра:
+// раз блокировка захвачена заново, прежний запуск уже не завершится
+func (r *jobRepository) AbandonRuns(ctx context.Context, jobID uint, now time.Time) error {
+	if err := r.db.Model(&domain.JobRun{}).
+		Where("job_id = ? AND status = ?", jobID, domain.JobRunRunning).
+		Updates(map[string]interface{}{
+			"status":      domain.JobRunFailed,
+			"finished_at": now,
+			"error":       "lease expired before the run finished",
+		}).Error; err != nil {
+		return r.HandleError(err)
+	}
+	return nil
+}
+
+// CreateRun создает запись о начатом запуске
+func (r *jobRepository) CreateRun(ctx context.Context, run *domain.JobRun) error {
+	if err := r.db.Create(run).Error; err != nil {
+		return r.HandleError(err)
+	}
+	return nil
+}
+
+// FinishRun в одной транзакции сохраняет итог запуска, следующий срок
+// по расписанию и снимает блокировку задачи
+func (r *jobRepository) FinishRun(ctx context.Context, job *domain.Job, run *domain.JobRun) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(run).Error; err != nil {
+			return r.HandleError(err)
+		}
+		updates := map[string]interface{}{
+			"last_run_at":      job.LastRunAt,
+			"last_status":      job.LastStatus,
+			"last_error":       job.LastError,
+			"last_duration_ms": job.LastDurationMs,
+			"lease_owner":      "",
+			"lease_token":      "",
+			"lease_until":      nil,
+		}
+		// Ручной запуск не сдвигает расписание. Срок следующего запуска меняем только
+		// у активной задачи: пока задача выполнялась, администратор мог ее приостановить.
+		// Если блокировку перехватил другой экземпляр, расписанием управляет он
+		if run.Trigger != domain.JobTriggerManual {
+			if err := tx.Model(&domain.Job{}).
+				Where("id = ? AND status = ? AND lease_token = ?", job.ID, domain.JobStatusActive, job.LeaseToken).
+				Update("next_run_at", job.NextRunAt).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		if err := tx.Model(&domain.Job{}).
+			Where("id = ? AND lease_token = ?", job.ID, job.LeaseToken).
+			Updates(updates).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetRuns получает последние запуски задачи, начиная с самых новых
+func (r *jobRepository) GetRuns(ctx context.Context, jobID uint, limit int) ([]domain.JobRun, error) {
+	var runs []domain.JobRun
+	if err := r.db.Where("job_id = ?", jobID).Order("started_at DESC, id DESC").
+		Limit(limit).Find(&runs).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return runs, nil
+}
+
+// Update обновляет задачу
+func (r *jobRepository) Update(ctx context.Context, job *domain.Job) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(job).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет задачу
+func (r *jobRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.Job{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список задач по имени
+func (r *jobRepository) List(ctx context.Context, offset, limit int) ([]domain.Job, error) {
+	var jobs []domain.Job
+	if err := r.db.Order("name").Offset(offset).Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return jobs, nil
+}
+
+// Count возвращает количество задач
+func (r *jobRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.Job{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
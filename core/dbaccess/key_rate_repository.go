@@ -0,0 +1,142 @@
+package dbaccess
+
+import (
+	"context"
+	"errors"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// KeyRateRepository интерфейс репозитория истории ключевой ставки
+type KeyRateRepository interface {
+	Repository[domain.KeyRate]
+	GetLatest(ctx context.Context) (*domain.KeyRate, error)
+	GetRange(ctx context.Context, from, to string) ([]domain.KeyRate, error)
+	GetEffective(ctx context.Context, date string) (*domain.KeyRate, error)
+	SaveRates(ctx context.Context, rates []domain.KeyRate) error
+}
+
+// keyRateRepository реализация репозитория истории ключевой ставки
+type keyRateRepository struct {
+	BaseRepository[domain.KeyRate]
+}
+
+// KeyRateRepositoryInstance создает новый репозиторий истории ключевой ставки
+func KeyRateRepositoryInstance(db *gorm.DB) KeyRateRepository {
+	return &keyRateRepository{
+		BaseRepository: *NewBaseRepository[domain.KeyRate](db),
+	}
+}
+
+// Create создает запись истории
+func (r *keyRateRepository) Create(ctx context.Context, rate *domain.KeyRate) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(rate).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает запись истории по ID
+func (r *keyRateRepository) GetByID(ctx context.Context, id uint) (*domain.KeyRate, error) {
+	var rate domain.KeyRate
+	if err := r.db.First(&rate, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &rate, nil
+}
+
+// GetLatest получает последнюю известную ставку
+func (r *keyRateRepository) GetLatest(ctx context.Context) (*domain.KeyRate, error) {
+	var rate domain.KeyRate
+	if err := r.db.Order("date DESC").First(&rate).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &rate, nil
+}
+
+// GetRange получает историю ставки за период с from по to включительно в порядке дат
+func (r *keyRateRepository) GetRange(ctx context.Context, from, to string) ([]domain.KeyRate, error) {
+	var rates []domain.KeyRate
+	if err := r.db.Where("date BETWEEN ? AND ?", from, to).Order("date").Find(&rates).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return rates, nil
+}
+
+// GetEffective получает ставку, действовавшую на дату date
+func (r *keyRateRepository) GetEffective(ctx context.Context, date string) (*domain.KeyRate, error) {
+	var rate domain.KeyRate
+	if err := r.db.Where("date <= ?", date).Order("date DESC").First(&rate).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &rate, nil
+}
+
+// SaveRates сохраняет значения ставки: новые даты добавляются, у существующих
+// обновляются ставка и источник. UpdatedAt отмечает последнее подтверждение значения источником
+func (r *keyRateRepository) SaveRates(ctx context.Context, rates []domain.KeyRate) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		for i := range rates {
+			var existing domain.KeyRate
+			err := tx.Where("date = ?", rates[i].Date).First(&existing).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := tx.Create(&rates[i]).Error; err != nil {
+					return r.HandleError(err)
+				}
+				continue
+			case err != nil:
+				return r.HandleError(err)
+			}
+			existing.Rate = rates[i].Rate
+			existing.Source = rates[i].Source
+			if err := tx.Save(&existing).Error; err != nil {
+				return r.HandleError(err)
+			}
+			rates[i] = existing
+		}
+		return nil
+	})
+}
+
+// Update обновляет запись истории
+func (r *keyRateRepository) Update(ctx context.Context, rate *domain.KeyRate) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(rate).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет запись истории
+func (r *keyRateRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&domain.KeyRate{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает историю ставки в порядке дат
+func (r *keyRateRepository) List(ctx context.Context, offset, limit int) ([]domain.KeyRate, error) {
+	var rates []domain.KeyRate
+	if err := r.db.Order("date").Offset(offset).Limit(limit).Find(&rates).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return rates, nil
+}
+
+// Count возвращает количество записей истории
+func (r *keyRateRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.KeyRate{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
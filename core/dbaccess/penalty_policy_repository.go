@@ -0,0 +1,115 @@
+package dbaccess
+
+import (
+	"context"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// PenaltyPolicyRepository интерфейс репозитория политик неустойки
+type PenaltyPolicyRepository interface {
+	Repository[domain.PenaltyPolicy]
+	GetByCode(ctx context.Context, code string) (*domain.PenaltyPolicy, error)
+	GetDefault(ctx context.Context) (*domain.PenaltyPolicy, error)
+	ClearDefault(ctx context.Context, exceptID uint) error
+}
+
+// penaltyPolicyRepository реализация репозитория политик неустойки
+type penaltyPolicyRepository struct {
+	BaseRepository[domain.PenaltyPolicy]
+}
+
+// PenaltyPolicyRepositoryInstance создает новый репозиторий политик неустойки
+func PenaltyPolicyRepositoryInstance(db *gorm.DB) PenaltyPolicyRepository {
+	return &penaltyPolicyRepository{
+		BaseRepository: *NewBaseRepository[domain.PenaltyPolicy](db),
+	}
+}
+
+// Create создает новую политику
+func (r *penaltyPolicyRepository) Create(ctx context.Context, policy *domain.PenaltyPolicy) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(policy).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// GetByID получает политику по ID
+func (r *penaltyPolicyRepository) GetByID(ctx context.Context, id uint) (*domain.PenaltyPolicy, error) {
+	var policy domain.PenaltyPolicy
+	if err := r.db.First(&policy, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &policy, nil
+}
+
+// GetByCode получает политику по коду
+func (r *penaltyPolicyRepository) GetByCode(ctx context.Context, code string) (*domain.PenaltyPolicy, error) {
+	var policy domain.PenaltyPolicy
+	if err := r.db.Where("code = ?", code).First(&policy).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &policy, nil
+}
+
+// GetDefault получает политику, применяемую к кредитам без указанной политики
+func (r *penaltyPolicyRepository) GetDefault(ctx context.Context) (*domain.PenaltyPolicy, error) {
+	var policy domain.PenaltyPolicy
+	if err := r.db.Where("is_default = ?", true).First(&policy).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &policy, nil
+}
+
+// ClearDefault снимает признак политики по умолчанию со всех политик, кроме указанной
+func (r *penaltyPolicyRepository) ClearDefault(ctx context.Context, exceptID uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.PenaltyPolicy{}).Where("id <> ? AND is_default = ?", exceptID, true).
+			Update("is_default", false).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Update обновляет политику
+func (r *penaltyPolicyRepository) Update(ctx context.Context, policy *domain.PenaltyPolicy) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(policy).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет политику
+func (r *penaltyPolicyRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.PenaltyPolicy{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список политик
+func (r *penaltyPolicyRepository) List(ctx context.Context, offset, limit int) ([]domain.PenaltyPolicy, error) {
+	var policies []domain.PenaltyPolicy
+	if err := r.db.Order("id").Offset(offset).Limit(limit).Find(&policies).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return policies, nil
+}
+
+// Count возвращает количество политик
+func (r *penaltyPolicyRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.PenaltyPolicy{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
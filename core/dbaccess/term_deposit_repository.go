@@ -0,0 +1,153 @@
+package dbaccess
+
+import (
+	"context"
+
+	"FinanceGolang/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// TermDepositRepository интерфейс репозитория срочных вкладов
+type TermDepositRepository interface {
+	Repository[domain.TermDeposit]
+	GetByUserID(ctx context.Context, userID uint) ([]domain.TermDeposit, error)
+	GetActive(ctx context.Context) ([]domain.TermDeposit, error)
+	Open(ctx context.Context, deposit *domain.TermDeposit, transaction func(*domain.TermDeposit) *domain.Transaction) error
+	Settle(ctx context.Context, deposit *domain.TermDeposit, credits []domain.Transaction) error
+}
+
+// termDepositRepository реализация репозитория срочных вкладов
+type termDepositRepository struct {
+	BaseRepository[domain.TermDeposit]
+}
+
+// TermDepositRepositoryInstance создает новый репозиторий срочных вкладов
+func TermDepositRepositoryInstance(db *gorm.DB) TermDepositRepository {
+	return &termDepositRepository{
+		BaseRepository: *NewBaseRepository[domain.TermDeposit](db),
+	}
+}
+
+// Create создает новый вклад
+func (r *termDepositRepository) Create(ctx context.Context, deposit *domain.TermDeposit) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(deposit).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Open списывает сумму вклада со счета клиента и открывает вклад в одной транзакции.
+// Списание выполняется, только если на счете достаточно средств
+func (r *termDepositRepository) Open(ctx context.Context, deposit *domain.TermDeposit, transaction func(*domain.TermDeposit) *domain.Transaction) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Account{}).Where("id = ? AND balance >= ?", deposit.AccountID, deposit.Amount).
+			Update("balance", gorm.Expr("balance - ?", deposit.Amount))
+		if result.Error != nil {
+			return r.HandleError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrInsufficientFunds
+		}
+		if err := tx.Create(deposit).Error; err != nil {
+			return r.HandleError(err)
+		}
+		if err := tx.Create(transaction(deposit)).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Settle сохраняет вклад вместе с зачислением выплат на счет клиента в одной транзакции.
+// Вклад сохраняется, только если он еще действует, чтобы расторжение и ежедневная
+// обработка не выплатили его дважды
+func (r *termDepositRepository) Settle(ctx context.Context, deposit *domain.TermDeposit, credits []domain.Transaction) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(deposit).Where("status = ?", domain.TermDepositStatusActive).
+			Select("*").Omit("created_at").Updates(deposit)
+		if result.Error != nil {
+			return r.HandleError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrTermDepositClosed
+		}
+		for i := range credits {
+			if err := tx.Model(&domain.Account{}).Where("id = ?", credits[i].ToAccountID).
+				Update("balance", gorm.Expr("balance + ?", credits[i].Amount)).Error; err != nil {
+				return r.HandleError(err)
+			}
+			if err := tx.Create(&credits[i]).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetByID получает вклад по ID
+func (r *termDepositRepository) GetByID(ctx context.Context, id uint) (*domain.TermDeposit, error) {
+	var deposit domain.TermDeposit
+	if err := r.db.First(&deposit, id).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &deposit, nil
+}
+
+// GetByUserID получает вклады пользователя
+func (r *termDepositRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.TermDeposit, error) {
+	var deposits []domain.TermDeposit
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&deposits).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return deposits, nil
+}
+
+// GetActive получает действующие вклады
+func (r *termDepositRepository) GetActive(ctx context.Context) ([]domain.TermDeposit, error) {
+	var deposits []domain.TermDeposit
+	if err := r.db.Where("status = ?", domain.TermDepositStatusActive).Order("id").Find(&deposits).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return deposits, nil
+}
+
+// Update обновляет вклад
+func (r *termDepositRepository) Update(ctx context.Context, deposit *domain.TermDeposit) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Save(deposit).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// Delete удаляет вклад
+func (r *termDepositRepository) Delete(ctx context.Context, id uint) error {
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.TermDeposit{}, id).Error; err != nil {
+			return r.HandleError(err)
+		}
+		return nil
+	})
+}
+
+// List получает список вкладов
+func (r *termDepositRepository) List(ctx context.Context, offset, limit int) ([]domain.TermDeposit, error) {
+	var deposits []domain.TermDeposit
+	if err := r.db.Order("id").Offset(offset).Limit(limit).Find(&deposits).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return deposits, nil
+}
+
+// Count возвращает количество вкладов
+func (r *termDepositRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.TermDeposit{}).Count(&count).Error; err != nil {
+		return 0, r.HandleError(err)
+	}
+	return count, nil
+}
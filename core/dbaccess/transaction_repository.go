@@ -21,6 +21,11 @@ type TransactionRepository interface {
 	GetMonthlyTransactions(ctx context.Context, year int, month time.Month) ([]domain.Transaction, error)
 	UpdateStatus(ctx context.Context, id uint, status domain.TransactionStatus) error
 	GetTransactionsByAmountRange(ctx context.Context, minAmount, maxAmount float64) ([]domain.Transaction, error)
+	GetByCardAndRRN(ctx context.Context, cardID uint, rrn string) (*domain.Transaction, error)
+	GetByCardAndAuthCode(ctx context.Context, cardID uint, authCode string) (*domain.Transaction, error)
+	GetExpiredHolds(ctx context.Context, now time.Time) ([]domain.Transaction, error)
+	Release(ctx context.Context, transaction *domain.Transaction, statuses ...domain.TransactionStatus) (bool, error)
+	Capture(ctx context.Context, transaction *domain.Transaction, amount float64, now time.Time) error
 }
 
 // transactionRepository реализация репозитория транзакций
@@ -192,3 +197,87 @@ func (r *transactionRepository) GetTransactionsByAmountRange(ctx context.Context
 	}
 	return transactions, nil
 }
+
+// GetByCardAndRRN получает операцию по карте по номеру ссылки (RRN)
+func (r *transactionRepository) GetByCardAndRRN(ctx context.Context, cardID uint, rrn string) (*domain.Transaction, error) {
+	var transaction domain.Transaction
+	if err := r.db.Where("card_id = ? AND rrn = ?", cardID, rrn).Order("id DESC").First(&transaction).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &transaction, nil
+}
+
+// GetByCardAndAuthCode получает операцию по карте по коду авторизации
+func (r *transactionRepository) GetByCardAndAuthCode(ctx context.Context, cardID uint, authCode string) (*domain.Transaction, error) {
+	var transaction domain.Transaction
+	if err := r.db.Where("card_id = ? AND auth_code = ?", cardID, authCode).Order("id DESC").First(&transaction).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return &transaction, nil
+}
+
+// GetExpiredHolds получает холды по картам, не подтвержденные до истечения срока
+func (r *transactionRepository) GetExpiredHolds(ctx context.Context, now time.Time) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	if err := r.db.Where("status = ? AND card_id <> 0 AND auth_code <> '' AND expires_at < ?",
+		domain.TransactionStatusPending, now).
+		Find(&transactions).Error; err != nil {
+		return nil, r.HandleError(err)
+	}
+	return transactions, nil
+}
+
+// Release отменяет операцию по карте и возвращает ее сумму на счет в одной транзакции.
+// Статус меняется, только если операция еще в одном из статусов statuses; иначе
+// (например, ее уже отменил параллельный запрос) сумма не возвращается и результат false
+func (r *transactionRepository) Release(ctx context.Context, transaction *domain.Transaction, statuses ...domain.TransactionStatus) (bool, error) {
+	var released bool
+	err := r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Model(transaction).Where("status IN ?", statuses).
+			Update("status", domain.TransactionStatusCancelled)
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Model(&domain.Account{}).Where("id = ?", transaction.FromAccountID).
+			Update("balance", gorm.Expr("balance + ?", transaction.Amount)).Error; err != nil {
+			return r.HandleError(err)
+		}
+		released = true
+		return nil
+	})
+	return released, err
+}
+
+// Capture подтверждает списание по холду суммой amount и возвращает на счет разницу
+// с заблокированной суммой в одной транзакции. Статус меняется, только если холд еще
+// не подтвержден и не отменен (например, параллельной отменой); иначе сумма не
+// возвращается и результат ErrTransactionNotPending
+func (r *transactionRepository) Capture(ctx context.Context, transaction *domain.Transaction, amount float64, now time.Time) error {
+	released := transaction.Amount - amount
+	return r.WithTransaction(ctx, func(tx *gorm.DB) error {
+		res := tx.Model(transaction).Where("status = ?", domain.TransactionStatusPending).
+			Updates(map[string]interface{}{
+				"status":       domain.TransactionStatusCompleted,
+				"amount":       amount,
+				"completed_at": now,
+			})
+		if res.Error != nil {
+			return r.HandleError(res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrTransactionNotPending
+		}
+		if released > 0 {
+			if err := tx.Model(&domain.Account{}).Where("id = ?", transaction.FromAccountID).
+				Update("balance", gorm.Expr("balance + ?", released)).Error; err != nil {
+				return r.HandleError(err)
+			}
+		}
+		transaction.Amount = amount
+		transaction.Complete(now)
+		return nil
+	})
+}
@@ -68,7 +68,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.
 // GetByUsername получает пользователя по username
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
 	var user domain.User
-	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+	if err := r.db.Preload("Roles").Where("username = ?", username).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
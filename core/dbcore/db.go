@@ -82,6 +82,16 @@ func InitDB() (*gorm.DB, error) {
 	return DB, nil
 }
 
+// UseClock - переключает время, которым GORM заполняет created_at и updated_at,
+// чтобы записи, созданные при смоделированном времени, попадали в свой операционный день
+func UseClock(clock domain.Clock) {
+	if DB != nil {
+		DB.Config.NowFunc = func() time.Time {
+			return clock.Now().Local()
+		}
+	}
+}
+
 // CloseDB - закрывает соединение с базой данных
 func CloseDB() {
 	if DB != nil {
@@ -115,8 +125,33 @@ func CreateTables(db *gorm.DB) error {
 		&domain.Transaction{},
 		&domain.Credit{},
 		&domain.PaymentSchedule{},
+		&domain.PenaltyPolicy{},
+		&domain.CreditProduct{},
+		&domain.CreditClosingCertificate{},
+		&domain.CreditAgreement{},
+		&domain.CreditRestructuring{},
+		&domain.CreditScheduleVersion{},
+		&domain.DunningStage{},
+		&domain.CollectionCase{},
+		&domain.DunningAction{},
+		&domain.CreditLine{},
+		&domain.CreditLineStatement{},
+		&domain.DepositProduct{},
+		&domain.TermDeposit{},
+		&domain.CalendarDay{},
+		&domain.KeyRate{},
+		&domain.CurrencyRate{},
+		&domain.CreditApplication{},
+		&domain.CreditDecision{},
 		&domain.Analytics{},
 		&domain.BalanceForecast{},
+		&domain.AuditLog{},
+		&domain.Job{},
+		&domain.JobRun{},
+		&domain.CardProduct{},
+		&domain.Dispute{},
+		&domain.DisputeEvent{},
+		&domain.DisputeAttachment{},
 	)
 
 	if err != nil {
@@ -128,6 +163,36 @@ func CreateTables(db *gorm.DB) error {
 		return fmt.Errorf("ошибка при инициализации ролей: %v", err)
 	}
 
+	// Заполняем справочник карточных продуктов
+	if err := InitializeCardProducts(db); err != nil {
+		return fmt.Errorf("ошибка при инициализации карточных продуктов: %v", err)
+	}
+
+	// Заполняем справочник политик неустойки по кредитам
+	if err := InitializePenaltyPolicies(db); err != nil {
+		return fmt.Errorf("ошибка при инициализации политик неустойки: %v", err)
+	}
+
+	// Заполняем стадии взыскания просроченной задолженности
+	if err := InitializeDunningStages(db); err != nil {
+		return fmt.Errorf("ошибка при инициализации стадий взыскания: %v", err)
+	}
+
+	// Заполняем справочник кредитных продуктов
+	if err := InitializeCreditProducts(db); err != nil {
+		return fmt.Errorf("ошибка при инициализации кредитных продуктов: %v", err)
+	}
+
+	// Заполняем справочник вкладов
+	if err := InitializeDepositProducts(db); err != nil {
+		return fmt.Errorf("ошибка при инициализации продуктов вкладов: %v", err)
+	}
+
+	// Загружаем производственный календарь
+	if err := InitializeBusinessCalendar(db); err != nil {
+		return fmt.Errorf("ошибка при инициализации производственного календаря: %v", err)
+	}
+
 	// Создаем админа после создания всех таблиц и инициализации ролей
 	if err := createAdmin(db); err != nil {
 		return fmt.Errorf("ошибка при создании админа: %v", err)
@@ -139,8 +204,18 @@ func CreateTables(db *gorm.DB) error {
 func createAdmin(db *gorm.DB) error {
 	// Проверяем, существует ли уже админ
 	var existingAdmin domain.User
-	if err := db.Where("username = ?", "admin").First(&existingAdmin).Error; err == nil {
-		// Админ уже существует, ничего не делаем
+	if err := db.Preload("Roles").Where("username = ?", "admin").First(&existingAdmin).Error; err == nil {
+		// Админ уже существует, проверяем только наличие роли администратора
+		if existingAdmin.IsAdmin() {
+			return nil
+		}
+		var adminRole domain.Role
+		if err := db.Where("name = ?", domain.RoleAdmin).First(&adminRole).Error; err != nil {
+			return fmt.Errorf("ошибка при получении роли админа: %v", err)
+		}
+		if err := db.Model(&existingAdmin).Association("Roles").Append(&adminRole); err != nil {
+			return fmt.Errorf("ошибка при назначении роли админа: %v", err)
+		}
 		return nil
 	}
 
@@ -166,6 +241,10 @@ func createAdmin(db *gorm.DB) error {
 		return fmt.Errorf("ошибка при создании админа: %v", err)
 	}
 
+	if err := db.Model(admin).Association("Roles").Append(&adminRole, &userRole); err != nil {
+		return fmt.Errorf("ошибка при назначении ролей админа: %v", err)
+	}
+
 	return nil
 }
 
@@ -175,6 +254,8 @@ func InitializeRoles(db *gorm.DB) error {
 	roles := []domain.Role{
 		{Name: domain.RoleAdmin, Description: "Администратор"},
 		{Name: domain.RoleUser, Description: "Пользователь"},
+		{Name: domain.RoleManager, Description: "Менеджер"},
+		{Name: domain.RoleOperator, Description: "Операционист"},
 	}
 
 	// Сохраняем роли в базе данных
@@ -187,6 +268,91 @@ func InitializeRoles(db *gorm.DB) error {
 	return nil
 }
 
+// InitializeCardProducts создает карточные продукты по умолчанию
+func InitializeCardProducts(db *gorm.DB) error {
+	for _, product := range domain.DefaultCardProducts() {
+		if err := db.FirstOrCreate(&product, domain.CardProduct{Code: product.Code}).Error; err != nil {
+			return fmt.Errorf("ошибка при создании карточного продукта %s: %v", product.Code, err)
+		}
+	}
+
+	return nil
+}
+
+// InitializePenaltyPolicies создает политики неустойки по умолчанию
+func InitializePenaltyPolicies(db *gorm.DB) error {
+	for _, policy := range domain.DefaultPenaltyPolicies() {
+		if err := db.FirstOrCreate(&policy, domain.PenaltyPolicy{Code: policy.Code}).Error; err != nil {
+			return fmt.Errorf("ошибка при создании политики неустойки %s: %v", policy.Code, err)
+		}
+	}
+
+	return nil
+}
+
+// InitializeDunningStages создает стадии взыскания по умолчанию
+func InitializeDunningStages(db *gorm.DB) error {
+	for _, stage := range domain.DefaultDunningStages() {
+		if err := db.FirstOrCreate(&stage, domain.DunningStage{Code: stage.Code}).Error; err != nil {
+			return fmt.Errorf("ошибка при создании стадии взыскания %s: %v", stage.Code, err)
+		}
+	}
+
+	return nil
+}
+
+// InitializeCreditProducts создает кредитные продукты по умолчанию
+func InitializeCreditProducts(db *gorm.DB) error {
+	for _, product := range domain.DefaultCreditProducts() {
+		if err := db.FirstOrCreate(&product, domain.CreditProduct{Code: product.Code}).Error; err != nil {
+			return fmt.Errorf("ошибка при создании кредитного продукта %s: %v", product.Code, err)
+		}
+	}
+
+	return nil
+}
+
+// InitializeDepositProducts создает продукты вкладов по умолчанию
+func InitializeDepositProducts(db *gorm.DB) error {
+	for _, product := range domain.DefaultDepositProducts() {
+		if err := db.FirstOrCreate(&product, domain.DepositProduct{Code: product.Code}).Error; err != nil {
+			return fmt.Errorf("ошибка при создании продукта вклада %s: %v", product.Code, err)
+		}
+	}
+
+	return nil
+}
+
+// InitializeBusinessCalendar загружает поставляемый производственный календарь.
+// Год загружается только если в базе по нему еще нет ни одного дня, чтобы не
+// затирать правки администратора и не возвращать удаленные им дни
+func InitializeBusinessCalendar(db *gorm.DB) error {
+	days, err := domain.DefaultCalendarDays()
+	if err != nil {
+		return fmt.Errorf("ошибка при чтении производственного календаря: %v", err)
+	}
+
+	seeded := make(map[string]bool)
+	for _, day := range days {
+		year := day.Date[:4]
+		if _, checked := seeded[year]; !checked {
+			var count int64
+			if err := db.Model(&domain.CalendarDay{}).Where("date LIKE ?", year+"-%").Count(&count).Error; err != nil {
+				return fmt.Errorf("ошибка при проверке календаря за %s год: %v", year, err)
+			}
+			seeded[year] = count > 0
+		}
+		if seeded[year] {
+			continue
+		}
+		if err := db.Create(&day).Error; err != nil {
+			return fmt.Errorf("ошибка при создании дня календаря %s: %v", day.Date, err)
+		}
+	}
+
+	return nil
+}
+
 func addNumberField(db *gorm.DB) error {
 	// Обновляем существующие записи
 	var accounts []domain.Account
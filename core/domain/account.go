@@ -23,61 +23,101 @@ const (
 
 type Account struct {
 	gorm.Model
-	Number        string     `json:"number" gorm:"unique;not null;default:''"`
-	Balance       float64    `json:"balance" gorm:"type:decimal(20,2);not null;default:0"`
-	UserID        uint       `json:"user_id" gorm:"not null"`
-	IsActive      bool       `json:"is_active" gorm:"default:true"`
-	InterestRate  float64    `json:"interest_rate" gorm:"type:decimal(5,2);default:0"`
-	LastOperation *time.Time `json:"last_operation"`
-	DailyLimit    float64    `json:"daily_limit" gorm:"type:decimal(20,2);default:100000"`
-	MonthlyLimit  float64    `json:"monthly_limit" gorm:"type:decimal(20,2);default:1000000"`
+	Number        string      `json:"number" gorm:"unique;not null;default:''"`
+	Type          AccountType `json:"type" gorm:"type:varchar(20);not null;default:'DEBIT'"`
+	Balance       float64     `json:"balance" gorm:"type:decimal(20,2);not null;default:0"`
+	CreditLimit   float64     `json:"credit_limit" gorm:"type:decimal(20,2);default:0"` // лимит кредитного счета
+	UserID        uint        `json:"user_id" gorm:"not null"`
+	IsActive      bool        `json:"is_active" gorm:"default:true"`
+	InterestRate  float64     `json:"interest_rate" gorm:"type:decimal(5,2);default:0"`
+	LastOperation *time.Time  `json:"last_operation"`
+	DailyLimit    float64     `json:"daily_limit" gorm:"type:decimal(20,2);default:100000"`
+	MonthlyLimit  float64     `json:"monthly_limit" gorm:"type:decimal(20,2);default:1000000"`
 }
 
 // Validate проверяет все поля счета
 func (a *Account) Validate() error {
+	if err := a.ValidateType(); err != nil {
+		return err
+	}
 	if err := a.ValidateBalance(); err != nil {
 		return err
 	}
 	return nil
 }
 
-// ValidateBalance проверяет корректность баланса
+// ValidateType проверяет тип счета; кредитный лимит бывает только у кредитного счета
+func (a *Account) ValidateType() error {
+	switch a.Type {
+	case "", AccountTypeDebit, AccountTypeSavings:
+		if a.CreditLimit != 0 {
+			return ErrInvalidAccountType
+		}
+	case AccountTypeCredit:
+		if a.CreditLimit < 0 {
+			return ErrInvalidAccountType
+		}
+	default:
+		return ErrInvalidAccountType
+	}
+	return nil
+}
+
+// ValidateBalance проверяет корректность баланса. Баланс кредитного счета отрицателен
+// на сумму задолженности и за счет процентов и штрафов может превысить лимит
 func (a *Account) ValidateBalance() error {
-	if a.Balance < 0 {
+	if a.Balance < 0 && !a.IsCredit() {
 		return ErrInvalidBalance
 	}
 	return nil
 }
 
+// IsCredit проверяет, является ли счет кредитным (с возобновляемым лимитом)
+func (a *Account) IsCredit() bool {
+	return a.Type == AccountTypeCredit
+}
+
+// Available возвращает сумму, доступную для расходных операций: собственные средства
+// и неиспользованная часть кредитного лимита
+func (a *Account) Available() float64 {
+	return a.Balance + a.CreditLimit
+}
+
+// Debt возвращает задолженность по кредитному счету
+func (a *Account) Debt() float64 {
+	if a.Balance >= 0 {
+		return 0
+	}
+	return -a.Balance
+}
+
 // CanWithdraw проверяет возможность снятия средств
 func (a *Account) CanWithdraw(amount float64) error {
 	if amount <= 0 {
 		return ErrInvalidBalance
 	}
-	if a.Balance < amount {
+	if a.Available() < amount {
 		return ErrInsufficientFunds
 	}
 	return nil
 }
 
 // Withdraw снимает средства со счета
-func (a *Account) Withdraw(amount float64) error {
+func (a *Account) Withdraw(amount float64, now time.Time) error {
 	if err := a.CanWithdraw(amount); err != nil {
 		return err
 	}
 	a.Balance -= amount
-	now := time.Now()
 	a.LastOperation = &now
 	return nil
 }
 
 // Deposit пополняет счет
-func (a *Account) Deposit(amount float64) error {
+func (a *Account) Deposit(amount float64, now time.Time) error {
 	if amount <= 0 {
 		return ErrInvalidBalance
 	}
 	a.Balance += amount
-	now := time.Now()
 	a.LastOperation = &now
 	return nil
 }
@@ -87,6 +127,9 @@ func (a *Account) BeforeCreate(tx *gorm.DB) error {
 	if a.Number == "" {
 		a.Number = GenerateAccountNumber()
 	}
+	if a.Type == "" {
+		a.Type = AccountTypeDebit
+	}
 	return a.Validate()
 }
 
@@ -103,7 +146,10 @@ func (a *Account) ToDTO() map[string]interface{} {
 	return map[string]interface{}{
 		"id":             a.ID,
 		"number":         a.Number,
+		"type":           a.Type,
 		"balance":        a.Balance,
+		"credit_limit":   a.CreditLimit,
+		"available":      a.Available(),
 		"is_active":      a.IsActive,
 		"interest_rate":  a.InterestRate,
 		"last_operation": a.LastOperation,
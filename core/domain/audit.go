@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"gorm.io/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCardReveal        AuditAction = "CARD_REVEAL"
+	AuditActionCardRevealDenied  AuditAction = "CARD_REVEAL_DENIED"
+	AuditActionCardUnblock       AuditAction = "CARD_UNBLOCK"
+	AuditActionCardUnblockDenied AuditAction = "CARD_UNBLOCK_DENIED"
+)
+
+// AuditLog запись журнала аудита доступа к чувствительным данным
+type AuditLog struct {
+	gorm.Model
+	UserID     uint        `json:"user_id" gorm:"index;not null"`
+	Action     AuditAction `json:"action" gorm:"type:varchar(50);not null"`
+	EntityType string      `json:"entity_type" gorm:"type:varchar(50);index"`
+	EntityID   uint        `json:"entity_id" gorm:"index"`
+	IPAddress  string      `json:"ip_address" gorm:"type:varchar(64)"`
+	Details    string      `json:"details" gorm:"type:text"`
+}
+
+// ToDTO преобразует модель в DTO
+func (a *AuditLog) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":          a.ID,
+		"user_id":     a.UserID,
+		"action":      a.Action,
+		"entity_type": a.EntityType,
+		"entity_id":   a.EntityID,
+		"ip_address":  a.IPAddress,
+		"details":     a.Details,
+		"created_at":  a.CreatedAt,
+	}
+}
@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCalendarDay     = errors.New("invalid calendar day")
+	ErrInvalidBusinessDayRule = errors.New("invalid business day rule")
+)
+
+// calendarDateLayout формат даты в производственном календаре
+const calendarDateLayout = "2006-01-02"
+
+// CalendarDate форматирует дату для производственного календаря
+func CalendarDate(year int, month time.Month, day int) string {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Format(calendarDateLayout)
+}
+
+// calendarFiles производственный календарь, поставляемый с приложением
+//
+//go:embed calendar/ru.json
+var calendarFiles embed.FS
+
+// CalendarDayType вид дня в производственном календаре
+type CalendarDayType string
+
+const (
+	CalendarDayHoliday CalendarDayType = "HOLIDAY" // нерабочий праздничный день или перенесенный выходной
+	CalendarDayWorkday CalendarDayType = "WORKDAY" // рабочая суббота или воскресенье по переносу
+)
+
+// BusinessDayRule правило переноса даты, выпавшей на нерабочий день
+type BusinessDayRule string
+
+const (
+	BusinessDayNext     BusinessDayRule = "NEXT"     // на следующий рабочий день
+	BusinessDayPrevious BusinessDayRule = "PREVIOUS" // на предыдущий рабочий день
+)
+
+// IsValid проверяет, поддерживается ли правило переноса
+func (r BusinessDayRule) IsValid() bool {
+	return r == BusinessDayNext || r == BusinessDayPrevious
+}
+
+// OrDefault возвращает правило, а если оно не задано - перенос на следующий рабочий день
+func (r BusinessDayRule) OrDefault() BusinessDayRule {
+	if r == "" {
+		return BusinessDayNext
+	}
+	return r
+}
+
+// CalendarDay исключение из пятидневной рабочей недели: праздник в будний день
+// или рабочий выходной. Дни, которых нет в календаре, рабочие с понедельника по пятницу
+type CalendarDay struct {
+	gorm.Model
+	Date        string          `json:"date" gorm:"type:varchar(10);uniqueIndex;not null"` // ГГГГ-ММ-ДД
+	Type        CalendarDayType `json:"type" gorm:"type:varchar(20);not null"`
+	Description string          `json:"description" gorm:"type:varchar(255)"`
+}
+
+// Validate проверяет дату и вид дня
+func (d *CalendarDay) Validate() error {
+	if _, err := time.Parse(calendarDateLayout, d.Date); err != nil {
+		return fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidCalendarDay)
+	}
+	if d.Type != CalendarDayHoliday && d.Type != CalendarDayWorkday {
+		return fmt.Errorf("%w: type must be HOLIDAY or WORKDAY", ErrInvalidCalendarDay)
+	}
+	return nil
+}
+
+// ToDTO преобразует модель в DTO
+func (d *CalendarDay) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"date":        d.Date,
+		"type":        d.Type,
+		"description": d.Description,
+		"updated_at":  d.UpdatedAt,
+	}
+}
+
+// calendarFile формат файла производственного календаря
+type calendarFile struct {
+	Country string        `json:"country"`
+	Days    []CalendarDay `json:"days"`
+}
+
+// ParseCalendarDays разбирает производственный календарь из JSON
+func ParseCalendarDays(data []byte) ([]CalendarDay, error) {
+	var file calendarFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendarDay, err)
+	}
+	seen := make(map[string]bool, len(file.Days))
+	for i := range file.Days {
+		if err := file.Days[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Days[i].Date, err)
+		}
+		if seen[file.Days[i].Date] {
+			return nil, fmt.Errorf("%w: duplicate date %s", ErrInvalidCalendarDay, file.Days[i].Date)
+		}
+		seen[file.Days[i].Date] = true
+	}
+	return file.Days, nil
+}
+
+// DefaultCalendarDays возвращает производственный календарь РФ, поставляемый с приложением
+func DefaultCalendarDays() ([]CalendarDay, error) {
+	data, err := calendarFiles.ReadFile("calendar/ru.json")
+	if err != nil {
+		return nil, err
+	}
+	return ParseCalendarDays(data)
+}
+
+// BusinessCalendar производственный календарь: определяет рабочие дни
+// и переносит даты, выпавшие на выходные и праздники
+type BusinessCalendar struct {
+	days map[string]CalendarDayType
+}
+
+// NewBusinessCalendar создает календарь по списку исключений
+func NewBusinessCalendar(days []CalendarDay) *BusinessCalendar {
+	calendar := &BusinessCalendar{days: make(map[string]CalendarDayType, len(days))}
+	for _, day := range days {
+		calendar.days[day.Date] = day.Type
+	}
+	return calendar
+}
+
+// IsBusinessDay проверяет, рабочий ли день t. Без календаря нерабочими
+// считаются только суббота и воскресенье
+func (c *BusinessCalendar) IsBusinessDay(t time.Time) bool {
+	if c == nil {
+		return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+	}
+	switch c.days[t.Format(calendarDateLayout)] {
+	case CalendarDayHoliday:
+		return false
+	case CalendarDayWorkday:
+		return true
+	}
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+// Adjust переносит дату t на рабочий день по правилу rule. Время дня сохраняется
+func (c *BusinessCalendar) Adjust(t time.Time, rule BusinessDayRule) time.Time {
+	step := 1
+	if rule.OrDefault() == BusinessDayPrevious {
+		step = -1
+	}
+	// Нерабочих дней подряд не бывает больше нескольких недель
+	for i := 0; i < 60 && !c.IsBusinessDay(t); i++ {
+		t = t.AddDate(0, 0, step)
+	}
+	return t
+}
@@ -3,22 +3,88 @@ package domain
 import (
 	"errors"
 	"regexp"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 var (
-	ErrInvalidCardNumber = errors.New("invalid card number")
-	ErrInvalidExpiryDate = errors.New("invalid expiry date")
-	ErrInvalidCVV        = errors.New("invalid CVV")
-	ErrCardExpired       = errors.New("card has expired")
+	ErrInvalidCardNumber  = errors.New("invalid card number")
+	ErrInvalidExpiryDate  = errors.New("invalid expiry date")
+	ErrInvalidCVV         = errors.New("invalid CVV")
+	ErrCardExpired        = errors.New("card has expired")
+	ErrCardInactive       = errors.New("card is not active")
+	ErrCardBlocked        = errors.New("card is blocked")
+	ErrCardNotOwned       = errors.New("card does not belong to the user")
+	ErrInvalidPIN         = errors.New("PIN must consist of 4 digits")
+	ErrPINNotSet          = errors.New("PIN is not set")
+	ErrPINAlreadySet      = errors.New("PIN is already set")
+	ErrWrongPIN           = errors.New("wrong PIN")
+	ErrPINRequired        = errors.New("PIN is required")
+	ErrCardLimitExceeded  = errors.New("card limit exceeded")
+	ErrCardClosed         = errors.New("card is closed")
+	ErrCardNotYetValid    = errors.New("card is not yet valid")
+	ErrMerchantNotAllowed = errors.New("merchant is not allowed for this card")
+	ErrCardCapExceeded    = errors.New("card amount cap exceeded")
+	ErrInvalidVirtualCard = errors.New("invalid virtual card parameters")
+	ErrChannelNotAllowed  = errors.New("operation channel is not allowed for this card")
+	ErrCardAlreadyUsed    = errors.New("single-use card has already been used")
+	ErrTooManyAttempts    = errors.New("too many wrong password attempts, try again later")
+)
+
+// MaxPINAttempts количество неверных вводов PIN подряд, после которого карта блокируется
+const MaxPINAttempts = 3
+
+// MaxCardPasswordAttempts количество неверных вводов пароля при показе реквизитов или
+// разблокировке карты, после которого эти операции недоступны в течение CardPasswordLockout
+const MaxCardPasswordAttempts = 5
+
+// CardPasswordLockout период, за который учитываются неверные вводы пароля
+const CardPasswordLockout = 15 * time.Minute
+
+// CardChannel канал проведения операции по карте
+type CardChannel string
+
+const (
+	CardChannelPOS  CardChannel = "POS"  // оплата в торговой точке
+	CardChannelATM  CardChannel = "ATM"  // операция в банкомате
+	CardChannelECOM CardChannel = "ECOM" // оплата в интернете
+)
+
+// CardKind вид карты
+type CardKind string
+
+const (
+	CardKindPhysical CardKind = "PHYSICAL" // пластиковая карта
+	CardKindVirtual  CardKind = "VIRTUAL"  // виртуальная карта для покупок в интернете
+)
+
+// VirtualCardMode режим использования виртуальной карты
+type VirtualCardMode string
+
+const (
+	VirtualCardModeSingleUse      VirtualCardMode = "SINGLE_USE"      // закрывается после первого списания
+	VirtualCardModeMerchantLocked VirtualCardMode = "MERCHANT_LOCKED" // работает только с одним продавцом
+)
+
+// CardRenewalLeadDays за сколько дней до окончания срока действия перевыпускается карта
+const CardRenewalLeadDays = 30
+
+// DefaultVirtualCardValidity срок действия виртуальной карты, если он не указан при выпуске
+const DefaultVirtualCardValidity = 24 * time.Hour
+
+// Причины закрытия карты
+const (
+	CardCloseReasonUsed    = "used"
+	CardCloseReasonExpired = "expired"
 )
 
 // Card представляет модель данных банковской карты.
 type Card struct {
 	gorm.Model
 	Number       string    `json:"number" gorm:"type:text;not null" validate:"required"`
+	NumberHash   string    `json:"-" gorm:"type:varchar(64);uniqueIndex"`
 	ExpiryDate   string    `json:"expiry_date" gorm:"type:text;not null" validate:"required"`
 	CVV          string    `json:"-" gorm:"type:text;not null" validate:"required"`
 	UserID       uint      `json:"user_id" gorm:"not null"`
@@ -27,17 +93,46 @@ type Card struct {
 	DailyLimit   float64   `json:"daily_limit" gorm:"default:100000"`
 	MonthlyLimit float64   `json:"monthly_limit" gorm:"default:1000000"`
 	LastUsed     time.Time `json:"last_used"`
+	PinHash      string    `json:"-" gorm:"type:text"`
+	PinAttempts  int       `json:"-" gorm:"default:0"`
+	IsBlocked    bool      `json:"is_blocked" gorm:"default:false"`
+	LastFour     string    `json:"last_four" gorm:"type:varchar(4)"`
+	Brand        string    `json:"brand" gorm:"type:varchar(20)"`
+	ProductID    uint      `json:"product_id" gorm:"index"`
+
+	// Срок действия в открытом виде (момент окончания действия карты)
+	// и связь с перевыпущенной картой
+	ExpiresAt      *time.Time `json:"expires_at" gorm:"index"`
+	PreviousCardID *uint      `json:"previous_card_id" gorm:"index"`
+	ReplacedByID   *uint      `json:"replaced_by_id"`
+
+	// Зашифрованный CVV перевыпущенной карты: выдается владельцу один раз
+	// при показе реквизитов и после этого стирается
+	PendingCVV string `json:"-" gorm:"type:text"`
+
+	// Ограничения по операциям, которые задает владелец карты
+	Controls CardControls `json:"controls" gorm:"type:text;serializer:json"`
+
+	// Параметры виртуальной карты
+	Kind         CardKind        `json:"kind" gorm:"type:varchar(20);default:PHYSICAL"`
+	VirtualMode  VirtualCardMode `json:"virtual_mode" gorm:"type:varchar(20)"`
+	AmountCap    float64         `json:"amount_cap"`
+	MerchantLock string          `json:"merchant_lock" gorm:"type:varchar(255)"`
+	ValidFrom    *time.Time      `json:"valid_from"`
+	ValidUntil   *time.Time      `json:"valid_until" gorm:"index"`
+	ClosedAt     *time.Time      `json:"closed_at"`
+	CloseReason  string          `json:"close_reason" gorm:"type:varchar(50)"`
 }
 
 // Validate проверяет все поля карты
-func (c *Card) Validate() error {
+func (c *Card) Validate(now time.Time) error {
 	if c.AccountID == 0 {
 		return errors.New("account_id is required")
 	}
 	if err := c.ValidateNumber(); err != nil {
 		return err
 	}
-	if err := c.ValidateExpiryDate(); err != nil {
+	if err := c.ValidateExpiryDate(now); err != nil {
 		return err
 	}
 	if err := c.ValidateCVV(); err != nil {
@@ -48,8 +143,8 @@ func (c *Card) Validate() error {
 
 // ValidateNumber проверяет корректность номера карты
 func (c *Card) ValidateNumber() error {
-	// Проверка формата (16 цифр)
-	numberRegex := regexp.MustCompile(`^\d{16}$`)
+	// Проверка формата (от 13 до 19 цифр, ISO/IEC 7812)
+	numberRegex := regexp.MustCompile(`^\d{13,19}$`)
 	if !numberRegex.MatchString(c.Number) {
 		return ErrInvalidCardNumber
 	}
@@ -79,8 +174,8 @@ func (c *Card) ValidateNumber() error {
 	return nil
 }
 
-// ValidateExpiryDate проверяет корректность срока действия
-func (c *Card) ValidateExpiryDate() error {
+// ValidateExpiryDate проверяет корректность срока действия на момент now
+func (c *Card) ValidateExpiryDate(now time.Time) error {
 	// Проверка формата MM/YY
 	expiryRegex := regexp.MustCompile(`^(0[1-9]|1[0-2])/([0-9]{2})$`)
 	if !expiryRegex.MatchString(c.ExpiryDate) {
@@ -96,8 +191,8 @@ func (c *Card) ValidateExpiryDate() error {
 		return ErrInvalidExpiryDate
 	}
 
-	// Проверка на истечение срока
-	if expiryTime.Before(time.Now()) {
+	// Проверка на истечение срока: карта действует до конца указанного месяца
+	if expiryTime.AddDate(0, 1, 0).Before(now) {
 		return ErrCardExpired
 	}
 
@@ -113,59 +208,263 @@ func (c *Card) ValidateCVV() error {
 	return nil
 }
 
+// ExpiryMoment возвращает момент окончания действия карты со сроком MM/YY:
+// карта действует до конца указанного месяца
+func ExpiryMoment(expiryDate string) (time.Time, error) {
+	if len(expiryDate) != 5 {
+		return time.Time{}, ErrInvalidExpiryDate
+	}
+	expiryTime, err := time.Parse("2006-01", "20"+expiryDate[3:]+"-"+expiryDate[:2])
+	if err != nil {
+		return time.Time{}, ErrInvalidExpiryDate
+	}
+	return expiryTime.AddDate(0, 1, 0), nil
+}
+
+// SetExpiry сохраняет срок действия в открытом виде
+func (c *Card) SetExpiry(expiryDate string) error {
+	expiresAt, err := ExpiryMoment(expiryDate)
+	if err != nil {
+		return err
+	}
+	c.ExpiresAt = &expiresAt
+	return nil
+}
+
+// NeedsRenewal проверяет, пора ли перевыпустить карту: карта пластиковая,
+// действует, еще не перевыпущена и до окончания срока осталось не больше CardRenewalLeadDays дней
+func (c *Card) NeedsRenewal(now time.Time) bool {
+	if c.IsVirtual() || !c.IsActive || c.IsClosed() || c.ReplacedByID != nil || c.ExpiresAt == nil {
+		return false
+	}
+	return !now.AddDate(0, 0, CardRenewalLeadDays).Before(*c.ExpiresAt)
+}
+
+// ApplyProductDefaults устанавливает лимиты выпускаемой карты из настроек продукта
+func (c *Card) ApplyProductDefaults(product *CardProduct) {
+	c.DailyLimit = product.DailyLimit
+	c.MonthlyLimit = product.MonthlyLimit
+}
+
+// InheritFrom переносит на перевыпущенную карту счет, продукт, лимиты
+// и настройки старой карты и связывает карты между собой
+func (c *Card) InheritFrom(old *Card) {
+	c.AccountID = old.AccountID
+	c.UserID = old.UserID
+	c.ProductID = old.ProductID
+	c.DailyLimit = old.DailyLimit
+	c.MonthlyLimit = old.MonthlyLimit
+	c.Controls = old.Controls
+	c.PreviousCardID = &old.ID
+}
+
 // IsExpired проверяет, истек ли срок действия карты
-func (c *Card) IsExpired() bool {
+func (c *Card) IsExpired(now time.Time) bool {
 	month := c.ExpiryDate[:2]
 	year := "20" + c.ExpiryDate[3:]
 	expiryTime, err := time.Parse("2006-01", year+"-"+month)
 	if err != nil {
 		return true
 	}
-	return expiryTime.Before(time.Now())
+	return expiryTime.AddDate(0, 1, 0).Before(now)
 }
 
-// GetCardType определяет тип карты по номеру
+// GetCardType определяет тип карты.
+// Номер карты хранится в зашифрованном виде, поэтому используется
+// платежная система, сохраненная при выпуске карты
 func (c *Card) GetCardType() string {
+	if c.Brand != "" {
+		return c.Brand
+	}
+	return DetectCardBrand(c.Number)
+}
+
+// DetectCardBrand определяет платежную систему по открытому номеру карты
+func DetectCardBrand(number string) string {
 	// Visa
-	if matched, _ := regexp.MatchString(`^4[0-9]{12}(?:[0-9]{3})?$`, c.Number); matched {
-		return "VISA"
+	if matched, _ := regexp.MatchString(`^4[0-9]{12}(?:[0-9]{3}){0,2}$`, number); matched {
+		return CardBrandVisa
 	}
-	// MasterCard
-	if matched, _ := regexp.MatchString(`^5[1-5][0-9]{14}$`, c.Number); matched {
-		return "MASTERCARD"
+	// MasterCard (51-55 и 2221-2720)
+	if matched, _ := regexp.MatchString(`^(5[1-5][0-9]{14}|2(22[1-9]|2[3-9][0-9]|[3-6][0-9]{2}|7[01][0-9]|720)[0-9]{12})$`, number); matched {
+		return CardBrandMastercard
 	}
-	// MIR
-	if matched, _ := regexp.MatchString(`^2[0-9]{15}$`, c.Number); matched {
-		return "MIR"
+	// MIR (2200-2204)
+	if matched, _ := regexp.MatchString(`^220[0-4][0-9]{12,15}$`, number); matched {
+		return CardBrandMIR
 	}
 	return "UNKNOWN"
 }
 
-// BeforeUpdate хук для валидации перед обновлением
+// SetDisplayData сохраняет открытые данные для отображения: последние 4 цифры и платежную систему
+func (c *Card) SetDisplayData(number string) {
+	if len(number) >= 4 {
+		c.LastFour = number[len(number)-4:]
+	}
+	c.Brand = DetectCardBrand(number)
+}
+
+// HasPIN проверяет, установлен ли PIN для карты
+func (c *Card) HasPIN() bool {
+	return c.PinHash != ""
+}
+
+// IsVirtual проверяет, является ли карта виртуальной
+func (c *Card) IsVirtual() bool {
+	return c.Kind == CardKindVirtual
+}
+
+// IsClosed проверяет, закрыта ли карта
+func (c *Card) IsClosed() bool {
+	return c.ClosedAt != nil
+}
+
+// IsValidityWindowOver проверяет, истек ли срок действия виртуальной карты
+func (c *Card) IsValidityWindowOver(now time.Time) bool {
+	return c.ValidUntil != nil && !now.Before(*c.ValidUntil)
+}
+
+// ValidateVirtual проверяет параметры выпуска виртуальной карты
+func (c *Card) ValidateVirtual() error {
+	switch c.VirtualMode {
+	case VirtualCardModeSingleUse, VirtualCardModeMerchantLocked:
+	default:
+		return ErrInvalidVirtualCard
+	}
+	if c.AmountCap <= 0 {
+		return ErrInvalidVirtualCard
+	}
+	if c.ValidFrom == nil || c.ValidUntil == nil || !c.ValidUntil.After(*c.ValidFrom) {
+		return ErrInvalidVirtualCard
+	}
+	return nil
+}
+
+// CheckVirtualUsage проверяет операцию по виртуальной карте: срок действия,
+// продавца и лимит суммы с учетом уже списанных средств
+func (c *Card) CheckVirtualUsage(amount float64, merchant string, spent float64, now time.Time) error {
+	if !c.IsVirtual() {
+		return nil
+	}
+	if c.ValidFrom != nil && now.Before(*c.ValidFrom) {
+		return ErrCardNotYetValid
+	}
+	if c.IsValidityWindowOver(now) {
+		return ErrCardExpired
+	}
+	if c.VirtualMode == VirtualCardModeMerchantLocked {
+		// Карта без заданного продавца закрепляется за первым продавцом при списании
+		merchant = strings.TrimSpace(merchant)
+		if merchant == "" || (c.MerchantLock != "" && !strings.EqualFold(merchant, c.MerchantLock)) {
+			return ErrMerchantNotAllowed
+		}
+	}
+	return c.CheckVirtualLimit(amount, spent)
+}
+
+// CheckVirtualLimit проверяет, что виртуальная карта не использована (для одноразовой)
+// и операция укладывается в лимит суммы с учетом уже списанных средств
+func (c *Card) CheckVirtualLimit(amount float64, spent float64) error {
+	if !c.IsVirtual() {
+		return nil
+	}
+	// Одноразовая карта с неподтвержденным холдом не принимает новые операции
+	if c.VirtualMode == VirtualCardModeSingleUse && spent > 0 {
+		return ErrCardAlreadyUsed
+	}
+	if c.AmountCap > 0 && spent+amount > c.AmountCap {
+		return ErrCardCapExceeded
+	}
+	return nil
+}
+
+// CloseAfterCapture проверяет, нужно ли закрыть карту после списания
+func (c *Card) CloseAfterCapture() bool {
+	return c.IsVirtual() && c.VirtualMode == VirtualCardModeSingleUse
+}
+
+// Close закрывает карту с указанием причины
+func (c *Card) Close(reason string, now time.Time) {
+	c.IsActive = false
+	c.ClosedAt = &now
+	c.CloseReason = reason
+}
+
+// CanTransact проверяет, можно ли проводить операции по карте
+func (c *Card) CanTransact() error {
+	if c.IsClosed() {
+		return ErrCardClosed
+	}
+	if !c.IsActive {
+		return ErrCardInactive
+	}
+	if c.IsBlocked {
+		return ErrCardBlocked
+	}
+	return nil
+}
+
+// BeforeUpdate хук перед обновлением.
+// Номер и срок действия хранятся в зашифрованном виде, поэтому их
+// валидация выполняется в сервисном слое при выпуске карты
 func (c *Card) BeforeUpdate(tx *gorm.DB) error {
-	return c.Validate()
+	return nil
 }
 
 // ToDTO преобразует модель в DTO
 func (c *Card) ToDTO() map[string]interface{} {
-	return map[string]interface{}{
-		"id":            c.ID,
-		"number":        c.MaskNumber(),
-		"expiry_date":   c.ExpiryDate,
-		"is_active":     c.IsActive,
-		"daily_limit":   c.DailyLimit,
-		"monthly_limit": c.MonthlyLimit,
-		"last_used":     c.LastUsed,
-		"created_at":    c.CreatedAt,
-		"updated_at":    c.UpdatedAt,
-		"account_id":    c.AccountID,
+	dto := map[string]interface{}{
+		"id":               c.ID,
+		"kind":             c.GetKind(),
+		"number":           c.MaskNumber(),
+		"last_four":        c.LastFour,
+		"brand":            c.GetCardType(),
+		"product_id":       c.ProductID,
+		"expiry_date":      c.ExpiryDate,
+		"is_active":        c.IsActive,
+		"daily_limit":      c.DailyLimit,
+		"monthly_limit":    c.MonthlyLimit,
+		"last_used":        c.LastUsed,
+		"is_blocked":       c.IsBlocked,
+		"pin_set":          c.HasPIN(),
+		"created_at":       c.CreatedAt,
+		"updated_at":       c.UpdatedAt,
+		"account_id":       c.AccountID,
+		"closed_at":        c.ClosedAt,
+		"expires_at":       c.ExpiresAt,
+		"previous_card_id": c.PreviousCardID,
+		"replaced_by_id":   c.ReplacedByID,
+		"controls":         c.Controls,
+	}
+
+	if c.IsVirtual() {
+		dto["virtual_mode"] = c.VirtualMode
+		dto["amount_cap"] = c.AmountCap
+		dto["merchant_lock"] = c.MerchantLock
+		dto["valid_from"] = c.ValidFrom
+		dto["valid_until"] = c.ValidUntil
+		dto["close_reason"] = c.CloseReason
 	}
+
+	return dto
+}
+
+// GetKind возвращает вид карты. Карты, выпущенные до появления
+// виртуальных карт, считаются пластиковыми
+func (c *Card) GetKind() CardKind {
+	if c.Kind == "" {
+		return CardKindPhysical
+	}
+	return c.Kind
 }
 
 // MaskNumber маскирует номер карты
 func (c *Card) MaskNumber() string {
-	if len(c.Number) != 16 {
+	if c.LastFour != "" {
+		return "**** **** **** " + c.LastFour
+	}
+	if len(c.Number) < 13 || len(c.Number) > 19 {
 		return c.Number
 	}
-	return c.Number[:4] + " **** **** " + c.Number[12:]
+	return c.Number[:4] + " **** **** " + c.Number[len(c.Number)-4:]
 }
@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCardControls         = errors.New("invalid card controls")
+	ErrOnlinePaymentsDisabled      = errors.New("online payments are disabled for this card")
+	ErrForeignTransactionsDisabled = errors.New("transactions abroad are disabled for this card")
+	ErrMerchantCategoryBlocked     = errors.New("merchant category is blocked for this card")
+	ErrOutsideTimeWindow           = errors.New("transactions are not allowed at this time")
+	ErrMCCLimitExceeded            = errors.New("merchant category limit exceeded")
+)
+
+// HomeCountry страна банка. Операции в других странах считаются операциями за рубежом
+const HomeCountry = "RU"
+
+// homeCountryCodes коды страны банка: ISO 3166-1 alpha-2, alpha-3 и цифровой
+var homeCountryCodes = []string{HomeCountry, "RUS", "643"}
+
+// MerchantCategory группа кодов категорий продавцов (MCC), которую можно запретить целиком
+type MerchantCategory string
+
+const (
+	MerchantCategoryGambling MerchantCategory = "GAMBLING" // азартные игры и лотереи
+	MerchantCategoryCrypto   MerchantCategory = "CRYPTO"   // криптобиржи и квази-наличные
+	MerchantCategoryAdult    MerchantCategory = "ADULT"    // услуги для взрослых
+)
+
+// merchantCategoryMCCs коды MCC, входящие в группы категорий
+var merchantCategoryMCCs = map[MerchantCategory][]string{
+	MerchantCategoryGambling: {"7800", "7801", "7802", "7995", "9406"},
+	MerchantCategoryCrypto:   {"6051"},
+	MerchantCategoryAdult:    {"5967", "7273"},
+}
+
+var (
+	mccRegex        = regexp.MustCompile(`^\d{4}$`)
+	timeOfDayRegex  = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+	timeOfDayLayout = "15:04"
+)
+
+// CardTimeWindow время суток, в которое разрешены операции по карте.
+// Если From больше To, окно переходит через полночь (например, 22:00-06:00)
+type CardTimeWindow struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// MCCLimit лимит расходов по коду категории продавца
+type MCCLimit struct {
+	MCC          string  `json:"mcc"`
+	DailyLimit   float64 `json:"daily_limit"`
+	MonthlyLimit float64 `json:"monthly_limit"`
+}
+
+// CardControls настройки ограничений по карте, которые задает владелец
+type CardControls struct {
+	OnlineDisabled    bool               `json:"online_disabled"`
+	AbroadDisabled    bool               `json:"abroad_disabled"`
+	BlockedCategories []MerchantCategory `json:"blocked_categories"`
+	BlockedMCCs       []string           `json:"blocked_mccs"`
+	TimeWindow        *CardTimeWindow    `json:"time_window"`
+	MCCLimits         []MCCLimit         `json:"mcc_limits"`
+}
+
+// Validate проверяет корректность настроек
+func (cc *CardControls) Validate() error {
+	for _, category := range cc.BlockedCategories {
+		if _, ok := merchantCategoryMCCs[category]; !ok {
+			return ErrInvalidCardControls
+		}
+	}
+	for _, mcc := range cc.BlockedMCCs {
+		if !mccRegex.MatchString(mcc) {
+			return ErrInvalidCardControls
+		}
+	}
+	if cc.TimeWindow != nil {
+		if !timeOfDayRegex.MatchString(cc.TimeWindow.From) || !timeOfDayRegex.MatchString(cc.TimeWindow.To) ||
+			cc.TimeWindow.From == cc.TimeWindow.To {
+			return ErrInvalidCardControls
+		}
+	}
+	seen := make(map[string]bool)
+	for _, limit := range cc.MCCLimits {
+		if !mccRegex.MatchString(limit.MCC) || seen[limit.MCC] {
+			return ErrInvalidCardControls
+		}
+		if limit.DailyLimit < 0 || limit.MonthlyLimit < 0 || (limit.DailyLimit == 0 && limit.MonthlyLimit == 0) {
+			return ErrInvalidCardControls
+		}
+		seen[limit.MCC] = true
+	}
+	return nil
+}
+
+// Check проверяет операцию по настройкам карты и возвращает конкретную причину отказа
+func (cc *CardControls) Check(channel CardChannel, mcc, country string, now time.Time) error {
+	if cc.OnlineDisabled && channel == CardChannelECOM {
+		return ErrOnlinePaymentsDisabled
+	}
+	if cc.AbroadDisabled && !IsDomesticCountry(country) {
+		return ErrForeignTransactionsDisabled
+	}
+	if mcc != "" && cc.isMCCBlocked(mcc) {
+		return ErrMerchantCategoryBlocked
+	}
+	if cc.TimeWindow != nil && !cc.TimeWindow.Contains(now) {
+		return ErrOutsideTimeWindow
+	}
+	return nil
+}
+
+// LimitFor возвращает лимит по коду категории продавца, если он задан
+func (cc *CardControls) LimitFor(mcc string) *MCCLimit {
+	for i := range cc.MCCLimits {
+		if cc.MCCLimits[i].MCC == mcc {
+			return &cc.MCCLimits[i]
+		}
+	}
+	return nil
+}
+
+// isMCCBlocked проверяет, запрещен ли код категории продавца отдельно или в составе группы
+func (cc *CardControls) isMCCBlocked(mcc string) bool {
+	for _, blocked := range cc.BlockedMCCs {
+		if blocked == mcc {
+			return true
+		}
+	}
+	for _, category := range cc.BlockedCategories {
+		for _, blocked := range merchantCategoryMCCs[category] {
+			if blocked == mcc {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Contains проверяет, попадает ли время суток в окно
+func (w *CardTimeWindow) Contains(now time.Time) bool {
+	from, errFrom := time.Parse(timeOfDayLayout, w.From)
+	to, errTo := time.Parse(timeOfDayLayout, w.To)
+	if errFrom != nil || errTo != nil {
+		return true
+	}
+
+	minutes := now.Hour()*60 + now.Minute()
+	fromMinutes := from.Hour()*60 + from.Minute()
+	toMinutes := to.Hour()*60 + to.Minute()
+
+	if fromMinutes < toMinutes {
+		return minutes >= fromMinutes && minutes < toMinutes
+	}
+	// Окно через полночь
+	return minutes >= fromMinutes || minutes < toMinutes
+}
+
+// IsDomesticCountry проверяет, что операция совершается в стране банка.
+// Пустой код страны считается внутренней операцией
+func IsDomesticCountry(country string) bool {
+	country = strings.TrimSpace(country)
+	if country == "" {
+		return true
+	}
+	for _, code := range homeCountryCodes {
+		if strings.EqualFold(country, code) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCardProduct  = errors.New("invalid card product")
+	ErrCardProductInactive = errors.New("card product is not active")
+)
+
+// Платежные системы, карты которых может выпускать банк
+const (
+	CardBrandVisa       = "VISA"
+	CardBrandMastercard = "MASTERCARD"
+	CardBrandMIR        = "MIR"
+)
+
+// Допустимая длина номера карты (ISO/IEC 7812)
+const (
+	MinPANLength = 13
+	MaxPANLength = 19
+)
+
+var binPrefixRegex = regexp.MustCompile(`^\d{1,11}$`)
+
+// CardProduct карточный продукт: платежная система, BIN-диапазон,
+// длина номера, срок действия и лимиты по умолчанию для выпускаемых карт
+type CardProduct struct {
+	gorm.Model
+	Code          string  `json:"code" gorm:"type:varchar(50);uniqueIndex;not null" validate:"required"`
+	Name          string  `json:"name" gorm:"type:varchar(255);not null"`
+	Brand         string  `json:"brand" gorm:"type:varchar(20);not null"`
+	BINPrefix     string  `json:"bin_prefix" gorm:"type:varchar(11);not null"`
+	PANLength     int     `json:"pan_length" gorm:"default:16"`
+	ValidityYears int     `json:"validity_years" gorm:"default:5"`
+	DailyLimit    float64 `json:"daily_limit" gorm:"default:100000"`
+	MonthlyLimit  float64 `json:"monthly_limit" gorm:"default:1000000"`
+	IsActive      bool    `json:"is_active" gorm:"default:true"`
+	IsDefault     bool    `json:"is_default" gorm:"default:false"`
+}
+
+// Validate проверяет настройки продукта
+func (p *CardProduct) Validate() error {
+	if p.Code == "" || p.Name == "" {
+		return ErrInvalidCardProduct
+	}
+	switch p.Brand {
+	case CardBrandVisa, CardBrandMastercard, CardBrandMIR:
+	default:
+		return ErrInvalidCardProduct
+	}
+	if !binPrefixRegex.MatchString(p.BINPrefix) {
+		return ErrInvalidCardProduct
+	}
+	if p.PANLength < MinPANLength || p.PANLength > MaxPANLength || len(p.BINPrefix) >= p.PANLength {
+		return ErrInvalidCardProduct
+	}
+	if !p.matchesBrand() {
+		return ErrInvalidCardProduct
+	}
+	if p.ValidityYears < 1 || p.ValidityYears > 10 {
+		return ErrInvalidCardProduct
+	}
+	if p.DailyLimit < 0 || p.MonthlyLimit < 0 {
+		return ErrInvalidCardProduct
+	}
+	return nil
+}
+
+// matchesBrand проверяет, что все номера BIN-диапазона продукта относятся к его платежной
+// системе. Номера диапазона идут подряд, поэтому достаточно проверить первый и последний
+func (p *CardProduct) matchesBrand() bool {
+	padding := p.PANLength - len(p.BINPrefix)
+	first := p.BINPrefix + strings.Repeat("0", padding)
+	last := p.BINPrefix + strings.Repeat("9", padding)
+	return DetectCardBrand(first) == p.Brand && DetectCardBrand(last) == p.Brand
+}
+
+// DefaultCardProducts продукты, создаваемые при инициализации базы данных
+func DefaultCardProducts() []CardProduct {
+	return []CardProduct{
+		{Code: "VISA_CLASSIC", Name: "Visa Classic", Brand: CardBrandVisa, BINPrefix: "427600", PANLength: 16, ValidityYears: 5, DailyLimit: 100000, MonthlyLimit: 1000000, IsActive: true, IsDefault: true},
+		{Code: "MC_STANDARD", Name: "Mastercard Standard", Brand: CardBrandMastercard, BINPrefix: "510000", PANLength: 16, ValidityYears: 5, DailyLimit: 100000, MonthlyLimit: 1000000, IsActive: true},
+		{Code: "MIR_CLASSIC", Name: "Мир Классическая", Brand: CardBrandMIR, BINPrefix: "220000", PANLength: 16, ValidityYears: 5, DailyLimit: 100000, MonthlyLimit: 1000000, IsActive: true},
+	}
+}
+
+// ToDTO преобразует модель в DTO
+func (p *CardProduct) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":             p.ID,
+		"code":           p.Code,
+		"name":           p.Name,
+		"brand":          p.Brand,
+		"bin_prefix":     p.BINPrefix,
+		"pan_length":     p.PANLength,
+		"validity_years": p.ValidityYears,
+		"daily_limit":    p.DailyLimit,
+		"monthly_limit":  p.MonthlyLimit,
+		"is_active":      p.IsActive,
+		"is_default":     p.IsDefault,
+	}
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrClockSimulationDisabled = errors.New("clock simulation is disabled")
+	ErrInvalidClockShift       = errors.New("clock can only be moved forward")
+)
+
+// Clock источник текущего времени. Сервисы берут время из часов, а доменные
+// методы получают его параметром now, поэтому сценарии с просрочкой, концом месяца
+// и окончанием сроков воспроизводятся на смоделированном времени
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock системное время
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock часы, идущие по системному времени
+var SystemClock Clock = systemClock{}
+
+// SimulatedClock часы со сдвигом относительно системного времени для тестовых
+// окружений. Время идет с обычной скоростью, сдвиг можно только увеличивать,
+// чтобы даты операций, графиков и блокировок не шли назад. Сдвиг не сохраняется
+// между перезапусками приложения
+type SimulatedClock struct {
+	mu     sync.RWMutex
+	offset time.Duration
+}
+
+// NewSimulatedClock создает часы без сдвига
+func NewSimulatedClock() *SimulatedClock {
+	return &SimulatedClock{}
+}
+
+// Now возвращает смоделированное время
+func (c *SimulatedClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Now().Add(c.offset)
+}
+
+// Offset возвращает сдвиг относительно системного времени
+func (c *SimulatedClock) Offset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.offset
+}
+
+// Advance переводит часы вперед на d
+func (c *SimulatedClock) Advance(d time.Duration) error {
+	if d <= 0 {
+		return ErrInvalidClockShift
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offset += d
+	return nil
+}
+
+// Reset возвращает часы к системному времени
+func (c *SimulatedClock) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offset = 0
+}
+
+// BusinessDate возвращает операционный день банка для момента t: полночь
+// по местному времени. Операции, начисления и сроки относятся к этому дню
+func BusinessDate(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// ClockState состояние часов приложения
+type ClockState struct {
+	Now          time.Time
+	BusinessDate time.Time
+	Simulated    bool
+	Offset       time.Duration
+}
+
+// ToDTO преобразует состояние в DTO
+func (s ClockState) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"now":            s.Now,
+		"business_date":  s.BusinessDate.Format("2006-01-02"),
+		"simulated":      s.Simulated,
+		"offset":         s.Offset.String(),
+		"offset_seconds": int64(s.Offset.Seconds()),
+	}
+}
@@ -15,6 +15,9 @@ var (
 	ErrCreditAlreadyActive  = errors.New("credit is already active")
 	ErrCreditNotActive      = errors.New("credit is not active")
 	ErrInvalidPaymentAmount = errors.New("invalid payment amount")
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrPaymentAlreadyPaid   = errors.New("payment already processed")
+	ErrCreditNotOwned       = errors.New("credit does not belong to the user")
 )
 
 type CreditStatus string
@@ -37,20 +40,54 @@ const (
 
 type Credit struct {
 	gorm.Model
-	AccountID     uint         `json:"account_id" gorm:"not null"`
-	UserID        uint         `json:"user_id" gorm:"not null"`
-	Amount        float64      `json:"amount" gorm:"type:decimal(20,2);not null"`
-	Term          int          `json:"term" gorm:"not null"` // в месяцах
-	InterestRate  float64      `json:"interest_rate" gorm:"type:decimal(5,2);not null"`
-	Status        CreditStatus `json:"status" gorm:"type:varchar(20);not null;default:'PENDING'"`
-	StartDate     time.Time    `json:"start_date"`
-	EndDate       time.Time    `json:"end_date"`
-	PaymentDay    int          `json:"payment_day" gorm:"not null"` // день месяца для платежа
-	NextPayment   time.Time    `json:"next_payment"`
-	TotalPaid     float64      `json:"total_paid" gorm:"type:decimal(20,2);default:0"`
-	RemainingDebt float64      `json:"remaining_debt" gorm:"type:decimal(20,2);not null"`
-	OverdueAmount float64      `json:"overdue_amount" gorm:"type:decimal(20,2);default:0"`
-	LastPayment   time.Time    `json:"last_payment"`
+	AccountID    uint    `json:"account_id" gorm:"not null"`
+	UserID       uint    `json:"user_id" gorm:"not null"`
+	Amount       float64 `json:"amount" gorm:"type:decimal(20,2);not null"`
+	Term         int     `json:"term" gorm:"not null"` // в месяцах
+	InterestRate float64 `json:"interest_rate" gorm:"type:decimal(5,2);not null"`
+	// Способ погашения: аннуитетные или дифференцированные платежи
+	RepaymentMethod RepaymentMethod `json:"repayment_method" gorm:"type:varchar(20);not null;default:'ANNUITY'"`
+	Status          CreditStatus    `json:"status" gorm:"type:varchar(20);not null;default:'PENDING'"`
+	StartDate       time.Time       `json:"start_date"`
+	EndDate         time.Time       `json:"end_date"`
+	PaymentDay      int             `json:"payment_day" gorm:"not null"` // день месяца для платежа
+	NextPayment     time.Time       `json:"next_payment"`
+	TotalPaid       float64         `json:"total_paid" gorm:"type:decimal(20,2);default:0"`
+	RemainingDebt   float64         `json:"remaining_debt" gorm:"type:decimal(20,2);not null"`
+	OverdueAmount   float64         `json:"overdue_amount" gorm:"type:decimal(20,2);default:0"` // просроченный долг вместе с неустойкой
+	PenaltyAmount   float64         `json:"penalty_amount" gorm:"type:decimal(20,2);default:0"` // непогашенные пени и штрафы
+	LastPayment     time.Time       `json:"last_payment"`
+	// Политика начисления неустойки; если не задана, применяется политика по умолчанию
+	PenaltyPolicyID *uint `json:"penalty_policy_id"`
+	// Кредитный продукт и условия ставки, зафиксированные при оформлении
+	ProductID   *uint    `json:"product_id"`
+	ProductCode string   `json:"product_code" gorm:"type:varchar(50)"`
+	RateType    RateType `json:"rate_type" gorm:"type:varchar(20)"`
+	RateMargin  float64  `json:"rate_margin" gorm:"type:decimal(5,2);default:0"`
+	IssueFee    float64  `json:"issue_fee" gorm:"type:decimal(20,2);default:0"` // комиссия за выдачу
+	// Полная стоимость кредита, рассчитанная по графику при выдаче
+	FullCostRate   float64 `json:"full_cost_rate" gorm:"type:decimal(8,3);default:0"`
+	FullCostAmount float64 `json:"full_cost_amount" gorm:"type:decimal(20,2);default:0"`
+	// Проценты, капитализированные в основной долг при реструктуризации
+	CapitalizedInterest float64 `json:"capitalized_interest" gorm:"type:decimal(20,2);default:0"`
+	// Номер действующей версии графика: увеличивается при каждой реструктуризации
+	ScheduleVersion int `json:"schedule_version" gorm:"not null;default:1"`
+	// Правило переноса даты платежа, выпавшей на выходной или праздник
+	BusinessDayRule BusinessDayRule `json:"business_day_rule" gorm:"type:varchar(20);not null;default:'NEXT'"`
+
+	// Производственный календарь для переноса дат графика; не хранится в базе
+	calendar *BusinessCalendar
+}
+
+// UseCalendar задает производственный календарь, по которому переносятся даты платежей.
+// Без календаря нерабочими считаются только суббота и воскресенье
+func (c *Credit) UseCalendar(calendar *BusinessCalendar) {
+	c.calendar = calendar
+}
+
+// paymentDate переносит договорную дату платежа на рабочий день по правилу кредита
+func (c *Credit) paymentDate(contractual time.Time) time.Time {
+	return c.calendar.Adjust(contractual, c.BusinessDayRule)
 }
 
 // Validate проверяет все поля кредита
@@ -70,12 +107,18 @@ func (c *Credit) Validate() error {
 	if err := c.ValidateStatus(); err != nil {
 		return err
 	}
+	if err := c.ValidateRepaymentMethod(); err != nil {
+		return err
+	}
+	if c.BusinessDayRule != "" && !c.BusinessDayRule.IsValid() {
+		return ErrInvalidBusinessDayRule
+	}
 	return nil
 }
 
 // ValidateAmount проверяет корректность суммы кредита
 func (c *Credit) ValidateAmount() error {
-	if c.Amount <= 0 {
+	if c.Amount <= 0 || c.IssueFee < 0 || c.IssueFee >= c.Amount {
 		return ErrInvalidCreditAmount
 	}
 	return nil
@@ -116,17 +159,27 @@ func (c *Credit) ValidateStatus() error {
 	}
 }
 
-// CalculateMonthlyPayment рассчитывает ежемесячный платеж
+// Principal возвращает сумму основного долга по кредиту с учетом
+// процентов, капитализированных при реструктуризации
+func (c *Credit) Principal() float64 {
+	return roundMoney(c.Amount + c.CapitalizedInterest)
+}
+
+// CalculateMonthlyPayment рассчитывает ежемесячный платеж.
+// Для дифференцированных платежей возвращает первый, самый крупный платеж
 func (c *Credit) CalculateMonthlyPayment() float64 {
-	// Формула аннуитетного платежа
 	monthlyRate := c.InterestRate / 12 / 100
+	if c.Method() == RepaymentMethodDifferentiated {
+		return c.Amount/float64(c.Term) + c.Amount*monthlyRate
+	}
+	// Формула аннуитетного платежа
 	denominator := 1 - 1/pow(1+monthlyRate, float64(c.Term))
 	return c.Amount * monthlyRate / denominator
 }
 
 // CalculateTotalAmount рассчитывает общую сумму к возврату
 func (c *Credit) CalculateTotalAmount() float64 {
-	return c.CalculateMonthlyPayment() * float64(c.Term)
+	return SummarizeSchedule(c.Method(), c.GenerateSchedule()).TotalPayment
 }
 
 // CalculateRemainingDebt рассчитывает оставшийся долг
@@ -134,15 +187,15 @@ func (c *Credit) CalculateRemainingDebt() float64 {
 	return c.CalculateTotalAmount() - c.TotalPaid
 }
 
-// IsOverdue проверяет, просрочен ли кредит
-func (c *Credit) IsOverdue() bool {
-	return c.Status == CreditStatusActive && time.Now().After(c.NextPayment)
+// IsOverdue проверяет, просрочен ли кредит на момент now
+func (c *Credit) IsOverdue(now time.Time) bool {
+	return c.Status == CreditStatusActive && now.After(c.NextPayment)
 }
 
 // UpdateStatus обновляет статус кредита
-func (c *Credit) UpdateStatus() {
+func (c *Credit) UpdateStatus(now time.Time) {
 	if c.Status == CreditStatusActive {
-		if c.IsOverdue() {
+		if c.IsOverdue(now) {
 			c.Status = CreditStatusOverdue
 		} else if c.RemainingDebt <= 0 {
 			c.Status = CreditStatusPaid
@@ -151,7 +204,7 @@ func (c *Credit) UpdateStatus() {
 }
 
 // MakePayment вносит платеж по кредиту
-func (c *Credit) MakePayment(amount float64) error {
+func (c *Credit) MakePayment(amount float64, now time.Time) error {
 	if c.Status != CreditStatusActive && c.Status != CreditStatusOverdue {
 		return ErrCreditNotActive
 	}
@@ -161,18 +214,19 @@ func (c *Credit) MakePayment(amount float64) error {
 
 	c.TotalPaid += amount
 	c.RemainingDebt = c.CalculateRemainingDebt()
-	c.LastPayment = time.Now()
+	c.LastPayment = now
 
 	// Обновляем дату следующего платежа
 	c.NextPayment = c.CalculateNextPaymentDate()
 
 	// Обновляем статус
-	c.UpdateStatus()
+	c.UpdateStatus(now)
 
 	return nil
 }
 
-// CalculateNextPaymentDate рассчитывает дату следующего платежа
+// CalculateNextPaymentDate рассчитывает дату следующего платежа с переносом
+// с выходного или праздника по правилу кредита
 func (c *Credit) CalculateNextPaymentDate() time.Time {
 	next := c.LastPayment.AddDate(0, 1, 0)
 	// Устанавливаем день платежа
@@ -187,7 +241,7 @@ func (c *Credit) CalculateNextPaymentDate() time.Time {
 	} else {
 		next = time.Date(next.Year(), next.Month(), c.PaymentDay, 0, 0, 0, 0, next.Location())
 	}
-	return next
+	return c.paymentDate(next)
 }
 
 // BeforeCreate хук для валидации перед созданием
@@ -203,23 +257,36 @@ func (c *Credit) BeforeUpdate(tx *gorm.DB) error {
 // ToDTO преобразует модель в DTO
 func (c *Credit) ToDTO() map[string]interface{} {
 	return map[string]interface{}{
-		"id":             c.ID,
-		"account_id":     c.AccountID,
-		"user_id":        c.UserID,
-		"amount":         c.Amount,
-		"term":           c.Term,
-		"interest_rate":  c.InterestRate,
-		"status":         c.Status,
-		"start_date":     c.StartDate,
-		"end_date":       c.EndDate,
-		"payment_day":    c.PaymentDay,
-		"next_payment":   c.NextPayment,
-		"total_paid":     c.TotalPaid,
-		"remaining_debt": c.RemainingDebt,
-		"overdue_amount": c.OverdueAmount,
-		"last_payment":   c.LastPayment,
-		"created_at":     c.CreatedAt,
-		"updated_at":     c.UpdatedAt,
+		"id":                   c.ID,
+		"account_id":           c.AccountID,
+		"user_id":              c.UserID,
+		"amount":               c.Amount,
+		"term":                 c.Term,
+		"interest_rate":        c.InterestRate,
+		"repayment_method":     c.Method(),
+		"status":               c.Status,
+		"start_date":           c.StartDate,
+		"end_date":             c.EndDate,
+		"payment_day":          c.PaymentDay,
+		"next_payment":         c.NextPayment,
+		"total_paid":           c.TotalPaid,
+		"remaining_debt":       c.RemainingDebt,
+		"overdue_amount":       c.OverdueAmount,
+		"penalty_amount":       c.PenaltyAmount,
+		"penalty_policy_id":    c.PenaltyPolicyID,
+		"product_id":           c.ProductID,
+		"product_code":         c.ProductCode,
+		"rate_type":            c.RateType,
+		"rate_margin":          c.RateMargin,
+		"issue_fee":            c.IssueFee,
+		"full_cost_rate":       c.FullCostRate,
+		"full_cost_amount":     c.FullCostAmount,
+		"capitalized_interest": c.CapitalizedInterest,
+		"schedule_version":     c.ScheduleVersion,
+		"business_day_rule":    c.BusinessDayRule.OrDefault(),
+		"last_payment":         c.LastPayment,
+		"created_at":           c.CreatedAt,
+		"updated_at":           c.UpdatedAt,
 	}
 }
 
@@ -238,36 +305,116 @@ func pow(x float64, n float64) float64 {
 }
 
 type PaymentSchedule struct {
-	ID            uint          `json:"id" gorm:"primaryKey"`
-	CreditID      uint          `json:"credit_id"`
-	PaymentNumber int           `json:"payment_number"`
-	DueDate       time.Time     `json:"due_date"`
-	Amount        float64       `json:"amount"`
-	Interest      float64       `json:"interest"`
-	Principal     float64       `json:"principal"`
-	TotalAmount   float64       `json:"total_amount"`
-	Status        PaymentStatus `json:"status" gorm:"type:varchar(20)"`
-	PaidAt        *time.Time    `json:"paid_at,omitempty"`
-	CreatedAt     time.Time     `json:"created_at"`
-	UpdatedAt     time.Time     `json:"updated_at"`
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	CreditID      uint      `json:"credit_id" gorm:"not null;uniqueIndex:idx_payment_schedule_number"`
+	PaymentNumber int       `json:"payment_number" gorm:"not null;uniqueIndex:idx_payment_schedule_number"`
+	DueDate       time.Time `json:"due_date" gorm:"index"` // дата платежа после переноса на рабочий день
+	// Дата платежа по договору; отличается от DueDate, если выпала на нерабочий день
+	ContractualDate time.Time     `json:"contractual_date"`
+	Amount          float64       `json:"amount" gorm:"type:decimal(20,2)"`
+	Interest        float64       `json:"interest" gorm:"type:decimal(20,2)"`  // плановые проценты
+	Principal       float64       `json:"principal" gorm:"type:decimal(20,2)"` // плановое погашение долга
+	TotalAmount     float64       `json:"total_amount" gorm:"type:decimal(20,2)"`
+	PaidPrincipal   float64       `json:"paid_principal" gorm:"type:decimal(20,2);default:0"`
+	PaidInterest    float64       `json:"paid_interest" gorm:"type:decimal(20,2);default:0"`
+	PaidAmount      float64       `json:"paid_amount" gorm:"type:decimal(20,2);default:0"`
+	OverdueDays     int           `json:"overdue_days" gorm:"default:0"`
+	Penalty         float64       `json:"penalty" gorm:"type:decimal(20,2);default:0"`  // начисленные пени
+	LateFee         float64       `json:"late_fee" gorm:"type:decimal(20,2);default:0"` // фиксированный штраф за просрочку
+	PaidPenalty     float64       `json:"paid_penalty" gorm:"type:decimal(20,2);default:0"`
+	PenaltyDays     int           `json:"penalty_days" gorm:"default:0"`     // дней просрочки, за которые начислены пени
+	Early           bool          `json:"early" gorm:"default:false"`        // досрочное погашение вне графика
+	Restructured    bool          `json:"restructured" gorm:"default:false"` // задолженность перенесена в новый график при реструктуризации
+	Status          PaymentStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	PaidAt          *time.Time    `json:"paid_at,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
 
 	Credit Credit `gorm:"foreignKey:CreditID" json:"credit"`
 }
 
+// ContractDate возвращает договорную дату платежа. У платежей, созданных
+// до ведения производственного календаря, она совпадает с датой платежа
+func (p *PaymentSchedule) ContractDate() time.Time {
+	if p.ContractualDate.IsZero() {
+		return p.DueDate
+	}
+	return p.ContractualDate
+}
+
+// IsPaid проверяет, оплачен ли платеж
+func (p *PaymentSchedule) IsPaid() bool {
+	return p.Status == PaymentStatusPaid
+}
+
+// IsDue проверяет, наступил ли срок неоплаченного платежа
+func (p *PaymentSchedule) IsDue(now time.Time) bool {
+	return !p.IsPaid() && !now.Before(p.DueDate)
+}
+
+// Outstanding возвращает неоплаченный остаток платежа
+func (p *PaymentSchedule) Outstanding() float64 {
+	return roundMoney(p.TotalAmount - p.PaidAmount)
+}
+
+// PenaltyOutstanding возвращает непогашенные пени и штрафы по платежу
+func (p *PaymentSchedule) PenaltyOutstanding() float64 {
+	return roundMoney(p.Penalty + p.LateFee - p.PaidPenalty)
+}
+
+// MarkPaid фиксирует полную оплату платежа
+func (p *PaymentSchedule) MarkPaid(now time.Time) error {
+	if p.IsPaid() {
+		return ErrPaymentAlreadyPaid
+	}
+	p.OverdueDays = overdueDays(p.DueDate, now)
+	p.PaidPrincipal = p.Principal
+	p.PaidInterest = p.Interest
+	p.PaidAmount = p.TotalAmount
+	p.Status = PaymentStatusPaid
+	p.PaidAt = &now
+	return nil
+}
+
+// MarkOverdue переводит платеж в просрочку и пересчитывает количество дней просрочки.
+// Возвращает true, если платеж стал просроченным только сейчас
+func (p *PaymentSchedule) MarkOverdue(now time.Time) bool {
+	if p.IsPaid() || !now.After(p.DueDate) {
+		return false
+	}
+	p.OverdueDays = overdueDays(p.DueDate, now)
+	if p.Status == PaymentStatusOverdue {
+		return false
+	}
+	p.Status = PaymentStatusOverdue
+	return true
+}
+
 // ToDTO преобразует структуру PaymentSchedule в DTO
 func (p *PaymentSchedule) ToDTO() map[string]interface{} {
 	dto := map[string]interface{}{
-		"id":             p.ID,
-		"credit_id":      p.CreditID,
-		"payment_number": p.PaymentNumber,
-		"due_date":       p.DueDate,
-		"amount":         p.Amount,
-		"interest":       p.Interest,
-		"principal":      p.Principal,
-		"total_amount":   p.TotalAmount,
-		"status":         p.Status,
-		"created_at":     p.CreatedAt,
-		"updated_at":     p.UpdatedAt,
+		"id":               p.ID,
+		"credit_id":        p.CreditID,
+		"payment_number":   p.PaymentNumber,
+		"due_date":         p.DueDate,
+		"contractual_date": p.ContractDate(),
+		"shifted":          !p.DueDate.Equal(p.ContractDate()),
+		"amount":           p.Amount,
+		"interest":         p.Interest,
+		"principal":        p.Principal,
+		"total_amount":     p.TotalAmount,
+		"paid_principal":   p.PaidPrincipal,
+		"paid_interest":    p.PaidInterest,
+		"paid_amount":      p.PaidAmount,
+		"overdue_days":     p.OverdueDays,
+		"penalty":          p.Penalty,
+		"late_fee":         p.LateFee,
+		"paid_penalty":     p.PaidPenalty,
+		"early":            p.Early,
+		"restructured":     p.Restructured,
+		"status":           p.Status,
+		"created_at":       p.CreatedAt,
+		"updated_at":       p.UpdatedAt,
 	}
 
 	if p.PaidAt != nil {
@@ -281,3 +428,47 @@ func (p *PaymentSchedule) ToDTO() map[string]interface{} {
 
 	return dto
 }
+
+// ApplySchedule пересчитывает итоги кредита по сохраненному графику:
+// выплаченную сумму, остаток основного долга, просроченную задолженность
+// вместе с неустойкой, дату следующего платежа и статус
+func (c *Credit) ApplySchedule(schedule []PaymentSchedule) {
+	var totalPaid, paidPrincipal, overdue, penalty float64
+	var next *PaymentSchedule
+	var lastPaid *time.Time
+	for i := range schedule {
+		p := &schedule[i]
+		totalPaid += p.PaidAmount + p.PaidPenalty
+		paidPrincipal += p.PaidPrincipal
+		penalty += p.PenaltyOutstanding()
+		if p.Status == PaymentStatusOverdue {
+			overdue += p.Outstanding()
+		}
+		if !p.IsPaid() && (next == nil || p.PaymentNumber < next.PaymentNumber) {
+			next = p
+		}
+		if p.PaidAt != nil && (lastPaid == nil || p.PaidAt.After(*lastPaid)) {
+			lastPaid = p.PaidAt
+		}
+	}
+
+	c.TotalPaid = roundMoney(totalPaid)
+	c.RemainingDebt = roundMoney(c.Principal() - paidPrincipal)
+	c.PenaltyAmount = roundMoney(penalty)
+	c.OverdueAmount = roundMoney(overdue + penalty)
+	if lastPaid != nil {
+		c.LastPayment = *lastPaid
+	}
+
+	switch {
+	case next == nil:
+		c.Status = CreditStatusPaid
+		c.RemainingDebt = 0
+	case c.OverdueAmount > 0:
+		c.NextPayment = next.DueDate
+		c.Status = CreditStatusOverdue
+	default:
+		c.NextPayment = next.DueDate
+		c.Status = CreditStatusActive
+	}
+}
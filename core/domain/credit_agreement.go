@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+var ErrAgreementNotFound = errors.New("credit agreement not found")
+
+// AgreementContentType формат документа кредитного договора
+const AgreementContentType = "text/html"
+
+// CreditAgreement кредитный договор с графиком платежей, сформированный при выдаче кредита
+type CreditAgreement struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	CreditID    uint      `json:"credit_id" gorm:"not null;uniqueIndex"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	Number      string    `json:"number" gorm:"type:varchar(32);not null;uniqueIndex"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(50);not null"`
+	Content     string    `json:"content" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// FileName возвращает имя файла для скачивания договора
+func (a *CreditAgreement) FileName() string {
+	return a.Number + ".html"
+}
+
+// agreementRow строка графика платежей в договоре вместе с остатком долга после платежа
+type agreementRow struct {
+	PaymentSchedule
+	Remaining float64
+}
+
+// agreementData данные для заполнения шаблона договора
+type agreementData struct {
+	Number   string
+	Date     time.Time
+	Borrower *User
+	Credit   *Credit
+	Schedule []agreementRow
+	Summary  ScheduleSummary
+	Penalty  *PenaltyPolicy
+	Purpose  string
+}
+
+var agreementTemplate = template.Must(template.New("agreement").Funcs(template.FuncMap{
+	"money": func(amount float64) string { return fmt.Sprintf("%.2f", amount) },
+	"date":  func(t time.Time) string { return t.Format("02.01.2006") },
+	"rate":  func(rate float64) string { return fmt.Sprintf("%.3f", rate) },
+}).Parse(agreementHTML))
+
+// NewCreditAgreement формирует кредитный договор по выданному кредиту и его графику.
+// ПСК указывается в правом верхнем углу первой страницы (ч. 1 ст. 6 Закона № 353-ФЗ)
+func NewCreditAgreement(credit *Credit, schedule []PaymentSchedule, borrower *User, penalty *PenaltyPolicy, purpose string) (*CreditAgreement, error) {
+	agreement := &CreditAgreement{
+		CreditID:    credit.ID,
+		UserID:      credit.UserID,
+		Number:      fmt.Sprintf("CA-%08d-%s", credit.ID, credit.StartDate.Format("20060102")),
+		ContentType: AgreementContentType,
+	}
+
+	rows := make([]agreementRow, len(schedule))
+	remaining := credit.Amount
+	for i, row := range schedule {
+		remaining = roundMoney(remaining - row.Principal)
+		rows[i] = agreementRow{PaymentSchedule: row, Remaining: remaining}
+	}
+
+	var content bytes.Buffer
+	err := agreementTemplate.Execute(&content, agreementData{
+		Number:   agreement.Number,
+		Date:     credit.StartDate,
+		Borrower: borrower,
+		Credit:   credit,
+		Schedule: rows,
+		Summary:  SummarizeSchedule(credit.Method(), schedule),
+		Penalty:  penalty,
+		Purpose:  purpose,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render credit agreement: %w", err)
+	}
+	agreement.Content = content.String()
+	return agreement, nil
+}
+
+const agreementHTML = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Договор потребительского кредита № {{.Number}}</title>
+<style>
+body { font-family: "Times New Roman", serif; font-size: 12pt; margin: 2cm; }
+.full-cost { float: right; border: 2px solid #000; padding: 8px 12px; text-align: center; }
+.full-cost strong { font-size: 14pt; }
+table { border-collapse: collapse; width: 100%; margin: 12px 0; }
+th, td { border: 1px solid #000; padding: 4px 6px; }
+td.num { text-align: right; }
+</style>
+</head>
+<body>
+<div class="full-cost">
+Полная стоимость кредита<br>
+<strong>{{rate .Credit.FullCostRate}} % годовых</strong><br>
+<strong>{{money .Credit.FullCostAmount}} руб.</strong>
+</div>
+
+<h1>Договор потребительского кредита № {{.Number}}</h1>
+<p>Дата заключения: {{date .Date}}</p>
+<p>Заемщик: {{if .Borrower}}{{.Borrower.Fio}}{{else}}клиент № {{.Credit.UserID}}{{end}}</p>
+
+<h2>Индивидуальные условия</h2>
+<table>
+<tr><th>Условие</th><th>Содержание</th></tr>
+<tr><td>Сумма кредита</td><td>{{money .Credit.Amount}} руб.</td></tr>
+<tr><td>Срок возврата кредита</td><td>{{.Credit.Term}} мес., до {{date .Credit.EndDate}}</td></tr>
+<tr><td>Процентная ставка</td><td>{{money .Credit.InterestRate}} % годовых{{if eq .Credit.RateType "KEY_RATE_MARGIN"}} (ключевая ставка Банка России + {{money .Credit.RateMargin}} п.п. на дату выдачи){{end}}</td></tr>
+<tr><td>Количество, размер и периодичность платежей</td><td>{{len .Schedule}} ежемесячных платежей {{if eq .Summary.Method "DIFFERENTIATED"}}от {{money .Summary.FirstPayment}} до {{money .Summary.LastPayment}} руб.{{else}}по {{money .Summary.FirstPayment}} руб., последний — {{money .Summary.LastPayment}} руб.{{end}}, {{.Credit.PaymentDay}} числа каждого месяца; платеж, выпавший на выходной или праздничный день, переносится на {{if eq .Credit.BusinessDayRule "PREVIOUS"}}предыдущий{{else}}следующий{{end}} рабочий день</td></tr>
+<tr><td>Счет зачисления и погашения</td><td>№ {{.Credit.AccountID}}</td></tr>
+<tr><td>Комиссия за выдачу кредита</td><td>{{money .Credit.IssueFee}} руб.</td></tr>
+<tr><td>Ответственность заемщика за ненадлежащее исполнение договора</td><td>{{if .Penalty}}Пени {{.Penalty.DailyRate}} % от просроченной задолженности за каждый день просрочки, но не более 20 % годовых{{if .Penalty.LateFee}}; штраф {{money .Penalty.LateFee}} руб. за каждый просроченный платеж{{end}}{{if .Penalty.GraceDays}}; неустойка начисляется по истечении {{.Penalty.GraceDays}} дн. просрочки{{end}}{{else}}Не установлена{{end}}</td></tr>
+{{if .Purpose}}<tr><td>Цель использования кредита</td><td>{{.Purpose}}</td></tr>{{end}}
+</table>
+
+<h2>График платежей</h2>
+<table>
+<tr><th>№</th><th>Дата платежа</th><th>Основной долг</th><th>Проценты</th><th>Сумма платежа</th><th>Остаток долга</th></tr>
+{{range .Schedule}}<tr><td>{{.PaymentNumber}}</td><td>{{date .DueDate}}</td><td class="num">{{money .Principal}}</td><td class="num">{{money .Interest}}</td><td class="num">{{money .TotalAmount}}</td><td class="num">{{money .Remaining}}</td></tr>
+{{end}}<tr><th colspan="2">Итого</th><th class="num">{{money .Summary.TotalPrincipal}}</th><th class="num">{{money .Summary.TotalInterest}}</th><th class="num">{{money .Summary.TotalPayment}}</th><th></th></tr>
+</table>
+
+<p>Подписи сторон:</p>
+<p>Кредитор: ____________________ &nbsp;&nbsp;&nbsp; Заемщик: ____________________</p>
+</body>
+</html>
+`
@@ -0,0 +1,364 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCreditApplication = errors.New("invalid credit application")
+	ErrApplicationNotOwned      = errors.New("credit application does not belong to the user")
+	ErrApplicationNotInReview   = errors.New("credit application is not awaiting review")
+	ErrAccountNotOwned          = errors.New("account does not belong to the user")
+)
+
+// Пороги автоматического решения по заявке: заявки со скорингом между порогами
+// передаются менеджеру
+const (
+	ScoreAutoApprove = 75
+	ScoreAutoReject  = 45
+	scoreBase        = 50
+
+	// MaxDebtToIncome предельная доля платежей по кредитам в доходе заемщика
+	MaxDebtToIncome = 0.7
+	// ScoringHistoryMonths за сколько месяцев анализируется история операций
+	ScoringHistoryMonths = 6
+)
+
+// CreditApplicationStatus статус заявки на кредит
+type CreditApplicationStatus string
+
+const (
+	ApplicationStatusManualReview CreditApplicationStatus = "MANUAL_REVIEW" // ждет решения менеджера
+	ApplicationStatusApproved     CreditApplicationStatus = "APPROVED"      // одобрена, кредит выдан
+	ApplicationStatusRejected     CreditApplicationStatus = "REJECTED"      // отклонена
+)
+
+// DecisionStage этап рассмотрения заявки
+type DecisionStage string
+
+const (
+	DecisionStageScoring DecisionStage = "SCORING" // автоматический скоринг
+	DecisionStageManager DecisionStage = "MANAGER" // решение менеджера
+)
+
+// DecisionOutcome результат этапа рассмотрения
+type DecisionOutcome string
+
+const (
+	DecisionApprove DecisionOutcome = "APPROVE"
+	DecisionReject  DecisionOutcome = "REJECT"
+	DecisionReview  DecisionOutcome = "REVIEW"
+)
+
+// CreditApplication заявка клиента на кредит
+type CreditApplication struct {
+	gorm.Model
+	UserID          uint                    `json:"user_id" gorm:"index;not null"`
+	AccountID       uint                    `json:"account_id" gorm:"not null"`
+	ProductID       *uint                   `json:"product_id"`
+	ProductCode     string                  `json:"product_code" gorm:"type:varchar(50)"`
+	RateType        RateType                `json:"rate_type" gorm:"type:varchar(20)"`
+	RateMargin      float64                 `json:"rate_margin" gorm:"type:decimal(5,2);default:0"`
+	PenaltyPolicyID *uint                   `json:"penalty_policy_id"`
+	Amount          float64                 `json:"amount" gorm:"type:decimal(20,2);not null"`
+	IssueFee        float64                 `json:"issue_fee" gorm:"type:decimal(20,2);default:0"`
+	Term            int                     `json:"term" gorm:"not null"`
+	RepaymentMethod RepaymentMethod         `json:"repayment_method" gorm:"type:varchar(20);not null"`
+	BusinessDayRule BusinessDayRule         `json:"business_day_rule" gorm:"type:varchar(20);not null;default:'NEXT'"`
+	DeclaredIncome  float64                 `json:"declared_income" gorm:"type:decimal(20,2);not null"` // ежемесячный доход по заявлению клиента
+	Description     string                  `json:"description" gorm:"type:text"`
+	InterestRate    float64                 `json:"interest_rate" gorm:"type:decimal(5,2);not null"`
+	MonthlyPayment  float64                 `json:"monthly_payment" gorm:"type:decimal(20,2)"`
+	Score           int                     `json:"score"`
+	Status          CreditApplicationStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	CreditID        *uint                   `json:"credit_id"`
+	DecidedAt       *time.Time              `json:"decided_at"`
+
+	Decisions []CreditDecision `json:"decisions" gorm:"foreignKey:ApplicationID"`
+}
+
+// CreditDecision решение по заявке вместе с факторами, на которых оно основано
+type CreditDecision struct {
+	gorm.Model
+	ApplicationID uint            `json:"application_id" gorm:"index;not null"`
+	Stage         DecisionStage   `json:"stage" gorm:"type:varchar(20);not null"`
+	Outcome       DecisionOutcome `json:"outcome" gorm:"type:varchar(20);not null"`
+	Score         int             `json:"score"`
+	Factors       []ScoringFactor `json:"factors" gorm:"serializer:json"`
+	ActorID       uint            `json:"actor_id"` // 0 - решение принято системой
+	Comment       string          `json:"comment" gorm:"type:text"`
+}
+
+// ScoringFactor фактор скоринга: значение показателя и его вклад в балл
+type ScoringFactor struct {
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Value       float64 `json:"value"`
+	Impact      int     `json:"impact"`
+	Decisive    bool    `json:"decisive,omitempty"` // фактор сам по себе определил отказ
+}
+
+// ScoringInput данные для скоринга заявки
+type ScoringInput struct {
+	MonthlyPayment          float64 // платеж по запрашиваемому кредиту
+	DeclaredIncome          float64
+	AverageMonthlyInflow    float64 // среднемесячные поступления на счета клиента
+	HistoryMonths           int     // сколько месяцев есть история операций
+	ExistingMonthlyPayments float64 // платежи по действующим кредитам
+	OverdueInstallments     int     // платежи, которые когда-либо были просрочены
+	HasCurrentOverdue       bool    // есть непогашенная просрочка
+}
+
+// ScoringResult результат автоматического скоринга
+type ScoringResult struct {
+	Score   int
+	Outcome DecisionOutcome
+	Factors []ScoringFactor
+}
+
+// NewCreditApplication создает заявку на кредит по условиям, рассчитанным калькулятором
+func NewCreditApplication(userID, accountID uint, terms *Credit, declaredIncome float64, description string) (*CreditApplication, error) {
+	if declaredIncome <= 0 {
+		return nil, ErrInvalidCreditApplication
+	}
+	if err := terms.Validate(); err != nil {
+		return nil, err
+	}
+	return &CreditApplication{
+		UserID:          userID,
+		AccountID:       accountID,
+		ProductID:       terms.ProductID,
+		ProductCode:     terms.ProductCode,
+		RateType:        terms.RateType,
+		RateMargin:      terms.RateMargin,
+		PenaltyPolicyID: terms.PenaltyPolicyID,
+		Amount:          terms.Amount,
+		IssueFee:        terms.IssueFee,
+		Term:            terms.Term,
+		RepaymentMethod: terms.Method(),
+		BusinessDayRule: terms.BusinessDayRule.OrDefault(),
+		DeclaredIncome:  declaredIncome,
+		Description:     description,
+		InterestRate:    terms.InterestRate,
+		MonthlyPayment:  roundMoney(terms.CalculateMonthlyPayment()),
+		Status:          ApplicationStatusManualReview,
+	}, nil
+}
+
+// ScoreApplication рассчитывает скоринговый балл заявки. Каждый фактор сохраняется
+// с его вкладом, чтобы решение можно было объяснить клиенту
+func ScoreApplication(input ScoringInput) ScoringResult {
+	result := ScoringResult{Score: scoreBase}
+	rejected := false
+	add := func(factor ScoringFactor) {
+		result.Score += factor.Impact
+		if factor.Decisive {
+			rejected = true
+		}
+		result.Factors = append(result.Factors, factor)
+	}
+
+	// Долговая нагрузка по подтвержденному доходу: берем меньшее из заявленного
+	// дохода и фактических поступлений, если история операций есть
+	income := input.DeclaredIncome
+	if input.HistoryMonths > 0 && input.AverageMonthlyInflow < income {
+		income = input.AverageMonthlyInflow
+	}
+	dti := 1.0
+	if income > 0 {
+		dti = (input.ExistingMonthlyPayments + input.MonthlyPayment) / income
+	}
+	dtiFactor := ScoringFactor{Code: "DEBT_TO_INCOME", Value: roundMoney(dti)}
+	switch {
+	case dti > MaxDebtToIncome:
+		dtiFactor.Description = fmt.Sprintf("Платежи по кредитам превышают %.0f%% дохода", MaxDebtToIncome*100)
+		dtiFactor.Impact = -50
+		dtiFactor.Decisive = true
+	case dti > 0.5:
+		dtiFactor.Description = "Высокая долговая нагрузка"
+		dtiFactor.Impact = -10
+	case dti > 0.3:
+		dtiFactor.Description = "Умеренная долговая нагрузка"
+		dtiFactor.Impact = 10
+	default:
+		dtiFactor.Description = "Низкая долговая нагрузка"
+		dtiFactor.Impact = 25
+	}
+	add(dtiFactor)
+
+	// Подтверждение дохода поступлениями на счета
+	confirmation := 0.0
+	if input.DeclaredIncome > 0 {
+		confirmation = input.AverageMonthlyInflow / input.DeclaredIncome
+	}
+	incomeFactor := ScoringFactor{Code: "INCOME_CONFIRMATION", Value: roundMoney(confirmation)}
+	switch {
+	case confirmation >= 0.8:
+		incomeFactor.Description = "Заявленный доход подтверждается поступлениями на счета"
+		incomeFactor.Impact = 15
+	case confirmation >= 0.5:
+		incomeFactor.Description = "Заявленный доход подтверждается частично"
+		incomeFactor.Impact = 5
+	default:
+		incomeFactor.Description = "Заявленный доход не подтверждается поступлениями на счета"
+		incomeFactor.Impact = -15
+	}
+	add(incomeFactor)
+
+	// Длительность истории операций
+	historyFactor := ScoringFactor{Code: "TRANSACTION_HISTORY", Value: float64(input.HistoryMonths)}
+	switch {
+	case input.HistoryMonths >= ScoringHistoryMonths:
+		historyFactor.Description = "Продолжительная история операций в банке"
+		historyFactor.Impact = 10
+	case input.HistoryMonths >= 3:
+		historyFactor.Description = "Короткая история операций в банке"
+		historyFactor.Impact = 5
+	default:
+		historyFactor.Description = "История операций в банке отсутствует или меньше трех месяцев"
+		historyFactor.Impact = -10
+	}
+	add(historyFactor)
+
+	// Кредитная дисциплина
+	overdueFactor := ScoringFactor{Code: "OVERDUE_HISTORY", Value: float64(input.OverdueInstallments)}
+	switch {
+	case input.HasCurrentOverdue:
+		overdueFactor.Description = "Есть непогашенная просроченная задолженность"
+		overdueFactor.Impact = -50
+		overdueFactor.Decisive = true
+	case input.OverdueInstallments == 0:
+		overdueFactor.Description = "Просрочек по кредитам не было"
+		overdueFactor.Impact = 10
+	case input.OverdueInstallments <= 2:
+		overdueFactor.Description = "Единичные просрочки по кредитам"
+		overdueFactor.Impact = -10
+	default:
+		overdueFactor.Description = "Систематические просрочки по кредитам"
+		overdueFactor.Impact = -25
+	}
+	add(overdueFactor)
+
+	switch {
+	case rejected || result.Score < ScoreAutoReject:
+		result.Outcome = DecisionReject
+	case result.Score >= ScoreAutoApprove:
+		result.Outcome = DecisionApprove
+	default:
+		result.Outcome = DecisionReview
+	}
+	return result
+}
+
+// ApplyScoring фиксирует результат скоринга и возвращает запись о решении
+func (a *CreditApplication) ApplyScoring(result ScoringResult, now time.Time) *CreditDecision {
+	a.Score = result.Score
+	switch result.Outcome {
+	case DecisionApprove:
+		a.Status = ApplicationStatusApproved
+		a.DecidedAt = &now
+	case DecisionReject:
+		a.Status = ApplicationStatusRejected
+		a.DecidedAt = &now
+	default:
+		a.Status = ApplicationStatusManualReview
+	}
+	return &CreditDecision{
+		ApplicationID: a.ID,
+		Stage:         DecisionStageScoring,
+		Outcome:       result.Outcome,
+		Score:         result.Score,
+		Factors:       result.Factors,
+	}
+}
+
+// Decide фиксирует решение менеджера по заявке на ручном рассмотрении
+func (a *CreditApplication) Decide(outcome DecisionOutcome, managerID uint, comment string, now time.Time) (*CreditDecision, error) {
+	if a.Status != ApplicationStatusManualReview {
+		return nil, ErrApplicationNotInReview
+	}
+	switch outcome {
+	case DecisionApprove:
+		a.Status = ApplicationStatusApproved
+	case DecisionReject:
+		a.Status = ApplicationStatusRejected
+	default:
+		return nil, ErrInvalidCreditApplication
+	}
+	a.DecidedAt = &now
+	return &CreditDecision{
+		ApplicationID: a.ID,
+		Stage:         DecisionStageManager,
+		Outcome:       outcome,
+		Score:         a.Score,
+		ActorID:       managerID,
+		Comment:       comment,
+	}, nil
+}
+
+// Terms возвращает условия кредита по заявке
+func (a *CreditApplication) Terms() *Credit {
+	return &Credit{
+		UserID:          a.UserID,
+		AccountID:       a.AccountID,
+		Amount:          a.Amount,
+		IssueFee:        a.IssueFee,
+		Term:            a.Term,
+		InterestRate:    a.InterestRate,
+		RepaymentMethod: a.RepaymentMethod,
+		BusinessDayRule: a.BusinessDayRule,
+		PenaltyPolicyID: a.PenaltyPolicyID,
+		ProductID:       a.ProductID,
+		ProductCode:     a.ProductCode,
+		RateType:        a.RateType,
+		RateMargin:      a.RateMargin,
+	}
+}
+
+// ToDTO преобразует модель в DTO
+func (a *CreditApplication) ToDTO() map[string]interface{} {
+	decisions := make([]map[string]interface{}, 0, len(a.Decisions))
+	for i := range a.Decisions {
+		decisions = append(decisions, a.Decisions[i].ToDTO())
+	}
+
+	return map[string]interface{}{
+		"id":                a.ID,
+		"user_id":           a.UserID,
+		"account_id":        a.AccountID,
+		"product_id":        a.ProductID,
+		"product_code":      a.ProductCode,
+		"amount":            a.Amount,
+		"issue_fee":         a.IssueFee,
+		"term":              a.Term,
+		"repayment_method":  a.RepaymentMethod,
+		"business_day_rule": a.BusinessDayRule,
+		"declared_income":   a.DeclaredIncome,
+		"description":       a.Description,
+		"interest_rate":     a.InterestRate,
+		"monthly_payment":   a.MonthlyPayment,
+		"score":             a.Score,
+		"status":            a.Status,
+		"credit_id":         a.CreditID,
+		"decided_at":        a.DecidedAt,
+		"created_at":        a.CreatedAt,
+		"decisions":         decisions,
+	}
+}
+
+// ToDTO преобразует модель в DTO
+func (d *CreditDecision) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"stage":      d.Stage,
+		"outcome":    d.Outcome,
+		"score":      d.Score,
+		"factors":    d.Factors,
+		"actor_id":   d.ActorID,
+		"comment":    d.Comment,
+		"created_at": d.CreatedAt,
+	}
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Расчет полной стоимости кредита (ПСК) по ч. 2 ст. 6 Закона № 353-ФЗ
+const (
+	// fullCostPeriodsPerYear число базовых периодов в году: базовый период — месяц
+	fullCostPeriodsPerYear = 12
+	// fullCostPeriodDays длительность базового периода в днях
+	fullCostPeriodDays = 365.0 / fullCostPeriodsPerYear
+	// fullCostPrecision точность подбора ставки базового периода
+	fullCostPrecision = 1e-12
+)
+
+// CashFlow денежный поток по кредиту: выдача кредита отрицательна,
+// платежи заемщика положительны
+type CashFlow struct {
+	Date   time.Time `json:"date"`
+	Amount float64   `json:"amount"`
+}
+
+// FullCost полная стоимость кредита в процентах годовых и в денежном выражении
+type FullCost struct {
+	Rate   float64 `json:"full_cost_rate"`   // ПСК, % годовых с точностью до третьего знака
+	Amount float64 `json:"full_cost_amount"` // все платежи заемщика сверх суммы кредита
+}
+
+// CashFlows возвращает денежные потоки по графику: в дату выдачи — сумма кредита
+// за вычетом комиссии за выдачу, далее плановые платежи. Неустойка в ПСК не включается
+func (c *Credit) CashFlows(schedule []PaymentSchedule) []CashFlow {
+	flows := make([]CashFlow, 0, len(schedule)+1)
+	flows = append(flows, CashFlow{Date: c.StartDate, Amount: roundMoney(c.IssueFee - c.Amount)})
+	for _, row := range schedule {
+		flows = append(flows, CashFlow{Date: row.DueDate, Amount: row.TotalAmount})
+	}
+	return flows
+}
+
+// FullCost рассчитывает ПСК по графику платежей
+func (c *Credit) FullCost(schedule []PaymentSchedule) FullCost {
+	return CalculateFullCost(c.StartDate, c.CashFlows(schedule))
+}
+
+// CalculateFullCost рассчитывает ПСК = i × ЧБП × 100, где i — ставка базового периода,
+// при которой Σ ДПk / ((1 + ek·i)(1 + i)^qk) = 0. Здесь qk — число полных базовых
+// периодов с даты выдачи до k-го платежа, ek — доля неполного базового периода
+func CalculateFullCost(start time.Time, flows []CashFlow) FullCost {
+	var amount float64
+	for _, flow := range flows {
+		amount += flow.Amount
+	}
+	cost := FullCost{Amount: roundMoney(amount)}
+	if len(flows) < 2 || amount <= 0 {
+		return cost
+	}
+
+	periods := make([]int, len(flows))
+	fractions := make([]float64, len(flows))
+	for k, flow := range flows {
+		periods[k], fractions[k] = fullCostPeriod(start, flow.Date)
+	}
+	presentValue := func(i float64) float64 {
+		var sum float64
+		for k, flow := range flows {
+			sum += flow.Amount / ((1 + fractions[k]*i) * math.Pow(1+i, float64(periods[k])))
+		}
+		return sum
+	}
+
+	// При нулевой ставке сумма потоков положительна, с ростом ставки
+	// приведенная стоимость платежей убывает — ищем корень делением отрезка
+	low, high := 0.0, 1.0
+	for presentValue(high) > 0 && high < 1e6 {
+		low, high = high, high*2
+	}
+	for high-low > fullCostPrecision {
+		mid := (low + high) / 2
+		if presentValue(mid) > 0 {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	cost.Rate = math.Round((low+high)/2*fullCostPeriodsPerYear*100*1000) / 1000
+	return cost
+}
+
+// fullCostPeriod возвращает число полных месяцев qk с даты start до date
+// и долю неполного месяца ek, рассчитанную по дням
+func fullCostPeriod(start, date time.Time) (int, float64) {
+	months := (date.Year()-start.Year())*12 + int(date.Month()) - int(start.Month())
+	for months > 0 && addMonths(start, months, start.Day()).After(date) {
+		months--
+	}
+	if months < 0 {
+		return 0, 0
+	}
+	days := int(date.Sub(addMonths(start, months, start.Day())).Hours() / 24)
+	return months, float64(days) / fullCostPeriodDays
+}
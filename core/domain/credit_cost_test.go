@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCreditFullCost(t *testing.T) {
+	// Платежи 15-го числа, выпавшие на выходные, переносятся на понедельник:
+	// 17.02, 17.03, 16.06 и 17.11.2025
+	start := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		method RepaymentMethod
+		fee    float64
+		rate   float64
+		amount float64
+	}{
+		{"annuity", RepaymentMethodAnnuity, 0, 11.963, 6618.53},
+		{"differentiated", RepaymentMethodDifferentiated, 0, 11.962, 6500},
+		{"annuity with issue fee", RepaymentMethodAnnuity, 2000, 15.805, 8618.53},
+		{"differentiated with issue fee", RepaymentMethodDifferentiated, 2000, 15.872, 8500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credit := &Credit{
+				Amount:          100000,
+				IssueFee:        tt.fee,
+				Term:            12,
+				InterestRate:    12,
+				PaymentDay:      15,
+				StartDate:       start,
+				RepaymentMethod: tt.method,
+			}
+			cost := credit.FullCost(credit.GenerateSchedule())
+			if cost.Rate != tt.rate {
+				t.Errorf("Rate = %.3f, want %.3f", cost.Rate, tt.rate)
+			}
+			if cost.Amount != tt.amount {
+				t.Errorf("Amount = %.2f, want %.2f", cost.Amount, tt.amount)
+			}
+		})
+	}
+}
+
+func TestCalculateFullCost(t *testing.T) {
+	start := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		flows  []CashFlow
+		rate   float64
+		amount float64
+	}{
+		{
+			// 1% за ровно один базовый период — 12% годовых
+			name: "one full period",
+			flows: []CashFlow{
+				{Date: start, Amount: -1000},
+				{Date: start.AddDate(0, 1, 0), Amount: 1010},
+			},
+			rate:   12,
+			amount: 10,
+		},
+		{
+			// Платеж через 73 дня: два полных месяца и доля 14/30,4167 третьего
+			name: "incomplete period",
+			flows: []CashFlow{
+				{Date: start, Amount: -1000},
+				{Date: start.AddDate(0, 0, 73), Amount: 1030},
+			},
+			rate:   14.495,
+			amount: 30,
+		},
+		{
+			name: "no overpayment",
+			flows: []CashFlow{
+				{Date: start, Amount: -1000},
+				{Date: start.AddDate(0, 1, 0), Amount: 1000},
+			},
+			rate:   0,
+			amount: 0,
+		},
+		{
+			name:   "issue only",
+			flows:  []CashFlow{{Date: start, Amount: -1000}},
+			rate:   0,
+			amount: -1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost := CalculateFullCost(start, tt.flows)
+			if cost.Rate != tt.rate {
+				t.Errorf("Rate = %.3f, want %.3f", cost.Rate, tt.rate)
+			}
+			if cost.Amount != tt.amount {
+				t.Errorf("Amount = %.2f, want %.2f", cost.Amount, tt.amount)
+			}
+		})
+	}
+}
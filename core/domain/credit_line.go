@@ -0,0 +1,351 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCreditLine    = errors.New("invalid credit line")
+	ErrCreditLineNotOwned   = errors.New("credit line does not belong to the user")
+	ErrCreditLineBlocked    = errors.New("credit line is blocked due to overdue minimum payment")
+	ErrCreditLimitBelowDebt = errors.New("credit limit cannot be lower than the current debt")
+)
+
+// Условия кредитной линии по умолчанию
+const (
+	DefaultCreditLineRate      = 29.9 // % годовых на использованные средства
+	DefaultMinPaymentPercent   = 5.0  // % задолженности в минимальном платеже
+	DefaultMinPaymentAmount    = 500.0
+	DefaultPaymentDueDays      = 20 // дней от выписки до даты платежа
+	DefaultCreditLineLateFee   = 590.0
+	MaxCreditLineLimit         = 3000000.0
+	MaxCreditLineBillingDay    = 28 // день выписки не позже 28-го, чтобы он был в каждом месяце
+	MaxCreditLinePaymentDueDay = 30
+)
+
+// CreditLineStatus статус кредитной линии
+type CreditLineStatus string
+
+const (
+	CreditLineStatusActive  CreditLineStatus = "ACTIVE"
+	CreditLineStatusOverdue CreditLineStatus = "OVERDUE" // минимальный платеж не внесен, расходные операции заблокированы
+)
+
+// StatementStatus статус выписки по кредитной линии
+type StatementStatus string
+
+const (
+	StatementStatusIssued  StatementStatus = "ISSUED"   // дата платежа еще не наступила
+	StatementStatusPaid    StatementStatus = "PAID"     // задолженность по выписке погашена полностью
+	StatementStatusMinPaid StatementStatus = "MIN_PAID" // внесен минимальный платеж
+	StatementStatusOverdue StatementStatus = "OVERDUE"  // минимальный платеж не внесен в срок
+)
+
+// CreditLine возобновляемая кредитная линия на кредитном счете. Проценты начисляются
+// ежедневно на использованные средства. На покупки по карте действует льготный период:
+// проценты на них откладываются и списываются, только если задолженность по выписке
+// не погашена полностью к дате платежа
+type CreditLine struct {
+	gorm.Model
+	AccountID         uint             `json:"account_id" gorm:"not null;uniqueIndex"`
+	UserID            uint             `json:"user_id" gorm:"not null;index"`
+	CreditLimit       float64          `json:"credit_limit" gorm:"type:decimal(20,2);not null"`
+	InterestRate      float64          `json:"interest_rate" gorm:"type:decimal(5,2);not null"`
+	MinPaymentPercent float64          `json:"min_payment_percent" gorm:"type:decimal(5,2);not null"`
+	MinPaymentAmount  float64          `json:"min_payment_amount" gorm:"type:decimal(20,2);default:0"`
+	LateFee           float64          `json:"late_fee" gorm:"type:decimal(20,2);default:0"`
+	BillingDay        int              `json:"billing_day" gorm:"not null"`
+	PaymentDueDays    int              `json:"payment_due_days" gorm:"not null"`
+	Status            CreditLineStatus `json:"status" gorm:"type:varchar(20);not null;default:'ACTIVE'"`
+	// Льготный период действует, пока выписки погашаются полностью
+	GraceActive bool      `json:"grace_active" gorm:"default:true"`
+	GraceSince  time.Time `json:"grace_since"` // покупки с этой даты относятся к льготному периоду
+	// Начисленные, но еще не выставленные проценты
+	AccruedInterest  float64   `json:"accrued_interest" gorm:"type:decimal(20,2);default:0"`
+	DeferredInterest float64   `json:"deferred_interest" gorm:"type:decimal(20,2);default:0"` // на покупки в льготном периоде
+	AccruedThrough   time.Time `json:"accrued_through"`
+	// Текущий расчетный период и начисления в нем, еще не попавшие в выписку
+	CycleStart        time.Time `json:"cycle_start"`
+	NextStatementDate time.Time `json:"next_statement_date"`
+	CycleInterest     float64   `json:"cycle_interest" gorm:"type:decimal(20,2);default:0"`
+	CycleFees         float64   `json:"cycle_fees" gorm:"type:decimal(20,2);default:0"`
+}
+
+// CreditLineStatement ежемесячная выписка по кредитной линии
+type CreditLineStatement struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CreditLineID uint      `json:"credit_line_id" gorm:"not null;index"`
+	PeriodStart  time.Time `json:"period_start"`
+	PeriodEnd    time.Time `json:"period_end"`
+	OpeningDebt  float64   `json:"opening_debt" gorm:"type:decimal(20,2)"`
+	Purchases    float64   `json:"purchases" gorm:"type:decimal(20,2)"`
+	CashAdvances float64   `json:"cash_advances" gorm:"type:decimal(20,2)"` // снятие наличных и переводы
+	Payments     float64   `json:"payments" gorm:"type:decimal(20,2)"`
+	Interest     float64   `json:"interest" gorm:"type:decimal(20,2)"`
+	Fees         float64   `json:"fees" gorm:"type:decimal(20,2)"`
+	ClosingDebt  float64   `json:"closing_debt" gorm:"type:decimal(20,2)"`
+	// Отложенные проценты льготного периода на дату выписки: не списываются при полном погашении
+	DeferredInterest float64         `json:"deferred_interest" gorm:"type:decimal(20,2)"`
+	MinPayment       float64         `json:"min_payment" gorm:"type:decimal(20,2)"`
+	DueDate          time.Time       `json:"due_date" gorm:"index"`
+	PaidAmount       float64         `json:"paid_amount" gorm:"type:decimal(20,2);default:0"`
+	Status           StatementStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+// CreditLineActivity операции по кредитному счету за период
+type CreditLineActivity struct {
+	Purchases    float64 // оплата покупок картой
+	CashAdvances float64 // снятие наличных, переводы и прочие списания
+	Payments     float64 // поступления на счет
+}
+
+// NewCreditLine открывает кредитную линию на условиях по умолчанию
+func NewCreditLine(userID, accountID uint, limit, rate float64, now time.Time) (*CreditLine, error) {
+	if rate == 0 {
+		rate = DefaultCreditLineRate
+	}
+	day := now.Day()
+	if day > MaxCreditLineBillingDay {
+		day = MaxCreditLineBillingDay
+	}
+	line := &CreditLine{
+		AccountID:         accountID,
+		UserID:            userID,
+		CreditLimit:       limit,
+		InterestRate:      rate,
+		MinPaymentPercent: DefaultMinPaymentPercent,
+		MinPaymentAmount:  DefaultMinPaymentAmount,
+		LateFee:           DefaultCreditLineLateFee,
+		BillingDay:        day,
+		PaymentDueDays:    DefaultPaymentDueDays,
+		Status:            CreditLineStatusActive,
+		GraceActive:       true,
+		GraceSince:        now,
+		AccruedThrough:    startOfDay(now),
+		CycleStart:        now,
+	}
+	line.NextStatementDate = line.statementDate(now)
+	if err := line.Validate(); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// Validate проверяет условия кредитной линии
+func (l *CreditLine) Validate() error {
+	if l.CreditLimit <= 0 || l.CreditLimit > MaxCreditLineLimit {
+		return ErrInvalidCreditLine
+	}
+	if l.InterestRate <= 0 || l.InterestRate > 100 {
+		return ErrInvalidCreditLine
+	}
+	if l.MinPaymentPercent <= 0 || l.MinPaymentPercent > 100 || l.MinPaymentAmount < 0 || l.LateFee < 0 {
+		return ErrInvalidCreditLine
+	}
+	if l.BillingDay < 1 || l.BillingDay > MaxCreditLineBillingDay {
+		return ErrInvalidCreditLine
+	}
+	if l.PaymentDueDays < 1 || l.PaymentDueDays > MaxCreditLinePaymentDueDay {
+		return ErrInvalidCreditLine
+	}
+	return nil
+}
+
+// CanSpend проверяет, разрешены ли расходные операции за счет кредитной линии
+func (l *CreditLine) CanSpend() error {
+	if l.Status == CreditLineStatusOverdue {
+		return ErrCreditLineBlocked
+	}
+	return nil
+}
+
+// AccrueInterest начисляет проценты за каждый день после AccruedThrough по день date
+// включительно на задолженность debt. Проценты на graceDebt — покупки в льготном
+// периоде — откладываются до даты платежа по выписке
+func (l *CreditLine) AccrueInterest(debt, graceDebt float64, date time.Time) {
+	end := startOfDay(date)
+	if !l.GraceActive {
+		graceDebt = 0
+	}
+	graceDebt = math.Max(0, math.Min(graceDebt, debt))
+
+	for day := startOfDay(l.AccruedThrough).AddDate(0, 0, 1); !day.After(end); day = day.AddDate(0, 0, 1) {
+		dailyRate := l.InterestRate / 100 / float64(daysInYear(day.Year()))
+		l.AccruedInterest = roundMoney(l.AccruedInterest + (debt-graceDebt)*dailyRate)
+		l.DeferredInterest = roundMoney(l.DeferredInterest + graceDebt*dailyRate)
+	}
+	if end.After(l.AccruedThrough) {
+		l.AccruedThrough = end
+	}
+}
+
+// StatementDue проверяет, наступила ли дата формирования выписки
+func (l *CreditLine) StatementDue(now time.Time) bool {
+	return !now.Before(l.NextStatementDate)
+}
+
+// AddCycleCharges учитывает в текущем расчетном периоде списанные вне выписки
+// проценты льготного периода и штрафы
+func (l *CreditLine) AddCycleCharges(interest, fee float64) {
+	l.CycleInterest = roundMoney(l.CycleInterest + interest)
+	l.CycleFees = roundMoney(l.CycleFees + fee)
+}
+
+// IssueStatement формирует выписку за текущий расчетный период и открывает следующий.
+// interest — выставленные в выписке проценты, closingDebt — задолженность с учетом процентов.
+// Проценты и штрафы списываются со счета как прочие списания, поэтому в выписке
+// они исключаются из снятий и переводов и показываются отдельно
+func (l *CreditLine) IssueStatement(activity CreditLineActivity, openingDebt, interest, closingDebt float64, now time.Time) *CreditLineStatement {
+	activity.CashAdvances = math.Max(0, activity.CashAdvances-interest-l.CycleInterest-l.CycleFees)
+	statement := &CreditLineStatement{
+		CreditLineID:     l.ID,
+		PeriodStart:      l.CycleStart,
+		PeriodEnd:        now,
+		OpeningDebt:      roundMoney(openingDebt),
+		Purchases:        roundMoney(activity.Purchases),
+		CashAdvances:     roundMoney(activity.CashAdvances),
+		Payments:         roundMoney(activity.Payments),
+		Interest:         roundMoney(interest + l.CycleInterest),
+		Fees:             l.CycleFees,
+		ClosingDebt:      roundMoney(closingDebt),
+		DeferredInterest: l.DeferredInterest,
+		DueDate:          startOfDay(now).AddDate(0, 0, l.PaymentDueDays+1).Add(-time.Second),
+		Status:           StatementStatusIssued,
+	}
+	statement.MinPayment = l.MinimumPayment(statement.ClosingDebt, statement.Interest)
+	if statement.ClosingDebt <= 0 {
+		statement.Status = StatementStatusPaid
+	}
+
+	l.AccruedInterest = 0
+	l.CycleInterest = 0
+	l.CycleFees = 0
+	l.CycleStart = now
+	l.NextStatementDate = l.statementDate(now)
+	return statement
+}
+
+// MinimumPayment рассчитывает минимальный платеж: процент от задолженности плюс
+// выставленные проценты, но не меньше минимальной суммы и не больше самой задолженности
+func (l *CreditLine) MinimumPayment(debt, interest float64) float64 {
+	if debt <= 0 {
+		return 0
+	}
+	payment := roundMoney(debt*l.MinPaymentPercent/100 + interest)
+	if payment < l.MinPaymentAmount {
+		payment = l.MinPaymentAmount
+	}
+	return math.Min(payment, roundMoney(debt))
+}
+
+// SettleStatement подводит итог по выписке в дату платежа. paid — поступления на счет
+// с даты выписки. Возвращает отложенные проценты, которые нужно списать, и штраф
+// за пропуск минимального платежа
+func (l *CreditLine) SettleStatement(statement *CreditLineStatement, paid float64) (interest, fee float64) {
+	statement.PaidAmount = roundMoney(paid)
+	switch {
+	case statement.PaidAmount >= statement.ClosingDebt:
+		// Задолженность погашена полностью: проценты льготного периода не взимаются,
+		// льготный период распространяется на покупки после выписки
+		statement.Status = StatementStatusPaid
+		l.DeferredInterest = 0
+		l.GraceActive = true
+		l.GraceSince = statement.PeriodEnd
+		return 0, 0
+	case statement.PaidAmount >= statement.MinPayment:
+		statement.Status = StatementStatusMinPaid
+	default:
+		statement.Status = StatementStatusOverdue
+		l.Status = CreditLineStatusOverdue
+		fee = l.LateFee
+	}
+
+	// Льготный период утрачен: отложенные проценты списываются, новые покупки
+	// облагаются процентами до полного погашения очередной выписки
+	interest = l.DeferredInterest
+	l.DeferredInterest = 0
+	l.GraceActive = false
+	return interest, fee
+}
+
+// RecheckOverdue снимает просрочку по выписке, если минимальный платеж внесен позже срока
+func (l *CreditLine) RecheckOverdue(statement *CreditLineStatement, paid float64) bool {
+	if statement.Status != StatementStatusOverdue || roundMoney(paid) < statement.MinPayment {
+		return false
+	}
+	statement.PaidAmount = roundMoney(paid)
+	statement.Status = StatementStatusMinPaid
+	return true
+}
+
+// statementDate возвращает дату следующей выписки после from
+func (l *CreditLine) statementDate(from time.Time) time.Time {
+	next := addMonths(startOfDay(from), 0, l.BillingDay)
+	if !next.After(from) {
+		next = addMonths(startOfDay(from), 1, l.BillingDay)
+	}
+	return next
+}
+
+// SummarizeCreditLineActivity разделяет операции по кредитному счету за период (from, to]
+// на покупки по карте, прочие списания и поступления. Отклоненные и отмененные операции не учитываются
+func SummarizeCreditLineActivity(accountID uint, transactions []Transaction, from, to time.Time) CreditLineActivity {
+	var activity CreditLineActivity
+	for _, t := range transactions {
+		if t.Status == TransactionStatusFailed || t.Status == TransactionStatusCancelled {
+			continue
+		}
+		if !t.CreatedAt.After(from) || t.CreatedAt.After(to) {
+			continue
+		}
+		switch {
+		case t.FromAccountID == accountID && t.ToAccountID == accountID:
+			continue
+		case t.FromAccountID == accountID && t.CardID != 0 && t.Type == TransactionTypePayment:
+			activity.Purchases += t.Amount
+		case t.FromAccountID == accountID:
+			activity.CashAdvances += t.Amount
+		case t.ToAccountID == accountID:
+			activity.Payments += t.Amount
+		}
+	}
+	activity.Purchases = roundMoney(activity.Purchases)
+	activity.CashAdvances = roundMoney(activity.CashAdvances)
+	activity.Payments = roundMoney(activity.Payments)
+	return activity
+}
+
+// ToDTO преобразует модель в DTO
+func (l *CreditLine) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":                  l.ID,
+		"account_id":          l.AccountID,
+		"user_id":             l.UserID,
+		"credit_limit":        l.CreditLimit,
+		"interest_rate":       l.InterestRate,
+		"min_payment_percent": l.MinPaymentPercent,
+		"min_payment_amount":  l.MinPaymentAmount,
+		"late_fee":            l.LateFee,
+		"billing_day":         l.BillingDay,
+		"payment_due_days":    l.PaymentDueDays,
+		"status":              l.Status,
+		"grace_active":        l.GraceActive,
+		"accrued_interest":    l.AccruedInterest,
+		"deferred_interest":   l.DeferredInterest,
+		"cycle_start":         l.CycleStart,
+		"next_statement_date": l.NextStatementDate,
+		"created_at":          l.CreatedAt,
+		"updated_at":          l.UpdatedAt,
+	}
+}
+
+// startOfDay возвращает начало суток
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,202 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidPenaltyPolicy = errors.New("invalid penalty policy")
+
+// Ограничения неустойки по потребительским кредитам (ч. 21 ст. 5 Закона № 353-ФЗ)
+const (
+	// MaxPenaltyAnnualRate не более 20% годовых, если проценты на сумму долга продолжают начисляться
+	MaxPenaltyAnnualRate = 20.0
+	// MaxPenaltyDailyRate не более 0,1% в день от просроченной задолженности
+	MaxPenaltyDailyRate = 0.1
+	// MaxGraceDays максимальный льготный период до начала начисления неустойки
+	MaxGraceDays = 30
+)
+
+// PenaltyPolicy правила начисления неустойки по просроченным платежам:
+// ежедневные пени в процентах от просроченной задолженности, фиксированный
+// штраф за каждый просроченный платеж и льготный период
+type PenaltyPolicy struct {
+	gorm.Model
+	Code      string  `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Name      string  `json:"name" gorm:"type:varchar(255);not null"`
+	DailyRate float64 `json:"daily_rate" gorm:"type:decimal(8,4);not null"` // % от просроченной задолженности в день
+	LateFee   float64 `json:"late_fee" gorm:"type:decimal(20,2);default:0"`
+	GraceDays int     `json:"grace_days" gorm:"default:0"`
+	IsDefault bool    `json:"is_default" gorm:"default:false"`
+}
+
+// Validate проверяет настройки политики
+func (p *PenaltyPolicy) Validate() error {
+	if p.Code == "" || p.Name == "" {
+		return ErrInvalidPenaltyPolicy
+	}
+	if p.DailyRate < 0 || p.DailyRate > MaxPenaltyDailyRate {
+		return ErrInvalidPenaltyPolicy
+	}
+	if p.LateFee < 0 || p.GraceDays < 0 || p.GraceDays > MaxGraceDays {
+		return ErrInvalidPenaltyPolicy
+	}
+	return nil
+}
+
+// DailyRateOn возвращает ставку пеней за день с учетом законного ограничения в 20% годовых
+func (p *PenaltyPolicy) DailyRateOn(date time.Time) float64 {
+	return math.Min(p.DailyRate, MaxPenaltyAnnualRate/float64(daysInYear(date.Year())))
+}
+
+// Accrue переводит наступившие неоплаченные платежи в просрочку и доначисляет
+// неустойку за дни просрочки после льготного периода, за которые она еще не начислена.
+// Строки графика изменяются на месте
+func (p *PenaltyPolicy) Accrue(schedule []PaymentSchedule, now time.Time) {
+	for i := range schedule {
+		row := &schedule[i]
+		row.MarkOverdue(now)
+		if row.Status != PaymentStatusOverdue {
+			continue
+		}
+
+		chargeable := row.OverdueDays - p.GraceDays
+		if chargeable <= 0 || chargeable <= row.PenaltyDays {
+			continue
+		}
+		if row.LateFee == 0 {
+			row.LateFee = p.LateFee
+		}
+		base := row.Outstanding()
+		penalty := row.Penalty
+		for day := row.PenaltyDays + 1; day <= chargeable; day++ {
+			date := row.DueDate.AddDate(0, 0, p.GraceDays+day)
+			penalty += base * p.DailyRateOn(date) / 100
+		}
+		row.Penalty = roundMoney(penalty)
+		row.PenaltyDays = chargeable
+	}
+}
+
+// ToDTO преобразует модель в DTO
+func (p *PenaltyPolicy) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":         p.ID,
+		"code":       p.Code,
+		"name":       p.Name,
+		"daily_rate": p.DailyRate,
+		"late_fee":   p.LateFee,
+		"grace_days": p.GraceDays,
+		"is_default": p.IsDefault,
+	}
+}
+
+// DefaultPenaltyPolicies политики, создаваемые при инициализации базы данных
+func DefaultPenaltyPolicies() []PenaltyPolicy {
+	return []PenaltyPolicy{
+		{Code: "STANDARD", Name: "Стандартная неустойка", DailyRate: 0.0548, LateFee: 500, GraceDays: 3, IsDefault: true},
+		{Code: "LOYAL", Name: "Неустойка без штрафа", DailyRate: 0.05, GraceDays: 5},
+	}
+}
+
+// PaymentAllocation распределение списанной суммы по видам задолженности
+type PaymentAllocation struct {
+	PaymentNumber    int     `json:"payment_number"`
+	Amount           float64 `json:"amount"`
+	Penalty          float64 `json:"penalty"`           // пени и штрафы
+	OverdueInterest  float64 `json:"overdue_interest"`  // просроченные проценты
+	OverduePrincipal float64 `json:"overdue_principal"` // просроченный основной долг
+	Interest         float64 `json:"interest"`          // проценты текущего платежа
+	Principal        float64 `json:"principal"`         // основной долг текущего платежа
+	Settled          bool    `json:"settled"`           // платеж погашен полностью
+}
+
+// AllocatePayment распределяет доступную сумму по задолженности в установленном порядке:
+// пени и штрафы, просроченные проценты, просроченный основной долг и только затем
+// текущий платеж number. Просроченная задолженность может гаситься частично,
+// текущий платеж — только целиком. Строки графика изменяются на месте
+func AllocatePayment(schedule []PaymentSchedule, number int, available float64, now time.Time) (*PaymentAllocation, error) {
+	var target *PaymentSchedule
+	for i := range schedule {
+		if schedule[i].PaymentNumber == number {
+			target = &schedule[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, ErrPaymentNotFound
+	}
+	if target.IsPaid() {
+		return nil, fmt.Errorf("payment #%d: %w", number, ErrPaymentAlreadyPaid)
+	}
+
+	allocation := &PaymentAllocation{PaymentNumber: number}
+	remaining := roundMoney(available)
+	take := func(due float64) float64 {
+		part := math.Min(remaining, roundMoney(due))
+		if part <= 0 {
+			return 0
+		}
+		remaining = roundMoney(remaining - part)
+		return part
+	}
+
+	for i := range schedule {
+		row := &schedule[i]
+		if part := take(row.PenaltyOutstanding()); part > 0 {
+			row.PaidPenalty = roundMoney(row.PaidPenalty + part)
+			allocation.Penalty += part
+		}
+	}
+	for i := range schedule {
+		row := &schedule[i]
+		if !row.IsDue(now) {
+			continue
+		}
+		if part := take(row.Interest - row.PaidInterest); part > 0 {
+			row.PaidInterest = roundMoney(row.PaidInterest + part)
+			allocation.OverdueInterest += part
+		}
+	}
+	for i := range schedule {
+		row := &schedule[i]
+		if !row.IsDue(now) {
+			continue
+		}
+		if part := take(row.Principal - row.PaidPrincipal); part > 0 {
+			row.PaidPrincipal = roundMoney(row.PaidPrincipal + part)
+			allocation.OverduePrincipal += part
+		}
+	}
+	if !target.IsDue(now) && remaining >= target.Outstanding() {
+		allocation.Interest = take(target.Interest - target.PaidInterest)
+		allocation.Principal = take(target.Principal - target.PaidPrincipal)
+		target.PaidInterest = roundMoney(target.PaidInterest + allocation.Interest)
+		target.PaidPrincipal = roundMoney(target.PaidPrincipal + allocation.Principal)
+	}
+
+	// Платежи, по которым погашены и проценты, и основной долг, считаются оплаченными
+	for i := range schedule {
+		row := &schedule[i]
+		if row.IsPaid() {
+			continue
+		}
+		if row.PaidInterest >= row.Interest && row.PaidPrincipal >= row.Principal {
+			row.MarkPaid(now)
+		} else {
+			row.PaidAmount = roundMoney(row.PaidInterest + row.PaidPrincipal)
+		}
+	}
+
+	allocation.Penalty = roundMoney(allocation.Penalty)
+	allocation.OverdueInterest = roundMoney(allocation.OverdueInterest)
+	allocation.OverduePrincipal = roundMoney(allocation.OverduePrincipal)
+	allocation.Amount = roundMoney(allocation.Penalty + allocation.OverdueInterest +
+		allocation.OverduePrincipal + allocation.Interest + allocation.Principal)
+	allocation.Settled = target.IsPaid()
+	return allocation, nil
+}
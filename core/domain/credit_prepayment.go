@@ -0,0 +1,275 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrInvalidPrepaymentMode = errors.New("invalid prepayment mode")
+	ErrPrepaymentTooSmall    = errors.New("prepayment does not cover accrued interest")
+	ErrPrepaymentExceedsDebt = errors.New("prepayment covers the whole debt, use full repayment")
+	ErrCreditHasDuePayments  = errors.New("credit has unpaid due payments")
+	ErrCertificateNotFound   = errors.New("closing certificate not found")
+	ErrPrepaymentOutdated    = errors.New("payment schedule has changed since the prepayment was calculated")
+)
+
+// PrepaymentMode способ пересчета графика после частичного досрочного погашения
+type PrepaymentMode string
+
+const (
+	// PrepaymentReduceTerm сохранить платеж и сократить срок
+	PrepaymentReduceTerm PrepaymentMode = "REDUCE_TERM"
+	// PrepaymentReducePayment сохранить срок и уменьшить платеж
+	PrepaymentReducePayment PrepaymentMode = "REDUCE_PAYMENT"
+)
+
+// Prepayment расчет досрочного погашения: запись о платеже и новая неоплаченная часть графика
+type Prepayment struct {
+	Mode      PrepaymentMode
+	Amount    float64 // сумма к списанию
+	Interest  float64 // проценты, начисленные с начала текущего периода до даты погашения
+	Principal float64 // погашаемый основной долг
+	Payment   PaymentSchedule
+	Schedule  []PaymentSchedule
+}
+
+// PlanPrepayment рассчитывает частичное досрочное погашение на дату now.
+// Из суммы сначала гасятся начисленные проценты, остаток идет в основной долг,
+// после чего неоплаченная часть графика строится заново
+func (c *Credit) PlanPrepayment(schedule []PaymentSchedule, amount float64, mode PrepaymentMode, now time.Time) (*Prepayment, error) {
+	if mode != PrepaymentReduceTerm && mode != PrepaymentReducePayment {
+		return nil, ErrInvalidPrepaymentMode
+	}
+	if amount <= 0 {
+		return nil, ErrInvalidPaymentAmount
+	}
+
+	unpaid, principal, interest, err := c.settlement(schedule, now)
+	if err != nil {
+		return nil, err
+	}
+	if amount <= interest {
+		return nil, ErrPrepaymentTooSmall
+	}
+	principalPart := roundMoney(amount - interest)
+	if principalPart >= principal {
+		return nil, ErrPrepaymentExceedsDebt
+	}
+	remaining := roundMoney(principal - principalPart)
+
+	prepayment := c.newPrepayment(schedule, mode, amount, interest, principalPart, now)
+
+	// Новая часть графика начинается с ближайшей даты платежа,
+	// проценты первого платежа начисляются с даты досрочного погашения
+	next := unpaid[0]
+	count := len(unpaid)
+	firstInterest := accruedInterest(remaining, c.InterestRate, now, next.ContractDate())
+	monthlyRate := c.InterestRate / 12 / 100
+
+	var principalFor func(interest float64) float64
+	switch {
+	case mode == PrepaymentReduceTerm && c.Method() == RepaymentMethodDifferentiated:
+		part := next.Principal
+		count = minInt(count, int(math.Ceil(remaining/part-1e-9)))
+		principalFor = fixedPrincipal(part)
+	case mode == PrepaymentReduceTerm:
+		payment := regularPayment(unpaid)
+		count = minInt(count, annuityTerm(remaining, monthlyRate, payment))
+		principalFor = annuityPrincipal(payment)
+	case c.Method() == RepaymentMethodDifferentiated:
+		principalFor = fixedPrincipal(roundMoney(remaining / float64(count)))
+	default:
+		principalFor = annuityPrincipal(roundMoney(annuityPayment(remaining, monthlyRate, count)))
+	}
+
+	prepayment.Schedule = c.buildRows(prepayment.Payment.PaymentNumber+1, c.period(next.ContractDate()), count,
+		remaining, firstInterest, principalFor)
+	return prepayment, nil
+}
+
+// PlanFullRepayment рассчитывает полное досрочное погашение на дату now:
+// весь остаток основного долга и проценты, начисленные до даты погашения
+func (c *Credit) PlanFullRepayment(schedule []PaymentSchedule, now time.Time) (*Prepayment, error) {
+	_, principal, interest, err := c.settlement(schedule, now)
+	if err != nil {
+		return nil, err
+	}
+	amount := roundMoney(principal + interest)
+	return c.newPrepayment(schedule, "", amount, interest, principal, now), nil
+}
+
+// Apply применяет досрочное погашение к кредиту: пересчитывает итоги
+// и дату окончания по новому графику. Возвращает график целиком
+func (p *Prepayment) Apply(credit *Credit, schedule []PaymentSchedule) []PaymentSchedule {
+	result := make([]PaymentSchedule, 0, len(schedule)+len(p.Schedule)+1)
+	for _, row := range schedule {
+		if row.IsPaid() {
+			result = append(result, row)
+		}
+	}
+	result = append(result, p.Payment)
+	result = append(result, p.Schedule...)
+
+	credit.ApplySchedule(result)
+	if len(p.Schedule) > 0 {
+		credit.EndDate = p.Schedule[len(p.Schedule)-1].DueDate
+	} else {
+		credit.EndDate = p.Payment.DueDate
+	}
+	return result
+}
+
+// settlement возвращает неоплаченные платежи, остаток основного долга и проценты,
+// начисленные с начала текущего процентного периода до даты now
+func (c *Credit) settlement(schedule []PaymentSchedule, now time.Time) ([]PaymentSchedule, float64, float64, error) {
+	if c.Status != CreditStatusActive && c.Status != CreditStatusOverdue {
+		return nil, 0, 0, ErrCreditNotActive
+	}
+
+	principal := c.Principal()
+	periodStart := c.StartDate
+	var unpaid []PaymentSchedule
+	for _, row := range schedule {
+		principal -= row.PaidPrincipal
+		if row.IsPaid() {
+			if row.ContractDate().After(periodStart) {
+				periodStart = row.ContractDate()
+			}
+			continue
+		}
+		if !now.Before(row.DueDate) {
+			return nil, 0, 0, ErrCreditHasDuePayments
+		}
+		unpaid = append(unpaid, row)
+	}
+	if len(unpaid) == 0 {
+		return nil, 0, 0, ErrCreditNotActive
+	}
+
+	principal = roundMoney(principal)
+	return unpaid, principal, accruedInterest(principal, c.InterestRate, periodStart, now), nil
+}
+
+// newPrepayment формирует запись о досрочном платеже, следующую за последним оплаченным платежом
+func (c *Credit) newPrepayment(schedule []PaymentSchedule, mode PrepaymentMode, amount, interest, principal float64, now time.Time) *Prepayment {
+	number := 0
+	for _, row := range schedule {
+		if row.IsPaid() && row.PaymentNumber > number {
+			number = row.PaymentNumber
+		}
+	}
+
+	return &Prepayment{
+		Mode:      mode,
+		Amount:    amount,
+		Interest:  interest,
+		Principal: principal,
+		Payment: PaymentSchedule{
+			CreditID:        c.ID,
+			PaymentNumber:   number + 1,
+			DueDate:         now,
+			ContractualDate: now,
+			Amount:          amount,
+			Interest:        interest,
+			Principal:       principal,
+			TotalAmount:     amount,
+			PaidPrincipal:   principal,
+			PaidInterest:    interest,
+			PaidAmount:      amount,
+			Status:          PaymentStatusPaid,
+			PaidAt:          &now,
+			Early:           true,
+		},
+	}
+}
+
+// CreditClosingCertificate справка о полном погашении кредита
+type CreditClosingCertificate struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	CreditID      uint      `json:"credit_id" gorm:"not null;uniqueIndex"`
+	UserID        uint      `json:"user_id" gorm:"not null;index"`
+	Number        string    `json:"number" gorm:"type:varchar(32);not null;uniqueIndex"`
+	Amount        float64   `json:"amount" gorm:"type:decimal(20,2)"`
+	TotalPaid     float64   `json:"total_paid" gorm:"type:decimal(20,2)"`
+	TotalInterest float64   `json:"total_interest" gorm:"type:decimal(20,2)"`
+	StartDate     time.Time `json:"start_date"`
+	ClosedAt      time.Time `json:"closed_at"`
+	Content       string    `json:"content" gorm:"type:text"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// NewClosingCertificate формирует справку о полном погашении по закрытому кредиту
+func NewClosingCertificate(credit *Credit, schedule []PaymentSchedule, closedAt time.Time) *CreditClosingCertificate {
+	var interest float64
+	for _, row := range schedule {
+		interest += row.PaidInterest
+	}
+
+	certificate := &CreditClosingCertificate{
+		CreditID:      credit.ID,
+		UserID:        credit.UserID,
+		Number:        fmt.Sprintf("CC-%08d-%s", credit.ID, closedAt.Format("20060102")),
+		Amount:        credit.Amount,
+		TotalPaid:     credit.TotalPaid,
+		TotalInterest: roundMoney(interest),
+		StartDate:     credit.StartDate,
+		ClosedAt:      closedAt,
+	}
+	certificate.Content = fmt.Sprintf(
+		"СПРАВКА № %s\n\n"+
+			"Настоящим подтверждается, что обязательства по кредиту № %d от %s "+
+			"на сумму %.2f руб. исполнены заемщиком в полном объеме %s.\n"+
+			"Всего уплачено: %.2f руб., в том числе проценты: %.2f руб.\n"+
+			"Задолженность по кредиту отсутствует, кредит закрыт.\n",
+		certificate.Number, credit.ID, credit.StartDate.Format("02.01.2006"), credit.Amount,
+		closedAt.Format("02.01.2006"), certificate.TotalPaid, certificate.TotalInterest,
+	)
+	return certificate
+}
+
+// accruedInterest начисляет проценты на остаток долга за полные дни между from и to
+func accruedInterest(principal, annualRate float64, from, to time.Time) float64 {
+	days := int(to.Sub(from).Hours() / 24)
+	if days <= 0 {
+		return 0
+	}
+	return roundMoney(principal * annualRate / 100 * float64(days) / float64(daysInYear(to.Year())))
+}
+
+// daysInYear возвращает количество дней в году
+func daysInYear(year int) int {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+// regularPayment возвращает регулярный аннуитетный платеж по неоплаченной части графика.
+// Первый платеж после досрочного погашения может отличаться, поэтому берется второй, если он есть
+func regularPayment(unpaid []PaymentSchedule) float64 {
+	if len(unpaid) > 1 {
+		return unpaid[1].TotalAmount
+	}
+	return unpaid[0].TotalAmount
+}
+
+// annuityPayment рассчитывает аннуитетный платеж на остаток долга за count месяцев
+func annuityPayment(principal, monthlyRate float64, count int) float64 {
+	return principal * monthlyRate / (1 - 1/pow(1+monthlyRate, float64(count)))
+}
+
+// annuityTerm рассчитывает количество аннуитетных платежей, за которое гасится остаток долга
+func annuityTerm(principal, monthlyRate, payment float64) int {
+	if principal*monthlyRate >= payment {
+		return math.MaxInt32
+	}
+	return int(math.Ceil(-math.Log(1-principal*monthlyRate/payment)/math.Log(1+monthlyRate) - 1e-9))
+}
+
+// minInt возвращает меньшее из чисел
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCreditProduct     = errors.New("invalid credit product")
+	ErrCreditProductInactive    = errors.New("credit product is not active")
+	ErrCreditTermsOutOfProduct  = errors.New("credit terms are outside the product limits")
+	ErrCreditProductNotEligible = errors.New("applicant is not eligible for the credit product")
+)
+
+// CreditProductType вид кредитного продукта
+type CreditProductType string
+
+const (
+	CreditProductConsumer    CreditProductType = "CONSUMER"    // потребительский кредит
+	CreditProductAuto        CreditProductType = "AUTO"        // автокредит
+	CreditProductRefinancing CreditProductType = "REFINANCING" // рефинансирование
+)
+
+// RateType способ определения процентной ставки
+type RateType string
+
+const (
+	RateTypeKeyRateMargin RateType = "KEY_RATE_MARGIN" // ключевая ставка ЦБ РФ плюс маржа
+	RateTypeFixed         RateType = "FIXED"           // фиксированная ставка
+)
+
+// CreditProduct кредитный продукт: лимиты суммы и срока, ставка, способ погашения,
+// политика неустойки и условия, которым должен соответствовать заемщик
+type CreditProduct struct {
+	gorm.Model
+	Code            string            `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Name            string            `json:"name" gorm:"type:varchar(255);not null"`
+	Type            CreditProductType `json:"type" gorm:"type:varchar(20);not null"`
+	MinAmount       float64           `json:"min_amount" gorm:"type:decimal(20,2);not null"`
+	MaxAmount       float64           `json:"max_amount" gorm:"type:decimal(20,2);not null"`
+	MinTerm         int               `json:"min_term" gorm:"not null"` // в месяцах
+	MaxTerm         int               `json:"max_term" gorm:"not null"`
+	RateType        RateType          `json:"rate_type" gorm:"type:varchar(20);not null"`
+	Margin          float64           `json:"margin" gorm:"type:decimal(5,2);default:0"`     // надбавка к ключевой ставке
+	FixedRate       float64           `json:"fixed_rate" gorm:"type:decimal(5,2);default:0"` // ставка для RateTypeFixed
+	RepaymentMethod RepaymentMethod   `json:"repayment_method" gorm:"type:varchar(20);not null;default:'ANNUITY'"`
+	IssueFee        float64           `json:"issue_fee" gorm:"type:decimal(20,2);default:0"` // комиссия за выдачу, включается в ПСК
+	// Перенос даты платежа, выпавшей на выходной или праздник: на следующий или предыдущий рабочий день
+	BusinessDayRule BusinessDayRule `json:"business_day_rule" gorm:"type:varchar(20);not null;default:'NEXT'"`
+	// Политика неустойки; если не задана, применяется политика по умолчанию
+	PenaltyPolicyID *uint `json:"penalty_policy_id"`
+	// Условия для заемщика
+	MinIncome        float64 `json:"min_income" gorm:"type:decimal(20,2);default:0"` // минимальный ежемесячный доход
+	MinHistoryMonths int     `json:"min_history_months" gorm:"default:0"`            // минимальная история операций в банке
+	AllowOverdue     bool    `json:"allow_overdue" gorm:"default:false"`             // допускается текущая просрочка по другим кредитам
+	IsActive         bool    `json:"is_active" gorm:"default:true"`
+	IsDefault        bool    `json:"is_default" gorm:"default:false"`
+}
+
+// Validate проверяет настройки продукта
+func (p *CreditProduct) Validate() error {
+	if p.Code == "" || p.Name == "" {
+		return ErrInvalidCreditProduct
+	}
+	switch p.Type {
+	case CreditProductConsumer, CreditProductAuto, CreditProductRefinancing:
+	default:
+		return ErrInvalidCreditProduct
+	}
+	if p.MinAmount <= 0 || p.MaxAmount < p.MinAmount {
+		return ErrInvalidCreditProduct
+	}
+	if p.MinTerm <= 0 || p.MaxTerm < p.MinTerm || p.MaxTerm > 360 {
+		return ErrInvalidCreditProduct
+	}
+	switch p.RateType {
+	case RateTypeKeyRateMargin:
+		if p.Margin < 0 || p.Margin > 100 {
+			return ErrInvalidCreditProduct
+		}
+	case RateTypeFixed:
+		if p.FixedRate <= 0 || p.FixedRate > 100 {
+			return ErrInvalidCreditProduct
+		}
+	default:
+		return ErrInvalidCreditProduct
+	}
+	if p.RepaymentMethod == "" {
+		p.RepaymentMethod = RepaymentMethodAnnuity
+	}
+	if !p.RepaymentMethod.IsValid() {
+		return ErrInvalidCreditProduct
+	}
+	p.BusinessDayRule = p.BusinessDayRule.OrDefault()
+	if !p.BusinessDayRule.IsValid() {
+		return ErrInvalidCreditProduct
+	}
+	if p.IssueFee < 0 || p.IssueFee >= p.MinAmount {
+		return ErrInvalidCreditProduct
+	}
+	if p.MinIncome < 0 || p.MinHistoryMonths < 0 {
+		return ErrInvalidCreditProduct
+	}
+	return nil
+}
+
+// UsesKeyRate проверяет, зависит ли ставка продукта от ключевой ставки
+func (p *CreditProduct) UsesKeyRate() bool {
+	return p.RateType == RateTypeKeyRateMargin
+}
+
+// InterestRate возвращает годовую ставку по продукту при текущей ключевой ставке
+func (p *CreditProduct) InterestRate(keyRate float64) float64 {
+	if p.RateType == RateTypeFixed {
+		return p.FixedRate
+	}
+	return roundMoney(keyRate + p.Margin)
+}
+
+// ValidateTerms проверяет, что сумма и срок укладываются в лимиты продукта
+func (p *CreditProduct) ValidateTerms(amount float64, term int) error {
+	if !p.IsActive {
+		return ErrCreditProductInactive
+	}
+	if amount < p.MinAmount || amount > p.MaxAmount {
+		return fmt.Errorf("amount must be between %.2f and %.2f: %w", p.MinAmount, p.MaxAmount, ErrCreditTermsOutOfProduct)
+	}
+	if term < p.MinTerm || term > p.MaxTerm {
+		return fmt.Errorf("term must be between %d and %d months: %w", p.MinTerm, p.MaxTerm, ErrCreditTermsOutOfProduct)
+	}
+	return nil
+}
+
+// CheckEligibility проверяет, соответствует ли заемщик условиям продукта
+func (p *CreditProduct) CheckEligibility(input ScoringInput) error {
+	if input.DeclaredIncome < p.MinIncome {
+		return fmt.Errorf("monthly income must be at least %.2f: %w", p.MinIncome, ErrCreditProductNotEligible)
+	}
+	if input.HistoryMonths < p.MinHistoryMonths {
+		return fmt.Errorf("at least %d months of account history required: %w", p.MinHistoryMonths, ErrCreditProductNotEligible)
+	}
+	if input.HasCurrentOverdue && !p.AllowOverdue {
+		return fmt.Errorf("current overdue debt on other credits: %w", ErrCreditProductNotEligible)
+	}
+	return nil
+}
+
+// ToDTO преобразует модель в DTO
+func (p *CreditProduct) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":                 p.ID,
+		"code":               p.Code,
+		"name":               p.Name,
+		"type":               p.Type,
+		"min_amount":         p.MinAmount,
+		"max_amount":         p.MaxAmount,
+		"min_term":           p.MinTerm,
+		"max_term":           p.MaxTerm,
+		"rate_type":          p.RateType,
+		"margin":             p.Margin,
+		"fixed_rate":         p.FixedRate,
+		"repayment_method":   p.RepaymentMethod,
+		"issue_fee":          p.IssueFee,
+		"business_day_rule":  p.BusinessDayRule.OrDefault(),
+		"penalty_policy_id":  p.PenaltyPolicyID,
+		"min_income":         p.MinIncome,
+		"min_history_months": p.MinHistoryMonths,
+		"allow_overdue":      p.AllowOverdue,
+		"is_active":          p.IsActive,
+		"is_default":         p.IsDefault,
+	}
+}
+
+// DefaultCreditProducts продукты, создаваемые при инициализации базы данных
+func DefaultCreditProducts() []CreditProduct {
+	return []CreditProduct{
+		{Code: "CONSUMER", Name: "Потребительский кредит", Type: CreditProductConsumer,
+			MinAmount: 10000, MaxAmount: 5000000, MinTerm: 3, MaxTerm: 60,
+			RateType: RateTypeKeyRateMargin, Margin: 5, RepaymentMethod: RepaymentMethodAnnuity,
+			BusinessDayRule: BusinessDayNext, IsActive: true, IsDefault: true},
+		{Code: "AUTO", Name: "Автокредит", Type: CreditProductAuto,
+			MinAmount: 100000, MaxAmount: 10000000, MinTerm: 12, MaxTerm: 84,
+			RateType: RateTypeKeyRateMargin, Margin: 3, RepaymentMethod: RepaymentMethodAnnuity,
+			BusinessDayRule: BusinessDayNext, IssueFee: 5000, MinIncome: 30000, IsActive: true},
+		{Code: "REFINANCING", Name: "Рефинансирование", Type: CreditProductRefinancing,
+			MinAmount: 50000, MaxAmount: 5000000, MinTerm: 12, MaxTerm: 84,
+			RateType: RateTypeFixed, FixedRate: 19.9, RepaymentMethod: RepaymentMethodAnnuity,
+			BusinessDayRule: BusinessDayNext, MinIncome: 25000, MinHistoryMonths: 3, IsActive: true},
+	}
+}
@@ -0,0 +1,434 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRestructuring      = errors.New("invalid restructuring request")
+	ErrRestructuringNotPending   = errors.New("restructuring request is not awaiting approval")
+	ErrRestructuringPending      = errors.New("credit already has a pending restructuring request")
+	ErrRestructuringSelfApproval = errors.New("restructuring request cannot be approved by its initiator")
+)
+
+const (
+	// MaxPaymentHolidayMonths предельная длительность кредитных каникул
+	MaxPaymentHolidayMonths = 6
+	// MaxTermExtensionMonths на сколько месяцев можно продлить срок за одну реструктуризацию
+	MaxTermExtensionMonths = 60
+)
+
+// RestructuringType вид реструктуризации кредита
+type RestructuringType string
+
+const (
+	RestructuringPaymentHoliday RestructuringType = "PAYMENT_HOLIDAY" // кредитные каникулы
+	RestructuringTermExtension  RestructuringType = "TERM_EXTENSION"  // продление срока
+	RestructuringRateReduction  RestructuringType = "RATE_REDUCTION"  // снижение ставки
+)
+
+// HolidayMode порядок уплаты процентов в кредитные каникулы
+type HolidayMode string
+
+const (
+	// HolidayInterestOnly в каникулы уплачиваются только проценты
+	HolidayInterestOnly HolidayMode = "INTEREST_ONLY"
+	// HolidayCapitalized платежей нет, проценты за каникулы прибавляются к основному долгу
+	HolidayCapitalized HolidayMode = "CAPITALIZED"
+)
+
+// RestructuringStatus статус заявки на реструктуризацию
+type RestructuringStatus string
+
+const (
+	RestructuringStatusPending  RestructuringStatus = "PENDING"  // ждет решения менеджера
+	RestructuringStatusApproved RestructuringStatus = "APPROVED" // одобрена, график перестроен
+	RestructuringStatusRejected RestructuringStatus = "REJECTED"
+)
+
+// CreditRestructuring заявка оператора на реструктуризацию кредита заемщика,
+// попавшего в трудную ситуацию. Новый график строится только после одобрения менеджером
+type CreditRestructuring struct {
+	gorm.Model
+	CreditID     uint                `json:"credit_id" gorm:"not null;index"`
+	UserID       uint                `json:"user_id" gorm:"not null;index"`
+	Type         RestructuringType   `json:"type" gorm:"type:varchar(20);not null"`
+	Months       int                 `json:"months"`                                 // длительность каникул или продление срока
+	HolidayMode  HolidayMode         `json:"holiday_mode" gorm:"type:varchar(20)"`   // только для кредитных каникул
+	InterestRate float64             `json:"interest_rate" gorm:"type:decimal(5,2)"` // новая ставка при снижении ставки
+	Reason       string              `json:"reason" gorm:"type:text"`
+	Status       RestructuringStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	OperatorID   uint                `json:"operator_id" gorm:"not null"` // оператор, оформивший заявку
+	ManagerID    *uint               `json:"manager_id"`                  // менеджер, принявший решение
+	Comment      string              `json:"comment" gorm:"type:text"`
+	DecidedAt    *time.Time          `json:"decided_at"`
+	// Результат реструктуризации
+	PreviousVersion     int     `json:"previous_version"` // версия замененного графика
+	ScheduleVersion     int     `json:"schedule_version"` // версия нового графика
+	CapitalizedInterest float64 `json:"capitalized_interest" gorm:"type:decimal(20,2);default:0"`
+}
+
+// NewCreditRestructuring оформляет заявку на реструктуризацию кредита
+func NewCreditRestructuring(credit *Credit, operatorID uint, restructuringType RestructuringType,
+	months int, mode HolidayMode, rate float64, reason string) (*CreditRestructuring, error) {
+	if err := credit.canRestructure(); err != nil {
+		return nil, err
+	}
+
+	r := &CreditRestructuring{
+		CreditID:   credit.ID,
+		UserID:     credit.UserID,
+		Type:       restructuringType,
+		Reason:     reason,
+		Status:     RestructuringStatusPending,
+		OperatorID: operatorID,
+	}
+	switch restructuringType {
+	case RestructuringPaymentHoliday:
+		if mode == "" {
+			mode = HolidayInterestOnly
+		}
+		if months < 1 || months > MaxPaymentHolidayMonths ||
+			(mode != HolidayInterestOnly && mode != HolidayCapitalized) {
+			return nil, ErrInvalidRestructuring
+		}
+		r.Months = months
+		r.HolidayMode = mode
+	case RestructuringTermExtension:
+		if months < 1 || months > MaxTermExtensionMonths {
+			return nil, ErrInvalidRestructuring
+		}
+		r.Months = months
+	case RestructuringRateReduction:
+		if rate <= 0 || rate >= credit.InterestRate {
+			return nil, ErrInvalidRestructuring
+		}
+		r.InterestRate = rate
+	default:
+		return nil, ErrInvalidRestructuring
+	}
+	return r, nil
+}
+
+// IsPending проверяет, ждет ли заявка решения
+func (r *CreditRestructuring) IsPending() bool {
+	return r.Status == RestructuringStatusPending
+}
+
+// Decide фиксирует решение менеджера. Одобрить заявку может только
+// сотрудник, который ее не оформлял
+func (r *CreditRestructuring) Decide(outcome DecisionOutcome, managerID uint, comment string, now time.Time) error {
+	if !r.IsPending() {
+		return ErrRestructuringNotPending
+	}
+	switch outcome {
+	case DecisionApprove:
+		if managerID == r.OperatorID {
+			return ErrRestructuringSelfApproval
+		}
+		r.Status = RestructuringStatusApproved
+	case DecisionReject:
+		r.Status = RestructuringStatusRejected
+	default:
+		return ErrInvalidRestructuring
+	}
+	r.ManagerID = &managerID
+	r.Comment = comment
+	r.DecidedAt = &now
+	return nil
+}
+
+// RestructuringPlan новый график кредита после реструктуризации
+type RestructuringPlan struct {
+	InterestRate        float64
+	Term                int
+	EndDate             time.Time
+	Arrears             float64           // просроченные проценты и основной долг, перенесенные в новый график
+	CapitalizedInterest float64           // проценты, прибавленные к основному долгу
+	Principal           float64           // основной долг, на который строится новый график
+	MonthlyPayment      float64           // регулярный платеж после каникул
+	Settled             []PaymentSchedule // просроченные платежи, задолженность по которым перенесена
+	Schedule            []PaymentSchedule // новая неоплаченная часть графика
+	Payments            []PaymentSchedule // график целиком
+}
+
+// Plan строит новый график на дату now. Оплаченные платежи сохраняются,
+// просроченные закрываются переносом задолженности: основной долг остается в долге,
+// неуплаченные проценты капитализируются. Остаток долга распределяется по новому
+// графику с ближайшей даты платежа после now
+func (r *CreditRestructuring) Plan(credit *Credit, schedule []PaymentSchedule, now time.Time) (*RestructuringPlan, error) {
+	if err := credit.canRestructure(); err != nil {
+		return nil, err
+	}
+
+	terms := *credit
+	if r.Type == RestructuringRateReduction {
+		terms.InterestRate = r.InterestRate
+	}
+	plan := &RestructuringPlan{InterestRate: terms.InterestRate}
+
+	principal := credit.Principal()
+	last := credit.StartDate
+	// Срок считается по договорным датам: дата окончания кредита могла быть перенесена на рабочий день
+	end := credit.EndDate
+	if len(schedule) > 0 {
+		end = schedule[0].ContractDate()
+	}
+	number := 0
+	for _, row := range schedule {
+		principal -= row.PaidPrincipal
+		if row.ContractDate().After(end) {
+			end = row.ContractDate()
+		}
+		if !row.IsPaid() && now.Before(row.DueDate) {
+			continue
+		}
+		if !row.IsPaid() {
+			plan.Arrears += row.Outstanding()
+			plan.CapitalizedInterest += row.Interest - row.PaidInterest
+			row.settleRestructured()
+			plan.Settled = append(plan.Settled, row)
+		}
+		plan.Payments = append(plan.Payments, row)
+		if row.ContractDate().After(last) {
+			last = row.ContractDate()
+		}
+		if row.PaymentNumber > number {
+			number = row.PaymentNumber
+		}
+	}
+	plan.Arrears = roundMoney(plan.Arrears)
+	plan.CapitalizedInterest = roundMoney(plan.CapitalizedInterest)
+	principal = roundMoney(principal + plan.CapitalizedInterest)
+	if principal <= 0 {
+		return nil, ErrCreditNotActive
+	}
+
+	// Первый платеж нового графика приходится на ближайшую дату платежа
+	// после последнего сохраненного платежа и после даты реструктуризации
+	period := terms.period(last)
+	for period < 1 || !terms.dueDate(period).After(last) || !terms.dueDate(period).After(now) {
+		period++
+	}
+	count := maxInt(terms.period(end)-period+1, 1)
+	firstInterest := accruedInterest(principal, terms.InterestRate, last, terms.dueDate(period))
+
+	switch r.Type {
+	case RestructuringTermExtension:
+		count += r.Months
+	case RestructuringPaymentHoliday:
+		interest := firstInterest
+		for i := 0; i < r.Months; i++ {
+			if i > 0 {
+				interest = terms.monthlyInterest(principal)
+			}
+			if r.HolidayMode == HolidayCapitalized {
+				principal = roundMoney(principal + interest)
+				plan.CapitalizedInterest = roundMoney(plan.CapitalizedInterest + interest)
+				continue
+			}
+			number++
+			plan.Schedule = append(plan.Schedule, PaymentSchedule{
+				CreditID:        credit.ID,
+				PaymentNumber:   number,
+				DueDate:         terms.paymentDate(terms.dueDate(period + i)),
+				ContractualDate: terms.dueDate(period + i),
+				Amount:          interest,
+				Interest:        interest,
+				TotalAmount:     interest,
+				Status:          PaymentStatusPending,
+			})
+		}
+		period += r.Months
+		firstInterest = terms.monthlyInterest(principal)
+	}
+
+	principalFor := annuityPrincipal(roundMoney(annuityPayment(principal, terms.InterestRate/12/100, count)))
+	if terms.Method() == RepaymentMethodDifferentiated {
+		principalFor = fixedPrincipal(roundMoney(principal / float64(count)))
+	}
+	rows := terms.buildRows(number+1, period, count, principal, firstInterest, principalFor)
+	plan.Schedule = append(plan.Schedule, rows...)
+	plan.Payments = append(plan.Payments, plan.Schedule...)
+
+	plan.Principal = principal
+	plan.MonthlyPayment = regularPayment(rows)
+	plan.EndDate = plan.Schedule[len(plan.Schedule)-1].DueDate
+	plan.Term = terms.period(plan.Schedule[len(plan.Schedule)-1].ContractDate())
+	if plan.Term > 360 {
+		return nil, ErrInvalidTerm
+	}
+	return plan, nil
+}
+
+// Apply применяет одобренный план к кредиту: меняет условия, увеличивает
+// версию графика и пересчитывает итоги по новому графику
+func (r *CreditRestructuring) Apply(credit *Credit, plan *RestructuringPlan) {
+	r.PreviousVersion = credit.ScheduleVersion
+	r.ScheduleVersion = credit.ScheduleVersion + 1
+	r.CapitalizedInterest = plan.CapitalizedInterest
+
+	credit.InterestRate = plan.InterestRate
+	credit.Term = plan.Term
+	credit.EndDate = plan.EndDate
+	credit.CapitalizedInterest = roundMoney(credit.CapitalizedInterest + plan.CapitalizedInterest)
+	credit.ScheduleVersion = r.ScheduleVersion
+	credit.ApplySchedule(plan.Payments)
+}
+
+// ToDTO преобразует модель в DTO
+func (r *CreditRestructuring) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":                   r.ID,
+		"credit_id":            r.CreditID,
+		"user_id":              r.UserID,
+		"type":                 r.Type,
+		"months":               r.Months,
+		"holiday_mode":         r.HolidayMode,
+		"interest_rate":        r.InterestRate,
+		"reason":               r.Reason,
+		"status":               r.Status,
+		"operator_id":          r.OperatorID,
+		"manager_id":           r.ManagerID,
+		"comment":              r.Comment,
+		"decided_at":           r.DecidedAt,
+		"previous_version":     r.PreviousVersion,
+		"schedule_version":     r.ScheduleVersion,
+		"capitalized_interest": r.CapitalizedInterest,
+		"created_at":           r.CreatedAt,
+	}
+}
+
+// ToDTO преобразует план в DTO для предварительного просмотра
+func (p *RestructuringPlan) ToDTO() map[string]interface{} {
+	schedule := make([]map[string]interface{}, 0, len(p.Schedule))
+	for i := range p.Schedule {
+		schedule = append(schedule, p.Schedule[i].ToDTO())
+	}
+	return map[string]interface{}{
+		"interest_rate":        p.InterestRate,
+		"term":                 p.Term,
+		"end_date":             p.EndDate,
+		"arrears":              p.Arrears,
+		"capitalized_interest": p.CapitalizedInterest,
+		"principal":            p.Principal,
+		"monthly_payment":      p.MonthlyPayment,
+		"schedule":             schedule,
+	}
+}
+
+// canRestructure проверяет, можно ли реструктурировать кредит
+func (c *Credit) canRestructure() error {
+	if c.Status != CreditStatusActive && c.Status != CreditStatusOverdue {
+		return ErrCreditNotActive
+	}
+	return nil
+}
+
+// settleRestructured закрывает просроченный платеж переносом неоплаченной части в новый
+// график: плановые суммы уменьшаются до фактически уплаченных. Неустойка по платежу
+// остается к уплате
+func (p *PaymentSchedule) settleRestructured() {
+	p.Principal = p.PaidPrincipal
+	p.Interest = p.PaidInterest
+	p.Amount = p.PaidAmount
+	p.TotalAmount = p.PaidAmount
+	p.Status = PaymentStatusPaid
+	p.Restructured = true
+}
+
+// CreditScheduleVersion снимок графика платежей, замененного при реструктуризации
+type CreditScheduleVersion struct {
+	ID              uint                     `json:"id" gorm:"primaryKey"`
+	CreditID        uint                     `json:"credit_id" gorm:"not null;uniqueIndex:idx_credit_schedule_version"`
+	Version         int                      `json:"version" gorm:"not null;uniqueIndex:idx_credit_schedule_version"`
+	RestructuringID uint                     `json:"restructuring_id" gorm:"not null;index"` // реструктуризация, заменившая график
+	InterestRate    float64                  `json:"interest_rate" gorm:"type:decimal(5,2)"`
+	Term            int                      `json:"term"`
+	EndDate         time.Time                `json:"end_date"`
+	RemainingDebt   float64                  `json:"remaining_debt" gorm:"type:decimal(20,2)"`
+	OverdueAmount   float64                  `json:"overdue_amount" gorm:"type:decimal(20,2)"`
+	Payments        []ScheduleVersionPayment `json:"payments" gorm:"serializer:json"`
+	CreatedAt       time.Time                `json:"created_at"`
+}
+
+// ScheduleVersionPayment платеж в снимке графика
+type ScheduleVersionPayment struct {
+	PaymentNumber   int           `json:"payment_number"`
+	DueDate         time.Time     `json:"due_date"`
+	ContractualDate time.Time     `json:"contractual_date"`
+	Interest        float64       `json:"interest"`
+	Principal       float64       `json:"principal"`
+	TotalAmount     float64       `json:"total_amount"`
+	PaidAmount      float64       `json:"paid_amount"`
+	Penalty         float64       `json:"penalty"` // пени и штрафы
+	PaidPenalty     float64       `json:"paid_penalty"`
+	OverdueDays     int           `json:"overdue_days"`
+	Early           bool          `json:"early,omitempty"`
+	Restructured    bool          `json:"restructured,omitempty"`
+	Status          PaymentStatus `json:"status"`
+	PaidAt          *time.Time    `json:"paid_at,omitempty"`
+}
+
+// NewScheduleVersion сохраняет действующий график кредита перед его заменой
+func NewScheduleVersion(credit *Credit, schedule []PaymentSchedule, restructuringID uint) *CreditScheduleVersion {
+	payments := make([]ScheduleVersionPayment, 0, len(schedule))
+	for _, row := range schedule {
+		payments = append(payments, ScheduleVersionPayment{
+			PaymentNumber:   row.PaymentNumber,
+			DueDate:         row.DueDate,
+			ContractualDate: row.ContractDate(),
+			Interest:        row.Interest,
+			Principal:       row.Principal,
+			TotalAmount:     row.TotalAmount,
+			PaidAmount:      row.PaidAmount,
+			Penalty:         roundMoney(row.Penalty + row.LateFee),
+			PaidPenalty:     row.PaidPenalty,
+			OverdueDays:     row.OverdueDays,
+			Early:           row.Early,
+			Restructured:    row.Restructured,
+			Status:          row.Status,
+			PaidAt:          row.PaidAt,
+		})
+	}
+
+	return &CreditScheduleVersion{
+		CreditID:        credit.ID,
+		Version:         credit.ScheduleVersion,
+		RestructuringID: restructuringID,
+		InterestRate:    credit.InterestRate,
+		Term:            credit.Term,
+		EndDate:         credit.EndDate,
+		RemainingDebt:   credit.RemainingDebt,
+		OverdueAmount:   credit.OverdueAmount,
+		Payments:        payments,
+	}
+}
+
+// ToDTO преобразует модель в DTO
+func (v *CreditScheduleVersion) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":               v.ID,
+		"credit_id":        v.CreditID,
+		"version":          v.Version,
+		"restructuring_id": v.RestructuringID,
+		"interest_rate":    v.InterestRate,
+		"term":             v.Term,
+		"end_date":         v.EndDate,
+		"remaining_debt":   v.RemainingDebt,
+		"overdue_amount":   v.OverdueAmount,
+		"payments":         v.Payments,
+		"created_at":       v.CreatedAt,
+	}
+}
+
+// maxInt возвращает большее из чисел
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrInvalidRepaymentMethod = errors.New("invalid repayment method")
+
+// RepaymentMethod способ погашения кредита
+type RepaymentMethod string
+
+const (
+	// RepaymentMethodAnnuity равные ежемесячные платежи
+	RepaymentMethodAnnuity RepaymentMethod = "ANNUITY"
+	// RepaymentMethodDifferentiated равные доли основного долга, проценты на остаток
+	RepaymentMethodDifferentiated RepaymentMethod = "DIFFERENTIATED"
+)
+
+// IsValid проверяет, поддерживается ли способ погашения
+func (m RepaymentMethod) IsValid() bool {
+	return m == RepaymentMethodAnnuity || m == RepaymentMethodDifferentiated
+}
+
+// ScheduleSummary итоги графика платежей
+type ScheduleSummary struct {
+	Method         RepaymentMethod `json:"repayment_method"`
+	FirstPayment   float64         `json:"first_payment"`
+	LastPayment    float64         `json:"last_payment"`
+	TotalPayment   float64         `json:"total_payment"`
+	TotalPrincipal float64         `json:"total_principal"`
+	TotalInterest  float64         `json:"total_interest"` // переплата по кредиту
+}
+
+// RepaymentComparison сравнение переплаты при аннуитетных и дифференцированных платежах
+type RepaymentComparison struct {
+	Annuity        ScheduleSummary `json:"annuity"`
+	Differentiated ScheduleSummary `json:"differentiated"`
+	// InterestSavings на сколько дифференцированные платежи дешевле аннуитетных
+	InterestSavings float64 `json:"interest_savings"`
+}
+
+// Method возвращает способ погашения кредита; для кредитов без указанного способа — аннуитет
+func (c *Credit) Method() RepaymentMethod {
+	if c.RepaymentMethod == "" {
+		return RepaymentMethodAnnuity
+	}
+	return c.RepaymentMethod
+}
+
+// ValidateRepaymentMethod проверяет корректность способа погашения
+func (c *Credit) ValidateRepaymentMethod() error {
+	if !c.Method().IsValid() {
+		return ErrInvalidRepaymentMethod
+	}
+	return nil
+}
+
+// GenerateSchedule строит график платежей выбранным способом погашения.
+// Суммы округляются до копеек, погрешность округления уходит в последний платеж,
+// чтобы сумма погашенного долга совпадала с суммой кредита
+func (c *Credit) GenerateSchedule() []PaymentSchedule {
+	if c.Method() == RepaymentMethodDifferentiated {
+		return c.generateDifferentiatedSchedule()
+	}
+	return c.generateAnnuitySchedule()
+}
+
+// CompareRepaymentMethods строит графики обоими способами и сравнивает переплату
+func (c *Credit) CompareRepaymentMethods() RepaymentComparison {
+	annuity := *c
+	annuity.RepaymentMethod = RepaymentMethodAnnuity
+	differentiated := *c
+	differentiated.RepaymentMethod = RepaymentMethodDifferentiated
+
+	comparison := RepaymentComparison{
+		Annuity:        SummarizeSchedule(RepaymentMethodAnnuity, annuity.GenerateSchedule()),
+		Differentiated: SummarizeSchedule(RepaymentMethodDifferentiated, differentiated.GenerateSchedule()),
+	}
+	comparison.InterestSavings = roundMoney(comparison.Annuity.TotalInterest - comparison.Differentiated.TotalInterest)
+	return comparison
+}
+
+// SummarizeSchedule подводит плановые итоги графика платежей
+func SummarizeSchedule(method RepaymentMethod, schedule []PaymentSchedule) ScheduleSummary {
+	summary := ScheduleSummary{Method: method}
+	if len(schedule) == 0 {
+		return summary
+	}
+	summary.FirstPayment = schedule[0].TotalAmount
+	summary.LastPayment = schedule[len(schedule)-1].TotalAmount
+	for _, p := range schedule {
+		summary.TotalPayment += p.TotalAmount
+		summary.TotalPrincipal += p.Principal
+		summary.TotalInterest += p.Interest
+	}
+	summary.TotalPayment = roundMoney(summary.TotalPayment)
+	summary.TotalPrincipal = roundMoney(summary.TotalPrincipal)
+	summary.TotalInterest = roundMoney(summary.TotalInterest)
+	return summary
+}
+
+// generateAnnuitySchedule строит график равных ежемесячных платежей
+func (c *Credit) generateAnnuitySchedule() []PaymentSchedule {
+	return c.buildRows(1, 1, c.Term, c.Amount, c.monthlyInterest(c.Amount),
+		annuityPrincipal(roundMoney(c.CalculateMonthlyPayment())))
+}
+
+// generateDifferentiatedSchedule строит график с равными долями основного долга
+// и процентами на остаток задолженности
+func (c *Credit) generateDifferentiatedSchedule() []PaymentSchedule {
+	return c.buildRows(1, 1, c.Term, c.Amount, c.monthlyInterest(c.Amount),
+		fixedPrincipal(roundMoney(c.Amount/float64(c.Term))))
+}
+
+// buildRows строит count платежей с номерами начиная с number на остаток долга remaining.
+// Первый платеж приходится на период period (месяц от даты выдачи), его проценты
+// передаются в firstInterest, проценты остальных платежей начисляются помесячно.
+// Долю основного долга каждого платежа определяет principalFor
+func (c *Credit) buildRows(number, period, count int, remaining, firstInterest float64, principalFor func(interest float64) float64) []PaymentSchedule {
+	schedule := make([]PaymentSchedule, 0, count)
+
+	for i := 0; i < count; i++ {
+		interest := roundMoney(firstInterest)
+		if i > 0 {
+			interest = c.monthlyInterest(remaining)
+		}
+		principal := roundMoney(principalFor(interest))
+		if i == count-1 || principal > remaining {
+			principal = roundMoney(remaining)
+		}
+		remaining = roundMoney(remaining - principal)
+		total := roundMoney(principal + interest)
+
+		schedule = append(schedule, PaymentSchedule{
+			CreditID:        c.ID,
+			PaymentNumber:   number + i,
+			DueDate:         c.paymentDate(c.dueDate(period + i)),
+			ContractualDate: c.dueDate(period + i),
+			Amount:          total,
+			Interest:        interest,
+			Principal:       principal,
+			TotalAmount:     total,
+			Status:          PaymentStatusPending,
+		})
+
+		if remaining <= 0 {
+			break
+		}
+	}
+
+	return schedule
+}
+
+// monthlyInterest рассчитывает проценты за месяц на остаток долга
+func (c *Credit) monthlyInterest(remaining float64) float64 {
+	return roundMoney(remaining * c.InterestRate / 12 / 100)
+}
+
+// annuityPrincipal доля основного долга при фиксированном платеже
+func annuityPrincipal(payment float64) func(interest float64) float64 {
+	return func(interest float64) float64 {
+		return payment - interest
+	}
+}
+
+// fixedPrincipal фиксированная доля основного долга
+func fixedPrincipal(principal float64) func(interest float64) float64 {
+	return func(interest float64) float64 {
+		return principal
+	}
+}
+
+// dueDate возвращает договорную дату n-го платежа с учетом дня платежа и длины месяца
+func (c *Credit) dueDate(n int) time.Time {
+	return addMonths(c.StartDate, n, c.PaymentDay)
+}
+
+// addMonths сдвигает дату на n месяцев и ставит день day, а если в месяце
+// столько дней нет — последний день месяца
+func addMonths(start time.Time, n, day int) time.Time {
+	firstOfMonth := time.Date(start.Year(), start.Month()+time.Month(n), 1,
+		start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+// period возвращает номер месяца от даты выдачи, на который приходится дата платежа
+func (c *Credit) period(dueDate time.Time) int {
+	return (dueDate.Year()-c.StartDate.Year())*12 + int(dueDate.Month()) - int(c.StartDate.Month())
+}
+
+// overdueDays возвращает количество полных дней между сроком платежа и моментом now
+func overdueDays(dueDate, now time.Time) int {
+	if !now.After(dueDate) {
+		return 0
+	}
+	return int(now.Sub(dueDate).Hours() / 24)
+}
+
+// roundMoney округляет сумму до копеек
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
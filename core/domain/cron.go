@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCronExpression = errors.New("invalid cron expression")
+
+// cronDescriptors сокращения для распространенных расписаний
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField допустимый диапазон поля расписания
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 0 и 7 - воскресенье
+}
+
+// CronSchedule расписание в формате cron из пяти полей:
+// минута, час, день месяца, месяц, день недели. Поддерживаются *, списки
+// через запятую, диапазоны a-b, шаг */n и a-b/n, а также @hourly, @daily,
+// @weekly, @monthly и @yearly
+type CronSchedule struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+}
+
+// ParseCron разбирает выражение cron
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if descriptor, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidCronExpression, len(parts))
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		value, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = value
+	}
+
+	// Воскресенье можно указать как 0 или 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	schedule := &CronSchedule{
+		expr:          expr,
+		minute:        bits[0],
+		hour:          bits[1],
+		dom:           bits[2],
+		month:         bits[3],
+		dow:           bits[4],
+		domRestricted: !strings.HasPrefix(parts[2], "*"),
+		dowRestricted: !strings.HasPrefix(parts[4], "*"),
+	}
+	// Расписание вроде 30 февраля никогда не сработает
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%w: schedule never fires", ErrInvalidCronExpression)
+	}
+	return schedule, nil
+}
+
+// parseCronField разбирает одно поле расписания в битовую маску допустимых значений
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		rangePart, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%w: bad step in %s field %q", ErrInvalidCronExpression, field.name, item)
+			}
+			rangePart, step = item[:i], n
+		}
+
+		from, to := field.min, field.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			from, err1 = strconv.Atoi(bounds[0])
+			to, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("%w: bad range in %s field %q", ErrInvalidCronExpression, field.name, item)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("%w: bad value in %s field %q", ErrInvalidCronExpression, field.name, item)
+			}
+			from, to = n, n
+			// Значение с шагом означает диапазон до конца поля: 5/15 = 5-59/15
+			if step > 1 {
+				to = field.max
+			}
+		}
+
+		if from < field.min || to > field.max || from > to {
+			return 0, fmt.Errorf("%w: %s field %q out of range %d-%d",
+				ErrInvalidCronExpression, field.name, item, field.min, field.max)
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// String возвращает исходное выражение
+func (s *CronSchedule) String() string {
+	return s.expr
+}
+
+// Next возвращает ближайший момент запуска строго после t с точностью до минуты
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Несуществующие даты вроде 31 февраля ищем не дальше пяти лет вперед
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !cronMatch(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !cronMatch(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !cronMatch(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches проверяет день месяца и день недели. Как в cron, если ограничены
+// оба поля, достаточно совпадения любого из них
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := cronMatch(s.dom, t.Day())
+	dowMatch := cronMatch(s.dow, int(t.Weekday()))
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// cronMatch проверяет, установлен ли бит значения в маске
+func cronMatch(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// Понедельник, 19 октября 2026 года
+	base := time.Date(2026, 10, 19, 10, 7, 30, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		year := 2026
+		if month < time.October {
+			year = 2028
+		}
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", base, at(time.October, 19, 10, 8)},
+		{"step", "*/15 * * * *", base, at(time.October, 19, 10, 15)},
+		{"strictly after a match", "*/15 * * * *", at(time.October, 19, 10, 15), at(time.October, 19, 10, 30)},
+		{"value with step", "5/20 * * * *", base, at(time.October, 19, 10, 25)},
+		{"list", "0,30 * * * *", base, at(time.October, 19, 10, 30)},
+		{"range with step", "0 9-17/4 * * *", base, at(time.October, 19, 13, 0)},
+		{"weekdays", "30 8 * * 1-5", base, at(time.October, 20, 8, 30)},
+		{"sunday as 0", "0 0 * * 0", base, at(time.October, 25, 0, 0)},
+		{"sunday as 7", "0 0 * * 7", base, at(time.October, 25, 0, 0)},
+		{"day of month only", "0 12 13 * *", base, at(time.November, 13, 12, 0)},
+		{"day of month or day of week", "0 12 13 * 5", base, at(time.October, 23, 12, 0)},
+		{"day of week with any day of month", "0 12 */1 * 5", base, at(time.October, 23, 12, 0)},
+		{"last day of a long month", "0 0 31 * *", base, at(time.October, 31, 0, 0)},
+		{"leap day", "0 0 29 2 *", base, at(time.February, 29, 0, 0)},
+		{"hourly descriptor", "@hourly", base, at(time.October, 19, 11, 0)},
+		{"weekly descriptor", "@WEEKLY", base, at(time.October, 25, 0, 0)},
+		{"monthly descriptor", "@monthly", base, at(time.November, 1, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+			if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+				t.Fatalf("Next(%s) = %s, want %s", tt.from.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"empty", ""},
+		{"four fields", "* * * *"},
+		{"six fields", "0 * * * * *"},
+		{"unknown descriptor", "@fortnightly"},
+		{"minute out of range", "60 * * * *"},
+		{"hour out of range", "* 24 * * *"},
+		{"day of month zero", "* * 0 * *"},
+		{"month out of range", "* * * 13 *"},
+		{"day of week out of range", "* * * * 8"},
+		{"zero step", "*/0 * * * *"},
+		{"reversed range", "30-10 * * * *"},
+		{"not a number", "a * * * *"},
+		{"never fires on february 30", "0 0 30 2 *"},
+		{"never fires on the 31st of short months", "0 0 31 4,6,9,11 *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCron(tt.expr); !errors.Is(err, ErrInvalidCronExpression) {
+				t.Fatalf("ParseCron(%q) error = %v, want %v", tt.expr, err, ErrInvalidCronExpression)
+			}
+		})
+	}
+}
+
+func TestCronString(t *testing.T) {
+	schedule, err := ParseCron("  @daily ")
+	if err != nil {
+		t.Fatalf("ParseCron: %v", err)
+	}
+	if got := schedule.String(); got != "@daily" {
+		t.Fatalf("String() = %q, want %q", got, "@daily")
+	}
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCurrencyRatesUnavailable = errors.New("currency rates are unavailable")
+	ErrCurrencyRateNotFound     = errors.New("currency rate not found")
+	ErrInvalidCurrencyRate      = errors.New("invalid currency rate")
+)
+
+// CurrencyRateDateLayout формат даты курса
+const CurrencyRateDateLayout = "2006-01-02"
+
+// FirstCurrencyRateDate дата первых официальных курсов Банка России
+const FirstCurrencyRateDate = "1992-07-01"
+
+// ValidateCurrencyRateDate проверяет, что на дату могут быть установлены курсы:
+// не раньше первых курсов Банка России и не позже завтрашнего дня
+func ValidateCurrencyRateDate(date, now time.Time) error {
+	day := date.Format(CurrencyRateDateLayout)
+	last := now.AddDate(0, 0, 1).Format(CurrencyRateDateLayout)
+	if day < FirstCurrencyRateDate || day > last {
+		return fmt.Errorf("%w: date must be between %s and %s", ErrInvalidCurrencyRate, FirstCurrencyRateDate, last)
+	}
+	return nil
+}
+
+// currencyCodePattern буквенный код валюты ISO 4217
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// IsCurrencyCode проверяет буквенный код валюты
+func IsCurrencyCode(code string) bool {
+	return currencyCodePattern.MatchString(code)
+}
+
+// CurrencyRate официальный курс валюты к рублю, установленный Банком России на дату Date.
+// Value - рублей за Nominal единиц валюты, UnitRate - рублей за одну единицу
+type CurrencyRate struct {
+	gorm.Model
+	Date     string  `json:"date" gorm:"type:varchar(10);not null;uniqueIndex:idx_currency_rate_date_code"` // ГГГГ-ММ-ДД
+	CharCode string  `json:"char_code" gorm:"type:varchar(3);not null;uniqueIndex:idx_currency_rate_date_code"`
+	NumCode  string  `json:"num_code" gorm:"type:varchar(3)"`
+	Name     string  `json:"name" gorm:"type:varchar(100)"`
+	Nominal  int     `json:"nominal" gorm:"not null"`
+	Value    float64 `json:"value" gorm:"type:decimal(15,4);not null"`
+	UnitRate float64 `json:"unit_rate" gorm:"type:decimal(20,10);not null"`
+}
+
+// Validate проверяет дату, код валюты, номинал и курс
+func (r *CurrencyRate) Validate() error {
+	if _, err := time.Parse(CurrencyRateDateLayout, r.Date); err != nil {
+		return fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidCurrencyRate)
+	}
+	if !IsCurrencyCode(r.CharCode) {
+		return fmt.Errorf("%w: currency code must be 3 latin letters", ErrInvalidCurrencyRate)
+	}
+	if r.Nominal <= 0 {
+		return fmt.Errorf("%w: nominal must be positive", ErrInvalidCurrencyRate)
+	}
+	if r.Value <= 0 || r.UnitRate <= 0 {
+		return fmt.Errorf("%w: rate must be positive", ErrInvalidCurrencyRate)
+	}
+	return nil
+}
+
+// ToDTO преобразует модель в DTO
+func (r *CurrencyRate) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"date":      r.Date,
+		"char_code": r.CharCode,
+		"num_code":  r.NumCode,
+		"name":      r.Name,
+		"nominal":   r.Nominal,
+		"value":     r.Value,
+		"unit_rate": r.UnitRate,
+	}
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidDepositProduct    = errors.New("invalid deposit product")
+	ErrDepositProductInactive   = errors.New("deposit product is not active")
+	ErrDepositTermsOutOfProduct = errors.New("deposit terms are outside the product limits")
+)
+
+// DepositProduct срочный вклад: лимиты суммы и срока, ставка (фиксированная или ключевая
+// ставка ЦБ РФ плюс надбавка на дату открытия) и ставка при досрочном расторжении
+type DepositProduct struct {
+	gorm.Model
+	Code      string   `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Name      string   `json:"name" gorm:"type:varchar(255);not null"`
+	MinAmount float64  `json:"min_amount" gorm:"type:decimal(20,2);not null"`
+	MaxAmount float64  `json:"max_amount" gorm:"type:decimal(20,2);not null"`
+	MinTerm   int      `json:"min_term" gorm:"not null"` // в месяцах
+	MaxTerm   int      `json:"max_term" gorm:"not null"`
+	RateType  RateType `json:"rate_type" gorm:"type:varchar(20);not null"`
+	Margin    float64  `json:"margin" gorm:"type:decimal(5,2);default:0"` // надбавка к ключевой ставке, может быть отрицательной
+	FixedRate float64  `json:"fixed_rate" gorm:"type:decimal(5,2);default:0"`
+	// Ставка, по которой пересчитываются проценты при досрочном расторжении
+	EarlyWithdrawalRate float64 `json:"early_withdrawal_rate" gorm:"type:decimal(5,2);default:0"`
+	// Перенос окончания срока, выпавшего на выходной или праздник
+	BusinessDayRule BusinessDayRule `json:"business_day_rule" gorm:"type:varchar(20);not null;default:'NEXT'"`
+	IsActive        bool            `json:"is_active" gorm:"default:true"`
+}
+
+// Validate проверяет настройки продукта
+func (p *DepositProduct) Validate() error {
+	if p.Code == "" || p.Name == "" {
+		return ErrInvalidDepositProduct
+	}
+	if p.MinAmount <= 0 || p.MaxAmount < p.MinAmount {
+		return ErrInvalidDepositProduct
+	}
+	if p.MinTerm <= 0 || p.MaxTerm < p.MinTerm || p.MaxTerm > 120 {
+		return ErrInvalidDepositProduct
+	}
+	switch p.RateType {
+	case RateTypeKeyRateMargin:
+		if p.Margin < -100 || p.Margin > 100 {
+			return ErrInvalidDepositProduct
+		}
+	case RateTypeFixed:
+		if p.FixedRate <= 0 || p.FixedRate > 100 {
+			return ErrInvalidDepositProduct
+		}
+	default:
+		return ErrInvalidDepositProduct
+	}
+	if p.EarlyWithdrawalRate < 0 || p.EarlyWithdrawalRate > 100 {
+		return ErrInvalidDepositProduct
+	}
+	p.BusinessDayRule = p.BusinessDayRule.OrDefault()
+	if !p.BusinessDayRule.IsValid() {
+		return ErrInvalidDepositProduct
+	}
+	return nil
+}
+
+// UsesKeyRate проверяет, зависит ли ставка продукта от ключевой ставки
+func (p *DepositProduct) UsesKeyRate() bool {
+	return p.RateType == RateTypeKeyRateMargin
+}
+
+// InterestRate возвращает годовую ставку по вкладу при текущей ключевой ставке.
+// Ставка не бывает ниже ставки досрочного расторжения
+func (p *DepositProduct) InterestRate(keyRate float64) float64 {
+	if p.RateType == RateTypeFixed {
+		return p.FixedRate
+	}
+	rate := roundMoney(keyRate + p.Margin)
+	if rate < p.EarlyWithdrawalRate {
+		rate = p.EarlyWithdrawalRate
+	}
+	return rate
+}
+
+// ValidateTerms проверяет, что сумма и срок укладываются в лимиты продукта
+func (p *DepositProduct) ValidateTerms(amount float64, term int) error {
+	if !p.IsActive {
+		return ErrDepositProductInactive
+	}
+	if amount < p.MinAmount || amount > p.MaxAmount {
+		return fmt.Errorf("amount must be between %.2f and %.2f: %w", p.MinAmount, p.MaxAmount, ErrDepositTermsOutOfProduct)
+	}
+	if term < p.MinTerm || term > p.MaxTerm {
+		return fmt.Errorf("term must be between %d and %d months: %w", p.MinTerm, p.MaxTerm, ErrDepositTermsOutOfProduct)
+	}
+	return nil
+}
+
+// ToDTO преобразует модель в DTO
+func (p *DepositProduct) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":                    p.ID,
+		"code":                  p.Code,
+		"name":                  p.Name,
+		"min_amount":            p.MinAmount,
+		"max_amount":            p.MaxAmount,
+		"min_term":              p.MinTerm,
+		"max_term":              p.MaxTerm,
+		"rate_type":             p.RateType,
+		"margin":                p.Margin,
+		"fixed_rate":            p.FixedRate,
+		"early_withdrawal_rate": p.EarlyWithdrawalRate,
+		"business_day_rule":     p.BusinessDayRule.OrDefault(),
+		"is_active":             p.IsActive,
+	}
+}
+
+// DefaultDepositProducts вклады, создаваемые при инициализации базы данных
+func DefaultDepositProducts() []DepositProduct {
+	return []DepositProduct{
+		{Code: "SAVINGS", Name: "Сберегательный", MinAmount: 10000, MaxAmount: 10000000,
+			MinTerm: 3, MaxTerm: 36, RateType: RateTypeKeyRateMargin, Margin: -2,
+			EarlyWithdrawalRate: 0.01, BusinessDayRule: BusinessDayNext, IsActive: true},
+		{Code: "FIXED", Name: "Надежный", MinAmount: 50000, MaxAmount: 30000000,
+			MinTerm: 6, MaxTerm: 24, RateType: RateTypeFixed, FixedRate: 15,
+			EarlyWithdrawalRate: 0.01, BusinessDayRule: BusinessDayNext, IsActive: true},
+	}
+}
@@ -0,0 +1,311 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidDispute             = errors.New("invalid dispute")
+	ErrDisputeNotAllowed          = errors.New("transaction cannot be disputed")
+	ErrDisputeExists              = errors.New("dispute for this transaction already exists")
+	ErrDisputeWindowExpired       = errors.New("dispute filing period has expired")
+	ErrDisputeClosed              = errors.New("dispute is already resolved")
+	ErrDisputeNotOwned            = errors.New("dispute does not belong to the user")
+	ErrProvisionalCreditPosted    = errors.New("provisional credit is already posted")
+	ErrDisputedTransactionVoided  = errors.New("disputed transaction has already been reversed")
+	ErrInvalidDisputeResolution   = errors.New("invalid dispute resolution")
+	ErrInvalidDisputeAttachment   = errors.New("invalid dispute attachment")
+	ErrDisputeAttachmentsExceeded = errors.New("too many dispute attachments")
+)
+
+// Сроки рассмотрения спора. Срок ответа по операции за рубежом увеличен,
+// как для трансграничных переводов в 161-ФЗ
+const (
+	DisputeFilingWindow         = 120 * 24 * time.Hour // срок, в течение которого операцию можно оспорить
+	DisputeReviewSLA            = 2 * 24 * time.Hour   // срок, в течение которого оператор берет спор в работу
+	DisputeResolutionSLA        = 30 * 24 * time.Hour  // срок решения по операции в России
+	DisputeResolutionSLAAbroad  = 60 * 24 * time.Hour  // срок решения по операции за рубежом
+	MaxDisputeAttachments       = 10
+	MaxDisputeAttachmentSize    = 10 << 20 // 10 МБ
+	disputeAttachmentNameLength = 255
+)
+
+var sha256HexRegex = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// DisputeReason код причины оспаривания операции
+type DisputeReason string
+
+const (
+	DisputeReasonFraud               DisputeReason = "FRAUD"                  // операция не совершалась держателем карты
+	DisputeReasonDuplicate           DisputeReason = "DUPLICATE"              // повторное списание
+	DisputeReasonIncorrectAmount     DisputeReason = "INCORRECT_AMOUNT"       // сумма отличается от согласованной
+	DisputeReasonNotReceived         DisputeReason = "NOT_RECEIVED"           // товар или услуга не получены
+	DisputeReasonNotAsDescribed      DisputeReason = "NOT_AS_DESCRIBED"       // товар не соответствует описанию
+	DisputeReasonCreditNotProcessed  DisputeReason = "CREDIT_NOT_PROCESSED"   // возврат от продавца не поступил
+	DisputeReasonCancelledRecurring  DisputeReason = "CANCELLED_RECURRING"    // списание по отмененной подписке
+	DisputeReasonATMCashNotDispensed DisputeReason = "ATM_CASH_NOT_DISPENSED" // банкомат не выдал наличные
+)
+
+// DisputeStatus статус спора
+type DisputeStatus string
+
+const (
+	DisputeStatusOpen        DisputeStatus = "OPEN"         // подан клиентом
+	DisputeStatusUnderReview DisputeStatus = "UNDER_REVIEW" // рассматривается оператором
+	DisputeStatusWon         DisputeStatus = "WON"          // решен в пользу клиента
+	DisputeStatusLost        DisputeStatus = "LOST"         // решен не в пользу клиента
+)
+
+// DisputeEventType событие в истории спора
+type DisputeEventType string
+
+const (
+	DisputeEventOpened                   DisputeEventType = "OPENED"
+	DisputeEventReviewStarted            DisputeEventType = "REVIEW_STARTED"
+	DisputeEventProvisionalCredit        DisputeEventType = "PROVISIONAL_CREDIT"
+	DisputeEventAttachmentAdded          DisputeEventType = "ATTACHMENT_ADDED"
+	DisputeEventWon                      DisputeEventType = "WON"
+	DisputeEventLost                     DisputeEventType = "LOST"
+	DisputeEventProvisionalCreditReverse DisputeEventType = "PROVISIONAL_CREDIT_REVERSED"
+	DisputeEventReviewOverdue            DisputeEventType = "REVIEW_OVERDUE"
+	DisputeEventResolutionOverdue        DisputeEventType = "RESOLUTION_OVERDUE"
+)
+
+// Dispute спор клиента по операции по карте
+type Dispute struct {
+	gorm.Model
+	TransactionID         uint          `json:"transaction_id" gorm:"uniqueIndex;not null"`
+	CardID                uint          `json:"card_id" gorm:"index;not null"`
+	AccountID             uint          `json:"account_id" gorm:"index;not null"`
+	UserID                uint          `json:"user_id" gorm:"index;not null"`
+	Reason                DisputeReason `json:"reason" gorm:"type:varchar(30);not null"`
+	Comment               string        `json:"comment" gorm:"type:text"`
+	Amount                float64       `json:"amount" gorm:"type:decimal(20,2);not null"`
+	Status                DisputeStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	OperatorID            *uint         `json:"operator_id"`
+	ProvisionalCreditTxID *uint         `json:"provisional_credit_tx_id"`
+	Resolution            string        `json:"resolution" gorm:"type:text"`
+	ReviewDueAt           time.Time     `json:"review_due_at"`
+	ResolutionDueAt       time.Time     `json:"resolution_due_at" gorm:"index"`
+	ReviewOverdueAt       *time.Time    `json:"review_overdue_at"`
+	ResolutionOverdueAt   *time.Time    `json:"resolution_overdue_at"`
+	ResolvedAt            *time.Time    `json:"resolved_at"`
+
+	Events      []DisputeEvent      `json:"events" gorm:"foreignKey:DisputeID"`
+	Attachments []DisputeAttachment `json:"attachments" gorm:"foreignKey:DisputeID"`
+}
+
+// DisputeEvent запись в истории спора
+type DisputeEvent struct {
+	gorm.Model
+	DisputeID uint             `json:"dispute_id" gorm:"index;not null"`
+	Type      DisputeEventType `json:"type" gorm:"type:varchar(40);not null"`
+	Status    DisputeStatus    `json:"status" gorm:"type:varchar(20);not null"`
+	ActorID   uint             `json:"actor_id"` // 0 - событие создано системой
+	Comment   string           `json:"comment" gorm:"type:text"`
+}
+
+// DisputeAttachment описание файла, приложенного к спору. Сам файл хранится во внешнем хранилище
+type DisputeAttachment struct {
+	gorm.Model
+	DisputeID   uint   `json:"dispute_id" gorm:"index;not null"`
+	FileName    string `json:"file_name" gorm:"type:varchar(255);not null"`
+	ContentType string `json:"content_type" gorm:"type:varchar(100)"`
+	Size        int64  `json:"size"`
+	Checksum    string `json:"checksum" gorm:"type:varchar(64)"` // SHA-256 в шестнадцатеричном виде
+	StorageURL  string `json:"storage_url" gorm:"type:text"`
+	UploadedBy  uint   `json:"uploaded_by"`
+}
+
+// IsValidDisputeReason проверяет код причины спора
+func IsValidDisputeReason(reason DisputeReason) bool {
+	switch reason {
+	case DisputeReasonFraud, DisputeReasonDuplicate, DisputeReasonIncorrectAmount,
+		DisputeReasonNotReceived, DisputeReasonNotAsDescribed, DisputeReasonCreditNotProcessed,
+		DisputeReasonCancelledRecurring, DisputeReasonATMCashNotDispensed:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanDisputeTransaction проверяет, что операцию по карте можно оспорить
+func CanDisputeTransaction(t *Transaction, now time.Time) error {
+	if t.CardID == 0 || t.Status != TransactionStatusCompleted {
+		return ErrDisputeNotAllowed
+	}
+	if t.Type != TransactionTypePayment && t.Type != TransactionTypeWithdrawal {
+		return ErrDisputeNotAllowed
+	}
+	if now.Sub(t.CreatedAt) > DisputeFilingWindow {
+		return ErrDisputeWindowExpired
+	}
+	return nil
+}
+
+// NewDispute создает спор по операции и рассчитывает сроки рассмотрения
+func NewDispute(t *Transaction, userID uint, reason DisputeReason, comment string, amount float64, now time.Time) (*Dispute, error) {
+	if !IsValidDisputeReason(reason) {
+		return nil, ErrInvalidDispute
+	}
+	if amount == 0 {
+		amount = t.Amount
+	}
+	if amount < 0 || amount > t.Amount {
+		return nil, ErrInvalidDispute
+	}
+	if reason == DisputeReasonATMCashNotDispensed && t.Type != TransactionTypeWithdrawal {
+		return nil, ErrInvalidDispute
+	}
+
+	resolutionSLA := DisputeResolutionSLA
+	if !IsDomesticCountry(t.Country) {
+		resolutionSLA = DisputeResolutionSLAAbroad
+	}
+
+	return &Dispute{
+		TransactionID:   t.ID,
+		CardID:          t.CardID,
+		AccountID:       t.FromAccountID,
+		UserID:          userID,
+		Reason:          reason,
+		Comment:         comment,
+		Amount:          amount,
+		Status:          DisputeStatusOpen,
+		ReviewDueAt:     now.Add(DisputeReviewSLA),
+		ResolutionDueAt: now.Add(resolutionSLA),
+	}, nil
+}
+
+// IsResolved проверяет, что по спору принято решение
+func (d *Dispute) IsResolved() bool {
+	return d.Status == DisputeStatusWon || d.Status == DisputeStatusLost
+}
+
+// HasProvisionalCredit проверяет, было ли предварительное зачисление по спору
+func (d *Dispute) HasProvisionalCredit() bool {
+	return d.ProvisionalCreditTxID != nil
+}
+
+// StartReview переводит спор в работу оператора
+func (d *Dispute) StartReview(operatorID uint) error {
+	if d.IsResolved() {
+		return ErrDisputeClosed
+	}
+	d.Status = DisputeStatusUnderReview
+	d.OperatorID = &operatorID
+	return nil
+}
+
+// Resolve фиксирует решение по спору
+func (d *Dispute) Resolve(status DisputeStatus, operatorID uint, resolution string, now time.Time) error {
+	if d.IsResolved() {
+		return ErrDisputeClosed
+	}
+	if status != DisputeStatusWon && status != DisputeStatusLost {
+		return ErrInvalidDisputeResolution
+	}
+	d.Status = status
+	d.OperatorID = &operatorID
+	d.Resolution = resolution
+	d.ResolvedAt = &now
+	return nil
+}
+
+// IsReviewOverdue проверяет, что спор не взят в работу в срок
+func (d *Dispute) IsReviewOverdue(now time.Time) bool {
+	return d.Status == DisputeStatusOpen && d.ReviewOverdueAt == nil && now.After(d.ReviewDueAt)
+}
+
+// IsResolutionOverdue проверяет, что решение по спору не принято в срок
+func (d *Dispute) IsResolutionOverdue(now time.Time) bool {
+	return !d.IsResolved() && d.ResolutionOverdueAt == nil && now.After(d.ResolutionDueAt)
+}
+
+// NewEvent создает запись истории с текущим статусом спора
+func (d *Dispute) NewEvent(eventType DisputeEventType, actorID uint, comment string) *DisputeEvent {
+	return &DisputeEvent{
+		DisputeID: d.ID,
+		Type:      eventType,
+		Status:    d.Status,
+		ActorID:   actorID,
+		Comment:   comment,
+	}
+}
+
+// Validate проверяет описание вложения
+func (a *DisputeAttachment) Validate() error {
+	if a.FileName == "" || len(a.FileName) > disputeAttachmentNameLength {
+		return ErrInvalidDisputeAttachment
+	}
+	if a.Size <= 0 || a.Size > MaxDisputeAttachmentSize {
+		return ErrInvalidDisputeAttachment
+	}
+	if a.Checksum != "" && !sha256HexRegex.MatchString(a.Checksum) {
+		return ErrInvalidDisputeAttachment
+	}
+	return nil
+}
+
+// ToDTO преобразует модель в DTO
+func (d *Dispute) ToDTO() map[string]interface{} {
+	events := make([]map[string]interface{}, 0, len(d.Events))
+	for i := range d.Events {
+		events = append(events, d.Events[i].ToDTO())
+	}
+	attachments := make([]map[string]interface{}, 0, len(d.Attachments))
+	for i := range d.Attachments {
+		attachments = append(attachments, d.Attachments[i].ToDTO())
+	}
+
+	return map[string]interface{}{
+		"id":                    d.ID,
+		"transaction_id":        d.TransactionID,
+		"card_id":               d.CardID,
+		"account_id":            d.AccountID,
+		"reason":                d.Reason,
+		"comment":               d.Comment,
+		"amount":                d.Amount,
+		"status":                d.Status,
+		"operator_id":           d.OperatorID,
+		"provisional_credit":    d.HasProvisionalCredit(),
+		"resolution":            d.Resolution,
+		"review_due_at":         d.ReviewDueAt,
+		"resolution_due_at":     d.ResolutionDueAt,
+		"review_overdue_at":     d.ReviewOverdueAt,
+		"resolution_overdue_at": d.ResolutionOverdueAt,
+		"resolved_at":           d.ResolvedAt,
+		"created_at":            d.CreatedAt,
+		"events":                events,
+		"attachments":           attachments,
+	}
+}
+
+// ToDTO преобразует модель в DTO
+func (e *DisputeEvent) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       e.Type,
+		"status":     e.Status,
+		"actor_id":   e.ActorID,
+		"comment":    e.Comment,
+		"created_at": e.CreatedAt,
+	}
+}
+
+// ToDTO преобразует модель в DTO
+func (a *DisputeAttachment) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":           a.ID,
+		"file_name":    a.FileName,
+		"content_type": a.ContentType,
+		"size":         a.Size,
+		"checksum":     a.Checksum,
+		"storage_url":  a.StorageURL,
+		"uploaded_by":  a.UploadedBy,
+		"created_at":   a.CreatedAt,
+	}
+}
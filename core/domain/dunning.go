@@ -0,0 +1,425 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidDunningStage  = errors.New("invalid dunning stage")
+	ErrInvalidPromiseToPay  = errors.New("invalid promise to pay")
+	ErrCollectionCaseClosed = errors.New("collection case is closed")
+	ErrInvalidDPDBucket     = errors.New("invalid days past due bucket")
+)
+
+// MaxPromiseDays на сколько дней обещание оплаты может приостановить взыскание
+const MaxPromiseDays = 30
+
+// DunningChannel канал стадии взыскания
+type DunningChannel string
+
+const (
+	DunningChannelEmail       DunningChannel = "EMAIL"        // письмо заемщику
+	DunningChannelManagerTask DunningChannel = "MANAGER_TASK" // задача менеджеру в рабочем списке
+	DunningChannelCollections DunningChannel = "COLLECTIONS"  // передача в отдел взыскания
+)
+
+// DunningStage стадия взыскания: срабатывает, когда просрочка по кредиту
+// достигает DaysPastDue дней. Шаблон заполняется данными DunningMessage
+type DunningStage struct {
+	gorm.Model
+	Code        string         `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Name        string         `json:"name" gorm:"type:varchar(255);not null"`
+	DaysPastDue int            `json:"days_past_due" gorm:"not null"`
+	Channel     DunningChannel `json:"channel" gorm:"type:varchar(20);not null"`
+	Subject     string         `json:"subject" gorm:"type:varchar(255)"`
+	Template    string         `json:"template" gorm:"type:text"`
+	IsActive    bool           `json:"is_active" gorm:"default:true"`
+}
+
+// DunningMessage данные для шаблона стадии взыскания
+type DunningMessage struct {
+	Fio           string
+	CreditID      uint
+	DaysPastDue   int
+	OverdueAmount float64
+	PenaltyAmount float64
+	RemainingDebt float64
+	Date          string
+}
+
+// Validate проверяет настройки стадии
+func (s *DunningStage) Validate() error {
+	if s.Code == "" || s.Name == "" || s.DaysPastDue < 1 {
+		return ErrInvalidDunningStage
+	}
+	switch s.Channel {
+	case DunningChannelEmail:
+		if s.Subject == "" || s.Template == "" {
+			return ErrInvalidDunningStage
+		}
+	case DunningChannelManagerTask, DunningChannelCollections:
+	default:
+		return ErrInvalidDunningStage
+	}
+	if _, err := template.New(s.Code).Parse(s.Template); err != nil {
+		return ErrInvalidDunningStage
+	}
+	return nil
+}
+
+// Render заполняет шаблон стадии данными о задолженности
+func (s *DunningStage) Render(message DunningMessage) (string, error) {
+	tmpl, err := template.New(s.Code).Parse(s.Template)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, message); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// ToDTO преобразует модель в DTO
+func (s *DunningStage) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":            s.ID,
+		"code":          s.Code,
+		"name":          s.Name,
+		"days_past_due": s.DaysPastDue,
+		"channel":       s.Channel,
+		"subject":       s.Subject,
+		"template":      s.Template,
+		"is_active":     s.IsActive,
+	}
+}
+
+// DefaultDunningStages стадии взыскания, создаваемые при инициализации базы данных
+func DefaultDunningStages() []DunningStage {
+	return []DunningStage{
+		{
+			Code: "REMINDER", Name: "Напоминание о просрочке", DaysPastDue: 1, Channel: DunningChannelEmail,
+			Subject:  "Напоминание о платеже по кредиту",
+			Template: "<p>{{.Fio}}, платеж по кредиту № {{.CreditID}} не поступил в срок.</p><p>Просроченная задолженность: {{printf \"%.2f\" .OverdueAmount}} ₽. Пожалуйста, пополните счет.</p>",
+			IsActive: true,
+		},
+		{
+			Code: "WARNING", Name: "Предупреждение о последствиях", DaysPastDue: 7, Channel: DunningChannelEmail,
+			Subject:  "Просрочка по кредиту: начисляется неустойка",
+			Template: "<p>{{.Fio}}, просрочка по кредиту № {{.CreditID}} составляет {{.DaysPastDue}} дн.</p><p>Задолженность с неустойкой: {{printf \"%.2f\" .OverdueAmount}} ₽. Если вам сложно платить, обратитесь в банк за реструктуризацией.</p>",
+			IsActive: true,
+		},
+		{
+			Code: "MANAGER", Name: "Звонок менеджера", DaysPastDue: 30, Channel: DunningChannelManagerTask,
+			Template: "Связаться с заемщиком {{.Fio}} по кредиту № {{.CreditID}}: просрочка {{.DaysPastDue}} дн., {{printf \"%.2f\" .OverdueAmount}} ₽",
+			IsActive: true,
+		},
+		{
+			Code: "COLLECTIONS", Name: "Передача в отдел взыскания", DaysPastDue: 90, Channel: DunningChannelCollections,
+			Template: "Кредит № {{.CreditID}} передан во взыскание: просрочка {{.DaysPastDue}} дн., {{printf \"%.2f\" .OverdueAmount}} ₽",
+			IsActive: true,
+		},
+	}
+}
+
+// CollectionStatus статус дела о просроченной задолженности
+type CollectionStatus string
+
+const (
+	CollectionStatusOpen        CollectionStatus = "OPEN"        // работа по стадиям взыскания
+	CollectionStatusCollections CollectionStatus = "COLLECTIONS" // передано в отдел взыскания
+	CollectionStatusClosed      CollectionStatus = "CLOSED"      // просрочка погашена
+)
+
+// CollectionCase дело о просроченной задолженности по кредиту. Открывается при
+// выходе кредита в просрочку и закрывается, когда просрочка погашена
+type CollectionCase struct {
+	gorm.Model
+	CreditID       uint             `json:"credit_id" gorm:"not null;index"`
+	UserID         uint             `json:"user_id" gorm:"not null;index"`
+	Status         CollectionStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	DaysPastDue    int              `json:"days_past_due" gorm:"index"`
+	OverdueAmount  float64          `json:"overdue_amount" gorm:"type:decimal(20,2);default:0"`
+	StageCode      string           `json:"stage_code" gorm:"type:varchar(50)"` // последняя пройденная стадия
+	StageDays      int              `json:"stage_days"`                         // порог просрочки последней стадии
+	PromisedAmount float64          `json:"promised_amount" gorm:"type:decimal(20,2);default:0"`
+	PromisedUntil  *time.Time       `json:"promised_until"` // до этой даты стадии приостановлены
+	BrokenPromises int              `json:"broken_promises" gorm:"default:0"`
+	ClosedAt       *time.Time       `json:"closed_at"`
+
+	Actions []DunningAction `json:"actions" gorm:"foreignKey:CaseID"`
+}
+
+// NewCollectionCase открывает дело по просроченному кредиту
+func NewCollectionCase(credit *Credit) *CollectionCase {
+	return &CollectionCase{
+		CreditID: credit.ID,
+		UserID:   credit.UserID,
+		Status:   CollectionStatusOpen,
+	}
+}
+
+// IsClosed проверяет, закрыто ли дело
+func (c *CollectionCase) IsClosed() bool {
+	return c.Status == CollectionStatusClosed
+}
+
+// Suspended проверяет, приостановлены ли стадии обещанием оплаты
+func (c *CollectionCase) Suspended(now time.Time) bool {
+	return c.PromisedUntil != nil && now.Before(*c.PromisedUntil)
+}
+
+// Refresh обновляет просрочку по кредиту. Если срок обещания оплаты истек,
+// а просрочка не погашена, обещание считается нарушенным и снимается.
+// Возвращает действие о нарушенном обещании или nil
+func (c *CollectionCase) Refresh(credit *Credit, daysPastDue int, now time.Time) *DunningAction {
+	c.DaysPastDue = daysPastDue
+	c.OverdueAmount = credit.OverdueAmount
+	if c.PromisedUntil == nil || c.Suspended(now) {
+		return nil
+	}
+
+	action := &DunningAction{
+		CaseID:      c.ID,
+		CreditID:    c.CreditID,
+		Kind:        DunningActionPromiseBroken,
+		DaysPastDue: daysPastDue,
+		Amount:      c.PromisedAmount,
+		Status:      DunningActionCompleted,
+	}
+	c.BrokenPromises++
+	c.PromisedAmount = 0
+	c.PromisedUntil = nil
+	return action
+}
+
+// NextStage возвращает стадию, которую нужно выполнить: самую позднюю из достигнутых
+// и еще не пройденных. Если просрочка перескочила несколько стадий, промежуточные
+// пропускаются, чтобы не отправлять заемщику несколько писем сразу
+func (c *CollectionCase) NextStage(stages []DunningStage) *DunningStage {
+	var next *DunningStage
+	for i := range stages {
+		stage := &stages[i]
+		if !stage.IsActive || stage.DaysPastDue > c.DaysPastDue || stage.DaysPastDue <= c.StageDays {
+			continue
+		}
+		if next == nil || stage.DaysPastDue > next.DaysPastDue {
+			next = stage
+		}
+	}
+	return next
+}
+
+// Advance фиксирует прохождение стадии
+func (c *CollectionCase) Advance(stage *DunningStage) {
+	c.StageCode = stage.Code
+	c.StageDays = stage.DaysPastDue
+	if stage.Channel == DunningChannelCollections {
+		c.Status = CollectionStatusCollections
+	}
+}
+
+// PromiseToPay фиксирует обещание заемщика погасить amount до даты until.
+// До этой даты стадии взыскания не выполняются
+func (c *CollectionCase) PromiseToPay(amount float64, until time.Time, operatorID uint, now time.Time) (*DunningAction, error) {
+	if c.IsClosed() {
+		return nil, ErrCollectionCaseClosed
+	}
+	if amount <= 0 || !until.After(now) || until.After(now.AddDate(0, 0, MaxPromiseDays)) {
+		return nil, ErrInvalidPromiseToPay
+	}
+
+	c.PromisedAmount = roundMoney(amount)
+	c.PromisedUntil = &until
+	return &DunningAction{
+		CaseID:      c.ID,
+		CreditID:    c.CreditID,
+		Kind:        DunningActionPromise,
+		DaysPastDue: c.DaysPastDue,
+		Amount:      c.PromisedAmount,
+		ActorID:     operatorID,
+		Content:     "Обещание оплаты до " + until.Format("02.01.2006"),
+		Status:      DunningActionCompleted,
+	}, nil
+}
+
+// Close закрывает дело после погашения просрочки
+func (c *CollectionCase) Close(now time.Time) {
+	c.Status = CollectionStatusClosed
+	c.DaysPastDue = 0
+	c.OverdueAmount = 0
+	c.PromisedUntil = nil
+	c.ClosedAt = &now
+}
+
+// ToDTO преобразует модель в DTO
+func (c *CollectionCase) ToDTO() map[string]interface{} {
+	actions := make([]map[string]interface{}, 0, len(c.Actions))
+	for i := range c.Actions {
+		actions = append(actions, c.Actions[i].ToDTO())
+	}
+
+	return map[string]interface{}{
+		"id":              c.ID,
+		"credit_id":       c.CreditID,
+		"user_id":         c.UserID,
+		"status":          c.Status,
+		"days_past_due":   c.DaysPastDue,
+		"bucket":          DPDBucket(c.DaysPastDue),
+		"overdue_amount":  c.OverdueAmount,
+		"stage_code":      c.StageCode,
+		"promised_amount": c.PromisedAmount,
+		"promised_until":  c.PromisedUntil,
+		"broken_promises": c.BrokenPromises,
+		"closed_at":       c.ClosedAt,
+		"created_at":      c.CreatedAt,
+		"actions":         actions,
+	}
+}
+
+// DunningActionKind вид записи в истории взыскания
+type DunningActionKind string
+
+const (
+	DunningActionStage         DunningActionKind = "STAGE"          // выполнена стадия взыскания
+	DunningActionPromise       DunningActionKind = "PROMISE"        // заемщик обещал оплатить
+	DunningActionPromiseBroken DunningActionKind = "PROMISE_BROKEN" // обещание не выполнено в срок
+)
+
+// DunningActionStatus результат действия
+type DunningActionStatus string
+
+const (
+	DunningActionCompleted DunningActionStatus = "COMPLETED"
+	DunningActionFailed    DunningActionStatus = "FAILED" // например, письмо не отправлено
+)
+
+// DunningAction запись в истории работы с просроченной задолженностью
+type DunningAction struct {
+	ID          uint                `json:"id" gorm:"primaryKey"`
+	CaseID      uint                `json:"case_id" gorm:"not null;index"`
+	CreditID    uint                `json:"credit_id" gorm:"not null;index"`
+	Kind        DunningActionKind   `json:"kind" gorm:"type:varchar(20);not null"`
+	StageCode   string              `json:"stage_code" gorm:"type:varchar(50)"`
+	Channel     DunningChannel      `json:"channel" gorm:"type:varchar(20)"`
+	DaysPastDue int                 `json:"days_past_due"`
+	Amount      float64             `json:"amount" gorm:"type:decimal(20,2)"`
+	Subject     string              `json:"subject" gorm:"type:varchar(255)"`
+	Content     string              `json:"content" gorm:"type:text"`
+	ActorID     uint                `json:"actor_id"` // 0 - действие выполнено системой
+	Status      DunningActionStatus `json:"status" gorm:"type:varchar(20);not null"`
+	Error       string              `json:"error" gorm:"type:text"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+// NewStageAction формирует запись о выполнении стадии с заполненным шаблоном
+func NewStageAction(collectionCase *CollectionCase, stage *DunningStage, message DunningMessage) *DunningAction {
+	action := &DunningAction{
+		CaseID:      collectionCase.ID,
+		CreditID:    collectionCase.CreditID,
+		Kind:        DunningActionStage,
+		StageCode:   stage.Code,
+		Channel:     stage.Channel,
+		DaysPastDue: collectionCase.DaysPastDue,
+		Amount:      collectionCase.OverdueAmount,
+		Subject:     stage.Subject,
+		Status:      DunningActionCompleted,
+	}
+	content, err := stage.Render(message)
+	if err != nil {
+		action.Fail(err)
+		return action
+	}
+	action.Content = content
+	return action
+}
+
+// Fail отмечает действие как невыполненное
+func (a *DunningAction) Fail(err error) {
+	a.Status = DunningActionFailed
+	a.Error = err.Error()
+}
+
+// ToDTO преобразует модель в DTO
+func (a *DunningAction) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":            a.ID,
+		"kind":          a.Kind,
+		"stage_code":    a.StageCode,
+		"channel":       a.Channel,
+		"days_past_due": a.DaysPastDue,
+		"amount":        a.Amount,
+		"subject":       a.Subject,
+		"content":       a.Content,
+		"actor_id":      a.ActorID,
+		"status":        a.Status,
+		"error":         a.Error,
+		"created_at":    a.CreatedAt,
+	}
+}
+
+// DaysPastDue возвращает количество дней просрочки по самому раннему неоплаченному платежу
+func DaysPastDue(schedule []PaymentSchedule, now time.Time) int {
+	for _, row := range schedule {
+		if row.IsDue(now) {
+			return overdueDays(row.DueDate, now)
+		}
+	}
+	return 0
+}
+
+// Корзины просрочки для рабочего списка взыскания
+const (
+	DPDBucket1To30  = "1-30"
+	DPDBucket31To60 = "31-60"
+	DPDBucket61To90 = "61-90"
+	DPDBucket90Plus = "90+"
+)
+
+// DPDBucket возвращает корзину просрочки для количества дней
+func DPDBucket(daysPastDue int) string {
+	switch {
+	case daysPastDue <= 0:
+		return ""
+	case daysPastDue <= 30:
+		return DPDBucket1To30
+	case daysPastDue <= 60:
+		return DPDBucket31To60
+	case daysPastDue <= 90:
+		return DPDBucket61To90
+	default:
+		return DPDBucket90Plus
+	}
+}
+
+// CollectionFilter фильтр рабочего списка взыскания
+type CollectionFilter struct {
+	Statuses  []CollectionStatus
+	MinDPD    int
+	MaxDPD    int // 0 - без ограничения
+	MinAmount float64
+	MaxAmount float64 // 0 - без ограничения
+}
+
+// SetBucket ограничивает фильтр корзиной просрочки
+func (f *CollectionFilter) SetBucket(bucket string) error {
+	switch bucket {
+	case "":
+	case DPDBucket1To30:
+		f.MinDPD, f.MaxDPD = 1, 30
+	case DPDBucket31To60:
+		f.MinDPD, f.MaxDPD = 31, 60
+	case DPDBucket61To90:
+		f.MinDPD, f.MaxDPD = 61, 90
+	case DPDBucket90Plus:
+		f.MinDPD, f.MaxDPD = 91, 0
+	default:
+		return ErrInvalidDPDBucket
+	}
+	return nil
+}
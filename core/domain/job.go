@@ -0,0 +1,207 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrJobRunning       = errors.New("job is already running")
+	ErrJobAlreadyPaused = errors.New("job is already paused")
+	ErrJobNotPaused     = errors.New("job is not paused")
+)
+
+// JobStatus состояние фоновой задачи
+type JobStatus string
+
+const (
+	JobStatusActive JobStatus = "ACTIVE" // запускается по расписанию
+	JobStatusPaused JobStatus = "PAUSED" // расписание приостановлено, ручной запуск доступен
+)
+
+// Job фоновая задача с расписанием cron. Запись хранит состояние задачи между
+// перезапусками приложения и блокировку (lease), которая не дает двум экземплярам
+// приложения выполнять задачу одновременно
+type Job struct {
+	gorm.Model
+	Name           string       `json:"name" gorm:"type:varchar(100);uniqueIndex;not null"`
+	Description    string       `json:"description" gorm:"type:varchar(255)"`
+	Schedule       string       `json:"schedule" gorm:"type:varchar(100);not null"`
+	Status         JobStatus    `json:"status" gorm:"type:varchar(20);not null;default:'ACTIVE'"`
+	NextRunAt      *time.Time   `json:"next_run_at" gorm:"index"`
+	LastRunAt      *time.Time   `json:"last_run_at"`
+	LastStatus     JobRunStatus `json:"last_status" gorm:"type:varchar(20)"`
+	LastError      string       `json:"last_error" gorm:"type:text"`
+	LastDurationMs int64        `json:"last_duration_ms"`
+	LeaseOwner     string       `json:"lease_owner" gorm:"type:varchar(255)"` // экземпляр, выполняющий задачу
+	LeaseToken     string       `json:"-" gorm:"type:varchar(64)"`
+	LeaseUntil     *time.Time   `json:"lease_until"`
+}
+
+// IsPaused проверяет, приостановлена ли задача
+func (j *Job) IsPaused() bool {
+	return j.Status == JobStatusPaused
+}
+
+// IsRunning проверяет, удерживает ли задачу какой-либо экземпляр приложения
+func (j *Job) IsRunning(now time.Time) bool {
+	return j.LeaseUntil != nil && now.Before(*j.LeaseUntil)
+}
+
+// IsDue проверяет, наступило ли время запуска по расписанию
+func (j *Job) IsDue(now time.Time) bool {
+	return !j.IsPaused() && j.NextRunAt != nil && !j.NextRunAt.After(now)
+}
+
+// Reschedule назначает следующий запуск по расписанию после момента now
+func (j *Job) Reschedule(schedule *CronSchedule, now time.Time) {
+	next := schedule.Next(now)
+	j.NextRunAt = &next
+}
+
+// Pause приостанавливает запуски по расписанию
+func (j *Job) Pause() error {
+	if j.IsPaused() {
+		return ErrJobAlreadyPaused
+	}
+	j.Status = JobStatusPaused
+	j.NextRunAt = nil
+	return nil
+}
+
+// Resume возобновляет запуски по расписанию. Пропущенные за время паузы
+// запуски не догоняются: следующий назначается от текущего момента
+func (j *Job) Resume(schedule *CronSchedule, now time.Time) error {
+	if !j.IsPaused() {
+		return ErrJobNotPaused
+	}
+	j.Status = JobStatusActive
+	j.Reschedule(schedule, now)
+	return nil
+}
+
+// Record переносит в задачу итог завершенного запуска
+func (j *Job) Record(run *JobRun) {
+	j.LastRunAt = &run.StartedAt
+	j.LastStatus = run.Status
+	j.LastError = run.Error
+	j.LastDurationMs = run.DurationMs
+}
+
+// ToDTO преобразует модель в DTO. Блокировка задачи выдается по реальному времени,
+// а не по смоделированным часам, поэтому и признак выполнения проверяется по нему
+func (j *Job) ToDTO() map[string]interface{} {
+	now := time.Now()
+	dto := map[string]interface{}{
+		"name":             j.Name,
+		"description":      j.Description,
+		"schedule":         j.Schedule,
+		"status":           j.Status,
+		"next_run_at":      j.NextRunAt,
+		"last_run_at":      j.LastRunAt,
+		"last_status":      j.LastStatus,
+		"last_error":       j.LastError,
+		"last_duration_ms": j.LastDurationMs,
+		"running":          j.IsRunning(now),
+	}
+	if j.IsRunning(now) {
+		dto["lease_owner"] = j.LeaseOwner
+		dto["lease_until"] = j.LeaseUntil
+	}
+	return dto
+}
+
+// JobTrigger причина запуска задачи
+type JobTrigger string
+
+const (
+	JobTriggerSchedule JobTrigger = "SCHEDULE" // запуск в срок по расписанию
+	JobTriggerCatchUp  JobTrigger = "CATCH_UP" // догоняющий запуск после простоя
+	JobTriggerManual   JobTrigger = "MANUAL"   // запуск администратором
+)
+
+// JobRunStatus результат запуска задачи
+type JobRunStatus string
+
+const (
+	JobRunRunning   JobRunStatus = "RUNNING"
+	JobRunSucceeded JobRunStatus = "SUCCEEDED"
+	JobRunFailed    JobRunStatus = "FAILED"
+)
+
+// JobRun запись в истории запусков задачи
+type JobRun struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	JobID       uint         `json:"job_id" gorm:"not null;index"`
+	JobName     string       `json:"job_name" gorm:"type:varchar(100);not null;index"`
+	Trigger     JobTrigger   `json:"trigger" gorm:"type:varchar(20);not null"`
+	ScheduledAt *time.Time   `json:"scheduled_at"` // срок запуска по расписанию
+	MissedRuns  int          `json:"missed_runs"`  // сколько запусков по расписанию объединено в догоняющий
+	ActorID     uint         `json:"actor_id"`     // 0 - запуск по расписанию
+	Instance    string       `json:"instance" gorm:"type:varchar(255)"`
+	Status      JobRunStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	StartedAt   time.Time    `json:"started_at"`
+	FinishedAt  *time.Time   `json:"finished_at"`
+	DurationMs  int64        `json:"duration_ms"`
+	Error       string       `json:"error" gorm:"type:text"`
+}
+
+// NewJobRun начинает запуск задачи
+func NewJobRun(job *Job, trigger JobTrigger, actorID uint, instance string, now time.Time) *JobRun {
+	run := &JobRun{
+		JobID:     job.ID,
+		JobName:   job.Name,
+		Trigger:   trigger,
+		ActorID:   actorID,
+		Instance:  instance,
+		Status:    JobRunRunning,
+		StartedAt: now,
+	}
+	if trigger != JobTriggerManual && job.NextRunAt != nil {
+		scheduledAt := *job.NextRunAt
+		run.ScheduledAt = &scheduledAt
+	}
+	return run
+}
+
+// Finish фиксирует завершение запуска с ошибкой или без
+func (r *JobRun) Finish(err error, now time.Time) {
+	r.FinishedAt = &now
+	r.DurationMs = now.Sub(r.StartedAt).Milliseconds()
+	if err != nil {
+		r.Status = JobRunFailed
+		r.Error = err.Error()
+		return
+	}
+	r.Status = JobRunSucceeded
+}
+
+// ToDTO преобразует модель в DTO
+func (r *JobRun) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":           r.ID,
+		"job_name":     r.JobName,
+		"trigger":      r.Trigger,
+		"scheduled_at": r.ScheduledAt,
+		"missed_runs":  r.MissedRuns,
+		"actor_id":     r.ActorID,
+		"instance":     r.Instance,
+		"status":       r.Status,
+		"started_at":   r.StartedAt,
+		"finished_at":  r.FinishedAt,
+		"duration_ms":  r.DurationMs,
+		"error":        r.Error,
+	}
+}
+
+// MissedRuns считает запуски по расписанию, пропущенные после срока from до момента now
+func MissedRuns(schedule *CronSchedule, from, now time.Time) int {
+	const maxMissed = 1000
+	missed := 0
+	for next := schedule.Next(from); !next.IsZero() && !next.After(now) && missed < maxMissed; next = schedule.Next(next) {
+		missed++
+	}
+	return missed
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrKeyRateUnavailable = errors.New("key rate is unavailable")
+	ErrInvalidKeyRate     = errors.New("invalid key rate")
+	ErrInvalidDateRange   = errors.New("invalid date range")
+)
+
+// KeyRateDateLayout формат даты в истории ключевой ставки
+const KeyRateDateLayout = "2006-01-02"
+
+// KeyRateSource источник значения ключевой ставки
+type KeyRateSource string
+
+const (
+	KeyRateSourceCBR  KeyRateSource = "CBR"  // веб-сервис Банка России
+	KeyRateSourceStub KeyRateSource = "STUB" // локальный файл для тестовых и изолированных окружений
+)
+
+// keyRateFiles история ключевой ставки для заглушки, поставляемая с приложением
+//
+//go:embed keyrate/stub.json
+var keyRateFiles embed.FS
+
+// KeyRate значение ключевой ставки, действующее с даты Date
+type KeyRate struct {
+	gorm.Model
+	Date   string        `json:"date" gorm:"type:varchar(10);uniqueIndex;not null"` // ГГГГ-ММ-ДД
+	Rate   float64       `json:"rate" gorm:"type:decimal(5,2);not null"`
+	Source KeyRateSource `json:"source" gorm:"type:varchar(20);not null"`
+}
+
+// Validate проверяет дату и значение ставки
+func (r *KeyRate) Validate() error {
+	if _, err := time.Parse(KeyRateDateLayout, r.Date); err != nil {
+		return fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidKeyRate)
+	}
+	if r.Rate <= 0 || r.Rate > 100 {
+		return fmt.Errorf("%w: rate must be between 0 and 100", ErrInvalidKeyRate)
+	}
+	return nil
+}
+
+// ToDTO преобразует модель в DTO
+func (r *KeyRate) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"date":   r.Date,
+		"rate":   r.Rate,
+		"source": r.Source,
+	}
+}
+
+// KeyRateQuote текущая ключевая ставка из кэша вместе с моментом последнего
+// успешного обновления. Stale - источник недоступен и отдано последнее известное значение
+type KeyRateQuote struct {
+	Rate      float64
+	Date      string
+	Source    KeyRateSource
+	FetchedAt time.Time
+	Stale     bool
+}
+
+// ToDTO преобразует котировку в DTO
+func (q KeyRateQuote) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"rate":       q.Rate,
+		"date":       q.Date,
+		"source":     q.Source,
+		"fetched_at": q.FetchedAt,
+		"stale":      q.Stale,
+	}
+}
+
+// keyRateFile формат файла истории ключевой ставки
+type keyRateFile struct {
+	Rates []struct {
+		Date string  `json:"date"`
+		Rate float64 `json:"rate"`
+	} `json:"rates"`
+}
+
+// ParseKeyRates разбирает историю ключевой ставки из JSON
+func ParseKeyRates(data []byte, source KeyRateSource) ([]KeyRate, error) {
+	var file keyRateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeyRate, err)
+	}
+	rates := make([]KeyRate, 0, len(file.Rates))
+	for _, item := range file.Rates {
+		rate := KeyRate{Date: item.Date, Rate: item.Rate, Source: source}
+		if err := rate.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", item.Date, err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// DefaultStubKeyRates возвращает историю ключевой ставки, поставляемую с приложением для заглушки
+func DefaultStubKeyRates() ([]KeyRate, error) {
+	data, err := keyRateFiles.ReadFile("keyrate/stub.json")
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyRates(data, KeyRateSourceStub)
+}
@@ -0,0 +1,236 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTermDeposit    = errors.New("invalid term deposit")
+	ErrTermDepositNotOwned   = errors.New("term deposit does not belong to the user")
+	ErrTermDepositClosed     = errors.New("term deposit is already closed")
+	ErrTermDepositSourceType = errors.New("term deposit can only be funded from a debit account")
+)
+
+// InterestPayout способ получения процентов по вкладу
+type InterestPayout string
+
+const (
+	InterestPayoutCapitalize InterestPayout = "CAPITALIZE" // ежемесячная капитализация
+	InterestPayoutAccount    InterestPayout = "PAYOUT"     // ежемесячная выплата на счет
+)
+
+// TermDepositStatus статус вклада
+type TermDepositStatus string
+
+const (
+	TermDepositStatusActive      TermDepositStatus = "ACTIVE"
+	TermDepositStatusClosed      TermDepositStatus = "CLOSED"       // возвращен в дату окончания срока
+	TermDepositStatusClosedEarly TermDepositStatus = "CLOSED_EARLY" // расторгнут досрочно
+)
+
+// TermDeposit срочный вклад. Проценты начисляются ежедневно на текущую сумму вклада
+// и ежемесячно капитализируются или выплачиваются на счет. В дату окончания срока вклад
+// пролонгируется по действующей ставке продукта или возвращается на счет
+type TermDeposit struct {
+	gorm.Model
+	UserID              uint              `json:"user_id" gorm:"not null;index"`
+	AccountID           uint              `json:"account_id" gorm:"not null;index"` // счет списания, выплаты процентов и возврата
+	ProductID           uint              `json:"product_id" gorm:"not null;index"`
+	Amount              float64           `json:"amount" gorm:"type:decimal(20,2);not null"`    // сумма на начало срока
+	Principal           float64           `json:"principal" gorm:"type:decimal(20,2);not null"` // сумма вклада с капитализированными процентами
+	Term                int               `json:"term" gorm:"not null"`                         // в месяцах
+	InterestRate        float64           `json:"interest_rate" gorm:"type:decimal(5,2);not null"`
+	EarlyWithdrawalRate float64           `json:"early_withdrawal_rate" gorm:"type:decimal(5,2);default:0"`
+	InterestPayout      InterestPayout    `json:"interest_payout" gorm:"type:varchar(20);not null"`
+	AutoRollover        bool              `json:"auto_rollover" gorm:"default:false"`
+	StartDate           time.Time         `json:"start_date"`
+	EndDate             time.Time         `json:"end_date" gorm:"index"` // дата возврата после переноса на рабочий день
+	ContractualEndDate  time.Time         `json:"contractual_end_date"`  // дата окончания срока по договору
+	BusinessDayRule     BusinessDayRule   `json:"business_day_rule" gorm:"type:varchar(20);not null;default:'NEXT'"`
+	NextInterestDate    time.Time         `json:"next_interest_date"`
+	AccruedInterest     float64           `json:"accrued_interest" gorm:"type:decimal(20,2);default:0"` // начислено с последней капитализации/выплаты
+	AccruedThrough      time.Time         `json:"accrued_through"`
+	TermInterest        float64           `json:"term_interest" gorm:"type:decimal(20,2);default:0"`  // капитализировано/выплачено за текущий срок
+	TotalInterest       float64           `json:"total_interest" gorm:"type:decimal(20,2);default:0"` // за все время
+	Rollovers           int               `json:"rollovers" gorm:"default:0"`
+	Status              TermDepositStatus `json:"status" gorm:"type:varchar(20);not null;default:'ACTIVE';index"`
+	ClosedAt            *time.Time        `json:"closed_at"`
+}
+
+// NewTermDeposit открывает вклад по продукту. keyRate используется, если ставка продукта
+// привязана к ключевой: ставка фиксируется на весь срок. Окончание срока, выпавшее
+// на нерабочий день, переносится по календарю calendar и правилу продукта
+func NewTermDeposit(product *DepositProduct, userID, accountID uint, amount float64, term int,
+	payout InterestPayout, rollover bool, keyRate float64, calendar *BusinessCalendar, now time.Time) (*TermDeposit, error) {
+	if err := product.ValidateTerms(amount, term); err != nil {
+		return nil, err
+	}
+	if payout == "" {
+		payout = InterestPayoutCapitalize
+	}
+	if payout != InterestPayoutCapitalize && payout != InterestPayoutAccount {
+		return nil, ErrInvalidTermDeposit
+	}
+
+	deposit := &TermDeposit{
+		UserID:              userID,
+		AccountID:           accountID,
+		ProductID:           product.ID,
+		Principal:           amount,
+		EarlyWithdrawalRate: product.EarlyWithdrawalRate,
+		InterestPayout:      payout,
+		AutoRollover:        rollover,
+		BusinessDayRule:     product.BusinessDayRule.OrDefault(),
+		Status:              TermDepositStatusActive,
+	}
+	deposit.startTerm(amount, term, product.InterestRate(keyRate), now, calendar)
+	return deposit, nil
+}
+
+// startTerm начинает новый срок вклада с даты start. Если срок заканчивается в нерабочий
+// день, вклад возвращается в ближайший рабочий день, а проценты начисляются по дату возврата
+func (d *TermDeposit) startTerm(amount float64, term int, rate float64, start time.Time, calendar *BusinessCalendar) {
+	d.Amount = roundMoney(amount)
+	d.Term = term
+	d.InterestRate = rate
+	d.StartDate = start
+	d.ContractualEndDate = addMonths(startOfDay(start), term, start.Day())
+	d.EndDate = calendar.Adjust(d.ContractualEndDate, d.BusinessDayRule)
+	d.NextInterestDate = d.interestDate(1)
+	d.AccruedThrough = startOfDay(start)
+	d.TermInterest = 0
+}
+
+// interestDate возвращает дату n-й ежемесячной капитализации/выплаты в текущем сроке
+func (d *TermDeposit) interestDate(n int) time.Time {
+	date := addMonths(startOfDay(d.StartDate), n, d.StartDate.Day())
+	if date.After(d.EndDate) {
+		return d.EndDate
+	}
+	return date
+}
+
+// IsActive проверяет, действует ли вклад
+func (d *TermDeposit) IsActive() bool {
+	return d.Status == TermDepositStatusActive
+}
+
+// Accrue начисляет проценты за каждый день после AccruedThrough по день date включительно
+func (d *TermDeposit) Accrue(date time.Time) {
+	end := startOfDay(date)
+	for day := d.AccruedThrough.AddDate(0, 0, 1); !day.After(end); day = day.AddDate(0, 0, 1) {
+		d.AccruedInterest = roundMoney(d.AccruedInterest + d.Principal*d.InterestRate/100/float64(daysInYear(day.Year())))
+	}
+	if end.After(d.AccruedThrough) {
+		d.AccruedThrough = end
+	}
+}
+
+// InterestDue проверяет, наступила ли дата капитализации или выплаты процентов
+func (d *TermDeposit) InterestDue(now time.Time) bool {
+	return d.IsActive() && !now.Before(d.NextInterestDate) && d.AccruedThrough.Before(d.EndDate)
+}
+
+// TakeInterest начисляет проценты по дату капитализации/выплаты и возвращает сумму,
+// которую нужно выплатить на счет. При капитализации проценты прибавляются к вкладу
+func (d *TermDeposit) TakeInterest() (payout float64) {
+	d.Accrue(d.NextInterestDate)
+	interest := d.AccruedInterest
+	d.AccruedInterest = 0
+	d.TermInterest = roundMoney(d.TermInterest + interest)
+	d.TotalInterest = roundMoney(d.TotalInterest + interest)
+
+	if d.NextInterestDate.Before(d.EndDate) {
+		n := (d.NextInterestDate.Year()-d.StartDate.Year())*12 + int(d.NextInterestDate.Month()) - int(d.StartDate.Month())
+		d.NextInterestDate = d.interestDate(n + 1)
+	}
+
+	if d.InterestPayout == InterestPayoutCapitalize {
+		d.Principal = roundMoney(d.Principal + interest)
+		return 0
+	}
+	return interest
+}
+
+// Matured проверяет, закончился ли срок вклада и выплачены ли все проценты за него
+func (d *TermDeposit) Matured(now time.Time) bool {
+	return d.IsActive() && !now.Before(d.EndDate) && !d.AccruedThrough.Before(d.EndDate)
+}
+
+// Rollover пролонгирует вклад на тот же срок по ставке rate с даты окончания предыдущего срока
+func (d *TermDeposit) Rollover(rate float64, calendar *BusinessCalendar) {
+	d.startTerm(d.Principal, d.Term, rate, d.EndDate, calendar)
+	d.Rollovers++
+}
+
+// Close закрывает вклад в дату окончания срока и возвращает сумму к возврату на счет
+func (d *TermDeposit) Close(now time.Time) float64 {
+	amount := d.Principal
+	d.Principal = 0
+	d.Status = TermDepositStatusClosed
+	d.ClosedAt = &now
+	return amount
+}
+
+// EarlyInterest рассчитывает проценты за текущий срок по ставке досрочного расторжения:
+// простые проценты на сумму на начало срока за фактическое число дней
+func (d *TermDeposit) EarlyInterest(now time.Time) float64 {
+	var interest float64
+	end := startOfDay(now)
+	for day := startOfDay(d.StartDate).AddDate(0, 0, 1); !day.After(end); day = day.AddDate(0, 0, 1) {
+		interest += d.Amount * d.EarlyWithdrawalRate / 100 / float64(daysInYear(day.Year()))
+	}
+	return roundMoney(interest)
+}
+
+// CloseEarly расторгает вклад досрочно. Проценты за текущий срок пересчитываются
+// по ставке досрочного расторжения: капитализированные и выплаченные сверх нее
+// удерживаются из суммы вклада. Возвращает сумму к возврату на счет
+func (d *TermDeposit) CloseEarly(now time.Time) (float64, error) {
+	if !d.IsActive() {
+		return 0, ErrTermDepositClosed
+	}
+	interest := d.EarlyInterest(now)
+	amount := math.Max(0, roundMoney(d.Principal-d.TermInterest+interest))
+
+	d.TotalInterest = roundMoney(d.TotalInterest - d.TermInterest + interest)
+	d.TermInterest = interest
+	d.AccruedInterest = 0
+	d.Principal = 0
+	d.Status = TermDepositStatusClosedEarly
+	d.ClosedAt = &now
+	return amount, nil
+}
+
+// ToDTO преобразует модель в DTO
+func (d *TermDeposit) ToDTO() map[string]interface{} {
+	return map[string]interface{}{
+		"id":                    d.ID,
+		"user_id":               d.UserID,
+		"account_id":            d.AccountID,
+		"product_id":            d.ProductID,
+		"amount":                d.Amount,
+		"principal":             d.Principal,
+		"term":                  d.Term,
+		"interest_rate":         d.InterestRate,
+		"early_withdrawal_rate": d.EarlyWithdrawalRate,
+		"interest_payout":       d.InterestPayout,
+		"auto_rollover":         d.AutoRollover,
+		"start_date":            d.StartDate,
+		"end_date":              d.EndDate,
+		"contractual_end_date":  d.ContractualEndDate,
+		"business_day_rule":     d.BusinessDayRule.OrDefault(),
+		"next_interest_date":    d.NextInterestDate,
+		"accrued_interest":      d.AccruedInterest,
+		"term_interest":         d.TermInterest,
+		"total_interest":        d.TotalInterest,
+		"rollovers":             d.Rollovers,
+		"status":                d.Status,
+		"closed_at":             d.ClosedAt,
+		"created_at":            d.CreatedAt,
+	}
+}
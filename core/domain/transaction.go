@@ -12,8 +12,16 @@ var (
 	ErrInvalidType        = errors.New("invalid transaction type")
 	ErrInvalidStatus      = errors.New("invalid transaction status")
 	ErrTransactionExpired = errors.New("transaction has expired")
+
+	ErrDuplicateTransaction  = errors.New("duplicate transaction")
+	ErrTransactionNotPending = errors.New("transaction is not pending")
+	ErrCaptureExceedsHold    = errors.New("capture amount exceeds authorized amount")
 )
 
+// CardHoldTTL срок, в течение которого холд по карте ожидает подтверждения списания.
+// По истечении срока холд снимается, а сумма возвращается на счет
+const CardHoldTTL = 7 * 24 * time.Hour
+
 type TransactionType string
 
 const (
@@ -27,10 +35,11 @@ const (
 type TransactionStatus string
 
 const (
-	TransactionStatusPending   TransactionStatus = "PENDING"
-	TransactionStatusCompleted TransactionStatus = "COMPLETED"
-	TransactionStatusFailed    TransactionStatus = "FAILED"
-	TransactionStatusCancelled TransactionStatus = "CANCELLED"
+	TransactionStatusPending     TransactionStatus = "PENDING"
+	TransactionStatusCompleted   TransactionStatus = "COMPLETED"
+	TransactionStatusFailed      TransactionStatus = "FAILED"
+	TransactionStatusCancelled   TransactionStatus = "CANCELLED"
+	TransactionStatusChargedBack TransactionStatus = "CHARGED_BACK" // сумма возвращена клиенту по спору
 )
 
 type Transaction struct {
@@ -40,6 +49,12 @@ type Transaction struct {
 	Amount        float64           `json:"amount" gorm:"type:decimal(20,2);not null"`
 	FromAccountID uint              `json:"from_account_id"`
 	ToAccountID   uint              `json:"to_account_id"`
+	CardID        uint              `json:"card_id" gorm:"index"`
+	MCC           string            `json:"mcc" gorm:"type:varchar(4);index"`
+	Country       string            `json:"country" gorm:"type:varchar(3)"`
+	Merchant      string            `json:"merchant" gorm:"type:varchar(255)"`
+	RRN           string            `json:"rrn" gorm:"type:varchar(12);index"`
+	AuthCode      string            `json:"auth_code" gorm:"type:varchar(6)"`
 	Description   string            `json:"description" gorm:"type:text"`
 	Metadata      string            `json:"metadata" gorm:"type:jsonb"`
 	ExpiresAt     time.Time         `json:"expires_at"`
@@ -80,7 +95,7 @@ func (t *Transaction) ValidateType() error {
 func (t *Transaction) ValidateStatus() error {
 	switch t.Status {
 	case TransactionStatusPending, TransactionStatusCompleted,
-		TransactionStatusFailed, TransactionStatusCancelled:
+		TransactionStatusFailed, TransactionStatusCancelled, TransactionStatusChargedBack:
 		return nil
 	default:
 		return ErrInvalidStatus
@@ -118,20 +133,23 @@ func (t *Transaction) ValidateAccounts() error {
 }
 
 // IsExpired проверяет, истек ли срок действия транзакции
-func (t *Transaction) IsExpired() bool {
-	return !t.ExpiresAt.IsZero() && t.ExpiresAt.Before(time.Now())
+func (t *Transaction) IsExpired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && t.ExpiresAt.Before(now)
+}
+
+// IsHold проверяет, что транзакция является холдом по карте, ожидающим списания
+func (t *Transaction) IsHold() bool {
+	return t.Status == TransactionStatusPending && t.CardID != 0 && t.AuthCode != ""
 }
 
 // Complete помечает транзакцию как завершенную
-func (t *Transaction) Complete() {
-	now := time.Now()
+func (t *Transaction) Complete(now time.Time) {
 	t.Status = TransactionStatusCompleted
 	t.CompletedAt = &now
 }
 
 // Fail помечает транзакцию как неудачную
-func (t *Transaction) Fail(err error) {
-	now := time.Now()
+func (t *Transaction) Fail(err error, now time.Time) {
 	t.Status = TransactionStatusFailed
 	t.FailedAt = &now
 	t.Error = err.Error()
@@ -169,6 +187,12 @@ func (t *Transaction) ToDTO() map[string]interface{} {
 		"amount":          amount,
 		"from_account_id": t.FromAccountID,
 		"to_account_id":   t.ToAccountID,
+		"card_id":         t.CardID,
+		"mcc":             t.MCC,
+		"country":         t.Country,
+		"merchant":        t.Merchant,
+		"rrn":             t.RRN,
+		"auth_code":       t.AuthCode,
 		"description":     t.Description,
 		"status":          t.Status,
 		"created_at":      t.CreatedAt.Format(time.RFC3339),
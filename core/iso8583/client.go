@@ -0,0 +1,46 @@
+package iso8583
+
+import (
+	"net"
+	"time"
+)
+
+// Client клиент для отправки сообщений ISO 8583 по TCP.
+// Запросы отправляются последовательно: следующий запрос ждет ответа на предыдущий
+type Client struct {
+	conn    net.Conn
+	spec    *Spec
+	timeout time.Duration
+}
+
+// Dial подключается к серверу
+func Dial(addr string, spec *Spec, timeout time.Duration) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, spec: spec, timeout: timeout}, nil
+}
+
+// Send отправляет запрос и ждет ответ
+func (c *Client) Send(request *Message) (*Message, error) {
+	packed, err := c.spec.Pack(request)
+	if err != nil {
+		return nil, err
+	}
+
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+	if err := WriteFrame(c.conn, packed); err != nil {
+		return nil, err
+	}
+	data, err := ReadFrame(c.conn)
+	if err != nil {
+		return nil, err
+	}
+	return c.spec.Unpack(data)
+}
+
+// Close закрывает соединение
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
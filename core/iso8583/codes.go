@@ -0,0 +1,21 @@
+package iso8583
+
+// Коды ответа (поле 39)
+const (
+	ResponseApproved             = "00" // операция одобрена
+	ResponseDoNotHonor           = "05" // отказ без уточнения причины
+	ResponseInvalidTransaction   = "12" // недопустимая операция
+	ResponseInvalidAmount        = "13" // неверная сумма
+	ResponseInvalidCardNumber    = "14" // карта не найдена
+	ResponseRecordNotFound       = "25" // исходная операция не найдена
+	ResponseFormatError          = "30" // ошибка формата сообщения
+	ResponseInsufficientFunds    = "51" // недостаточно средств
+	ResponseExpiredCard          = "54" // истек срок действия карты
+	ResponseIncorrectPIN         = "55" // неверный PIN
+	ResponseNotPermitted         = "57" // операция запрещена для держателя карты
+	ResponseExceedsLimit         = "61" // превышен лимит суммы
+	ResponseRestrictedCard       = "62" // карта ограничена (закрыта или неактивна)
+	ResponsePINTriesExceeded     = "75" // превышено число попыток ввода PIN
+	ResponseDuplicateTransaction = "94" // повторная операция
+	ResponseSystemError          = "96" // системная ошибка
+)
@@ -0,0 +1,35 @@
+package iso8583
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// maxFrameLength максимальная длина сообщения, которую позволяет 2-байтовый заголовок
+const maxFrameLength = 0xFFFF
+
+// WriteFrame записывает сообщение с 2-байтовым заголовком длины (big-endian)
+func WriteFrame(w io.Writer, data []byte) error {
+	if len(data) > maxFrameLength {
+		return fmt.Errorf("%w: frame length %d exceeds %d", ErrInvalidMessage, len(data), maxFrameLength)
+	}
+	frame := make([]byte, 2+len(data))
+	binary.BigEndian.PutUint16(frame, uint16(len(data)))
+	copy(frame[2:], data)
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadFrame читает одно сообщение с 2-байтовым заголовком длины
+func ReadFrame(r io.Reader) ([]byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	data := make([]byte, binary.BigEndian.Uint16(header))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,84 @@
+package iso8583
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestWriteFrame(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{"empty", []byte{}, []byte{0x00, 0x00}},
+		{"short", []byte("0800"), []byte{0x00, 0x04, '0', '8', '0', '0'}},
+		{"two-byte length", bytes.Repeat([]byte("A"), 0x0102), append([]byte{0x01, 0x02}, bytes.Repeat([]byte("A"), 0x0102)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteFrame(&buf, tt.data); err != nil {
+				t.Fatalf("WriteFrame: %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Fatalf("WriteFrame = % X, want % X", buf.Bytes(), tt.want)
+			}
+
+			read, err := ReadFrame(&buf)
+			if err != nil {
+				t.Fatalf("ReadFrame: %v", err)
+			}
+			if !bytes.Equal(read, tt.data) {
+				t.Fatalf("ReadFrame = %q, want %q", read, tt.data)
+			}
+		})
+	}
+}
+
+func TestWriteFrameTooLong(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFrame(&buf, make([]byte, maxFrameLength+1)); !errors.Is(err, ErrInvalidMessage) {
+		t.Fatalf("WriteFrame error = %v, want %v", err, ErrInvalidMessage)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("WriteFrame wrote %d bytes for a rejected frame", buf.Len())
+	}
+}
+
+func TestReadFrameSequence(t *testing.T) {
+	stream := bytes.NewReader([]byte{0x00, 0x02, 'A', 'B', 0x00, 0x01, 'C'})
+	for _, want := range []string{"AB", "C"} {
+		read, err := ReadFrame(stream)
+		if err != nil {
+			t.Fatalf("ReadFrame: %v", err)
+		}
+		if string(read) != want {
+			t.Fatalf("ReadFrame = %q, want %q", read, want)
+		}
+	}
+	if _, err := ReadFrame(stream); !errors.Is(err, io.EOF) {
+		t.Fatalf("ReadFrame at end of stream error = %v, want %v", err, io.EOF)
+	}
+}
+
+func TestReadFrameTruncated(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"partial header", []byte{0x00}},
+		{"partial body", []byte{0x00, 0x05, 'A', 'B'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadFrame(bytes.NewReader(tt.data)); !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("ReadFrame error = %v, want %v", err, io.ErrUnexpectedEOF)
+			}
+		})
+	}
+}
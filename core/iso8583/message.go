@@ -0,0 +1,284 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Типы сообщений (MTI)
+const (
+	MTIAuthorizationRequest  = "0100"
+	MTIAuthorizationResponse = "0110"
+	MTIFinancialRequest      = "0200"
+	MTIFinancialResponse     = "0210"
+	MTIReversalRequest       = "0400"
+	MTIReversalResponse      = "0410"
+	MTIReversalAdvice        = "0420"
+	MTIReversalAdviceResp    = "0430"
+)
+
+// Номера полей, которые используются при обработке сообщений
+const (
+	FieldPAN                 = 2
+	FieldProcessingCode      = 3
+	FieldAmount              = 4
+	FieldTransmissionTime    = 7
+	FieldSTAN                = 11
+	FieldLocalTime           = 12
+	FieldLocalDate           = 13
+	FieldExpiryDate          = 14
+	FieldMCC                 = 18
+	FieldCountryCode         = 19
+	FieldPOSEntryMode        = 22
+	FieldPOSConditionCode    = 25
+	FieldAcquirerID          = 32
+	FieldRRN                 = 37
+	FieldAuthCode            = 38
+	FieldResponseCode        = 39
+	FieldTerminalID          = 41
+	FieldMerchantID          = 42
+	FieldMerchantName        = 43
+	FieldCurrencyCode        = 49
+	FieldPINData             = 52
+	FieldOriginalDataElement = 90
+)
+
+// responseEchoFields поля запроса, которые возвращаются в ответе без изменений
+var responseEchoFields = []int{
+	FieldPAN, FieldProcessingCode, FieldAmount, FieldTransmissionTime, FieldSTAN,
+	FieldLocalTime, FieldLocalDate, FieldAcquirerID, FieldRRN, FieldTerminalID,
+	FieldMerchantID, FieldCurrencyCode,
+}
+
+var mtiRegex = regexp.MustCompile(`^\d{4}$`)
+
+// Message сообщение ISO 8583. Значения полей хранятся в виде строк без дополнения
+type Message struct {
+	MTI    string
+	Fields map[int]string
+}
+
+// NewMessage создает пустое сообщение указанного типа
+func NewMessage(mti string) *Message {
+	return &Message{MTI: mti, Fields: make(map[int]string)}
+}
+
+// NewResponse создает ответ на запрос: тип ответа получается из типа запроса,
+// идентифицирующие операцию поля копируются из запроса
+func NewResponse(request *Message) *Message {
+	response := NewMessage(ResponseMTI(request.MTI))
+	for _, field := range responseEchoFields {
+		if value, ok := request.Fields[field]; ok {
+			response.Fields[field] = value
+		}
+	}
+	return response
+}
+
+// ResponseMTI возвращает тип ответа для типа запроса (0100 -> 0110)
+func ResponseMTI(mti string) string {
+	if len(mti) != 4 || mti[2] < '0' || mti[2] > '8' {
+		return mti
+	}
+	return mti[:2] + string(mti[2]+1) + mti[3:]
+}
+
+// Get возвращает значение поля или пустую строку
+func (m *Message) Get(field int) string {
+	return m.Fields[field]
+}
+
+// Has проверяет наличие поля в сообщении
+func (m *Message) Has(field int) bool {
+	_, ok := m.Fields[field]
+	return ok
+}
+
+// Set устанавливает значение поля
+func (m *Message) Set(field int, value string) {
+	m.Fields[field] = value
+}
+
+// fieldNumbers возвращает номера полей сообщения по возрастанию
+func (m *Message) fieldNumbers() []int {
+	numbers := make([]int, 0, len(m.Fields))
+	for number := range m.Fields {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// String возвращает сообщение в читаемом виде для логов.
+// PAN маскируется, PIN-блок не выводится
+func (m *Message) String() string {
+	var b strings.Builder
+	b.WriteString("MTI=" + m.MTI)
+	for _, number := range m.fieldNumbers() {
+		value := m.Fields[number]
+		switch number {
+		case FieldPAN:
+			value = maskPAN(value)
+		case FieldPINData:
+			value = "****"
+		}
+		fmt.Fprintf(&b, " DE%d=%q", number, value)
+	}
+	return b.String()
+}
+
+// maskPAN оставляет первые шесть и последние четыре цифры номера карты
+func maskPAN(pan string) string {
+	if len(pan) < 10 {
+		return strings.Repeat("*", len(pan))
+	}
+	return pan[:6] + strings.Repeat("*", len(pan)-10) + pan[len(pan)-4:]
+}
+
+// Pack собирает сообщение по спецификации: MTI, битовая карта и поля
+func (s *Spec) Pack(m *Message) ([]byte, error) {
+	if !mtiRegex.MatchString(m.MTI) {
+		return nil, fmt.Errorf("%w: MTI %q", ErrInvalidMessage, m.MTI)
+	}
+
+	numbers := m.fieldNumbers()
+	bitmap := make([]byte, 8)
+	for _, number := range numbers {
+		if number < 2 || number > 128 {
+			return nil, fmt.Errorf("%w: field %d", ErrUnknownField, number)
+		}
+		if number > 64 && len(bitmap) == 8 {
+			bitmap = append(bitmap, make([]byte, 8)...)
+			bitmap[0] |= 0x80
+		}
+		bitmap[(number-1)/8] |= 0x80 >> uint((number-1)%8)
+	}
+
+	var b strings.Builder
+	b.WriteString(m.MTI)
+	b.WriteString(strings.ToUpper(hex.EncodeToString(bitmap)))
+	for _, number := range numbers {
+		field, ok := s.Fields[number]
+		if !ok {
+			return nil, fmt.Errorf("%w: field %d", ErrUnknownField, number)
+		}
+		encoded, err := field.pack(m.Fields[number])
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %d: %v", ErrInvalidMessage, number, err)
+		}
+		b.WriteString(encoded)
+	}
+	return []byte(b.String()), nil
+}
+
+// Unpack разбирает сообщение по спецификации
+func (s *Spec) Unpack(data []byte) (*Message, error) {
+	raw := string(data)
+	if len(raw) < 4+16 {
+		return nil, fmt.Errorf("%w: message is too short", ErrInvalidMessage)
+	}
+
+	m := NewMessage(raw[:4])
+	if !mtiRegex.MatchString(m.MTI) {
+		return nil, fmt.Errorf("%w: MTI %q", ErrInvalidMessage, m.MTI)
+	}
+
+	bitmap, err := hex.DecodeString(raw[4:20])
+	if err != nil {
+		return nil, fmt.Errorf("%w: bitmap: %v", ErrInvalidMessage, err)
+	}
+	offset := 20
+	if bitmap[0]&0x80 != 0 {
+		if len(raw) < offset+16 {
+			return nil, fmt.Errorf("%w: secondary bitmap is missing", ErrInvalidMessage)
+		}
+		secondary, err := hex.DecodeString(raw[offset : offset+16])
+		if err != nil {
+			return nil, fmt.Errorf("%w: secondary bitmap: %v", ErrInvalidMessage, err)
+		}
+		bitmap = append(bitmap, secondary...)
+		offset += 16
+	}
+
+	for number := 2; number <= len(bitmap)*8; number++ {
+		if bitmap[(number-1)/8]&(0x80>>uint((number-1)%8)) == 0 {
+			continue
+		}
+		field, ok := s.Fields[number]
+		if !ok {
+			return nil, fmt.Errorf("%w: field %d", ErrUnknownField, number)
+		}
+		value, read, err := field.unpack(raw[offset:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %d: %v", ErrInvalidMessage, number, err)
+		}
+		m.Fields[number] = value
+		offset += read
+	}
+
+	if offset != len(raw) {
+		return nil, fmt.Errorf("%w: %d unexpected trailing bytes", ErrInvalidMessage, len(raw)-offset)
+	}
+	return m, nil
+}
+
+// pack кодирует значение поля: дополняет поле фиксированной длины
+// или добавляет префикс длины для поля переменной длины
+func (f FieldSpec) pack(value string) (string, error) {
+	if !f.checkCharset(value) {
+		return "", fmt.Errorf("value does not match charset %s", f.Charset)
+	}
+	if len(value) > f.Length {
+		return "", fmt.Errorf("value length %d exceeds %d", len(value), f.Length)
+	}
+
+	if f.Type != FieldTypeFixed {
+		return fmt.Sprintf("%0*d", f.prefixLength(), len(value)) + value, nil
+	}
+
+	padding := f.Length - len(value)
+	switch f.Charset {
+	case CharsetNumeric:
+		return strings.Repeat("0", padding) + value, nil
+	case CharsetHex:
+		if padding != 0 {
+			return "", fmt.Errorf("value length %d must be %d", len(value), f.Length)
+		}
+		return value, nil
+	default:
+		return value + strings.Repeat(" ", padding), nil
+	}
+}
+
+// unpack читает значение поля и возвращает количество прочитанных символов.
+// У буквенно-цифровых полей отбрасываются завершающие пробелы
+func (f FieldSpec) unpack(raw string) (string, int, error) {
+	length := f.Length
+	prefix := f.prefixLength()
+	if prefix > 0 {
+		if len(raw) < prefix {
+			return "", 0, fmt.Errorf("length prefix is missing")
+		}
+		parsed, err := strconv.Atoi(raw[:prefix])
+		if err != nil || parsed > f.Length {
+			return "", 0, fmt.Errorf("invalid length prefix %q", raw[:prefix])
+		}
+		length = parsed
+	}
+	if len(raw) < prefix+length {
+		return "", 0, fmt.Errorf("value is truncated")
+	}
+
+	value := raw[prefix : prefix+length]
+	if !f.checkCharset(value) {
+		return "", 0, fmt.Errorf("value does not match charset %s", f.Charset)
+	}
+	if f.Charset == CharsetAlphaNumeric || f.Charset == CharsetAlphaSpecial {
+		value = strings.TrimRight(value, " ")
+	}
+	return value, prefix + length, nil
+}
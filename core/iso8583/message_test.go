@@ -0,0 +1,180 @@
+package iso8583
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func defaultSpec(t *testing.T) *Spec {
+	t.Helper()
+	spec, err := DefaultSpec()
+	if err != nil {
+		t.Fatalf("DefaultSpec: %v", err)
+	}
+	return spec
+}
+
+func TestPackUnpackVectors(t *testing.T) {
+	spec := defaultSpec(t)
+
+	tests := []struct {
+		name   string
+		mti    string
+		fields map[int]string
+		packed string
+	}{
+		{
+			name: "primary bitmap",
+			mti:  MTIFinancialRequest,
+			fields: map[int]string{
+				FieldPAN:            "4276001234567890",
+				FieldProcessingCode: "000000",
+				FieldAmount:         "000000150000",
+				FieldSTAN:           "123456",
+				FieldRRN:            "000000000001",
+				FieldTerminalID:     "TERM01",
+			},
+			packed: "0200" + "7020000008800000" +
+				"16" + "4276001234567890" + "000000" + "000000150000" + "123456" +
+				"000000000001" + "TERM01  ",
+		},
+		{
+			name: "response with auth code",
+			mti:  MTIAuthorizationResponse,
+			fields: map[int]string{
+				FieldSTAN:         "000042",
+				FieldAuthCode:     "A1B2C3",
+				FieldResponseCode: "00",
+			},
+			packed: "0110" + "0020000006000000" + "000042" + "A1B2C3" + "00",
+		},
+		{
+			name: "secondary bitmap",
+			mti:  MTIReversalRequest,
+			fields: map[int]string{
+				FieldPAN:                 "4276001234567890",
+				FieldOriginalDataElement: "020012345610191200000000010020000000000000",
+			},
+			packed: "0400" + "C000000000000000" + "0000004000000000" +
+				"16" + "4276001234567890" + "020012345610191200000000010020000000000000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMessage(tt.mti)
+			for number, value := range tt.fields {
+				m.Set(number, value)
+			}
+
+			packed, err := spec.Pack(m)
+			if err != nil {
+				t.Fatalf("Pack: %v", err)
+			}
+			if string(packed) != tt.packed {
+				t.Fatalf("Pack = %q, want %q", packed, tt.packed)
+			}
+
+			unpacked, err := spec.Unpack(packed)
+			if err != nil {
+				t.Fatalf("Unpack: %v", err)
+			}
+			if unpacked.MTI != tt.mti {
+				t.Errorf("MTI = %q, want %q", unpacked.MTI, tt.mti)
+			}
+			if !reflect.DeepEqual(unpacked.Fields, tt.fields) {
+				t.Errorf("Fields = %v, want %v", unpacked.Fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestPackPadsFixedFields(t *testing.T) {
+	spec := defaultSpec(t)
+
+	m := NewMessage(MTIAuthorizationRequest)
+	m.Set(FieldAmount, "150000")
+	m.Set(FieldMerchantName, "SHOP")
+
+	packed, err := spec.Pack(m)
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	want := "0100" + "1000000000200000" + "000000150000" + "SHOP" + strings.Repeat(" ", 36)
+	if string(packed) != want {
+		t.Fatalf("Pack = %q, want %q", packed, want)
+	}
+}
+
+func TestPackErrors(t *testing.T) {
+	spec := defaultSpec(t)
+
+	tests := []struct {
+		name   string
+		mti    string
+		fields map[int]string
+		err    error
+	}{
+		{"invalid MTI", "02A0", nil, ErrInvalidMessage},
+		{"field out of spec", MTIFinancialRequest, map[int]string{5: "1"}, ErrUnknownField},
+		{"numeric field with letters", MTIFinancialRequest, map[int]string{FieldAmount: "12A"}, ErrInvalidMessage},
+		{"value too long", MTIFinancialRequest, map[int]string{FieldPAN: "42760012345678901234"}, ErrInvalidMessage},
+		{"short hex field", MTIFinancialRequest, map[int]string{FieldPINData: "0412AC89"}, ErrInvalidMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMessage(tt.mti)
+			for number, value := range tt.fields {
+				m.Set(number, value)
+			}
+			if _, err := spec.Pack(m); !errors.Is(err, tt.err) {
+				t.Fatalf("Pack error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUnpackErrors(t *testing.T) {
+	spec := defaultSpec(t)
+
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"too short", "0200702000", ErrInvalidMessage},
+		{"bitmap is not hex", "0200ZZ20000008800000", ErrInvalidMessage},
+		{"secondary bitmap is missing", "0400C000000000000000", ErrInvalidMessage},
+		{"field out of spec", "0200" + "0800000000000000" + "1", ErrUnknownField},
+		{"length prefix exceeds field", "0200" + "4000000000000000" + "20" + "42760012345678901234", ErrInvalidMessage},
+		{"truncated value", "0200" + "4000000000000000" + "16" + "4276001234", ErrInvalidMessage},
+		{"trailing bytes", "0200" + "0000000002000000" + "00" + "X", ErrInvalidMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := spec.Unpack([]byte(tt.data)); !errors.Is(err, tt.err) {
+				t.Fatalf("Unpack error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestResponseMTI(t *testing.T) {
+	tests := map[string]string{
+		MTIAuthorizationRequest: MTIAuthorizationResponse,
+		MTIFinancialRequest:     MTIFinancialResponse,
+		MTIReversalRequest:      MTIReversalResponse,
+		MTIReversalAdvice:       MTIReversalAdviceResp,
+		"0190":                  "0190",
+		"01":                    "01",
+	}
+	for request, want := range tests {
+		if got := ResponseMTI(request); got != want {
+			t.Errorf("ResponseMTI(%q) = %q, want %q", request, got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidPINBlock = errors.New("invalid PIN block")
+
+// EncodePINBlock формирует PIN-блок ISO 9564 формата 0: PIN-поле (0, длина PIN, PIN,
+// дополнение F) складывается по XOR с 12 правыми цифрами PAN без контрольной.
+// В тестовом контуре PIN-блок передается без шифрования ключом PIN
+func EncodePINBlock(pin, pan string) (string, error) {
+	if len(pin) < 4 || len(pin) > 12 || strings.Trim(pin, "0123456789") != "" {
+		return "", fmt.Errorf("%w: PIN must contain 4 to 12 digits", ErrInvalidPINBlock)
+	}
+	panField, err := pinBlockPANField(pan)
+	if err != nil {
+		return "", err
+	}
+
+	pinField, err := hex.DecodeString(fmt.Sprintf("0%X%s%s", len(pin), pin, strings.Repeat("F", 14-len(pin))))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidPINBlock, err)
+	}
+
+	block := make([]byte, 8)
+	for i := range block {
+		block[i] = pinField[i] ^ panField[i]
+	}
+	return strings.ToUpper(hex.EncodeToString(block)), nil
+}
+
+// DecodePINBlock извлекает PIN из PIN-блока ISO 9564 формата 0
+func DecodePINBlock(pinBlock, pan string) (string, error) {
+	block, err := hex.DecodeString(pinBlock)
+	if err != nil || len(block) != 8 {
+		return "", fmt.Errorf("%w: PIN block must be 16 hex characters", ErrInvalidPINBlock)
+	}
+	panField, err := pinBlockPANField(pan)
+	if err != nil {
+		return "", err
+	}
+
+	pinField := make([]byte, 8)
+	for i := range pinField {
+		pinField[i] = block[i] ^ panField[i]
+	}
+	decoded := strings.ToUpper(hex.EncodeToString(pinField))
+
+	if decoded[0] != '0' {
+		return "", fmt.Errorf("%w: unsupported format", ErrInvalidPINBlock)
+	}
+	length := int(pinField[0] & 0x0F)
+	if length < 4 || length > 12 {
+		return "", fmt.Errorf("%w: invalid PIN length", ErrInvalidPINBlock)
+	}
+	pin := decoded[2 : 2+length]
+	if strings.Trim(pin, "0123456789") != "" || strings.Trim(decoded[2+length:], "F") != "" {
+		return "", fmt.Errorf("%w: invalid PIN field", ErrInvalidPINBlock)
+	}
+	return pin, nil
+}
+
+// pinBlockPANField формирует PAN-поле: четыре нуля и 12 правых цифр PAN без контрольной
+func pinBlockPANField(pan string) ([]byte, error) {
+	if len(pan) < 13 || strings.Trim(pan, "0123456789") != "" {
+		return nil, fmt.Errorf("%w: invalid PAN", ErrInvalidPINBlock)
+	}
+	digits := pan[len(pan)-13 : len(pan)-1]
+	return hex.DecodeString("0000" + digits)
+}
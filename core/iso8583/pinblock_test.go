@@ -0,0 +1,86 @@
+package iso8583
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPINBlockVectors(t *testing.T) {
+	tests := []struct {
+		name  string
+		pin   string
+		pan   string
+		block string
+	}{
+		// Пример формата 0 из ISO 9564-1
+		{"four digits", "1234", "43219876543210987", "0412AC89ABCDEF67"},
+		{"sixteen digit PAN", "1234", "4111111111111111", "041225EEEEEEEEEE"},
+		{"six digits", "123456", "4276001234567890", "06125457DCBA9876"},
+		{"twelve digits", "123456789012", "4276001234567890", "0C1254575BD57576"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block, err := EncodePINBlock(tt.pin, tt.pan)
+			if err != nil {
+				t.Fatalf("EncodePINBlock: %v", err)
+			}
+			if block != tt.block {
+				t.Fatalf("EncodePINBlock = %s, want %s", block, tt.block)
+			}
+
+			pin, err := DecodePINBlock(tt.block, tt.pan)
+			if err != nil {
+				t.Fatalf("DecodePINBlock: %v", err)
+			}
+			if pin != tt.pin {
+				t.Fatalf("DecodePINBlock = %s, want %s", pin, tt.pin)
+			}
+		})
+	}
+}
+
+func TestEncodePINBlockErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		pin  string
+		pan  string
+	}{
+		{"short PIN", "123", "4276001234567890"},
+		{"long PIN", "1234567890123", "4276001234567890"},
+		{"non-digit PIN", "12a4", "4276001234567890"},
+		{"short PAN", "1234", "427600123456"},
+		{"non-digit PAN", "1234", "427600123456789X"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncodePINBlock(tt.pin, tt.pan); !errors.Is(err, ErrInvalidPINBlock) {
+				t.Fatalf("EncodePINBlock error = %v, want %v", err, ErrInvalidPINBlock)
+			}
+		})
+	}
+}
+
+func TestDecodePINBlockErrors(t *testing.T) {
+	const pan = "43219876543210987"
+
+	tests := []struct {
+		name  string
+		block string
+	}{
+		{"not hex", "0412AC89ABCDEFZZ"},
+		{"short block", "0412AC89"},
+		{"other format", "1412AC89ABCDEF67"},
+		{"PIN length below 4", "0312AC89ABCDEF67"},
+		{"broken padding", "0412AC89ABCDEF66"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodePINBlock(tt.block, pan); !errors.Is(err, ErrInvalidPINBlock) {
+				t.Fatalf("DecodePINBlock error = %v, want %v", err, ErrInvalidPINBlock)
+			}
+		})
+	}
+}
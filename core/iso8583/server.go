@@ -0,0 +1,162 @@
+package iso8583
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Handler обрабатывает запрос и возвращает ответ. Ответ nil означает, что отвечать не нужно
+type Handler interface {
+	Handle(request *Message) *Message
+}
+
+// HandlerFunc позволяет использовать функцию как Handler
+type HandlerFunc func(request *Message) *Message
+
+// Handle вызывает функцию-обработчик
+func (f HandlerFunc) Handle(request *Message) *Message {
+	return f(request)
+}
+
+// idleTimeout время, после которого простаивающее соединение закрывается
+const idleTimeout = 5 * time.Minute
+
+// Server TCP-сервер, принимающий сообщения ISO 8583. Каждое соединение
+// обслуживается отдельной горутиной, запросы в соединении обрабатываются по очереди
+type Server struct {
+	Addr    string
+	Spec    *Spec
+	Handler Handler
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+// NewServer создает сервер
+func NewServer(addr string, spec *Spec, handler Handler) *Server {
+	return &Server{Addr: addr, Spec: spec, Handler: handler}
+}
+
+// ListenAndServe открывает порт и обслуживает соединения до вызова Close
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve обслуживает соединения на переданном listener
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		listener.Close()
+		return net.ErrClosed
+	}
+	s.listener = listener
+	s.conns = make(map[net.Conn]struct{})
+	s.mu.Unlock()
+
+	logrus.WithField("addr", listener.Addr().String()).Info("ISO 8583 шлюз запущен")
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close останавливает сервер и закрывает открытые соединения
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+// serveConn читает запросы из соединения и отправляет ответы
+func (s *Server) serveConn(conn net.Conn) {
+	log := logrus.WithField("remote", conn.RemoteAddr().String())
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		data, err := ReadFrame(conn)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.WithError(err).Warn("Ошибка чтения сообщения ISO 8583")
+			}
+			return
+		}
+
+		response := s.handle(data, log)
+		if response == nil {
+			continue
+		}
+
+		packed, err := s.Spec.Pack(response)
+		if err != nil {
+			log.WithError(err).Error("Ошибка сборки ответа ISO 8583")
+			continue
+		}
+		if err := WriteFrame(conn, packed); err != nil {
+			log.WithError(err).Warn("Ошибка отправки ответа ISO 8583")
+			return
+		}
+	}
+}
+
+// handle разбирает запрос и передает его обработчику.
+// На неразбираемое сообщение отвечает кодом ошибки формата, если удалось определить MTI
+func (s *Server) handle(data []byte, log *logrus.Entry) *Message {
+	request, err := s.Spec.Unpack(data)
+	if err != nil {
+		log.WithError(err).Warn("Ошибка разбора сообщения ISO 8583")
+		if len(data) < 4 || !mtiRegex.MatchString(string(data[:4])) {
+			return nil
+		}
+		response := NewMessage(ResponseMTI(string(data[:4])))
+		response.Set(FieldResponseCode, ResponseFormatError)
+		return response
+	}
+
+	log.WithField("message", request.String()).Debug("Получено сообщение ISO 8583")
+	response := s.Handler.Handle(request)
+	if response != nil {
+		log.WithField("message", response.String()).Debug("Отправлен ответ ISO 8583")
+	}
+	return response
+}
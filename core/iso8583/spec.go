@@ -0,0 +1,171 @@
+// Package iso8583 реализует разбор и сборку сообщений ISO 8583 в ASCII-представлении,
+// TCP-сервер и клиент для обмена сообщениями с процессинговым центром.
+//
+// Сообщение передается кадром: 2 байта длины (big-endian), затем MTI (4 символа),
+// битовая карта в шестнадцатеричном виде (16 символов, 32 при наличии вторичной карты)
+// и поля в порядке номеров. Формат полей задается спецификацией в JSON.
+package iso8583
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+)
+
+var (
+	ErrInvalidSpec    = errors.New("invalid ISO 8583 field spec")
+	ErrUnknownField   = errors.New("field is not defined in spec")
+	ErrInvalidMessage = errors.New("invalid ISO 8583 message")
+)
+
+//go:embed specs/default.json
+var specFiles embed.FS
+
+// FieldType способ кодирования длины поля
+type FieldType string
+
+const (
+	FieldTypeFixed  FieldType = "FIXED"  // поле фиксированной длины
+	FieldTypeLLVar  FieldType = "LLVAR"  // длина поля в 2 символах перед значением
+	FieldTypeLLLVar FieldType = "LLLVAR" // длина поля в 3 символах перед значением
+)
+
+// Charset допустимые символы значения поля
+type Charset string
+
+const (
+	CharsetNumeric      Charset = "n"   // только цифры, дополняется нулями слева
+	CharsetAlphaNumeric Charset = "an"  // буквы и цифры, дополняется пробелами справа
+	CharsetAlphaSpecial Charset = "ans" // печатные символы, дополняется пробелами справа
+	CharsetHex          Charset = "hex" // двоичные данные в шестнадцатеричном виде
+)
+
+// FieldSpec описание поля сообщения
+type FieldSpec struct {
+	Name    string    `json:"name"`
+	Type    FieldType `json:"type"`
+	Length  int       `json:"length"` // точная длина для FIXED, максимальная для LLVAR/LLLVAR
+	Charset Charset   `json:"charset"`
+}
+
+// Spec спецификация полей сообщений
+type Spec struct {
+	Name   string
+	Fields map[int]FieldSpec
+}
+
+// specFile формат файла спецификации: номера полей задаются строками
+type specFile struct {
+	Name   string               `json:"name"`
+	Fields map[string]FieldSpec `json:"fields"`
+}
+
+// DefaultSpec возвращает встроенную спецификацию полей
+func DefaultSpec() (*Spec, error) {
+	data, err := specFiles.ReadFile("specs/default.json")
+	if err != nil {
+		return nil, err
+	}
+	return ParseSpec(data)
+}
+
+// LoadSpec загружает спецификацию из файла. Пустой путь означает встроенную спецификацию
+func LoadSpec(path string) (*Spec, error) {
+	if path == "" {
+		return DefaultSpec()
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ISO 8583 spec: %v", err)
+	}
+	return ParseSpec(data)
+}
+
+// ParseSpec разбирает спецификацию из JSON
+func ParseSpec(data []byte) (*Spec, error) {
+	var file specFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSpec, err)
+	}
+
+	spec := &Spec{Name: file.Name, Fields: make(map[int]FieldSpec, len(file.Fields))}
+	for key, field := range file.Fields {
+		number, err := strconv.Atoi(key)
+		if err != nil || number < 2 || number > 128 {
+			return nil, fmt.Errorf("%w: field number %q", ErrInvalidSpec, key)
+		}
+		if err := field.validate(); err != nil {
+			return nil, fmt.Errorf("%w: field %d: %v", ErrInvalidSpec, number, err)
+		}
+		spec.Fields[number] = field
+	}
+	return spec, nil
+}
+
+// validate проверяет описание поля
+func (f FieldSpec) validate() error {
+	switch f.Type {
+	case FieldTypeFixed:
+		if f.Length <= 0 {
+			return errors.New("length must be positive")
+		}
+	case FieldTypeLLVar:
+		if f.Length <= 0 || f.Length > 99 {
+			return errors.New("LLVAR length must be between 1 and 99")
+		}
+	case FieldTypeLLLVar:
+		if f.Length <= 0 || f.Length > 999 {
+			return errors.New("LLLVAR length must be between 1 and 999")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", f.Type)
+	}
+
+	switch f.Charset {
+	case CharsetNumeric, CharsetAlphaNumeric, CharsetAlphaSpecial, CharsetHex:
+	default:
+		return fmt.Errorf("unknown charset %q", f.Charset)
+	}
+	return nil
+}
+
+// prefixLength возвращает количество символов префикса длины
+func (f FieldSpec) prefixLength() int {
+	switch f.Type {
+	case FieldTypeLLVar:
+		return 2
+	case FieldTypeLLLVar:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// checkCharset проверяет символы значения
+func (f FieldSpec) checkCharset(value string) bool {
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+		switch f.Charset {
+		case CharsetNumeric:
+			if ch < '0' || ch > '9' {
+				return false
+			}
+		case CharsetAlphaNumeric:
+			if !(ch >= '0' && ch <= '9' || ch >= 'A' && ch <= 'Z' || ch >= 'a' && ch <= 'z' || ch == ' ') {
+				return false
+			}
+		case CharsetHex:
+			if !(ch >= '0' && ch <= '9' || ch >= 'A' && ch <= 'F' || ch >= 'a' && ch <= 'f') {
+				return false
+			}
+		default:
+			if ch < 0x20 || ch > 0x7e {
+				return false
+			}
+		}
+	}
+	return true
+}
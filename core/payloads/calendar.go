@@ -0,0 +1,20 @@
+package payloads
+
+// Вид дня производственного календаря, задаваемый администратором
+type CalendarDayRequest struct {
+	Type        string `json:"type" binding:"required,oneof=HOLIDAY WORKDAY"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+// День календаря в загружаемом файле
+type ImportCalendarDay struct {
+	Date        string `json:"date" binding:"required,datetime=2006-01-02"`
+	Type        string `json:"type" binding:"required,oneof=HOLIDAY WORKDAY"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+// Загрузка производственного календаря, например на новый год после
+// публикации постановления Правительства о переносе выходных дней
+type ImportCalendarRequest struct {
+	Days []ImportCalendarDay `json:"days" binding:"required,min=1,max=400,dive"`
+}
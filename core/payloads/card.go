@@ -1,5 +1,7 @@
 package payloads
 
+import "time"
+
 // Структура для выдачи информации о карте
 type UnsecureCard struct {
 	ID          uint   `json:"id"`
@@ -9,3 +11,56 @@ type UnsecureCard struct {
 	ExpiryDate  string `json:"expiry_date"` // Срок действия карты (не зашифрованый).
 	CVV         string `json:"CVV"`         // CVV код (не хешированый).
 }
+
+// Запрос на установку PIN-кода карты
+type SetPINRequest struct {
+	PIN string `json:"pin" binding:"required"`
+}
+
+// Запрос на смену PIN-кода карты
+type ChangePINRequest struct {
+	OldPIN string `json:"old_pin" binding:"required"`
+	NewPIN string `json:"new_pin" binding:"required"`
+}
+
+// Запрос на авторизацию операции по карте (оплата или снятие в банкомате)
+type CardAuthorizationRequest struct {
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	PIN         string  `json:"pin"`
+	Channel     string  `json:"channel"`
+	Merchant    string  `json:"merchant"`
+	MCC         string  `json:"mcc"`
+	Country     string  `json:"country"`
+	RRN         string  `json:"rrn"`
+	Description string  `json:"description"`
+}
+
+// Запрос на показ реквизитов карты (требуется повторный ввод пароля)
+type RevealCardRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// Запрос на разблокировку карты (требуется повторный ввод пароля)
+type UnblockCardRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// Реквизиты карты в открытом виде
+type RevealedCard struct {
+	ID         uint   `json:"id"`
+	Number     string `json:"number"`
+	ExpiryDate string `json:"expiry_date"`
+	Brand      string `json:"brand"`
+	CVV        string `json:"cvv,omitempty"` // только при первом показе реквизитов перевыпущенной карты
+}
+
+// Запрос на выпуск виртуальной карты
+type CreateVirtualCardRequest struct {
+	AccountID  uint       `json:"account_id" binding:"required"`
+	ProductID  uint       `json:"product_id"`
+	Mode       string     `json:"mode" binding:"required,oneof=SINGLE_USE MERCHANT_LOCKED"`
+	AmountCap  float64    `json:"amount_cap" binding:"required,gt=0"`
+	Merchant   string     `json:"merchant"`
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+}
@@ -0,0 +1,7 @@
+package payloads
+
+// Перевод смоделированных часов вперед на days дней и hours часов
+type AdvanceClockRequest struct {
+	Days  int `json:"days" binding:"gte=0,lte=366"`
+	Hours int `json:"hours" binding:"gte=0,lte=23"`
+}
@@ -0,0 +1,16 @@
+package payloads
+
+// Фильтры рабочего списка взыскания
+type CollectionWorklistQuery struct {
+	Bucket    string  `form:"bucket" binding:"omitempty,oneof=1-30 31-60 61-90 90+"`
+	Status    string  `form:"status" binding:"omitempty,oneof=OPEN COLLECTIONS CLOSED"`
+	MinAmount float64 `form:"min_amount" binding:"omitempty,gte=0"`
+	MaxAmount float64 `form:"max_amount" binding:"omitempty,gte=0"`
+}
+
+// Обещание заемщика погасить просрочку, зафиксированное оператором
+type PromiseToPayRequest struct {
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	PromisedUntil string  `json:"promised_until" binding:"required,datetime=2006-01-02"`
+	Comment       string  `json:"comment" binding:"max=2000"`
+}
@@ -0,0 +1,17 @@
+package payloads
+
+// Заявка на кредит
+type CreditApplicationRequest struct {
+	AccountID       uint    `json:"account_id" binding:"required"`
+	ProductID       uint    `json:"product_id" binding:"required"`
+	Amount          float64 `json:"amount" binding:"required,gt=0"`
+	TermMonths      int     `json:"term_months" binding:"required,gt=0"`
+	RepaymentMethod string  `json:"repayment_method" binding:"omitempty,oneof=ANNUITY DIFFERENTIATED"`
+	DeclaredIncome  float64 `json:"declared_income" binding:"required,gt=0"`
+	Description     string  `json:"description" binding:"max=255"`
+}
+
+// Решение менеджера по заявке на кредит
+type CreditApplicationDecisionRequest struct {
+	Comment string `json:"comment" binding:"max=2000"`
+}
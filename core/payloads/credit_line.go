@@ -0,0 +1,13 @@
+package payloads
+
+// Открытие кредитной линии менеджером
+type OpenCreditLineRequest struct {
+	UserID       uint    `json:"user_id" binding:"required"`
+	CreditLimit  float64 `json:"credit_limit" binding:"required,gt=0"`
+	InterestRate float64 `json:"interest_rate" binding:"omitempty,gt=0,lte=100"`
+}
+
+// Изменение лимита кредитной линии
+type UpdateCreditLimitRequest struct {
+	CreditLimit float64 `json:"credit_limit" binding:"required,gt=0"`
+}
@@ -0,0 +1,16 @@
+package payloads
+
+// Заявка оператора на реструктуризацию кредита
+type CreditRestructuringRequest struct {
+	CreditID     uint    `json:"credit_id" binding:"required"`
+	Type         string  `json:"type" binding:"required,oneof=PAYMENT_HOLIDAY TERM_EXTENSION RATE_REDUCTION"`
+	Months       int     `json:"months" binding:"omitempty,gt=0"`
+	HolidayMode  string  `json:"holiday_mode" binding:"omitempty,oneof=INTEREST_ONLY CAPITALIZED"`
+	InterestRate float64 `json:"interest_rate" binding:"omitempty,gt=0"`
+	Reason       string  `json:"reason" binding:"required,max=2000"`
+}
+
+// Решение менеджера по реструктуризации
+type CreditRestructuringDecisionRequest struct {
+	Comment string `json:"comment" binding:"max=2000"`
+}
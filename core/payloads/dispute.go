@@ -0,0 +1,29 @@
+package payloads
+
+// Запрос на открытие спора по операции по карте
+type OpenDisputeRequest struct {
+	TransactionID uint    `json:"transaction_id" binding:"required"`
+	Reason        string  `json:"reason" binding:"required"`
+	Comment       string  `json:"comment" binding:"max=2000"`
+	Amount        float64 `json:"amount" binding:"gte=0"` // 0 - оспаривается вся сумма операции
+}
+
+// Описание файла, приложенного к спору
+type DisputeAttachmentRequest struct {
+	FileName    string `json:"file_name" binding:"required"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size" binding:"required,gt=0"`
+	Checksum    string `json:"checksum"`
+	StorageURL  string `json:"storage_url"`
+}
+
+// Действие оператора по спору с комментарием
+type DisputeActionRequest struct {
+	Comment string `json:"comment" binding:"max=2000"`
+}
+
+// Решение оператора по спору
+type ResolveDisputeRequest struct {
+	Outcome string `json:"outcome" binding:"required,oneof=WON LOST"`
+	Comment string `json:"comment" binding:"required,max=2000"`
+}
@@ -0,0 +1,11 @@
+package payloads
+
+// Открытие срочного вклада
+type OpenTermDepositRequest struct {
+	AccountID      uint    `json:"account_id" binding:"required"`
+	ProductID      uint    `json:"product_id" binding:"required"`
+	Amount         float64 `json:"amount" binding:"required,gt=0"`
+	TermMonths     int     `json:"term_months" binding:"required,gt=0"`
+	InterestPayout string  `json:"interest_payout" binding:"omitempty,oneof=CAPITALIZE PAYOUT"`
+	AutoRollover   bool    `json:"auto_rollover"`
+}
@@ -2,14 +2,34 @@ package security
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/ProtonMail/go-crypto/openpgp/armor"
 )
 
+// Пути к ключам PGP и парольная фраза закрытого ключа
+var (
+	publicKeyFile        = "public_key.asc"
+	privateKeyFile       = "private_key.asc"
+	privateKeyPassphrase []byte
+)
+
+// ConfigurePGP задает пути к файлам ключей и парольную фразу закрытого ключа
+func ConfigurePGP(publicKeyPath, privateKeyPath, passphrase string) {
+	if publicKeyPath != "" {
+		publicKeyFile = publicKeyPath
+	}
+	if privateKeyPath != "" {
+		privateKeyFile = privateKeyPath
+	}
+	privateKeyPassphrase = []byte(passphrase)
+}
+
 func GenerateKeyPair(email string) (string, string, error) {
 	// Генерация ключа
 	entity, err := openpgp.NewEntity(email, "Generated PGP Key", "passphrase", nil)
@@ -23,11 +43,13 @@ func GenerateKeyPair(email string) (string, string, error) {
 	if err != nil {
 		return "", "", err
 	}
-	defer publicKeyWriter.Close()
 
 	if err := entity.Serialize(publicKeyWriter); err != nil {
 		return "", "", err
 	}
+	if err := publicKeyWriter.Close(); err != nil {
+		return "", "", err
+	}
 
 	// Экспорт закрытого ключа
 	var privateKeyBuf bytes.Buffer
@@ -35,9 +57,20 @@ func GenerateKeyPair(email string) (string, string, error) {
 	if err != nil {
 		return "", "", err
 	}
-	defer privateKeyWriter.Close()
 
-	if err := entity.SerializePrivate(privateKeyWriter, nil); err != nil {
+	if len(privateKeyPassphrase) > 0 {
+		// Закрытый ключ защищается парольной фразой из конфигурации.
+		// Самоподписи уже созданы NewEntity, повторная подпись зашифрованным ключом невозможна
+		if err := entity.EncryptPrivateKeys(privateKeyPassphrase, nil); err != nil {
+			return "", "", err
+		}
+		if err := entity.SerializePrivateWithoutSigning(privateKeyWriter, nil); err != nil {
+			return "", "", err
+		}
+	} else if err := entity.SerializePrivate(privateKeyWriter, nil); err != nil {
+		return "", "", err
+	}
+	if err := privateKeyWriter.Close(); err != nil {
 		return "", "", err
 	}
 
@@ -45,9 +78,6 @@ func GenerateKeyPair(email string) (string, string, error) {
 }
 
 func MainGenerateKeyPair() {
-	publicKeyFile := "public_key.asc"
-	privateKeyFile := "private_key.asc"
-
 	// Проверка существования ключей
 	if _, err := os.Stat(publicKeyFile); err == nil {
 		fmt.Println("Public key already exists. Skipping key generation.")
@@ -78,13 +108,57 @@ func MainGenerateKeyPair() {
 		fmt.Println("Error saving public key:", err)
 		return
 	}
-	if err := ioutil.WriteFile(privateKeyFile, []byte(privateKey), 0644); err != nil {
+	// Закрытый ключ доступен только владельцу процесса
+	if err := ioutil.WriteFile(privateKeyFile, []byte(privateKey), 0600); err != nil {
 		fmt.Println("Error saving private key:", err)
 		return
 	}
 
 	fmt.Println("Public key:")
 	fmt.Println(publicKey)
-	fmt.Println("Private key:")
-	fmt.Println(privateKey)
+	fmt.Println("Private key saved to", privateKeyFile)
+}
+
+// DecryptData расшифровывает данные, зашифрованные EncryptData, закрытым ключом PGP.
+func DecryptData(encoded string) (string, error) {
+	armored, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("error decoding encrypted data: %v", err)
+	}
+
+	privateKey, err := ioutil.ReadFile(privateKeyFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading private key file: %v", err)
+	}
+
+	entityList, err := openpgp.ReadArmoredKeyRing(strings.NewReader(string(privateKey)))
+	if err != nil {
+		return "", fmt.Errorf("error reading private key: %v", err)
+	}
+
+	// Снимаем защиту с закрытых ключей, если они зашифрованы парольной фразой
+	for _, entity := range entityList {
+		if entity.PrivateKey != nil && entity.PrivateKey.Encrypted {
+			if err := entity.DecryptPrivateKeys(privateKeyPassphrase); err != nil {
+				return "", fmt.Errorf("error unlocking private key: %v", err)
+			}
+		}
+	}
+
+	block, err := armor.Decode(bytes.NewReader(armored))
+	if err != nil {
+		return "", fmt.Errorf("error decoding PGP message: %v", err)
+	}
+
+	md, err := openpgp.ReadMessage(block.Body, entityList, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("error decrypting PGP message: %v", err)
+	}
+
+	plaintext, err := ioutil.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return "", fmt.Errorf("error reading decrypted data: %v", err)
+	}
+
+	return string(plaintext), nil
 }
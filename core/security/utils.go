@@ -3,12 +3,14 @@ package security
 import (
 	"bytes"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
 	"io/ioutil"
-	"math/rand"
+	"math/big"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -20,9 +22,7 @@ import (
 
 // EncryptData шифрует данные с использованием PGP.
 func EncryptData(data string) (string, error) {
-	// Загрузка публичного ключа из файла
-	publicKeyFile := "public_key.asc"
-
+	// Загрузка публичного ключа из файла (путь задается через ConfigurePGP)
 	// Проверка существования файла с публичным ключом
 	if _, err := os.Stat(publicKeyFile); os.IsNotExist(err) {
 		fmt.Println("Отсутствует ключ, генерируем его")
@@ -50,12 +50,16 @@ func EncryptData(data string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	defer plaintext.Close()
 
 	if _, err := plaintext.Write([]byte(data)); err != nil {
 		return "", err
 	}
 
+	// Поток шифрования закрывается до armor-писателя, иначе сообщение обрезается
+	if err := plaintext.Close(); err != nil {
+		return "", err
+	}
+
 	if err := writer.Close(); err != nil {
 		return "", err
 	}
@@ -70,17 +74,44 @@ func GenerateHMAC(data string, secret []byte) string {
 	return base64.StdEncoding.EncodeToString(h.Sum(nil))
 }
 
-func GenerateCVV() string {
-	rand.Seed(time.Now().UnixNano())
-	cvv := rand.Intn(900) + 100 // Генерируем случайное число от 100 до 999
-	return fmt.Sprintf("%d", cvv)
+// FingerprintPAN вычисляет детерминированный отпечаток номера карты (HMAC-SHA256).
+// Зашифрованный PGP номер нельзя использовать для поиска, поэтому карты
+// ищутся по отпечатку, который хранится рядом с шифротекстом
+func FingerprintPAN(number string, secret []byte) string {
+	return GenerateHMAC(strings.Join(strings.Fields(number), ""), secret)
+}
+
+// randomInt возвращает криптографически стойкое случайное число в диапазоне [0, max)
+func randomInt(max int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return 0, err
+	}
+	return int(n.Int64()), nil
+}
+
+func GenerateCVV() (string, error) {
+	cvv, err := randomInt(900)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d", cvv+100), nil // Число от 100 до 999
+}
+
+// GenerateAuthCode генерирует шестизначный код авторизации операции по карте
+func GenerateAuthCode() (string, error) {
+	code, err := randomInt(1000000)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", code), nil
 }
 
-func GenerateExpiryDate() string {
-	currentTime := time.Now()
+// GenerateExpiryDate возвращает срок действия карты через validityYears лет от даты выпуска
+func GenerateExpiryDate(validityYears int, now time.Time) string {
 	// Получаем текущий месяц и год
-	month := int(currentTime.Month())
-	year := currentTime.Year() + 5 // Добавляем 5 лет к текущему году
+	month := int(now.Month())
+	year := now.Year() + validityYears
 
 	// Форматируем как MM/YY, где MM - 01-12, YY - последние две цифры года
 	return fmt.Sprintf("%02d/%02d", month, year%100) // Форматируем как MM/YY
@@ -95,14 +126,42 @@ func HashCVV(cvv string) (string, error) {
 	return string(hashedCVV), nil
 }
 
-// Генерация валидного номера карты
-func GenerateCardNumber(prefix string, length int) string {
+// pinRegex формат PIN-кода карты
+var pinRegex = regexp.MustCompile(`^\d{4}$`)
+
+// ValidatePIN проверяет формат PIN-кода
+func ValidatePIN(pin string) bool {
+	return pinRegex.MatchString(pin)
+}
+
+// HashPIN хеширует PIN-код карты с использованием bcrypt (соль генерируется автоматически).
+// Исходное значение PIN нигде не сохраняется и не логируется.
+func HashPIN(pin string) (string, error) {
+	hashedPIN, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPIN), nil
+}
+
+// CheckPIN сравнивает PIN-код с сохраненным хешем
+func CheckPIN(hashedPIN, pin string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hashedPIN), []byte(pin)) == nil
+}
+
+// Генерация валидного номера карты.
+// Цифры номера генерируются криптографически стойким генератором
+func GenerateCardNumber(prefix string, length int) (string, error) {
 	var cardNumber strings.Builder
 
 	// Если префикс не указан, выбираем случайный
 	if prefix == "" {
 		prefixes := []string{"4", "5", "34", "37", "6"}
-		prefix = prefixes[rand.Intn(len(prefixes))]
+		i, err := randomInt(len(prefixes))
+		if err != nil {
+			return "", err
+		}
+		prefix = prefixes[i]
 	}
 
 	// Записываем префикс
@@ -110,14 +169,18 @@ func GenerateCardNumber(prefix string, length int) string {
 
 	// Генерируем случайные цифры до нужной длины (минус контрольная цифра)
 	for cardNumber.Len() < length-1 {
-		cardNumber.WriteString(strconv.Itoa(rand.Intn(10)))
+		digit, err := randomInt(10)
+		if err != nil {
+			return "", err
+		}
+		cardNumber.WriteString(strconv.Itoa(digit))
 	}
 
 	// Вычисляем контрольную цифру
 	checkDigit := calculateLuhnCheckDigit(cardNumber.String())
 	cardNumber.WriteString(strconv.Itoa(checkDigit))
 
-	return cardNumber.String()
+	return cardNumber.String(), nil
 }
 
 // Проверка валидности номера карты по алгоритму Луна
@@ -27,18 +27,24 @@ type AccountService interface {
 type accountService struct {
 	accountRepo     dbaccess.AccountRepository
 	transactionRepo dbaccess.TransactionRepository
+	creditLineRepo  dbaccess.CreditLineRepository
 }
 
-func AccountServiceInstance(accountRepo dbaccess.AccountRepository, transactionRepo dbaccess.TransactionRepository) AccountService {
+func AccountServiceInstance(accountRepo dbaccess.AccountRepository, transactionRepo dbaccess.TransactionRepository, creditLineRepo dbaccess.CreditLineRepository) AccountService {
 	return &accountService{
 		accountRepo:     accountRepo,
 		transactionRepo: transactionRepo,
+		creditLineRepo:  creditLineRepo,
 	}
 }
 
 // Базовые операции со счетом
 func (s *accountService) CreateAccount(account *domain.Account, userID uint) error {
 	fmt.Println("Creating account for user ID:", userID)
+	// Кредитный счет открывается только вместе с кредитной линией
+	if account.IsCredit() || account.CreditLimit != 0 {
+		return domain.ErrInvalidAccountType
+	}
 	account.UserID = userID
 	if err := s.accountRepo.Create(context.Background(), account); err != nil {
 		return fmt.Errorf("could not create account: %v", err)
@@ -122,8 +128,11 @@ func (s *accountService) Withdraw(accountID uint, amount float64, description st
 		return fmt.Errorf("failed to get account: %v", err)
 	}
 
-	if account.Balance < amount {
-		return errors.New("insufficient funds")
+	if err := account.CanWithdraw(amount); err != nil {
+		return err
+	}
+	if err := checkCreditLine(s.creditLineRepo, account); err != nil {
+		return err
 	}
 
 	// Создаем транзакцию
@@ -167,8 +176,11 @@ func (s *accountService) Transfer(fromAccountID, toAccountID uint, amount float6
 		return fmt.Errorf("failed to get destination account: %v", err)
 	}
 
-	if fromAccount.Balance < amount {
-		return errors.New("insufficient funds")
+	if err := fromAccount.CanWithdraw(amount); err != nil {
+		return err
+	}
+	if err := checkCreditLine(s.creditLineRepo, fromAccount); err != nil {
+		return err
 	}
 
 	// Создаем транзакцию
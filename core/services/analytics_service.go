@@ -11,17 +11,20 @@ type AnalyticsService struct {
 	transactionRepo dbaccess.TransactionRepository
 	accountRepo     dbaccess.AccountRepository
 	creditRepo      dbaccess.CreditRepository
+	clock           domain.Clock
 }
 
 func NewAnalyticsService(
 	transactionRepo dbaccess.TransactionRepository,
 	accountRepo dbaccess.AccountRepository,
 	creditRepo dbaccess.CreditRepository,
+	clock domain.Clock,
 ) *AnalyticsService {
 	return &AnalyticsService{
 		transactionRepo: transactionRepo,
 		accountRepo:     accountRepo,
 		creditRepo:      creditRepo,
+		clock:           clock,
 	}
 }
 
@@ -64,7 +67,7 @@ func (s *AnalyticsService) GetBalanceForecast(accountID uint, months int) (*doma
 		MonthlyForecast: make([]domain.MonthlyForecast, months),
 	}
 
-	now := time.Now()
+	now := s.clock.Now()
 	for i := 0; i < months; i++ {
 		monthStart := time.Date(now.Year(), now.Month()+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
 		monthEnd := monthStart.AddDate(0, 1, -1)
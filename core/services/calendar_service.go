@@ -0,0 +1,97 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+type CalendarService interface {
+	GetYear(year int) ([]domain.CalendarDay, error)
+	SetDay(date string, req *payloads.CalendarDayRequest) (*domain.CalendarDay, error)
+	DeleteDay(date string) error
+	Import(req *payloads.ImportCalendarRequest) (int, error)
+}
+
+type calendarService struct {
+	calendarRepo dbaccess.CalendarRepository
+	clock        domain.Clock
+}
+
+func CalendarServiceInstance(calendarRepo dbaccess.CalendarRepository, clock domain.Clock) CalendarService {
+	return &calendarService{calendarRepo: calendarRepo, clock: clock}
+}
+
+// GetYear возвращает праздники и рабочие выходные за год, без указания года - за текущий
+func (s *calendarService) GetYear(year int) ([]domain.CalendarDay, error) {
+	if year == 0 {
+		year = s.clock.Now().Year()
+	}
+	return s.calendarRepo.GetByYear(context.Background(), year)
+}
+
+// SetDay отмечает дату праздником или рабочим выходным. Новые даты учитываются
+// в графиках, которые строятся после изменения: сохраненные графики не пересчитываются
+func (s *calendarService) SetDay(date string, req *payloads.CalendarDayRequest) (*domain.CalendarDay, error) {
+	day := &domain.CalendarDay{
+		Date:        date,
+		Type:        domain.CalendarDayType(req.Type),
+		Description: req.Description,
+	}
+	if err := day.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.calendarRepo.Upsert(context.Background(), day); err != nil {
+		return nil, fmt.Errorf("failed to save calendar day: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"date": day.Date,
+		"type": day.Type,
+	}).Info("Изменен производственный календарь")
+	return day, nil
+}
+
+// DeleteDay удаляет дату из календаря: день снова считается обычным
+func (s *calendarService) DeleteDay(date string) error {
+	if err := s.calendarRepo.DeleteByDate(context.Background(), date); err != nil {
+		return err
+	}
+	logrus.WithField("date", date).Info("Дата удалена из производственного календаря")
+	return nil
+}
+
+// Import загружает дни календаря: новые даты добавляются, существующие обновляются.
+// Возвращает количество загруженных дней
+func (s *calendarService) Import(req *payloads.ImportCalendarRequest) (int, error) {
+	days := make([]domain.CalendarDay, 0, len(req.Days))
+	seen := make(map[string]bool, len(req.Days))
+	for _, item := range req.Days {
+		day := domain.CalendarDay{
+			Date:        item.Date,
+			Type:        domain.CalendarDayType(item.Type),
+			Description: item.Description,
+		}
+		if err := day.Validate(); err != nil {
+			return 0, fmt.Errorf("%s: %w", item.Date, err)
+		}
+		if seen[day.Date] {
+			return 0, fmt.Errorf("%w: duplicate date %s", domain.ErrInvalidCalendarDay, day.Date)
+		}
+		seen[day.Date] = true
+		days = append(days, day)
+	}
+
+	for i := range days {
+		if err := s.calendarRepo.Upsert(context.Background(), &days[i]); err != nil {
+			return i, fmt.Errorf("failed to save calendar day %s: %w", days[i].Date, err)
+		}
+	}
+
+	logrus.WithField("days", len(days)).Info("Загружен производственный календарь")
+	return len(days), nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/iso8583"
+	"FinanceGolang/core/payloads"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Код валюты рубля по ISO 4217 (поле 49)
+const currencyCodeRUB = "643"
+
+// Признаки операции в интернете: код условий (поле 25) и способ ввода (поле 22)
+const (
+	posConditionECOM = "59"
+	posEntryModeECOM = "81"
+)
+
+// Код обработки (первые две цифры поля 3) для снятия наличных
+const processingCodeCash = "01"
+
+var (
+	errGatewayCurrency    = errors.New("unsupported transaction currency")
+	errGatewayUnknownCard = errors.New("unknown card")
+)
+
+// cardGateway обрабатывает сообщения ISO 8583 от процессингового центра:
+// 0100 - холд, 0200 - списание (подтверждение холда, если передан код авторизации),
+// 0400/0420 - отмена операции по RRN
+type cardGateway struct {
+	cardService CardService
+}
+
+// CardGatewayInstance создает обработчик сообщений ISO 8583 для операций по картам
+func CardGatewayInstance(cardService CardService) iso8583.Handler {
+	return &cardGateway{cardService: cardService}
+}
+
+// Handle обрабатывает запрос и формирует ответ с кодом результата (поле 39)
+func (g *cardGateway) Handle(request *iso8583.Message) *iso8583.Message {
+	response := iso8583.NewResponse(request)
+
+	var (
+		transaction *domain.Transaction
+		err         error
+	)
+	switch request.MTI {
+	case iso8583.MTIAuthorizationRequest:
+		transaction, err = g.authorize(request, true)
+	case iso8583.MTIFinancialRequest:
+		if request.Has(iso8583.FieldAuthCode) {
+			transaction, err = g.capture(request)
+		} else {
+			transaction, err = g.authorize(request, false)
+		}
+	case iso8583.MTIReversalRequest, iso8583.MTIReversalAdvice:
+		transaction, err = g.reverse(request)
+	default:
+		response.Set(iso8583.FieldResponseCode, iso8583.ResponseInvalidTransaction)
+		return response
+	}
+
+	code := responseCode(err)
+	response.Set(iso8583.FieldResponseCode, code)
+	if err == nil && transaction.AuthCode != "" {
+		response.Set(iso8583.FieldAuthCode, transaction.AuthCode)
+	}
+
+	entry := logrus.WithFields(logrus.Fields{
+		"mti":           request.MTI,
+		"rrn":           request.Get(iso8583.FieldRRN),
+		"response_code": code,
+	})
+	if err != nil {
+		entry.WithError(err).Info("Операция ISO 8583 отклонена")
+	} else {
+		entry.Info("Операция ISO 8583 одобрена")
+	}
+	return response
+}
+
+// authorize проводит холд (0100) или списание без холда (0200)
+func (g *cardGateway) authorize(request *iso8583.Message, hold bool) (*domain.Transaction, error) {
+	card, err := g.findCard(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err := authorizationRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	if hold {
+		return g.cardService.AuthorizeHold(card.ID, req)
+	}
+	return g.cardService.Authorize(card.ID, req)
+}
+
+// capture подтверждает холд с кодом авторизации из поля 38
+func (g *cardGateway) capture(request *iso8583.Message) (*domain.Transaction, error) {
+	card, err := g.cardByNumber(request)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(request.Get(iso8583.FieldAmount))
+	if err != nil {
+		return nil, err
+	}
+	return g.cardService.CaptureHold(card.ID, request.Get(iso8583.FieldAuthCode), amount)
+}
+
+// reverse отменяет операцию с RRN из поля 37
+func (g *cardGateway) reverse(request *iso8583.Message) (*domain.Transaction, error) {
+	card, err := g.cardByNumber(request)
+	if err != nil {
+		return nil, err
+	}
+	return g.cardService.Reverse(card.ID, request.Get(iso8583.FieldRRN))
+}
+
+// findCard находит карту по номеру и сверяет срок действия из поля 14 (YYMM)
+func (g *cardGateway) findCard(request *iso8583.Message) (*domain.Card, error) {
+	card, err := g.cardByNumber(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiry := request.Get(iso8583.FieldExpiryDate); expiry != "" && card.ExpiresAt != nil {
+		if len(expiry) != 4 {
+			return nil, domain.ErrInvalidExpiryDate
+		}
+		expiresAt, err := domain.ExpiryMoment(expiry[2:] + "/" + expiry[:2])
+		if err != nil || !expiresAt.Equal(*card.ExpiresAt) {
+			return nil, domain.ErrInvalidExpiryDate
+		}
+	}
+	return card, nil
+}
+
+// cardByNumber находит карту по номеру из поля 2. Ненайденная карта отличается
+// от ненайденной исходной операции: у них разные коды ответа
+func (g *cardGateway) cardByNumber(request *iso8583.Message) (*domain.Card, error) {
+	card, err := g.cardService.GetCardByNumber(request.Get(iso8583.FieldPAN))
+	if errors.Is(err, dbaccess.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %v", errGatewayUnknownCard, err)
+	}
+	return card, err
+}
+
+// authorizationRequest переводит поля сообщения в запрос на авторизацию операции по карте
+func authorizationRequest(request *iso8583.Message) (*payloads.CardAuthorizationRequest, error) {
+	if currency := request.Get(iso8583.FieldCurrencyCode); currency != "" && currency != currencyCodeRUB {
+		return nil, errGatewayCurrency
+	}
+	amount, err := parseAmount(request.Get(iso8583.FieldAmount))
+	if err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, domain.ErrInvalidAmount
+	}
+
+	req := &payloads.CardAuthorizationRequest{
+		Amount:   amount,
+		Channel:  string(requestChannel(request)),
+		Merchant: strings.TrimSpace(request.Get(iso8583.FieldMerchantName)),
+		MCC:      request.Get(iso8583.FieldMCC),
+		Country:  request.Get(iso8583.FieldCountryCode),
+		RRN:      request.Get(iso8583.FieldRRN),
+	}
+	if pinBlock := request.Get(iso8583.FieldPINData); pinBlock != "" {
+		pin, err := iso8583.DecodePINBlock(pinBlock, request.Get(iso8583.FieldPAN))
+		if err != nil {
+			return nil, domain.ErrWrongPIN
+		}
+		req.PIN = pin
+	}
+	return req, nil
+}
+
+// requestChannel определяет канал операции по коду обработки и условиям в точке продаж
+func requestChannel(request *iso8583.Message) domain.CardChannel {
+	if strings.HasPrefix(request.Get(iso8583.FieldProcessingCode), processingCodeCash) {
+		return domain.CardChannelATM
+	}
+	if request.Get(iso8583.FieldPOSConditionCode) == posConditionECOM ||
+		strings.HasPrefix(request.Get(iso8583.FieldPOSEntryMode), posEntryModeECOM) {
+		return domain.CardChannelECOM
+	}
+	return domain.CardChannelPOS
+}
+
+// parseAmount переводит сумму из минимальных единиц валюты (копеек) в рубли
+func parseAmount(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	minor, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || minor < 0 {
+		return 0, domain.ErrInvalidAmount
+	}
+	return float64(minor) / 100, nil
+}
+
+// responseCode переводит результат обработки в код ответа ISO 8583
+func responseCode(err error) string {
+	switch {
+	case err == nil:
+		return iso8583.ResponseApproved
+	case errors.Is(err, errGatewayUnknownCard):
+		return iso8583.ResponseInvalidCardNumber
+	case errors.Is(err, dbaccess.ErrNotFound):
+		// Для отмены и подтверждения не найдена исходная операция
+		return iso8583.ResponseRecordNotFound
+	case errors.Is(err, domain.ErrCardExpired), errors.Is(err, domain.ErrInvalidExpiryDate):
+		return iso8583.ResponseExpiredCard
+	case errors.Is(err, domain.ErrWrongPIN), errors.Is(err, domain.ErrPINNotSet),
+		errors.Is(err, domain.ErrPINRequired):
+		return iso8583.ResponseIncorrectPIN
+	case errors.Is(err, domain.ErrCardBlocked):
+		return iso8583.ResponsePINTriesExceeded
+	case errors.Is(err, domain.ErrCardInactive), errors.Is(err, domain.ErrCardClosed),
+		errors.Is(err, domain.ErrCardNotYetValid), errors.Is(err, domain.ErrCardAlreadyUsed),
+		errors.Is(err, domain.ErrCreditLineBlocked):
+		return iso8583.ResponseRestrictedCard
+	case errors.Is(err, domain.ErrInsufficientFunds):
+		return iso8583.ResponseInsufficientFunds
+	case errors.Is(err, domain.ErrCardLimitExceeded), errors.Is(err, domain.ErrCardCapExceeded),
+		errors.Is(err, domain.ErrMCCLimitExceeded):
+		return iso8583.ResponseExceedsLimit
+	case errors.Is(err, domain.ErrMerchantNotAllowed), errors.Is(err, domain.ErrChannelNotAllowed),
+		errors.Is(err, domain.ErrOnlinePaymentsDisabled), errors.Is(err, domain.ErrForeignTransactionsDisabled),
+		errors.Is(err, domain.ErrMerchantCategoryBlocked), errors.Is(err, domain.ErrOutsideTimeWindow):
+		return iso8583.ResponseNotPermitted
+	case errors.Is(err, domain.ErrDuplicateTransaction):
+		return iso8583.ResponseDuplicateTransaction
+	case errors.Is(err, domain.ErrInvalidAmount), errors.Is(err, domain.ErrCaptureExceedsHold):
+		return iso8583.ResponseInvalidAmount
+	case errors.Is(err, domain.ErrTransactionNotPending), errors.Is(err, domain.ErrTransactionExpired),
+		errors.Is(err, errGatewayCurrency):
+		return iso8583.ResponseInvalidTransaction
+	default:
+		return iso8583.ResponseDoNotHonor
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"context"
+	"fmt"
+)
+
+type CardProductService interface {
+	GetActiveProducts() ([]domain.CardProduct, error)
+	GetAllProducts() ([]domain.CardProduct, error)
+	CreateProduct(product *domain.CardProduct) error
+	UpdateProduct(id uint, product *domain.CardProduct) (*domain.CardProduct, error)
+}
+
+type cardProductService struct {
+	cardProductRepo dbaccess.CardProductRepository
+}
+
+func CardProductServiceInstance(cardProductRepo dbaccess.CardProductRepository) CardProductService {
+	return &cardProductService{cardProductRepo: cardProductRepo}
+}
+
+// GetActiveProducts возвращает продукты, доступные клиентам для выпуска карт
+func (s *cardProductService) GetActiveProducts() ([]domain.CardProduct, error) {
+	return s.cardProductRepo.GetActive(context.Background())
+}
+
+// GetAllProducts возвращает все продукты, включая отключенные
+func (s *cardProductService) GetAllProducts() ([]domain.CardProduct, error) {
+	return s.cardProductRepo.List(context.Background(), 0, -1)
+}
+
+// CreateProduct создает новый карточный продукт
+func (s *cardProductService) CreateProduct(product *domain.CardProduct) error {
+	if err := product.Validate(); err != nil {
+		return err
+	}
+	if err := s.cardProductRepo.Create(context.Background(), product); err != nil {
+		return fmt.Errorf("failed to create card product: %w", err)
+	}
+	return s.applyDefault(product)
+}
+
+// UpdateProduct изменяет настройки продукта. Уже выпущенные карты не затрагиваются
+func (s *cardProductService) UpdateProduct(id uint, update *domain.CardProduct) (*domain.CardProduct, error) {
+	product, err := s.cardProductRepo.GetByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Name = update.Name
+	product.Brand = update.Brand
+	product.BINPrefix = update.BINPrefix
+	product.PANLength = update.PANLength
+	product.ValidityYears = update.ValidityYears
+	product.DailyLimit = update.DailyLimit
+	product.MonthlyLimit = update.MonthlyLimit
+	product.IsActive = update.IsActive
+	product.IsDefault = update.IsDefault
+
+	if err := product.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.cardProductRepo.Update(context.Background(), product); err != nil {
+		return nil, fmt.Errorf("failed to update card product: %w", err)
+	}
+	if err := s.applyDefault(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// applyDefault оставляет признак продукта по умолчанию только у одного продукта
+func (s *cardProductService) applyDefault(product *domain.CardProduct) error {
+	if !product.IsDefault {
+		return nil
+	}
+	if err := s.cardProductRepo.ClearDefault(context.Background(), product.ID); err != nil {
+		return fmt.Errorf("failed to update default card product: %w", err)
+	}
+	return nil
+}
@@ -6,34 +6,167 @@ import (
 	"FinanceGolang/core/payloads"
 	"FinanceGolang/core/security"
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
 type CardService interface {
 	CreateCard(card *domain.Card, userID uint) (*payloads.UnsecureCard, error)
+	CreateVirtualCard(req *payloads.CreateVirtualCardRequest, userID uint) (*payloads.UnsecureCard, error)
+	CloseExpiredVirtualCards() (int, error)
+	RenewExpiringCards() ([]CardRenewal, error)
+	CloseExpiredCards() (int, error)
 	GetCardByID(id uint) (*domain.Card, error)
 	GetUserCards(userID uint) ([]domain.Card, error)
+	GetCardByNumber(number string) (*domain.Card, error)
+
+	// Управление PIN-кодом
+	SetPIN(cardID, userID uint, pin string) error
+	ChangePIN(cardID, userID uint, oldPIN, newPIN string) error
+	VerifyPIN(cardID uint, pin string) error
+	UnblockCard(cardID, userID uint, password, ipAddress string) error
+
+	// Ограничения по операциям
+	UpdateControls(cardID, userID uint, controls *domain.CardControls) (*domain.Card, error)
+
+	// Показ реквизитов карты
+	RevealCard(cardID, userID uint, password, ipAddress string) (*payloads.RevealedCard, error)
+
+	// Операции по карте
+	Authorize(cardID uint, req *payloads.CardAuthorizationRequest) (*domain.Transaction, error)
+	AuthorizeByNumber(number string, req *payloads.CardAuthorizationRequest) (*domain.Transaction, error)
+	AuthorizeHold(cardID uint, req *payloads.CardAuthorizationRequest) (*domain.Transaction, error)
+	CaptureHold(cardID uint, authCode string, amount float64) (*domain.Transaction, error)
+	Reverse(cardID uint, rrn string) (*domain.Transaction, error)
+	ReleaseExpiredHolds() (int, error)
+}
+
+// CardRenewal результат перевыпуска карты
+type CardRenewal struct {
+	OldCard domain.Card
+	NewCard domain.Card
 }
 
+// maxCardNumberAttempts количество попыток сгенерировать номер карты, не совпадающий с выпущенными ранее
+const maxCardNumberAttempts = 5
+
+// errInvalidGeneratedCard ошибка выпуска карты с некорректными сгенерированными реквизитами
+var errInvalidGeneratedCard = errors.New("failed to generate valid card details")
+
 type cardService struct {
-	cardRepo    dbaccess.CardRepository
-	accountRepo dbaccess.AccountRepository
-	publicKey   string
-	hmacSecret  []byte
+	cardRepo        dbaccess.CardRepository
+	accountRepo     dbaccess.AccountRepository
+	transactionRepo dbaccess.TransactionRepository
+	userRepo        dbaccess.UserRepository
+	auditRepo       dbaccess.AuditRepository
+	cardProductRepo dbaccess.CardProductRepository
+	creditLineRepo  dbaccess.CreditLineRepository
+	publicKey       string
+	hmacSecret      []byte
+	clock           domain.Clock
 }
 
-func CardServiceInstance(cardRepo dbaccess.CardRepository, accountRepo dbaccess.AccountRepository, publicKey string, hmacSecret []byte) CardService {
+func CardServiceInstance(
+	cardRepo dbaccess.CardRepository,
+	accountRepo dbaccess.AccountRepository,
+	transactionRepo dbaccess.TransactionRepository,
+	userRepo dbaccess.UserRepository,
+	auditRepo dbaccess.AuditRepository,
+	cardProductRepo dbaccess.CardProductRepository,
+	creditLineRepo dbaccess.CreditLineRepository,
+	publicKey string,
+	hmacSecret []byte,
+	clock domain.Clock,
+) CardService {
 	return &cardService{
-		cardRepo:    cardRepo,
-		accountRepo: accountRepo,
-		publicKey:   publicKey,
-		hmacSecret:  hmacSecret,
+		cardRepo:        cardRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		userRepo:        userRepo,
+		auditRepo:       auditRepo,
+		cardProductRepo: cardProductRepo,
+		creditLineRepo:  creditLineRepo,
+		publicKey:       publicKey,
+		hmacSecret:      hmacSecret,
+		clock:           clock,
 	}
 }
 
+// CreateCard выпускает пластиковую карту по выбранному продукту (или по продукту по умолчанию)
 func (s *cardService) CreateCard(card *domain.Card, userID uint) (*payloads.UnsecureCard, error) {
+	product, err := s.resolveProduct(card.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	card.Kind = domain.CardKindPhysical
+	card.ApplyProductDefaults(product)
+	return s.issueCard(card, product, userID, security.GenerateExpiryDate(product.ValidityYears, s.clock.Now()))
+}
+
+// resolveProduct получает продукт для выпуска карты. Если продукт не указан, используется продукт по умолчанию
+func (s *cardService) resolveProduct(productID uint) (*domain.CardProduct, error) {
+	var product *domain.CardProduct
+	var err error
+	if productID == 0 {
+		product, err = s.cardProductRepo.GetDefault(context.Background())
+	} else {
+		product, err = s.cardProductRepo.GetByID(context.Background(), productID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card product: %w", err)
+	}
+	if !product.IsActive {
+		return nil, domain.ErrCardProductInactive
+	}
+	return product, nil
+}
+
+// CreateVirtualCard выпускает виртуальную карту с лимитом суммы и сроком действия.
+// Срок действия на карте совпадает с месяцем окончания окна действия
+func (s *cardService) CreateVirtualCard(req *payloads.CreateVirtualCardRequest, userID uint) (*payloads.UnsecureCard, error) {
+	now := s.clock.Now()
+
+	validFrom := now
+	if req.ValidFrom != nil {
+		validFrom = *req.ValidFrom
+	}
+	validUntil := validFrom.Add(domain.DefaultVirtualCardValidity)
+	if req.ValidUntil != nil {
+		validUntil = *req.ValidUntil
+	}
+
+	product, err := s.resolveProduct(req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	card := &domain.Card{
+		AccountID:    req.AccountID,
+		Kind:         domain.CardKindVirtual,
+		VirtualMode:  domain.VirtualCardMode(req.Mode),
+		AmountCap:    req.AmountCap,
+		MerchantLock: strings.TrimSpace(req.Merchant),
+		ValidFrom:    &validFrom,
+		ValidUntil:   &validUntil,
+	}
+	if err := card.ValidateVirtual(); err != nil {
+		return nil, err
+	}
+	if !validUntil.After(now) {
+		return nil, fmt.Errorf("%w: validity window is already over", domain.ErrInvalidVirtualCard)
+	}
+	card.ApplyProductDefaults(product)
+
+	return s.issueCard(card, product, userID, validUntil.Format("01/06"))
+}
+
+// issueCard генерирует реквизиты по настройкам продукта, шифрует и сохраняет карту
+func (s *cardService) issueCard(card *domain.Card, product *domain.CardProduct, userID uint, expiryDate string) (*payloads.UnsecureCard, error) {
 	// Проверяем, что счет принадлежит пользователю
 	accounts, err := s.accountRepo.GetByUserID(context.Background(), userID)
 	if err != nil {
@@ -57,27 +190,35 @@ func (s *cardService) CreateCard(card *domain.Card, userID uint) (*payloads.Unse
 	var unsecureCard payloads.UnsecureCard
 
 	// Генерируем данные карты
-	unsecureCard.Number = security.GenerateCardNumber("4", 16)
-	unsecureCard.CVV = security.GenerateCVV()
-	unsecureCard.ExpiryDate = security.GenerateExpiryDate()
+	number, numberHash, err := s.generateUniqueNumber(product)
+	if err != nil {
+		return nil, err
+	}
+	cvv, err := security.GenerateCVV()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CVV: %v", err)
+	}
+	unsecureCard.Number = number
+	unsecureCard.CVV = cvv
+	unsecureCard.ExpiryDate = expiryDate
 	unsecureCard.AccountName = accountName
 	unsecureCard.AccountID = card.AccountID
 
 	// Проверка валидности номера карты
 	if !security.IsValidCardNumber(unsecureCard.Number) {
-		return nil, fmt.Errorf("invalid card number: %s", unsecureCard.Number)
+		return nil, rejectGeneratedCard(unsecureCard.Number, "номер не проходит проверку Луна")
 	}
 
 	// Дополнительная валидация формата номера карты
-	cardRegex := regexp.MustCompile(`^[0-9]{16}$`)
+	cardRegex := regexp.MustCompile(`^[0-9]{13,19}$`)
 	if !cardRegex.MatchString(unsecureCard.Number) {
-		return nil, fmt.Errorf("invalid card number format: %s", unsecureCard.Number)
+		return nil, rejectGeneratedCard(unsecureCard.Number, "неверный формат номера")
 	}
 
 	// Валидация CVV
 	cvvRegex := regexp.MustCompile(`^[0-9]{3}$`)
 	if !cvvRegex.MatchString(unsecureCard.CVV) {
-		return nil, fmt.Errorf("invalid CVV format: %s", unsecureCard.CVV)
+		return nil, rejectGeneratedCard(unsecureCard.Number, "неверный формат CVV")
 	}
 
 	// Валидация даты истечения срока действия
@@ -97,9 +238,8 @@ func (s *cardService) CreateCard(card *domain.Card, userID uint) (*payloads.Unse
 	}
 
 	// Валидация карты
-	if err := tempCard.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid card data: %v (number: %s, expiry: %s, cvv: %s, account_id: %d)",
-			err, unsecureCard.Number, unsecureCard.ExpiryDate, unsecureCard.CVV, unsecureCard.AccountID)
+	if err := tempCard.Validate(s.clock.Now()); err != nil {
+		return nil, rejectGeneratedCard(unsecureCard.Number, err.Error())
 	}
 
 	// Шифрование номера карты и срока действия
@@ -121,8 +261,17 @@ func (s *cardService) CreateCard(card *domain.Card, userID uint) (*payloads.Unse
 	// Сохранение зашифрованных данных в структуру
 	card.Number = encryptedNumber
 	card.ExpiryDate = encryptedExpiryDate
+	card.NumberHash = numberHash
 	card.CVV = hashedCVV
-	card.CreatedAt = time.Now()
+	card.SetDisplayData(unsecureCard.Number)
+	if card.Brand != product.Brand {
+		return nil, fmt.Errorf("%w: card number does not belong to %s", domain.ErrInvalidCardProduct, product.Brand)
+	}
+	card.ProductID = product.ID
+	if err := card.SetExpiry(unsecureCard.ExpiryDate); err != nil {
+		return nil, err
+	}
+	card.CreatedAt = s.clock.Now()
 	card.UserID = userID
 	card.AccountID = unsecureCard.AccountID
 	card.IsActive = true
@@ -138,11 +287,50 @@ func (s *cardService) CreateCard(card *domain.Card, userID uint) (*payloads.Unse
 	return &unsecureCard, nil
 }
 
+// rejectGeneratedCard сообщает об ошибке выпуска без реквизитов карты: номер и CVV не должны
+// попадать ни в ответ, ни в логи, поэтому в лог пишется только маскированный номер
+func rejectGeneratedCard(number, reason string) error {
+	masked := &domain.Card{}
+	masked.SetDisplayData(number)
+	logrus.WithFields(logrus.Fields{
+		"card":   masked.MaskNumber(),
+		"reason": reason,
+	}).Error("Сгенерированы некорректные реквизиты карты")
+	return errInvalidGeneratedCard
+}
+
+// generateUniqueNumber генерирует номер карты в BIN-диапазоне продукта,
+// отпечаток которого еще не встречается среди выпущенных карт
+func (s *cardService) generateUniqueNumber(product *domain.CardProduct) (string, string, error) {
+	for i := 0; i < maxCardNumberAttempts; i++ {
+		number, err := security.GenerateCardNumber(product.BINPrefix, product.PANLength)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to generate card number: %v", err)
+		}
+		numberHash := security.FingerprintPAN(number, s.hmacSecret)
+
+		exists, err := s.cardRepo.ExistsByNumberHash(context.Background(), numberHash)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to check card number uniqueness: %v", err)
+		}
+		if !exists {
+			return number, numberHash, nil
+		}
+	}
+	return "", "", fmt.Errorf("failed to generate unique card number after %d attempts", maxCardNumberAttempts)
+}
+
+// GetCardByNumber находит карту по открытому номеру через его отпечаток
+func (s *cardService) GetCardByNumber(number string) (*domain.Card, error) {
+	return s.cardRepo.GetByNumberHash(context.Background(), security.FingerprintPAN(number, s.hmacSecret))
+}
+
 func (s *cardService) GetCardByID(id uint) (*domain.Card, error) {
 	card, err := s.cardRepo.GetByID(context.Background(), id)
 	if err != nil {
 		return nil, err
 	}
+	s.ensureDisplayData(card)
 	return card, nil
 }
 
@@ -166,8 +354,775 @@ func (s *cardService) GetUserCards(userID uint) ([]domain.Card, error) {
 		if err != nil {
 			return nil, err
 		}
+		for i := range cards {
+			s.ensureDisplayData(&cards[i])
+		}
 		allCards = append(allCards, cards...)
 	}
 
 	return allCards, nil
 }
+
+// ensureDisplayData заполняет последние 4 цифры, платежную систему, отпечаток
+// номера и срок действия в открытом виде для карт, выпущенных до появления этих полей
+func (s *cardService) ensureDisplayData(card *domain.Card) {
+	if card.LastFour != "" && card.NumberHash != "" && card.ExpiresAt != nil {
+		return
+	}
+
+	if card.LastFour == "" || card.NumberHash == "" {
+		number, err := security.DecryptData(card.Number)
+		if err != nil {
+			logrus.WithError(err).WithField("card_id", card.ID).Warn("Не удалось расшифровать номер карты")
+			return
+		}
+		card.SetDisplayData(number)
+		card.NumberHash = security.FingerprintPAN(number, s.hmacSecret)
+		if err := s.cardRepo.UpdateDisplayData(context.Background(), card.ID, card.LastFour, card.Brand, card.NumberHash); err != nil {
+			logrus.WithError(err).WithField("card_id", card.ID).Warn("Не удалось сохранить данные карты")
+		}
+	}
+
+	if card.ExpiresAt == nil {
+		if err := s.decryptExpiry(card); err != nil {
+			logrus.WithError(err).WithField("card_id", card.ID).Warn("Не удалось расшифровать срок действия карты")
+			return
+		}
+		if err := s.cardRepo.UpdateExpiresAt(context.Background(), card.ID, *card.ExpiresAt); err != nil {
+			logrus.WithError(err).WithField("card_id", card.ID).Warn("Не удалось сохранить срок действия карты")
+		}
+	}
+}
+
+// RevealCard возвращает полный номер и срок действия карты после повторной
+// проверки пароля владельца, для перевыпущенной карты при первом показе - и CVV.
+// Каждое обращение фиксируется в журнале аудита
+func (s *cardService) RevealCard(cardID, userID uint, password, ipAddress string) (*payloads.RevealedCard, error) {
+	card, err := s.getOwnedCard(cardID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOwnerPassword(card.ID, userID, password, ipAddress, domain.AuditActionCardRevealDenied); err != nil {
+		return nil, err
+	}
+
+	number, err := security.DecryptData(card.Number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt card number: %v", err)
+	}
+	expiryDate, err := security.DecryptData(card.ExpiryDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt expiry date: %v", err)
+	}
+
+	if err := s.auditRepo.Create(context.Background(), &domain.AuditLog{
+		UserID:     userID,
+		Action:     domain.AuditActionCardReveal,
+		EntityType: "card",
+		EntityID:   card.ID,
+		IPAddress:  ipAddress,
+	}); err != nil {
+		// Без записи в журнал аудита реквизиты не выдаются
+		return nil, fmt.Errorf("failed to write audit log: %v", err)
+	}
+
+	revealed := &payloads.RevealedCard{
+		ID:         card.ID,
+		Number:     number,
+		ExpiryDate: expiryDate,
+		Brand:      domain.DetectCardBrand(number),
+	}
+	if card.PendingCVV != "" {
+		cvv, err := s.takePendingCVV(card)
+		if err != nil {
+			return nil, err
+		}
+		revealed.CVV = cvv
+	}
+	return revealed, nil
+}
+
+// takePendingCVV расшифровывает CVV перевыпущенной карты и стирает его. Пустая строка -
+// CVV уже выдан параллельным запросом
+func (s *cardService) takePendingCVV(card *domain.Card) (string, error) {
+	cvv, err := security.DecryptData(card.PendingCVV)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt CVV: %v", err)
+	}
+	cleared, err := s.cardRepo.ClearPendingCVV(context.Background(), card.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to clear CVV: %v", err)
+	}
+	if !cleared {
+		return "", nil
+	}
+	card.PendingCVV = ""
+	return cvv, nil
+}
+
+// audit записывает событие в журнал аудита, ошибки записи только логируются
+func (s *cardService) audit(userID uint, action domain.AuditAction, cardID uint, ipAddress, details string) {
+	if err := s.auditRepo.Create(context.Background(), &domain.AuditLog{
+		UserID:     userID,
+		Action:     action,
+		EntityType: "card",
+		EntityID:   cardID,
+		IPAddress:  ipAddress,
+		Details:    details,
+	}); err != nil {
+		logrus.WithError(err).WithField("card_id", cardID).Error("Ошибка записи в журнал аудита")
+	}
+}
+
+// checkOwnerPassword повторно проверяет пароль владельца карты. Неверные вводы
+// фиксируются в журнале аудита с действием denied; после MaxCardPasswordAttempts
+// неверных вводов за CardPasswordLockout проверка отклоняется без сверки пароля
+func (s *cardService) checkOwnerPassword(cardID, userID uint, password, ipAddress string, denied domain.AuditAction) error {
+	failures, err := s.auditRepo.CountSince(context.Background(), userID,
+		[]domain.AuditAction{domain.AuditActionCardRevealDenied, domain.AuditActionCardUnblockDenied},
+		s.clock.Now().Add(-domain.CardPasswordLockout))
+	if err != nil {
+		return fmt.Errorf("failed to count password attempts: %v", err)
+	}
+	if failures >= domain.MaxCardPasswordAttempts {
+		logrus.WithFields(logrus.Fields{
+			"user_id": userID,
+			"card_id": cardID,
+		}).Warn("Превышено количество неверных вводов пароля для операций с картой")
+		return domain.ErrTooManyAttempts
+	}
+
+	user, err := s.userRepo.GetByID(context.Background(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+	if err := user.CheckPassword(password); err != nil {
+		s.audit(userID, denied, cardID, ipAddress, "wrong password")
+		return err
+	}
+	return nil
+}
+
+// getOwnedCard получает карту и проверяет, что она принадлежит пользователю
+func (s *cardService) getOwnedCard(cardID, userID uint) (*domain.Card, error) {
+	card, err := s.cardRepo.GetByID(context.Background(), cardID)
+	if err != nil {
+		return nil, err
+	}
+	if card.UserID != userID {
+		return nil, domain.ErrCardNotOwned
+	}
+	return card, nil
+}
+
+// UpdateControls сохраняет ограничения по операциям, заданные владельцем карты
+func (s *cardService) UpdateControls(cardID, userID uint, controls *domain.CardControls) (*domain.Card, error) {
+	card, err := s.getOwnedCard(cardID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := controls.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.cardRepo.UpdateControls(context.Background(), card.ID, *controls); err != nil {
+		return nil, fmt.Errorf("failed to save card controls: %v", err)
+	}
+	card.Controls = *controls
+	return card, nil
+}
+
+// SetPIN устанавливает PIN-код для карты, у которой он еще не задан
+func (s *cardService) SetPIN(cardID, userID uint, pin string) error {
+	card, err := s.getOwnedCard(cardID, userID)
+	if err != nil {
+		return err
+	}
+	if card.HasPIN() {
+		return domain.ErrPINAlreadySet
+	}
+	return s.savePIN(card, pin)
+}
+
+// ChangePIN меняет PIN-код карты после проверки текущего
+func (s *cardService) ChangePIN(cardID, userID uint, oldPIN, newPIN string) error {
+	card, err := s.getOwnedCard(cardID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkPIN(card, oldPIN); err != nil {
+		return err
+	}
+	return s.savePIN(card, newPIN)
+}
+
+// VerifyPIN проверяет PIN-код карты.
+// Три неверных ввода подряд блокируют карту
+func (s *cardService) VerifyPIN(cardID uint, pin string) error {
+	card, err := s.cardRepo.GetByID(context.Background(), cardID)
+	if err != nil {
+		return err
+	}
+	return s.checkPIN(card, pin)
+}
+
+// UnblockCard снимает блокировку карты по действию владельца и сбрасывает счетчик попыток
+// ввода PIN. Блокировку защищает от подбора PIN, поэтому снять ее можно только после
+// повторной проверки пароля владельца; каждое обращение фиксируется в журнале аудита
+func (s *cardService) UnblockCard(cardID, userID uint, password, ipAddress string) error {
+	card, err := s.getOwnedCard(cardID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkOwnerPassword(card.ID, userID, password, ipAddress, domain.AuditActionCardUnblockDenied); err != nil {
+		return err
+	}
+
+	if err := s.cardRepo.UpdatePINAttempts(context.Background(), card.ID, 0, false); err != nil {
+		return fmt.Errorf("failed to unblock card: %v", err)
+	}
+	s.audit(userID, domain.AuditActionCardUnblock, card.ID, ipAddress, "")
+	return nil
+}
+
+// savePIN проверяет формат и сохраняет хеш PIN-кода
+func (s *cardService) savePIN(card *domain.Card, pin string) error {
+	if !security.ValidatePIN(pin) {
+		return domain.ErrInvalidPIN
+	}
+	hashedPIN, err := security.HashPIN(pin)
+	if err != nil {
+		return fmt.Errorf("failed to hash PIN: %v", err)
+	}
+	if err := s.cardRepo.UpdatePIN(context.Background(), card.ID, hashedPIN); err != nil {
+		return fmt.Errorf("failed to save PIN: %v", err)
+	}
+	return nil
+}
+
+// checkPIN сверяет PIN-код с хешем и ведет учет неверных попыток
+func (s *cardService) checkPIN(card *domain.Card, pin string) error {
+	if card.IsBlocked {
+		return domain.ErrCardBlocked
+	}
+	if !card.HasPIN() {
+		return domain.ErrPINNotSet
+	}
+
+	if !security.CheckPIN(card.PinHash, pin) {
+		attempts, blocked, err := s.cardRepo.RegisterWrongPIN(context.Background(), card.ID, domain.MaxPINAttempts)
+		if err != nil {
+			return fmt.Errorf("failed to update PIN attempts: %v", err)
+		}
+		card.PinAttempts, card.IsBlocked = attempts, blocked
+		if blocked {
+			return domain.ErrCardBlocked
+		}
+		return domain.ErrWrongPIN
+	}
+
+	reset, err := s.cardRepo.ResetPINAttempts(context.Background(), card.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reset PIN attempts: %v", err)
+	}
+	if !reset {
+		card.IsBlocked = true
+		return domain.ErrCardBlocked
+	}
+	card.PinAttempts = 0
+	return nil
+}
+
+// Authorize проводит операцию по карте: оплату в торговой точке, в интернете
+// или снятие наличных в банкомате. Для банкомата PIN обязателен, для оплаты
+// в торговой точке проверяется, если передан
+func (s *cardService) Authorize(cardID uint, req *payloads.CardAuthorizationRequest) (*domain.Transaction, error) {
+	return s.authorize(cardID, req, false)
+}
+
+// AuthorizeHold проверяет операцию так же, как Authorize, но не списывает сумму
+// окончательно, а блокирует ее на счете (холд) до подтверждения списания или отмены
+func (s *cardService) AuthorizeHold(cardID uint, req *payloads.CardAuthorizationRequest) (*domain.Transaction, error) {
+	return s.authorize(cardID, req, true)
+}
+
+// authorize проводит проверки операции и списывает или блокирует сумму на счете
+func (s *cardService) authorize(cardID uint, req *payloads.CardAuthorizationRequest, hold bool) (*domain.Transaction, error) {
+	card, err := s.cardRepo.GetByID(context.Background(), cardID)
+	if err != nil {
+		return nil, err
+	}
+	if err := card.CanTransact(); err != nil {
+		return nil, err
+	}
+
+	// Повторный запрос с тем же RRN отклоняется до проверки PIN, чтобы не расходовать попытки ввода
+	if req.RRN != "" {
+		if _, err := s.transactionRepo.GetByCardAndRRN(context.Background(), card.ID, req.RRN); err == nil {
+			return nil, domain.ErrDuplicateTransaction
+		} else if !errors.Is(err, dbaccess.ErrNotFound) {
+			return nil, fmt.Errorf("failed to check duplicate transaction: %v", err)
+		}
+	}
+
+	channel := domain.CardChannel(req.Channel)
+	if channel == "" {
+		channel = domain.CardChannelPOS
+	}
+
+	now := s.clock.Now()
+	if card.IsVirtual() {
+		if err := s.checkVirtualCard(card, channel, req, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// Ограничения владельца проверяются до PIN, чтобы отказ не расходовал попытки ввода
+	if err := card.Controls.Check(channel, req.MCC, req.Country, now); err != nil {
+		return nil, err
+	}
+
+	switch channel {
+	case domain.CardChannelATM:
+		if req.PIN == "" {
+			return nil, domain.ErrPINRequired
+		}
+		if err := s.checkPIN(card, req.PIN); err != nil {
+			return nil, err
+		}
+	case domain.CardChannelPOS:
+		if req.PIN != "" {
+			if err := s.checkPIN(card, req.PIN); err != nil {
+				return nil, err
+			}
+		}
+	case domain.CardChannelECOM:
+	default:
+		return nil, fmt.Errorf("unsupported channel: %s", req.Channel)
+	}
+
+	if err := s.checkLimits(card, req.Amount, req.MCC, now); err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountRepo.GetByID(context.Background(), card.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %v", err)
+	}
+	// По кредитному счету операция проходит в пределах доступного лимита
+	if err := account.CanWithdraw(req.Amount); err != nil {
+		return nil, err
+	}
+	if err := checkCreditLine(s.creditLineRepo, account); err != nil {
+		return nil, err
+	}
+
+	authCode, err := security.GenerateAuthCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate authorization code: %v", err)
+	}
+
+	transactionType := domain.TransactionTypePayment
+	description := req.Description
+	if channel == domain.CardChannelATM {
+		transactionType = domain.TransactionTypeWithdrawal
+		if description == "" {
+			description = "Снятие наличных в банкомате"
+		}
+	} else if description == "" {
+		description = fmt.Sprintf("Оплата картой: %s", req.Merchant)
+	}
+
+	transaction := &domain.Transaction{
+		Type:          transactionType,
+		FromAccountID: account.ID,
+		CardID:        card.ID,
+		MCC:           req.MCC,
+		Country:       req.Country,
+		Merchant:      strings.TrimSpace(req.Merchant),
+		RRN:           req.RRN,
+		AuthCode:      authCode,
+		Amount:        req.Amount,
+		Description:   description,
+		Status:        domain.TransactionStatusCompleted,
+	}
+	if hold {
+		transaction.Status = domain.TransactionStatusPending
+		transaction.ExpiresAt = now.Add(domain.CardHoldTTL)
+	} else {
+		transaction.CompletedAt = &now
+	}
+	// Остаток счета и лимит виртуальной карты повторно проверяются вместе со списанием:
+	// параллельная операция могла израсходовать их после проверки выше
+	if err := s.cardRepo.Debit(context.Background(), card, transaction, now); err != nil {
+		if errors.Is(err, domain.ErrCardAlreadyUsed) || errors.Is(err, domain.ErrCardCapExceeded) ||
+			errors.Is(err, domain.ErrInsufficientFunds) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to debit card operation: %v", err)
+	}
+
+	if card.IsVirtual() {
+		s.lockMerchant(card, req.Merchant)
+		if !hold && card.CloseAfterCapture() {
+			s.closeCard(card, domain.CardCloseReasonUsed, now)
+		}
+	}
+
+	return transaction, nil
+}
+
+// CaptureHold подтверждает списание по холду. Сумма списания может быть меньше
+// заблокированной (например, при частичной отгрузке), разница возвращается на счет
+func (s *cardService) CaptureHold(cardID uint, authCode string, amount float64) (*domain.Transaction, error) {
+	transaction, err := s.transactionRepo.GetByCardAndAuthCode(context.Background(), cardID, authCode)
+	if err != nil {
+		return nil, err
+	}
+	if !transaction.IsHold() {
+		return nil, domain.ErrTransactionNotPending
+	}
+	if transaction.IsExpired(s.clock.Now()) {
+		return nil, domain.ErrTransactionExpired
+	}
+	if amount <= 0 {
+		amount = transaction.Amount
+	}
+	if amount > transaction.Amount {
+		return nil, domain.ErrCaptureExceedsHold
+	}
+
+	// Разница возвращается вместе со сменой статуса: параллельная отмена холда
+	// (Reverse или истечение срока) вернет сумму на счет только один раз
+	if err := s.transactionRepo.Capture(context.Background(), transaction, amount, s.clock.Now()); err != nil {
+		if errors.Is(err, domain.ErrTransactionNotPending) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to capture hold: %v", err)
+	}
+
+	// Одноразовая виртуальная карта закрывается после подтверждения списания
+	card, err := s.cardRepo.GetByID(context.Background(), cardID)
+	if err != nil {
+		logrus.WithError(err).WithField("card_id", cardID).Error("Ошибка получения карты после списания")
+		return transaction, nil
+	}
+	if card.CloseAfterCapture() && !card.IsClosed() {
+		s.closeCard(card, domain.CardCloseReasonUsed, s.clock.Now())
+	}
+
+	return transaction, nil
+}
+
+// Reverse отменяет операцию по карте с указанным RRN и возвращает сумму на счет.
+// Повторная отмена уже отмененной операции не считается ошибкой
+func (s *cardService) Reverse(cardID uint, rrn string) (*domain.Transaction, error) {
+	transaction, err := s.transactionRepo.GetByCardAndRRN(context.Background(), cardID, rrn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch transaction.Status {
+	case domain.TransactionStatusCancelled:
+		return transaction, nil
+	case domain.TransactionStatusPending, domain.TransactionStatusCompleted:
+	default:
+		return nil, domain.ErrTransactionNotPending
+	}
+
+	released, err := s.transactionRepo.Release(context.Background(), transaction,
+		domain.TransactionStatusPending, domain.TransactionStatusCompleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release transaction: %v", err)
+	}
+	if !released {
+		// Статус успел измениться: повторная отмена по-прежнему не ошибка
+		current, err := s.transactionRepo.GetByID(context.Background(), transaction.ID)
+		if err != nil {
+			return nil, err
+		}
+		if current.Status != domain.TransactionStatusCancelled {
+			return nil, domain.ErrTransactionNotPending
+		}
+		return current, nil
+	}
+	return transaction, nil
+}
+
+// ReleaseExpiredHolds снимает холды, не подтвержденные до истечения срока.
+// Возвращает количество снятых холдов
+func (s *cardService) ReleaseExpiredHolds() (int, error) {
+	holds, err := s.transactionRepo.GetExpiredHolds(context.Background(), s.clock.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired holds: %v", err)
+	}
+
+	released := 0
+	for i := range holds {
+		// Холд, подтвержденный или отмененный после выборки, не снимается
+		ok, err := s.transactionRepo.Release(context.Background(), &holds[i], domain.TransactionStatusPending)
+		if err != nil {
+			logrus.WithError(err).WithField("transaction_id", holds[i].ID).Error("Ошибка снятия холда")
+			continue
+		}
+		if ok {
+			released++
+		}
+	}
+	return released, nil
+}
+
+// checkVirtualCard проверяет операцию по виртуальной карте.
+// Карта с истекшим сроком действия закрывается при первой попытке операции
+func (s *cardService) checkVirtualCard(card *domain.Card, channel domain.CardChannel, req *payloads.CardAuthorizationRequest, now time.Time) error {
+	if channel == domain.CardChannelATM {
+		return domain.ErrChannelNotAllowed
+	}
+
+	if card.IsValidityWindowOver(now) {
+		s.closeCard(card, domain.CardCloseReasonExpired, now)
+		return domain.ErrCardExpired
+	}
+
+	spent, err := s.cardRepo.GetTotalUsage(context.Background(), card.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get card usage: %v", err)
+	}
+	return card.CheckVirtualUsage(req.Amount, req.Merchant, spent, now)
+}
+
+// lockMerchant закрепляет карту за продавцом при первой операции
+func (s *cardService) lockMerchant(card *domain.Card, merchant string) {
+	if card.VirtualMode != domain.VirtualCardModeMerchantLocked || card.MerchantLock != "" {
+		return
+	}
+	if err := s.cardRepo.UpdateMerchantLock(context.Background(), card.ID, strings.TrimSpace(merchant)); err != nil {
+		logrus.WithError(err).WithField("card_id", card.ID).Error("Ошибка закрепления карты за продавцом")
+	}
+}
+
+// closeCard закрывает карту, ошибки сохранения только логируются:
+// к этому моменту операция по карте уже проведена или отклонена
+func (s *cardService) closeCard(card *domain.Card, reason string, now time.Time) {
+	card.Close(reason, now)
+	if err := s.cardRepo.Close(context.Background(), card.ID, reason, now); err != nil {
+		logrus.WithError(err).WithField("card_id", card.ID).Error("Ошибка закрытия карты")
+	}
+}
+
+// CloseExpiredVirtualCards закрывает виртуальные карты с истекшим сроком действия.
+// Возвращает количество закрытых карт
+func (s *cardService) CloseExpiredVirtualCards() (int, error) {
+	now := s.clock.Now()
+	cards, err := s.cardRepo.GetExpiredVirtualCards(context.Background(), now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired virtual cards: %v", err)
+	}
+
+	closed := 0
+	for _, card := range cards {
+		if err := s.cardRepo.Close(context.Background(), card.ID, domain.CardCloseReasonExpired, now); err != nil {
+			logrus.WithError(err).WithField("card_id", card.ID).Error("Ошибка закрытия карты")
+			continue
+		}
+		closed++
+	}
+	return closed, nil
+}
+
+// checkMCCLimit проверяет дневной и месячный лимиты по коду категории продавца
+func (s *cardService) checkMCCLimit(card *domain.Card, limit *domain.MCCLimit, amount float64, now time.Time) error {
+	if limit.DailyLimit > 0 {
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		usage, err := s.cardRepo.GetMCCUsage(context.Background(), card.ID, limit.MCC, startOfDay, startOfDay.Add(24*time.Hour))
+		if err != nil {
+			return fmt.Errorf("failed to get merchant category usage: %v", err)
+		}
+		if usage+amount > limit.DailyLimit {
+			return fmt.Errorf("%w: MCC %s daily limit %.2f", domain.ErrMCCLimitExceeded, limit.MCC, limit.DailyLimit)
+		}
+	}
+
+	if limit.MonthlyLimit > 0 {
+		startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		usage, err := s.cardRepo.GetMCCUsage(context.Background(), card.ID, limit.MCC, startOfMonth, startOfMonth.AddDate(0, 1, 0))
+		if err != nil {
+			return fmt.Errorf("failed to get merchant category usage: %v", err)
+		}
+		if usage+amount > limit.MonthlyLimit {
+			return fmt.Errorf("%w: MCC %s monthly limit %.2f", domain.ErrMCCLimitExceeded, limit.MCC, limit.MonthlyLimit)
+		}
+	}
+
+	return nil
+}
+
+// AuthorizeByNumber проводит операцию по карте, найденной по открытому номеру (PAN)
+func (s *cardService) AuthorizeByNumber(number string, req *payloads.CardAuthorizationRequest) (*domain.Transaction, error) {
+	card, err := s.GetCardByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	return s.Authorize(card.ID, req)
+}
+
+// checkLimits проверяет дневной и месячный лимиты карты и лимиты по категории продавца
+func (s *cardService) checkLimits(card *domain.Card, amount float64, mcc string, now time.Time) error {
+	dailyUsage, err := s.cardRepo.GetDailyUsage(context.Background(), card.ID, now)
+	if err != nil {
+		return fmt.Errorf("failed to get daily usage: %v", err)
+	}
+	if card.DailyLimit > 0 && dailyUsage+amount > card.DailyLimit {
+		return fmt.Errorf("%w: daily limit %.2f", domain.ErrCardLimitExceeded, card.DailyLimit)
+	}
+
+	monthlyUsage, err := s.cardRepo.GetMonthlyUsage(context.Background(), card.ID, now.Year(), now.Month())
+	if err != nil {
+		return fmt.Errorf("failed to get monthly usage: %v", err)
+	}
+	if card.MonthlyLimit > 0 && monthlyUsage+amount > card.MonthlyLimit {
+		return fmt.Errorf("%w: monthly limit %.2f", domain.ErrCardLimitExceeded, card.MonthlyLimit)
+	}
+
+	if limit := card.Controls.LimitFor(mcc); limit != nil {
+		if err := s.checkMCCLimit(card, limit, amount, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decryptExpiry расшифровывает срок действия карты и сохраняет его в открытом виде в модели
+func (s *cardService) decryptExpiry(card *domain.Card) error {
+	expiryDate, err := security.DecryptData(card.ExpiryDate)
+	if err != nil {
+		return err
+	}
+	return card.SetExpiry(expiryDate)
+}
+
+// backfillExpiry заполняет срок действия в открытом виде для карт, выпущенных до появления поля
+func (s *cardService) backfillExpiry() {
+	cards, err := s.cardRepo.GetCardsWithoutExpiry(context.Background())
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка получения карт без срока действия")
+		return
+	}
+
+	for i := range cards {
+		card := &cards[i]
+		if err := s.decryptExpiry(card); err != nil {
+			logrus.WithError(err).WithField("card_id", card.ID).Warn("Не удалось расшифровать срок действия карты")
+			continue
+		}
+		if err := s.cardRepo.UpdateExpiresAt(context.Background(), card.ID, *card.ExpiresAt); err != nil {
+			logrus.WithError(err).WithField("card_id", card.ID).Error("Ошибка сохранения срока действия карты")
+		}
+	}
+}
+
+// RenewExpiringCards перевыпускает пластиковые карты за CardRenewalLeadDays дней до окончания
+// срока действия. Карты перевыпускаются только для активных клиентов с действующим счетом.
+// Новая карта выпускается по тому же продукту, получает лимиты старой карты и связывается с ней
+func (s *cardService) RenewExpiringCards() ([]CardRenewal, error) {
+	s.backfillExpiry()
+
+	now := s.clock.Now()
+	cards, err := s.cardRepo.GetCardsDueForRenewal(context.Background(), now.AddDate(0, 0, domain.CardRenewalLeadDays))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cards due for renewal: %v", err)
+	}
+
+	var renewals []CardRenewal
+	for i := range cards {
+		oldCard := &cards[i]
+		s.ensureDisplayData(oldCard)
+		if !oldCard.NeedsRenewal(now) {
+			continue
+		}
+
+		newCard, err := s.renewCard(oldCard)
+		if err != nil {
+			logrus.WithError(err).WithField("card_id", oldCard.ID).Error("Ошибка перевыпуска карты")
+			continue
+		}
+		if newCard == nil {
+			continue
+		}
+
+		renewals = append(renewals, CardRenewal{OldCard: *oldCard, NewCard: *newCard})
+	}
+
+	return renewals, nil
+}
+
+// renewCard выпускает карту на замену старой. Возвращает nil, если клиент
+// или счет неактивны и карта перевыпуску не подлежит
+func (s *cardService) renewCard(oldCard *domain.Card) (*domain.Card, error) {
+	user, err := s.userRepo.GetByID(context.Background(), oldCard.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+	account, err := s.accountRepo.GetByID(context.Background(), oldCard.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %v", err)
+	}
+	if !user.IsActive || !account.IsActive {
+		return nil, nil
+	}
+
+	// Если продукт старой карты больше не выпускается, используется продукт по умолчанию
+	product, err := s.resolveProduct(oldCard.ProductID)
+	if err != nil {
+		if product, err = s.resolveProduct(0); err != nil {
+			return nil, err
+		}
+	}
+
+	newCard := &domain.Card{Kind: domain.CardKindPhysical}
+	newCard.InheritFrom(oldCard)
+	issued, err := s.issueCard(newCard, product, oldCard.UserID, security.GenerateExpiryDate(product.ValidityYears, s.clock.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	// CVV хранится только в виде хеша, поэтому до первого показа реквизитов
+	// владельцу он сохраняется зашифрованным
+	encryptedCVV, err := security.EncryptData(issued.CVV)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt CVV: %v", err)
+	}
+	if err := s.cardRepo.SetPendingCVV(context.Background(), newCard.ID, encryptedCVV); err != nil {
+		return nil, fmt.Errorf("failed to save CVV: %v", err)
+	}
+	newCard.PendingCVV = encryptedCVV
+
+	if err := s.cardRepo.SetReplacedBy(context.Background(), oldCard.ID, newCard.ID); err != nil {
+		return nil, fmt.Errorf("failed to link renewed card: %v", err)
+	}
+	oldCard.ReplacedByID = &newCard.ID
+
+	return newCard, nil
+}
+
+// CloseExpiredCards закрывает карты в день окончания срока действия.
+// Возвращает количество закрытых карт
+func (s *cardService) CloseExpiredCards() (int, error) {
+	now := s.clock.Now()
+	cards, err := s.cardRepo.GetExpiredCards(context.Background(), now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired cards: %v", err)
+	}
+
+	closed := 0
+	for _, card := range cards {
+		if err := s.cardRepo.Close(context.Background(), card.ID, domain.CardCloseReasonExpired, now); err != nil {
+			logrus.WithError(err).WithField("card_id", card.ID).Error("Ошибка закрытия карты")
+			continue
+		}
+		closed++
+	}
+	return closed, nil
+}
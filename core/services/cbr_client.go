@@ -0,0 +1,89 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultCBRURL адрес веб-сервиса DailyInfo Банка России
+const DefaultCBRURL = "https://www.cbr.ru/DailyInfoWebServ/DailyInfo.asmx"
+
+// cbrNamespace пространство имен методов DailyInfo
+const cbrNamespace = "http://web.cbr.ru/"
+
+// cbrRetryDelay пауза после неудачного обращения к ЦБ РФ: пока она не истекла,
+// запросы обслуживаются сохраненными значениями без ожидания таймаута
+const cbrRetryDelay = time.Minute
+
+// cbrClient клиент веб-сервиса DailyInfo по SOAP 1.2
+type cbrClient struct {
+	url    string
+	client *http.Client
+}
+
+// newCBRClient создает клиент DailyInfo. Сертификат сервера проверяется,
+// запрос ограничен таймаутом
+func newCBRClient(url string, timeout time.Duration) *cbrClient {
+	if url == "" {
+		url = DefaultCBRURL
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &cbrClient{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// call отправляет метод request в SOAP-конверте и возвращает тело ответа.
+// Имя метода берется из XMLName запроса
+func (c *cbrClient) call(ctx context.Context, request interface{}) ([]byte, error) {
+	// Формируем SOAP-запрос
+	var root = struct {
+		XMLName xml.Name `xml:"soap12:Envelope"`
+		Xsi     string   `xml:"xmlns:xsi,attr"`
+		Xsd     string   `xml:"xmlns:xsd,attr"`
+		Soap12  string   `xml:"xmlns:soap12,attr"`
+		Body    struct {
+			XMLName xml.Name `xml:"soap12:Body"`
+			Request interface{}
+		}
+	}{
+		Xsi:    "http://www.w3.org/2001/XMLSchema-instance",
+		Xsd:    "http://www.w3.org/2001/XMLSchema",
+		Soap12: "http://www.w3.org/2003/05/soap-envelope",
+	}
+	root.Body.Request = request
+
+	out, err := xml.MarshalIndent(&root, " ", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании запроса к ЦБ РФ: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewBuffer(out))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании запроса к ЦБ РФ: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/soap+xml; charset=utf-8")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при запросе к ЦБ РФ: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ЦБ РФ вернул статус %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении ответа: %v", err)
+	}
+	return body, nil
+}
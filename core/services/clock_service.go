@@ -0,0 +1,100 @@
+package services
+
+import (
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// clockStep на сколько за раз переводятся часы: после каждого шага выполняются
+// наступившие фоновые задачи, так что ни один операционный день не пропускается
+const clockStep = 24 * time.Hour
+
+type ClockService interface {
+	// State возвращает текущее время, операционный день и сдвиг часов
+	State() domain.ClockState
+	// Advance переводит смоделированные часы вперед и выполняет наступившие задачи
+	Advance(req *payloads.AdvanceClockRequest) (domain.ClockState, []domain.JobRun, error)
+	// Reset возвращает часы к системному времени
+	Reset() (domain.ClockState, error)
+}
+
+type clockService struct {
+	clock      domain.Clock
+	simulated  *domain.SimulatedClock
+	jobService JobService
+}
+
+// ClockServiceInstance создает сервис часов. simulated равен nil,
+// если моделирование времени выключено
+func ClockServiceInstance(clock domain.Clock, simulated *domain.SimulatedClock, jobService JobService) ClockService {
+	return &clockService{
+		clock:      clock,
+		simulated:  simulated,
+		jobService: jobService,
+	}
+}
+
+// State возвращает текущее время, операционный день и сдвиг часов
+func (s *clockService) State() domain.ClockState {
+	now := s.clock.Now()
+	state := domain.ClockState{
+		Now:          now,
+		BusinessDate: domain.BusinessDate(now),
+	}
+	if s.simulated != nil {
+		state.Simulated = true
+		state.Offset = s.simulated.Offset()
+	}
+	return state
+}
+
+// Advance переводит часы вперед по одному дню. После каждого шага выполняются
+// задачи, срок которых наступил, как если бы приложение работало все это время:
+// списание платежей, начисление неустойки, взыскание, вклады и карты
+func (s *clockService) Advance(req *payloads.AdvanceClockRequest) (domain.ClockState, []domain.JobRun, error) {
+	if s.simulated == nil {
+		return domain.ClockState{}, nil, domain.ErrClockSimulationDisabled
+	}
+	remaining := time.Duration(req.Days)*24*time.Hour + time.Duration(req.Hours)*time.Hour
+	if remaining <= 0 {
+		return domain.ClockState{}, nil, domain.ErrInvalidClockShift
+	}
+
+	runs := []domain.JobRun{}
+	for remaining > 0 {
+		step := min(remaining, clockStep)
+		if err := s.simulated.Advance(step); err != nil {
+			return domain.ClockState{}, nil, err
+		}
+		remaining -= step
+		runs = append(runs, s.jobService.RunDue()...)
+	}
+
+	state := s.State()
+	logrus.WithFields(logrus.Fields{
+		"days":          req.Days,
+		"hours":         req.Hours,
+		"now":           state.Now,
+		"business_date": state.BusinessDate.Format("2006-01-02"),
+		"runs":          len(runs),
+	}).Warn("Смоделированные часы переведены вперед")
+	return state, runs, nil
+}
+
+// Reset возвращает часы к системному времени. Сроки фоновых задач, назначенные
+// по смоделированному времени, пересчитываются от текущего момента. Операции,
+// проведенные при сдвинутых часах, остаются в базе как есть
+func (s *clockService) Reset() (domain.ClockState, error) {
+	if s.simulated == nil {
+		return domain.ClockState{}, domain.ErrClockSimulationDisabled
+	}
+	s.simulated.Reset()
+	if err := s.jobService.Register(); err != nil {
+		return domain.ClockState{}, err
+	}
+	logrus.Warn("Смоделированные часы возвращены к системному времени")
+	return s.State(), nil
+}
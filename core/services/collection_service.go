@@ -0,0 +1,285 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type CollectionService interface {
+	// Настройка стадий взыскания
+	GetStages() ([]domain.DunningStage, error)
+	CreateStage(stage *domain.DunningStage) error
+	UpdateStage(id uint, stage *domain.DunningStage) (*domain.DunningStage, error)
+
+	// Работа операторов с просроченной задолженностью
+	GetWorklist(query *payloads.CollectionWorklistQuery) ([]domain.CollectionCase, error)
+	GetCase(caseID uint) (*domain.CollectionCase, error)
+	PromiseToPay(caseID, operatorID uint, req *payloads.PromiseToPayRequest) (*domain.CollectionCase, error)
+
+	// Ежедневный проход по просроченным кредитам
+	ProcessDunning() (int, error)
+}
+
+type collectionService struct {
+	stageRepo       dbaccess.DunningStageRepository
+	caseRepo        dbaccess.CollectionCaseRepository
+	creditRepo      dbaccess.CreditRepository
+	userRepo        dbaccess.UserRepository
+	creditService   CreditService
+	externalService *ExternalService
+	clock           domain.Clock
+}
+
+func CollectionServiceInstance(
+	stageRepo dbaccess.DunningStageRepository,
+	caseRepo dbaccess.CollectionCaseRepository,
+	creditRepo dbaccess.CreditRepository,
+	userRepo dbaccess.UserRepository,
+	creditService CreditService,
+	externalService *ExternalService,
+	clock domain.Clock,
+) CollectionService {
+	return &collectionService{
+		stageRepo:       stageRepo,
+		caseRepo:        caseRepo,
+		creditRepo:      creditRepo,
+		userRepo:        userRepo,
+		creditService:   creditService,
+		externalService: externalService,
+		clock:           clock,
+	}
+}
+
+// GetStages возвращает все стадии взыскания в порядке нарастания просрочки
+func (s *collectionService) GetStages() ([]domain.DunningStage, error) {
+	return s.stageRepo.List(context.Background(), 0, -1)
+}
+
+// CreateStage создает стадию взыскания
+func (s *collectionService) CreateStage(stage *domain.DunningStage) error {
+	if err := stage.Validate(); err != nil {
+		return err
+	}
+	if err := s.stageRepo.Create(context.Background(), stage); err != nil {
+		return fmt.Errorf("failed to create dunning stage: %w", err)
+	}
+	return nil
+}
+
+// UpdateStage изменяет стадию. Код стадии не меняется, чтобы не терять связь
+// с уже выполненными действиями
+func (s *collectionService) UpdateStage(id uint, update *domain.DunningStage) (*domain.DunningStage, error) {
+	stage, err := s.stageRepo.GetByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	stage.Name = update.Name
+	stage.DaysPastDue = update.DaysPastDue
+	stage.Channel = update.Channel
+	stage.Subject = update.Subject
+	stage.Template = update.Template
+	stage.IsActive = update.IsActive
+
+	if err := stage.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.stageRepo.Update(context.Background(), stage); err != nil {
+		return nil, fmt.Errorf("failed to update dunning stage: %w", err)
+	}
+	return stage, nil
+}
+
+// GetWorklist возвращает рабочий список взыскания. По умолчанию — незакрытые дела
+func (s *collectionService) GetWorklist(query *payloads.CollectionWorklistQuery) ([]domain.CollectionCase, error) {
+	filter := domain.CollectionFilter{
+		Statuses:  []domain.CollectionStatus{domain.CollectionStatusOpen, domain.CollectionStatusCollections},
+		MinAmount: query.MinAmount,
+		MaxAmount: query.MaxAmount,
+	}
+	if query.Status != "" {
+		filter.Statuses = []domain.CollectionStatus{domain.CollectionStatus(query.Status)}
+	}
+	if err := filter.SetBucket(query.Bucket); err != nil {
+		return nil, err
+	}
+	return s.caseRepo.GetWorklist(context.Background(), filter)
+}
+
+// GetCase возвращает дело вместе с историей действий
+func (s *collectionService) GetCase(caseID uint) (*domain.CollectionCase, error) {
+	return s.caseRepo.GetByID(context.Background(), caseID)
+}
+
+// PromiseToPay фиксирует обещание оплаты: до указанной даты включительно
+// стадии взыскания по делу не выполняются
+func (s *collectionService) PromiseToPay(caseID, operatorID uint, req *payloads.PromiseToPayRequest) (*domain.CollectionCase, error) {
+	collectionCase, err := s.caseRepo.GetByID(context.Background(), caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", req.PromisedUntil, time.Local)
+	if err != nil {
+		return nil, domain.ErrInvalidPromiseToPay
+	}
+	until := date.AddDate(0, 0, 1).Add(-time.Second)
+
+	action, err := collectionCase.PromiseToPay(req.Amount, until, operatorID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if req.Comment != "" {
+		action.Content += ". " + req.Comment
+	}
+	if err := s.caseRepo.UpdateWithActions(context.Background(), collectionCase, []domain.DunningAction{*action}); err != nil {
+		return nil, fmt.Errorf("failed to save promise to pay: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"case_id":     collectionCase.ID,
+		"credit_id":   collectionCase.CreditID,
+		"operator_id": operatorID,
+		"amount":      collectionCase.PromisedAmount,
+		"until":       until,
+	}).Info("Зафиксировано обещание оплаты")
+	return s.caseRepo.GetByID(context.Background(), caseID)
+}
+
+// ProcessDunning проходит по просроченным кредитам: открывает дела, обновляет
+// просрочку и выполняет очередную стадию взыскания. Дела по кредитам, вышедшим
+// из просрочки, закрываются. Возвращает количество выполненных стадий
+func (s *collectionService) ProcessDunning() (int, error) {
+	stages, err := s.stageRepo.GetActive(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get dunning stages: %w", err)
+	}
+	credits, err := s.creditRepo.GetCreditsByStatus(context.Background(), domain.CreditStatusOverdue)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get overdue credits: %w", err)
+	}
+	openCases, err := s.caseRepo.GetOpen(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get collection cases: %w", err)
+	}
+
+	cases := make(map[uint]*domain.CollectionCase, len(openCases))
+	for i := range openCases {
+		cases[openCases[i].CreditID] = &openCases[i]
+	}
+
+	now := s.clock.Now()
+	executed := 0
+	for i := range credits {
+		credit := &credits[i]
+		collectionCase, ok := cases[credit.ID]
+		if !ok {
+			collectionCase = domain.NewCollectionCase(credit)
+			if err := s.caseRepo.Create(context.Background(), collectionCase); err != nil {
+				return executed, fmt.Errorf("failed to create collection case: %w", err)
+			}
+		}
+		delete(cases, credit.ID)
+
+		ran, err := s.processCase(collectionCase, credit, stages, now)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"case_id":   collectionCase.ID,
+				"credit_id": credit.ID,
+				"error":     err,
+			}).Error("Ошибка при обработке дела о просрочке")
+			continue
+		}
+		if ran {
+			executed++
+		}
+	}
+
+	// Оставшиеся открытые дела относятся к кредитам, просрочка по которым погашена
+	for _, collectionCase := range cases {
+		collectionCase.Close(now)
+		if err := s.caseRepo.Update(context.Background(), collectionCase); err != nil {
+			return executed, fmt.Errorf("failed to close collection case: %w", err)
+		}
+		logrus.WithFields(logrus.Fields{
+			"case_id":   collectionCase.ID,
+			"credit_id": collectionCase.CreditID,
+		}).Info("Дело о просрочке закрыто")
+	}
+
+	return executed, nil
+}
+
+// processCase обновляет дело по кредиту и выполняет очередную стадию, если она
+// достигнута и стадии не приостановлены обещанием оплаты
+func (s *collectionService) processCase(collectionCase *domain.CollectionCase, credit *domain.Credit, stages []domain.DunningStage, now time.Time) (bool, error) {
+	schedule, err := s.creditService.GetPaymentSchedule(credit.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get payment schedule: %w", err)
+	}
+
+	var actions []domain.DunningAction
+	if broken := collectionCase.Refresh(credit, domain.DaysPastDue(schedule, now), now); broken != nil {
+		actions = append(actions, *broken)
+	}
+
+	var stage *domain.DunningStage
+	if !collectionCase.Suspended(now) {
+		stage = collectionCase.NextStage(stages)
+	}
+	if stage != nil {
+		action, err := s.executeStage(collectionCase, credit, stage, now)
+		if err != nil {
+			return false, err
+		}
+		collectionCase.Advance(stage)
+		actions = append(actions, *action)
+	}
+
+	if err := s.caseRepo.UpdateWithActions(context.Background(), collectionCase, actions); err != nil {
+		return false, fmt.Errorf("failed to update collection case: %w", err)
+	}
+	if stage != nil {
+		logrus.WithFields(logrus.Fields{
+			"case_id":       collectionCase.ID,
+			"credit_id":     credit.ID,
+			"stage":         stage.Code,
+			"channel":       stage.Channel,
+			"days_past_due": collectionCase.DaysPastDue,
+		}).Info("Выполнена стадия взыскания")
+	}
+	return stage != nil, nil
+}
+
+// executeStage формирует сообщение стадии и отправляет его по каналу стадии.
+// Задачи менеджеру и передача во взыскание попадают в рабочий список операторов,
+// письма отправляются заемщику. Неотправленное письмо фиксируется как невыполненное
+// действие, стадия при этом считается пройденной
+func (s *collectionService) executeStage(collectionCase *domain.CollectionCase, credit *domain.Credit, stage *domain.DunningStage, now time.Time) (*domain.DunningAction, error) {
+	user, err := s.userRepo.GetByID(context.Background(), credit.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	action := domain.NewStageAction(collectionCase, stage, domain.DunningMessage{
+		Fio:           user.Fio,
+		CreditID:      credit.ID,
+		DaysPastDue:   collectionCase.DaysPastDue,
+		OverdueAmount: credit.OverdueAmount,
+		PenaltyAmount: credit.PenaltyAmount,
+		RemainingDebt: credit.RemainingDebt,
+		Date:          now.Format("02.01.2006"),
+	})
+	if action.Status == domain.DunningActionCompleted && stage.Channel == domain.DunningChannelEmail {
+		if err := s.externalService.SendEmail(user.Email, stage.Subject, action.Content); err != nil {
+			action.Fail(err)
+		}
+	}
+	return action, nil
+}
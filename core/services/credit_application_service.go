@@ -0,0 +1,326 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type CreditApplicationService interface {
+	// Действия клиента
+	Apply(userID uint, req *payloads.CreditApplicationRequest) (*domain.CreditApplication, error)
+	GetUserApplications(userID uint) ([]domain.CreditApplication, error)
+	GetUserApplication(applicationID, userID uint) (*domain.CreditApplication, error)
+
+	// Действия менеджера
+	GetApplications(status domain.CreditApplicationStatus) ([]domain.CreditApplication, error)
+	GetApplication(applicationID uint) (*domain.CreditApplication, error)
+	Approve(applicationID, managerID uint, comment string) (*domain.CreditApplication, error)
+	Reject(applicationID, managerID uint, comment string) (*domain.CreditApplication, error)
+}
+
+type creditApplicationService struct {
+	applicationRepo dbaccess.CreditApplicationRepository
+	creditRepo      dbaccess.CreditRepository
+	accountRepo     dbaccess.AccountRepository
+	transactionRepo dbaccess.TransactionRepository
+	creditService   CreditService
+	clock           domain.Clock
+}
+
+func CreditApplicationServiceInstance(
+	applicationRepo dbaccess.CreditApplicationRepository,
+	creditRepo dbaccess.CreditRepository,
+	accountRepo dbaccess.AccountRepository,
+	transactionRepo dbaccess.TransactionRepository,
+	creditService CreditService,
+	clock domain.Clock,
+) CreditApplicationService {
+	return &creditApplicationService{
+		applicationRepo: applicationRepo,
+		creditRepo:      creditRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		creditService:   creditService,
+		clock:           clock,
+	}
+}
+
+// Apply принимает заявку на кредит, проводит автоматический скоринг и при
+// автоматическом одобрении сразу выдает кредит
+func (s *creditApplicationService) Apply(userID uint, req *payloads.CreditApplicationRequest) (*domain.CreditApplication, error) {
+	account, err := s.accountRepo.GetByID(context.Background(), req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.UserID != userID {
+		return nil, domain.ErrAccountNotOwned
+	}
+
+	// Условия кредита рассчитываются по продукту и фиксируются в заявке
+	product, err := s.creditService.GetProduct(req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := s.creditService.CalculateCredit(product.ID, req.Amount, req.TermMonths, domain.RepaymentMethod(req.RepaymentMethod))
+	if err != nil {
+		return nil, err
+	}
+
+	description := req.Description
+	if description == "" {
+		description = product.Name
+	}
+	application, err := domain.NewCreditApplication(userID, req.AccountID, terms, req.DeclaredIncome, description)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := s.scoringInput(userID, application)
+	if err != nil {
+		return nil, err
+	}
+	if err := product.CheckEligibility(input); err != nil {
+		return nil, err
+	}
+	decision := application.ApplyScoring(domain.ScoreApplication(input), s.clock.Now())
+	if err := s.applicationRepo.CreateWithDecision(context.Background(), application, decision); err != nil {
+		return nil, fmt.Errorf("failed to create credit application: %w", err)
+	}
+
+	if application.Status == domain.ApplicationStatusApproved {
+		if err := s.disburse(application); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.applicationRepo.GetByID(context.Background(), application.ID)
+}
+
+// GetUserApplications возвращает заявки пользователя
+func (s *creditApplicationService) GetUserApplications(userID uint) ([]domain.CreditApplication, error) {
+	return s.applicationRepo.GetByUserID(context.Background(), userID)
+}
+
+// GetUserApplication возвращает заявку пользователя вместе с решениями
+func (s *creditApplicationService) GetUserApplication(applicationID, userID uint) (*domain.CreditApplication, error) {
+	application, err := s.applicationRepo.GetByID(context.Background(), applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.UserID != userID {
+		return nil, domain.ErrApplicationNotOwned
+	}
+	return application, nil
+}
+
+// GetApplications возвращает очередь заявок; по умолчанию — ожидающие решения менеджера
+func (s *creditApplicationService) GetApplications(status domain.CreditApplicationStatus) ([]domain.CreditApplication, error) {
+	if status == "" {
+		status = domain.ApplicationStatusManualReview
+	}
+	return s.applicationRepo.GetByStatus(context.Background(), status)
+}
+
+// GetApplication возвращает заявку вместе с решениями
+func (s *creditApplicationService) GetApplication(applicationID uint) (*domain.CreditApplication, error) {
+	return s.applicationRepo.GetByID(context.Background(), applicationID)
+}
+
+// Approve одобряет заявку на ручном рассмотрении и выдает кредит
+func (s *creditApplicationService) Approve(applicationID, managerID uint, comment string) (*domain.CreditApplication, error) {
+	return s.decide(applicationID, managerID, domain.DecisionApprove, comment)
+}
+
+// Reject отклоняет заявку на ручном рассмотрении
+func (s *creditApplicationService) Reject(applicationID, managerID uint, comment string) (*domain.CreditApplication, error) {
+	return s.decide(applicationID, managerID, domain.DecisionReject, comment)
+}
+
+// decide фиксирует решение менеджера. Заявка сначала выводится из очереди на рассмотрение,
+// и только потом выдается кредит, поэтому параллельные решения не выдадут его дважды.
+// Если выдать кредит не удалось, заявка возвращается на рассмотрение
+func (s *creditApplicationService) decide(applicationID, managerID uint, outcome domain.DecisionOutcome, comment string) (*domain.CreditApplication, error) {
+	application, err := s.applicationRepo.GetByID(context.Background(), applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	decision, err := application.Decide(outcome, managerID, comment, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applicationRepo.Decide(context.Background(), application, decision); err != nil {
+		if errors.Is(err, domain.ErrApplicationNotInReview) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save decision: %w", err)
+	}
+
+	if application.Status == domain.ApplicationStatusApproved {
+		credit, err := s.creditService.IssueCredit(application)
+		if err != nil {
+			s.returnToReview(application, managerID)
+			return nil, fmt.Errorf("failed to issue credit: %w", err)
+		}
+		application.CreditID = &credit.ID
+		if err := s.applicationRepo.Update(context.Background(), application); err != nil {
+			return nil, fmt.Errorf("failed to update credit application: %w", err)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"application_id": application.ID,
+		"manager_id":     managerID,
+		"outcome":        outcome,
+	}).Info("Решение менеджера по заявке на кредит")
+
+	return s.applicationRepo.GetByID(context.Background(), application.ID)
+}
+
+// returnToReview возвращает одобренную менеджером заявку на рассмотрение, если кредит
+// по ней выдать не удалось. Ошибки только логируются
+func (s *creditApplicationService) returnToReview(application *domain.CreditApplication, managerID uint) {
+	application.Status = domain.ApplicationStatusManualReview
+	application.DecidedAt = nil
+	decision := &domain.CreditDecision{
+		ApplicationID: application.ID,
+		Stage:         domain.DecisionStageManager,
+		Outcome:       domain.DecisionReview,
+		Score:         application.Score,
+		ActorID:       managerID,
+		Comment:       "Не удалось выдать кредит, заявка возвращена на рассмотрение",
+	}
+	if err := s.applicationRepo.UpdateWithDecision(context.Background(), application, decision); err != nil {
+		logrus.WithError(err).WithField("application_id", application.ID).
+			Error("Не удалось вернуть заявку на рассмотрение")
+	}
+}
+
+// disburse выдает кредит по автоматически одобренной заявке. Если выдать кредит
+// не удалось, заявка возвращается на рассмотрение менеджеру
+func (s *creditApplicationService) disburse(application *domain.CreditApplication) error {
+	credit, err := s.creditService.IssueCredit(application)
+	if err != nil {
+		logrus.WithError(err).WithField("application_id", application.ID).
+			Error("Не удалось выдать кредит по одобренной заявке")
+
+		application.Status = domain.ApplicationStatusManualReview
+		application.DecidedAt = nil
+		decision := &domain.CreditDecision{
+			ApplicationID: application.ID,
+			Stage:         domain.DecisionStageScoring,
+			Outcome:       domain.DecisionReview,
+			Score:         application.Score,
+			Comment:       "Не удалось выдать кредит автоматически, заявка передана менеджеру",
+		}
+		if err := s.applicationRepo.UpdateWithDecision(context.Background(), application, decision); err != nil {
+			return fmt.Errorf("failed to save decision: %w", err)
+		}
+		return nil
+	}
+
+	application.CreditID = &credit.ID
+	if err := s.applicationRepo.Update(context.Background(), application); err != nil {
+		return fmt.Errorf("failed to update credit application: %w", err)
+	}
+	return nil
+}
+
+// scoringInput собирает данные для скоринга: поступления на счета клиента,
+// платежи по действующим кредитам и историю просрочек
+func (s *creditApplicationService) scoringInput(userID uint, application *domain.CreditApplication) (domain.ScoringInput, error) {
+	input := domain.ScoringInput{
+		MonthlyPayment: application.MonthlyPayment,
+		DeclaredIncome: application.DeclaredIncome,
+	}
+
+	accounts, err := s.accountRepo.GetByUserID(context.Background(), userID)
+	if err != nil {
+		return input, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	own := make(map[uint]bool, len(accounts))
+	for _, account := range accounts {
+		own[account.ID] = true
+	}
+
+	// Поступления от третьих лиц за период анализа; переводы между своими
+	// счетами и выдачи кредитов доходом не считаются
+	now := s.clock.Now()
+	since := now.AddDate(0, -domain.ScoringHistoryMonths, 0)
+	var inflow float64
+	var firstOperation time.Time
+	seen := make(map[uint]bool)
+	for _, account := range accounts {
+		transactions, err := s.transactionRepo.GetByAccountID(context.Background(), account.ID)
+		if err != nil {
+			return input, fmt.Errorf("failed to get transactions: %w", err)
+		}
+		for _, t := range transactions {
+			if seen[t.ID] || t.Status != domain.TransactionStatusCompleted {
+				continue
+			}
+			seen[t.ID] = true
+			if firstOperation.IsZero() || t.CreatedAt.Before(firstOperation) {
+				firstOperation = t.CreatedAt
+			}
+			if t.CreatedAt.Before(since) || t.Type == domain.TransactionTypeCredit {
+				continue
+			}
+			if own[t.ToAccountID] && !own[t.FromAccountID] {
+				inflow += t.Amount
+			}
+		}
+	}
+	if !firstOperation.IsZero() {
+		input.HistoryMonths = monthsBetween(firstOperation, now)
+		if input.HistoryMonths > domain.ScoringHistoryMonths {
+			input.HistoryMonths = domain.ScoringHistoryMonths
+		}
+	}
+	months := input.HistoryMonths
+	if months == 0 {
+		months = 1
+	}
+	input.AverageMonthlyInflow = inflow / float64(months)
+
+	// Текущая кредитная нагрузка и просрочки
+	credits, err := s.creditRepo.GetCreditsByUserID(context.Background(), userID)
+	if err != nil {
+		return input, fmt.Errorf("failed to get credits: %w", err)
+	}
+	for _, credit := range credits {
+		if credit.Status != domain.CreditStatusActive && credit.Status != domain.CreditStatusOverdue {
+			continue
+		}
+		input.ExistingMonthlyPayments += credit.CalculateMonthlyPayment()
+		if credit.Status == domain.CreditStatusOverdue || credit.OverdueAmount > 0 {
+			input.HasCurrentOverdue = true
+		}
+	}
+	overdue, err := s.creditRepo.CountOverduePayments(context.Background(), userID)
+	if err != nil {
+		return input, fmt.Errorf("failed to count overdue payments: %w", err)
+	}
+	input.OverdueInstallments = int(overdue)
+
+	return input, nil
+}
+
+// monthsBetween возвращает количество полных месяцев между датами
+func monthsBetween(from, to time.Time) int {
+	months := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	if months < 0 {
+		return 0
+	}
+	return months
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type CreditLineService interface {
+	// Действия клиента
+	GetUserCreditLines(userID uint) ([]domain.CreditLine, error)
+	GetUserCreditLine(lineID, userID uint) (*domain.CreditLine, *domain.Account, error)
+	GetStatements(lineID, userID uint) ([]domain.CreditLineStatement, error)
+
+	// Действия менеджера
+	OpenCreditLine(req *payloads.OpenCreditLineRequest) (*domain.CreditLine, *domain.Account, error)
+	GetCreditLines() ([]domain.CreditLine, error)
+	UpdateLimit(lineID uint, limit float64) (*domain.CreditLine, error)
+
+	// Ежедневная обработка: начисление процентов, выписки, контроль платежей
+	ProcessCreditLines() error
+}
+
+type creditLineService struct {
+	creditLineRepo  dbaccess.CreditLineRepository
+	accountRepo     dbaccess.AccountRepository
+	transactionRepo dbaccess.TransactionRepository
+	userRepo        dbaccess.UserRepository
+	clock           domain.Clock
+}
+
+func CreditLineServiceInstance(
+	creditLineRepo dbaccess.CreditLineRepository,
+	accountRepo dbaccess.AccountRepository,
+	transactionRepo dbaccess.TransactionRepository,
+	userRepo dbaccess.UserRepository,
+	clock domain.Clock,
+) CreditLineService {
+	return &creditLineService{
+		creditLineRepo:  creditLineRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		userRepo:        userRepo,
+		clock:           clock,
+	}
+}
+
+// GetUserCreditLines возвращает кредитные линии пользователя
+func (s *creditLineService) GetUserCreditLines(userID uint) ([]domain.CreditLine, error) {
+	return s.creditLineRepo.GetByUserID(context.Background(), userID)
+}
+
+// GetUserCreditLine возвращает кредитную линию пользователя вместе с кредитным счетом
+func (s *creditLineService) GetUserCreditLine(lineID, userID uint) (*domain.CreditLine, *domain.Account, error) {
+	line, err := s.userCreditLine(lineID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	account, err := s.accountRepo.GetByID(context.Background(), line.AccountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return line, account, nil
+}
+
+// GetStatements возвращает выписки по кредитной линии пользователя
+func (s *creditLineService) GetStatements(lineID, userID uint) ([]domain.CreditLineStatement, error) {
+	if _, err := s.userCreditLine(lineID, userID); err != nil {
+		return nil, err
+	}
+	return s.creditLineRepo.GetStatements(context.Background(), lineID)
+}
+
+// OpenCreditLine открывает клиенту кредитный счет с возобновляемым лимитом
+func (s *creditLineService) OpenCreditLine(req *payloads.OpenCreditLineRequest) (*domain.CreditLine, *domain.Account, error) {
+	if _, err := s.userRepo.GetByID(context.Background(), req.UserID); err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	line, err := domain.NewCreditLine(req.UserID, 0, req.CreditLimit, req.InterestRate, s.clock.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	account := &domain.Account{
+		UserID:      req.UserID,
+		Type:        domain.AccountTypeCredit,
+		CreditLimit: line.CreditLimit,
+		IsActive:    true,
+	}
+	if err := s.creditLineRepo.CreateWithAccount(context.Background(), account, line); err != nil {
+		return nil, nil, fmt.Errorf("failed to open credit line: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"credit_line_id": line.ID,
+		"user_id":        line.UserID,
+		"credit_limit":   line.CreditLimit,
+	}).Info("Открыта кредитная линия")
+	return line, account, nil
+}
+
+// GetCreditLines возвращает все кредитные линии
+func (s *creditLineService) GetCreditLines() ([]domain.CreditLine, error) {
+	return s.creditLineRepo.List(context.Background(), 0, -1)
+}
+
+// UpdateLimit изменяет лимит кредитной линии. Лимит нельзя снизить ниже текущей задолженности
+func (s *creditLineService) UpdateLimit(lineID uint, limit float64) (*domain.CreditLine, error) {
+	line, err := s.creditLineRepo.GetByID(context.Background(), lineID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.accountRepo.GetByID(context.Background(), line.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if limit < account.Debt() {
+		return nil, domain.ErrCreditLimitBelowDebt
+	}
+
+	line.CreditLimit = limit
+	if err := line.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.creditLineRepo.UpdateLimit(context.Background(), line); err != nil {
+		return nil, fmt.Errorf("failed to update credit limit: %w", err)
+	}
+	return line, nil
+}
+
+// ProcessCreditLines начисляет проценты по всем кредитным линиям, подводит итоги
+// по выпискам с наступившей датой платежа и формирует выписки за истекшие расчетные периоды
+func (s *creditLineService) ProcessCreditLines() error {
+	lines, err := s.creditLineRepo.List(context.Background(), 0, -1)
+	if err != nil {
+		return fmt.Errorf("failed to get credit lines: %w", err)
+	}
+
+	now := s.clock.Now()
+	for i := range lines {
+		if err := s.process(&lines[i], now); err != nil {
+			return fmt.Errorf("failed to process credit line %d: %w", lines[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// process выполняет ежедневную обработку одной кредитной линии. Списания, выписки
+// и начисления по линии сохраняются вместе, чтобы сбой не оставил их рассогласованными
+func (s *creditLineService) process(line *domain.CreditLine, now time.Time) error {
+	account, err := s.accountRepo.GetByID(context.Background(), line.AccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+	transactions, err := s.transactionRepo.GetByAccountID(context.Background(), account.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	// Проценты на использованные средства; на покупки в льготном периоде — отложенные
+	grace := domain.SummarizeCreditLineActivity(account.ID, transactions, line.GraceSince, now)
+	line.AccrueInterest(account.Debt(), grace.Purchases, now)
+
+	statements, charges, err := s.settleStatements(line, account, transactions, now)
+	if err != nil {
+		return err
+	}
+
+	var statement *domain.CreditLineStatement
+	if line.StatementDue(now) {
+		var charge *domain.Transaction
+		statement, charge, err = s.issueStatement(line, account, append(transactions, charges...), now)
+		if err != nil {
+			return err
+		}
+		if charge != nil {
+			charges = append(charges, *charge)
+		}
+	}
+
+	if err := s.creditLineRepo.SaveProcessing(context.Background(), line, statements, statement, charges); err != nil {
+		return fmt.Errorf("failed to save credit line processing: %w", err)
+	}
+
+	if statement != nil {
+		logrus.WithFields(logrus.Fields{
+			"credit_line_id": line.ID,
+			"closing_debt":   statement.ClosingDebt,
+			"min_payment":    statement.MinPayment,
+			"due_date":       statement.DueDate,
+		}).Info("Сформирована выписка по кредитной линии")
+	}
+	return nil
+}
+
+// settleStatements подводит итог по выпискам, дата платежа по которым наступила,
+// и снимает блокировку, если просроченный минимальный платеж внесен.
+// Возвращает измененные выписки и списания процентов и штрафов по ним
+func (s *creditLineService) settleStatements(line *domain.CreditLine, account *domain.Account, transactions []domain.Transaction, now time.Time) ([]domain.CreditLineStatement, []domain.Transaction, error) {
+	statements, err := s.creditLineRepo.GetUnsettledStatements(context.Background(), line.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get statements: %w", err)
+	}
+
+	var settled []domain.CreditLineStatement
+	var charges []domain.Transaction
+	overdue := 0
+	for i := range statements {
+		statement := &statements[i]
+		switch statement.Status {
+		case domain.StatementStatusIssued:
+			if now.Before(statement.DueDate) {
+				continue
+			}
+			paid := domain.SummarizeCreditLineActivity(account.ID, transactions, statement.PeriodEnd, statement.DueDate).Payments
+			interest, fee := line.SettleStatement(statement, paid)
+			line.AddCycleCharges(interest, fee)
+			if interest > 0 {
+				charges = append(charges, *s.charge(account, interest, now, fmt.Sprintf("Проценты льготного периода по выписке от %s", statement.PeriodEnd.Format("02.01.2006"))))
+			}
+			if fee > 0 {
+				charges = append(charges, *s.charge(account, fee, now, fmt.Sprintf("Штраф за пропуск минимального платежа по выписке от %s", statement.PeriodEnd.Format("02.01.2006"))))
+			}
+		case domain.StatementStatusOverdue:
+			paid := domain.SummarizeCreditLineActivity(account.ID, transactions, statement.PeriodEnd, now).Payments
+			if !line.RecheckOverdue(statement, paid) {
+				overdue++
+				continue
+			}
+		}
+		if statement.Status == domain.StatementStatusOverdue {
+			overdue++
+		}
+		settled = append(settled, *statement)
+	}
+
+	if overdue == 0 && line.Status == domain.CreditLineStatusOverdue {
+		line.Status = domain.CreditLineStatusActive
+	}
+	return settled, charges, nil
+}
+
+// issueStatement выставляет начисленные проценты и формирует выписку за расчетный период.
+// transactions должны включать списания, сделанные в этой же обработке
+func (s *creditLineService) issueStatement(line *domain.CreditLine, account *domain.Account, transactions []domain.Transaction, now time.Time) (*domain.CreditLineStatement, *domain.Transaction, error) {
+	openingDebt := 0.0
+	last, err := s.creditLineRepo.GetLastStatement(context.Background(), line.ID)
+	if err != nil && !errors.Is(err, dbaccess.ErrNotFound) {
+		return nil, nil, fmt.Errorf("failed to get last statement: %w", err)
+	}
+	if last != nil {
+		openingDebt = last.ClosingDebt
+	}
+
+	var charge *domain.Transaction
+	interest := line.AccruedInterest
+	if interest > 0 {
+		charge = s.charge(account, interest, now, "Проценты по кредитной линии за расчетный период")
+		transactions = append(transactions, *charge)
+	}
+
+	activity := domain.SummarizeCreditLineActivity(account.ID, transactions, line.CycleStart, now)
+	statement := line.IssueStatement(activity, openingDebt, interest, account.Debt(), now)
+	return statement, charge, nil
+}
+
+// charge готовит списание с кредитного счета процентов или штрафа и учитывает его в балансе счета.
+// Списание сохраняется вместе с результатом обработки линии
+func (s *creditLineService) charge(account *domain.Account, amount float64, now time.Time, description string) *domain.Transaction {
+	account.Balance -= amount
+
+	transaction := &domain.Transaction{
+		Type:          domain.TransactionTypePayment,
+		FromAccountID: account.ID,
+		Amount:        amount,
+		Description:   description,
+		Status:        domain.TransactionStatusCompleted,
+	}
+	transaction.CreatedAt = now
+	return transaction
+}
+
+// userCreditLine возвращает кредитную линию, проверяя, что она принадлежит пользователю
+func (s *creditLineService) userCreditLine(lineID, userID uint) (*domain.CreditLine, error) {
+	line, err := s.creditLineRepo.GetByID(context.Background(), lineID)
+	if err != nil {
+		return nil, err
+	}
+	if line.UserID != userID {
+		return nil, domain.ErrCreditLineNotOwned
+	}
+	return line, nil
+}
+
+// checkCreditLine проверяет, разрешены ли расходные операции по кредитному счету
+func checkCreditLine(creditLineRepo dbaccess.CreditLineRepository, account *domain.Account) error {
+	if !account.IsCredit() {
+		return nil
+	}
+	line, err := creditLineRepo.GetByAccountID(context.Background(), account.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get credit line: %w", err)
+	}
+	return line.CanSpend()
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"context"
+	"errors"
+	"fmt"
+)
+
+type CreditProductService interface {
+	GetActiveProducts() ([]domain.CreditProduct, error)
+	GetAllProducts() ([]domain.CreditProduct, error)
+	CreateProduct(product *domain.CreditProduct) error
+	UpdateProduct(id uint, product *domain.CreditProduct) (*domain.CreditProduct, error)
+}
+
+type creditProductService struct {
+	creditProductRepo dbaccess.CreditProductRepository
+	penaltyPolicyRepo dbaccess.PenaltyPolicyRepository
+}
+
+func CreditProductServiceInstance(
+	creditProductRepo dbaccess.CreditProductRepository,
+	penaltyPolicyRepo dbaccess.PenaltyPolicyRepository,
+) CreditProductService {
+	return &creditProductService{
+		creditProductRepo: creditProductRepo,
+		penaltyPolicyRepo: penaltyPolicyRepo,
+	}
+}
+
+// GetActiveProducts возвращает продукты, доступные клиентам для оформления кредита
+func (s *creditProductService) GetActiveProducts() ([]domain.CreditProduct, error) {
+	return s.creditProductRepo.GetActive(context.Background())
+}
+
+// GetAllProducts возвращает все продукты, включая отключенные
+func (s *creditProductService) GetAllProducts() ([]domain.CreditProduct, error) {
+	return s.creditProductRepo.List(context.Background(), 0, -1)
+}
+
+// CreateProduct создает новый кредитный продукт
+func (s *creditProductService) CreateProduct(product *domain.CreditProduct) error {
+	if err := s.validate(product); err != nil {
+		return err
+	}
+	if err := s.creditProductRepo.Create(context.Background(), product); err != nil {
+		return fmt.Errorf("failed to create credit product: %w", err)
+	}
+	return s.applyDefault(product)
+}
+
+// UpdateProduct изменяет настройки продукта. Условия уже выданных кредитов не меняются
+func (s *creditProductService) UpdateProduct(id uint, update *domain.CreditProduct) (*domain.CreditProduct, error) {
+	product, err := s.creditProductRepo.GetByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Name = update.Name
+	product.Type = update.Type
+	product.MinAmount = update.MinAmount
+	product.MaxAmount = update.MaxAmount
+	product.MinTerm = update.MinTerm
+	product.MaxTerm = update.MaxTerm
+	product.RateType = update.RateType
+	product.Margin = update.Margin
+	product.FixedRate = update.FixedRate
+	product.RepaymentMethod = update.RepaymentMethod
+	product.BusinessDayRule = update.BusinessDayRule
+	product.IssueFee = update.IssueFee
+	product.PenaltyPolicyID = update.PenaltyPolicyID
+	product.MinIncome = update.MinIncome
+	product.MinHistoryMonths = update.MinHistoryMonths
+	product.AllowOverdue = update.AllowOverdue
+	product.IsActive = update.IsActive
+	product.IsDefault = update.IsDefault
+
+	if err := s.validate(product); err != nil {
+		return nil, err
+	}
+	if err := s.creditProductRepo.Update(context.Background(), product); err != nil {
+		return nil, fmt.Errorf("failed to update credit product: %w", err)
+	}
+	if err := s.applyDefault(product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// validate проверяет настройки продукта и существование его политики неустойки
+func (s *creditProductService) validate(product *domain.CreditProduct) error {
+	if err := product.Validate(); err != nil {
+		return err
+	}
+	if product.PenaltyPolicyID == nil {
+		return nil
+	}
+	_, err := s.penaltyPolicyRepo.GetByID(context.Background(), *product.PenaltyPolicyID)
+	if errors.Is(err, dbaccess.ErrNotFound) {
+		return fmt.Errorf("penalty policy %d not found: %w", *product.PenaltyPolicyID, domain.ErrInvalidCreditProduct)
+	}
+	return err
+}
+
+// applyDefault оставляет признак продукта по умолчанию только у одного продукта
+func (s *creditProductService) applyDefault(product *domain.CreditProduct) error {
+	if !product.IsDefault {
+		return nil
+	}
+	if err := s.creditProductRepo.ClearDefault(context.Background(), product.ID); err != nil {
+		return fmt.Errorf("failed to update default credit product: %w", err)
+	}
+	return nil
+}
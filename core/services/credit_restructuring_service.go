@@ -0,0 +1,223 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type CreditRestructuringService interface {
+	// Действия оператора
+	CreateRestructuring(operatorID uint, req *payloads.CreditRestructuringRequest) (*domain.CreditRestructuring, *domain.RestructuringPlan, error)
+	GetRestructurings(status domain.RestructuringStatus) ([]domain.CreditRestructuring, error)
+	GetRestructuring(restructuringID uint) (*domain.CreditRestructuring, *domain.RestructuringPlan, error)
+	GetCreditHistory(creditID uint) ([]domain.CreditRestructuring, []domain.CreditScheduleVersion, error)
+
+	// Действия менеджера
+	Approve(restructuringID, managerID uint, comment string) (*domain.CreditRestructuring, error)
+	Reject(restructuringID, managerID uint, comment string) (*domain.CreditRestructuring, error)
+}
+
+type creditRestructuringService struct {
+	restructuringRepo dbaccess.CreditRestructuringRepository
+	creditRepo        dbaccess.CreditRepository
+	creditService     CreditService
+	calendarRepo      dbaccess.CalendarRepository
+	clock             domain.Clock
+}
+
+func CreditRestructuringServiceInstance(
+	restructuringRepo dbaccess.CreditRestructuringRepository,
+	creditRepo dbaccess.CreditRepository,
+	creditService CreditService,
+	calendarRepo dbaccess.CalendarRepository,
+	clock domain.Clock,
+) CreditRestructuringService {
+	return &creditRestructuringService{
+		restructuringRepo: restructuringRepo,
+		creditRepo:        creditRepo,
+		creditService:     creditService,
+		calendarRepo:      calendarRepo,
+		clock:             clock,
+	}
+}
+
+// CreateRestructuring оформляет заявку на реструктуризацию и возвращает ее вместе
+// с предварительным расчетом нового графика. По кредиту может быть только одна
+// заявка, ожидающая решения
+func (s *creditRestructuringService) CreateRestructuring(operatorID uint, req *payloads.CreditRestructuringRequest) (*domain.CreditRestructuring, *domain.RestructuringPlan, error) {
+	credit, err := s.creditRepo.GetByID(context.Background(), req.CreditID)
+	if err != nil {
+		return nil, nil, err
+	}
+	pending, err := s.restructuringRepo.HasPending(context.Background(), credit.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check pending restructurings: %w", err)
+	}
+	if pending {
+		return nil, nil, domain.ErrRestructuringPending
+	}
+
+	restructuring, err := domain.NewCreditRestructuring(credit, operatorID, domain.RestructuringType(req.Type),
+		req.Months, domain.HolidayMode(req.HolidayMode), req.InterestRate, req.Reason)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan, err := s.plan(restructuring, credit, s.clock.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.restructuringRepo.Create(context.Background(), restructuring); err != nil {
+		return nil, nil, fmt.Errorf("failed to create restructuring: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"restructuring_id": restructuring.ID,
+		"credit_id":        credit.ID,
+		"operator_id":      operatorID,
+		"type":             restructuring.Type,
+	}).Info("Оформлена заявка на реструктуризацию кредита")
+	return restructuring, plan, nil
+}
+
+// GetRestructurings возвращает заявки на реструктуризацию; по умолчанию — ожидающие решения
+func (s *creditRestructuringService) GetRestructurings(status domain.RestructuringStatus) ([]domain.CreditRestructuring, error) {
+	if status == "" {
+		status = domain.RestructuringStatusPending
+	}
+	return s.restructuringRepo.GetByStatus(context.Background(), status)
+}
+
+// GetRestructuring возвращает заявку. Для заявки, ожидающей решения, новый график
+// рассчитывается на текущую дату, чтобы менеджер видел последствия одобрения
+func (s *creditRestructuringService) GetRestructuring(restructuringID uint) (*domain.CreditRestructuring, *domain.RestructuringPlan, error) {
+	restructuring, err := s.restructuringRepo.GetByID(context.Background(), restructuringID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !restructuring.IsPending() {
+		return restructuring, nil, nil
+	}
+
+	credit, err := s.creditRepo.GetByID(context.Background(), restructuring.CreditID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get credit: %w", err)
+	}
+	plan, err := s.plan(restructuring, credit, s.clock.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	return restructuring, plan, nil
+}
+
+// GetCreditHistory возвращает реструктуризации кредита и снимки замененных графиков
+func (s *creditRestructuringService) GetCreditHistory(creditID uint) ([]domain.CreditRestructuring, []domain.CreditScheduleVersion, error) {
+	if _, err := s.creditRepo.GetByID(context.Background(), creditID); err != nil {
+		return nil, nil, err
+	}
+	restructurings, err := s.restructuringRepo.GetByCreditID(context.Background(), creditID)
+	if err != nil {
+		return nil, nil, err
+	}
+	versions, err := s.restructuringRepo.GetScheduleVersions(context.Background(), creditID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return restructurings, versions, nil
+}
+
+// Approve одобряет реструктуризацию: график перестраивается на дату одобрения,
+// а прежний график сохраняется в истории версий
+func (s *creditRestructuringService) Approve(restructuringID, managerID uint, comment string) (*domain.CreditRestructuring, error) {
+	restructuring, err := s.restructuringRepo.GetByID(context.Background(), restructuringID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	if err := restructuring.Decide(domain.DecisionApprove, managerID, comment, now); err != nil {
+		return nil, err
+	}
+
+	credit, err := s.creditRepo.GetByID(context.Background(), restructuring.CreditID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credit: %w", err)
+	}
+	schedule, err := s.creditService.GetPaymentSchedule(credit.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment schedule: %w", err)
+	}
+	if err := s.useCalendar(credit); err != nil {
+		return nil, err
+	}
+	plan, err := restructuring.Plan(credit, schedule, now)
+	if err != nil {
+		return nil, err
+	}
+
+	version := domain.NewScheduleVersion(credit, schedule, restructuring.ID)
+	restructuring.Apply(credit, plan)
+	if err := s.restructuringRepo.Apply(context.Background(), restructuring, credit, version, plan); err != nil {
+		if errors.Is(err, domain.ErrRestructuringNotPending) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to apply restructuring: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"restructuring_id": restructuring.ID,
+		"credit_id":        credit.ID,
+		"manager_id":       managerID,
+		"schedule_version": credit.ScheduleVersion,
+		"interest_rate":    credit.InterestRate,
+		"end_date":         credit.EndDate,
+	}).Info("Кредит реструктурирован")
+	return restructuring, nil
+}
+
+// Reject отклоняет заявку на реструктуризацию
+func (s *creditRestructuringService) Reject(restructuringID, managerID uint, comment string) (*domain.CreditRestructuring, error) {
+	restructuring, err := s.restructuringRepo.GetByID(context.Background(), restructuringID)
+	if err != nil {
+		return nil, err
+	}
+	if err := restructuring.Decide(domain.DecisionReject, managerID, comment, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.restructuringRepo.Reject(context.Background(), restructuring); err != nil {
+		if errors.Is(err, domain.ErrRestructuringNotPending) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update restructuring: %w", err)
+	}
+	return restructuring, nil
+}
+
+// plan рассчитывает новый график по сохраненному графику кредита
+func (s *creditRestructuringService) plan(restructuring *domain.CreditRestructuring, credit *domain.Credit, now time.Time) (*domain.RestructuringPlan, error) {
+	schedule, err := s.creditService.GetPaymentSchedule(credit.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment schedule: %w", err)
+	}
+	if err := s.useCalendar(credit); err != nil {
+		return nil, err
+	}
+	return restructuring.Plan(credit, schedule, now)
+}
+
+// useCalendar подключает к кредиту производственный календарь, чтобы даты
+// нового графика переносились на рабочие дни
+func (s *creditRestructuringService) useCalendar(credit *domain.Credit) error {
+	calendar, err := s.calendarRepo.GetCalendar(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get business calendar: %w", err)
+	}
+	credit.UseCalendar(calendar)
+	return nil
+}
@@ -6,103 +6,246 @@ import (
 	"context"
 	"errors"
 	"fmt"
-
-	// "strconv"
-	"time"
 )
 
 type CreditService interface {
-	CreateCredit(userID uint, accountID uint, amount float64, termMonths int, description string) (*domain.Credit, error)
+	IssueCredit(application *domain.CreditApplication) (*domain.Credit, error)
+	CalculateCredit(productID uint, amount float64, termMonths int, method domain.RepaymentMethod) (*domain.Credit, error)
+	CalculateSchedule(credit *domain.Credit) ([]domain.PaymentSchedule, error)
+	GetProduct(productID uint) (*domain.CreditProduct, error)
 	GetCreditByID(id uint) (*domain.Credit, error)
 	GetUserCredits(userID uint) ([]domain.Credit, error)
 	GetPaymentSchedule(creditID uint) ([]domain.PaymentSchedule, error)
-	ProcessPayment(creditID uint, paymentNumber int) error
-	ProcessOverduePayments() error
+	ProcessPayment(creditID uint, paymentNumber int) (*domain.PaymentAllocation, error)
+	ProcessUserPayment(creditID, userID uint, paymentNumber int) (*domain.PaymentAllocation, error)
+	AccruePenalties() error
+	EarlyRepayment(creditID, userID uint, amount float64, mode domain.PrepaymentMode) (*domain.Credit, *domain.Prepayment, error)
+	FullRepayment(creditID, userID uint) (*domain.Credit, *domain.CreditClosingCertificate, error)
+	GetClosingCertificate(creditID, userID uint) (*domain.CreditClosingCertificate, error)
+	GetAgreement(creditID, userID uint) (*domain.CreditAgreement, error)
 }
 
 type creditService struct {
-	creditRepo      dbaccess.CreditRepository
-	accountRepo     dbaccess.AccountRepository
-	transactionRepo dbaccess.TransactionRepository
-	keyRateService  *ExternalService
+	creditRepo        dbaccess.CreditRepository
+	accountRepo       dbaccess.AccountRepository
+	transactionRepo   dbaccess.TransactionRepository
+	creditProductRepo dbaccess.CreditProductRepository
+	penaltyPolicyRepo dbaccess.PenaltyPolicyRepository
+	userRepo          dbaccess.UserRepository
+	keyRateService    KeyRateService
+	calendarRepo      dbaccess.CalendarRepository
+	clock             domain.Clock
 }
 
 func CreditServiceInstance(
 	creditRepo dbaccess.CreditRepository,
 	accountRepo dbaccess.AccountRepository,
 	transactionRepo dbaccess.TransactionRepository,
-	keyRateService *ExternalService,
+	creditProductRepo dbaccess.CreditProductRepository,
+	penaltyPolicyRepo dbaccess.PenaltyPolicyRepository,
+	userRepo dbaccess.UserRepository,
+	keyRateService KeyRateService,
+	calendarRepo dbaccess.CalendarRepository,
+	clock domain.Clock,
 ) CreditService {
 	return &creditService{
-		creditRepo:      creditRepo,
-		accountRepo:     accountRepo,
-		transactionRepo: transactionRepo,
-		keyRateService:  keyRateService,
+		creditRepo:        creditRepo,
+		accountRepo:       accountRepo,
+		transactionRepo:   transactionRepo,
+		creditProductRepo: creditProductRepo,
+		penaltyPolicyRepo: penaltyPolicyRepo,
+		userRepo:          userRepo,
+		keyRateService:    keyRateService,
+		calendarRepo:      calendarRepo,
+		clock:             clock,
 	}
 }
 
-func (s *creditService) CreateCredit(userID uint, accountID uint, amount float64, termMonths int, description string) (*domain.Credit, error) {
+// IssueCredit выдает кредит по одобренной заявке на условиях, зафиксированных в заявке,
+// и зачисляет сумму кредита на счет заемщика
+func (s *creditService) IssueCredit(application *domain.CreditApplication) (*domain.Credit, error) {
+	if application.Status != domain.ApplicationStatusApproved {
+		return nil, domain.ErrApplicationNotInReview
+	}
+
 	// Проверяем, что счет принадлежит пользователю
-	account, err := s.accountRepo.GetByID(context.Background(), accountID)
+	account, err := s.accountRepo.GetByID(context.Background(), application.AccountID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get account: %v", err)
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if account.UserID != application.UserID {
+		return nil, domain.ErrAccountNotOwned
+	}
+
+	// Текущее время для инициализации дат
+	now := s.clock.Now()
+
+	// Создаем кредит
+	credit := application.Terms()
+	credit.Status = domain.CreditStatusActive
+	credit.StartDate = now
+	credit.PaymentDay = now.Day()
+	credit.RemainingDebt = credit.Amount
+	credit.LastPayment = now // Инициализируем LastPayment текущей датой
+	if err := credit.Validate(); err != nil {
+		return nil, err
 	}
-	if account.UserID != userID {
-		return nil, errors.New("account does not belong to the user")
+
+	// Если у продукта нет своей политики неустойки, закрепляем за кредитом политику по умолчанию
+	if credit.PenaltyPolicyID == nil {
+		policy, err := s.penaltyPolicyRepo.GetDefault(context.Background())
+		if err != nil && !errors.Is(err, dbaccess.ErrNotFound) {
+			return nil, fmt.Errorf("failed to get penalty policy: %w", err)
+		}
+		if policy != nil {
+			credit.PenaltyPolicyID = &policy.ID
+		}
 	}
 
-	// Получаем текущую ключевую ставку
-	keyRate, err := s.keyRateService.GetKeyRate()
+	// Строим график платежей с переносом дат на рабочие дни, фиксируем по нему ПСК
+	// и сохраняем график вместе с кредитом
+	if err := s.useCalendar(credit); err != nil {
+		return nil, err
+	}
+	schedule := credit.GenerateSchedule()
+	credit.NextPayment = schedule[0].DueDate
+	credit.EndDate = schedule[len(schedule)-1].DueDate
+	fullCost := credit.FullCost(schedule)
+	credit.FullCostRate = fullCost.Rate
+	credit.FullCostAmount = fullCost.Amount
+	borrower, err := s.userRepo.GetByID(context.Background(), credit.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get key rate: %v", err)
+		return nil, fmt.Errorf("failed to get borrower: %w", err)
 	}
+	policy, err := s.penaltyPolicy(credit)
+	if err != nil {
+		return nil, err
+	}
+	if policy.ID == 0 {
+		policy = nil
+	}
+
+	// Кредит, график, договор и зачисление на счет сохраняются вместе: если выдача не
+	// удалась, заявка возвращается на рассмотрение без выданного кредита
+	err = s.creditRepo.Issue(context.Background(), credit, schedule, func(credit *domain.Credit) (*domain.CreditAgreement, []domain.Transaction, error) {
+		// Формируем кредитный договор с графиком платежей
+		agreement, err := domain.NewCreditAgreement(credit, schedule, borrower, policy, application.Description)
+		if err != nil {
+			return nil, nil, err
+		}
 
-	// Рассчитываем процентную ставку (ключевая ставка + 5%)
-	interestRate := keyRate + 5.0
+		// Зачисляем сумму кредита на счет пользователя, удерживая комиссию за выдачу
+		transactions := []domain.Transaction{{
+			Type:        domain.TransactionTypeCredit,
+			ToAccountID: credit.AccountID,
+			Amount:      credit.Amount,
+			Description: fmt.Sprintf("Зачисление по кредиту #%d: %s", credit.ID, application.Description),
+			Status:      domain.TransactionStatusCompleted,
+		}}
+		if credit.IssueFee > 0 {
+			transactions = append(transactions, domain.Transaction{
+				Type:          domain.TransactionTypePayment,
+				FromAccountID: credit.AccountID,
+				Amount:        credit.IssueFee,
+				Description:   fmt.Sprintf("Комиссия за выдачу кредита #%d", credit.ID),
+				Status:        domain.TransactionStatusCompleted,
+			})
+		}
+		return agreement, transactions, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue credit: %w", err)
+	}
 
-	// Текущее время для инициализации дат
-	now := time.Now()
+	return credit, nil
+}
 
-	// Создаем кредит
-	credit := &domain.Credit{
-		UserID:        userID,
-		AccountID:     accountID,
-		Amount:        amount,
-		Term:          termMonths,
-		InterestRate:  interestRate,
-		Status:        domain.CreditStatusActive,
-		StartDate:     now,
-		EndDate:       now.AddDate(0, termMonths, 0),
-		PaymentDay:    now.Day(),
-		NextPayment:   now.AddDate(0, 1, 0),
-		RemainingDebt: amount,
-		LastPayment:   now, // Инициализируем LastPayment текущей датой
+// CalculateCredit рассчитывает условия кредита по продукту без его оформления (кредитный калькулятор).
+// Без указания продукта используется продукт по умолчанию, без указания способа погашения — способ продукта
+func (s *creditService) CalculateCredit(productID uint, amount float64, termMonths int, method domain.RepaymentMethod) (*domain.Credit, error) {
+	product, err := s.product(productID)
+	if err != nil {
+		return nil, err
+	}
+	if err := product.ValidateTerms(amount, termMonths); err != nil {
+		return nil, err
 	}
 
-	// Сохраняем кредит
-	if err := s.creditRepo.Create(context.Background(), credit); err != nil {
-		return nil, fmt.Errorf("failed to create credit: %v", err)
+	var keyRate float64
+	if product.UsesKeyRate() {
+		if keyRate, err = s.keyRateService.GetKeyRate(); err != nil {
+			return nil, fmt.Errorf("failed to get key rate: %w", err)
+		}
+	}
+	if method == "" {
+		method = product.RepaymentMethod
 	}
 
-	// Зачисляем сумму кредита на счет пользователя
-	account.Balance += amount
-	if err := s.accountRepo.Update(context.Background(), account); err != nil {
-		return nil, fmt.Errorf("failed to update account balance: %v", err)
+	now := s.clock.Now()
+	credit := &domain.Credit{
+		Amount:          amount,
+		Term:            termMonths,
+		InterestRate:    product.InterestRate(keyRate),
+		RepaymentMethod: method,
+		Status:          domain.CreditStatusPending,
+		StartDate:       now,
+		EndDate:         now.AddDate(0, termMonths, 0),
+		PaymentDay:      now.Day(),
+		RemainingDebt:   amount,
+		PenaltyPolicyID: product.PenaltyPolicyID,
+		ProductID:       &product.ID,
+		ProductCode:     product.Code,
+		RateType:        product.RateType,
+		RateMargin:      product.Margin,
+		IssueFee:        product.IssueFee,
+		BusinessDayRule: product.BusinessDayRule.OrDefault(),
 	}
+	if err := credit.Validate(); err != nil {
+		return nil, err
+	}
+	return credit, nil
+}
 
-	// Создаем транзакцию о зачислении кредита
-	transaction := &domain.Transaction{
-		Type:        domain.TransactionTypeCredit,
-		ToAccountID: accountID,
-		Amount:      amount,
-		Description: fmt.Sprintf("Зачисление по кредиту #%d: %s", credit.ID, description),
-		Status:      domain.TransactionStatusCompleted,
+// CalculateSchedule строит график платежей по рассчитанным условиям кредита
+// с переносом дат платежей на рабочие дни по производственному календарю
+func (s *creditService) CalculateSchedule(credit *domain.Credit) ([]domain.PaymentSchedule, error) {
+	if err := s.useCalendar(credit); err != nil {
+		return nil, err
 	}
-	if err := s.transactionRepo.Create(context.Background(), transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %v", err)
+	return credit.GenerateSchedule(), nil
+}
+
+// useCalendar подключает к кредиту производственный календарь для переноса дат платежей
+func (s *creditService) useCalendar(credit *domain.Credit) error {
+	calendar, err := s.calendarRepo.GetCalendar(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get business calendar: %w", err)
 	}
+	credit.UseCalendar(calendar)
+	return nil
+}
 
-	return credit, nil
+// GetProduct возвращает кредитный продукт, доступный для оформления
+func (s *creditService) GetProduct(productID uint) (*domain.CreditProduct, error) {
+	return s.product(productID)
+}
+
+// product возвращает продукт по ID или продукт по умолчанию
+func (s *creditService) product(productID uint) (*domain.CreditProduct, error) {
+	var product *domain.CreditProduct
+	var err error
+	if productID == 0 {
+		product, err = s.creditProductRepo.GetDefault(context.Background())
+	} else {
+		product, err = s.creditProductRepo.GetByID(context.Background(), productID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credit product: %w", err)
+	}
+	if !product.IsActive {
+		return nil, domain.ErrCreditProductInactive
+	}
+	return product, nil
 }
 
 func (s *creditService) GetCreditByID(id uint) (*domain.Credit, error) {
@@ -118,169 +261,309 @@ func (s *creditService) GetPaymentSchedule(creditID uint) ([]domain.PaymentSched
 	if err != nil {
 		return nil, err
 	}
+	return s.loadSchedule(credit)
+}
 
-	// Проверяем, если LastPayment пустая, установим её равной StartDate
-	if credit.LastPayment.IsZero() {
-		credit.LastPayment = credit.StartDate
+// loadSchedule возвращает сохраненный график платежей. Для кредитов, выданных
+// до появления сохраненных графиков, график строится и сохраняется при первом обращении
+func (s *creditService) loadSchedule(credit *domain.Credit) ([]domain.PaymentSchedule, error) {
+	schedule, err := s.creditRepo.GetPaymentSchedule(context.Background(), credit.ID)
+	if err != nil {
+		return nil, err
 	}
-
-	var schedule []domain.PaymentSchedule
-	remainingAmount := credit.Amount
-	monthlyRate := credit.InterestRate / 12 / 100
-
-	for i := 1; i <= credit.Term; i++ {
-		interestAmount := remainingAmount * monthlyRate
-		principalAmount := credit.CalculateMonthlyPayment() - interestAmount
-		remainingAmount -= principalAmount
-
-		// Создаем копию объекта кредита для включения в платеж
-		creditCopy := *credit
-
-		schedule = append(schedule, domain.PaymentSchedule{
-			CreditID:      credit.ID,
-			PaymentNumber: i,
-			DueDate:       credit.StartDate.AddDate(0, i, 0),
-			Amount:        credit.CalculateMonthlyPayment(),
-			Interest:      interestAmount,
-			Principal:     principalAmount,
-			TotalAmount:   credit.CalculateMonthlyPayment(),
-			Status:        domain.PaymentStatusPending,
-			Credit:        creditCopy,
-		})
+	if len(schedule) > 0 {
+		return schedule, nil
 	}
 
+	if err := s.useCalendar(credit); err != nil {
+		return nil, err
+	}
+	schedule = credit.GenerateSchedule()
+	if err := s.creditRepo.CreatePaymentSchedule(context.Background(), schedule); err != nil {
+		return nil, fmt.Errorf("failed to save payment schedule: %w", err)
+	}
 	return schedule, nil
 }
 
-func (s *creditService) ProcessPayment(creditID uint, paymentNumber int) error {
+// ProcessUserPayment списывает платеж number по кредиту пользователя userID
+func (s *creditService) ProcessUserPayment(creditID, userID uint, paymentNumber int) (*domain.PaymentAllocation, error) {
+	credit, err := s.creditRepo.GetByID(context.Background(), creditID)
+	if err != nil {
+		return nil, err
+	}
+	if credit.UserID != userID {
+		return nil, domain.ErrCreditNotOwned
+	}
+	return s.ProcessPayment(creditID, paymentNumber)
+}
+
+// ProcessPayment списывает со счета платеж number. Перед ним доначисляется неустойка,
+// а списанная сумма направляется сначала на пени и штрафы, затем на просроченные
+// проценты и основной долг и только потом на сам платеж. Если средств на платеж
+// не хватает, списывается то, что есть, и возвращается ErrInsufficientFunds
+func (s *creditService) ProcessPayment(creditID uint, paymentNumber int) (*domain.PaymentAllocation, error) {
 	// Получаем кредит
 	credit, err := s.creditRepo.GetByID(context.Background(), creditID)
 	if err != nil {
-		return fmt.Errorf("failed to get credit: %v", err)
+		return nil, fmt.Errorf("failed to get credit: %w", err)
+	}
+	if credit.Status != domain.CreditStatusActive && credit.Status != domain.CreditStatusOverdue {
+		return nil, domain.ErrCreditNotActive
+	}
+
+	// Получаем график платежей
+	schedule, err := s.loadSchedule(credit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment schedule: %w", err)
+	}
+	policy, err := s.penaltyPolicy(credit)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountRepo.GetByID(context.Background(), credit.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
 	}
 
-	// Проверяем, не был ли уже оплачен этот платеж
-	transactions, err := s.transactionRepo.GetByType(context.Background(), domain.TransactionTypePayment)
+	// Доначисляем неустойку и распределяем доступные средства по задолженности
+	now := s.clock.Now()
+	before := append([]domain.PaymentSchedule(nil), schedule...)
+	policy.Accrue(schedule, now)
+	allocation, err := domain.AllocatePayment(schedule, paymentNumber, account.Balance, now)
 	if err != nil {
-		return fmt.Errorf("failed to get payment transactions: %v", err)
+		return nil, err
 	}
 
-	for _, t := range transactions {
-		if t.FromAccountID == credit.AccountID && t.Status == domain.TransactionStatusCompleted {
-			// Проверяем номер платежа в описании
-			var paidPaymentNumber int
-			_, err := fmt.Sscanf(t.Description, "Платеж по кредиту #%d, платеж #%d", &creditID, &paidPaymentNumber)
-			if err == nil && paidPaymentNumber == paymentNumber {
-				return fmt.Errorf("payment #%d already processed", paymentNumber)
+	var transaction *domain.Transaction
+	if allocation.Amount > 0 {
+		description := fmt.Sprintf("Платеж по кредиту #%d, платеж #%d", credit.ID, paymentNumber)
+		if !allocation.Settled {
+			description = fmt.Sprintf("Частичное погашение просроченной задолженности по кредиту #%d", credit.ID)
+		}
+		transaction = &domain.Transaction{
+			Type:          domain.TransactionTypePayment,
+			FromAccountID: credit.AccountID,
+			Amount:        allocation.Amount,
+			Description:   description,
+			Status:        domain.TransactionStatusCompleted,
+		}
+	}
+
+	// Пересчитываем итоги кредита по графику и сохраняем измененные платежи вместе со
+	// списанием: параллельное списание того же платежа (запрос клиента и задача
+	// планировщика) проходит только один раз
+	credit.ApplySchedule(schedule)
+	if changed := changedPayments(before, schedule); len(changed) > 0 || transaction != nil {
+		if err := s.creditRepo.ApplyPayment(context.Background(), credit, changed, transaction); err != nil {
+			switch {
+			case errors.Is(err, domain.ErrInsufficientFunds), errors.Is(err, domain.ErrCreditNotActive),
+				errors.Is(err, domain.ErrPaymentAlreadyPaid):
+				return nil, err
 			}
+			return nil, fmt.Errorf("failed to save payment: %w", err)
 		}
 	}
 
-	// Получаем график платежей
-	schedule, err := s.GetPaymentSchedule(creditID)
+	if !allocation.Settled {
+		return allocation, domain.ErrInsufficientFunds
+	}
+	return allocation, nil
+}
+
+// AccruePenalties ежедневно начисляет неустойку по просроченным платежам
+// и пересчитывает просроченную задолженность кредитов
+func (s *creditService) AccruePenalties() error {
+	now := s.clock.Now()
+	duePayments, err := s.creditRepo.GetDuePayments(context.Background(), now)
 	if err != nil {
-		return fmt.Errorf("failed to get payment schedule: %v", err)
+		return fmt.Errorf("failed to get due payments: %w", err)
 	}
 
-	// Находим нужный платеж
-	var payment *domain.PaymentSchedule
-	for i := range schedule {
-		if schedule[i].PaymentNumber == paymentNumber {
-			payment = &schedule[i]
-			break
+	processed := make(map[uint]bool)
+	for _, payment := range duePayments {
+		if processed[payment.CreditID] {
+			continue
+		}
+		processed[payment.CreditID] = true
+
+		credit, err := s.creditRepo.GetByID(context.Background(), payment.CreditID)
+		if err != nil {
+			return fmt.Errorf("failed to get credit %d: %w", payment.CreditID, err)
+		}
+		schedule, err := s.loadSchedule(credit)
+		if err != nil {
+			return fmt.Errorf("failed to get payment schedule for credit %d: %w", credit.ID, err)
+		}
+		policy, err := s.penaltyPolicy(credit)
+		if err != nil {
+			return err
+		}
+
+		before := append([]domain.PaymentSchedule(nil), schedule...)
+		policy.Accrue(schedule, now)
+		changed := changedPayments(before, schedule)
+		if len(changed) == 0 {
+			continue
+		}
+		credit.ApplySchedule(schedule)
+		if err := s.creditRepo.UpdateWithSchedule(context.Background(), credit, changed); err != nil {
+			return fmt.Errorf("failed to update credit %d: %w", credit.ID, err)
 		}
-	}
-	if payment == nil {
-		return errors.New("payment not found")
 	}
 
-	// Проверяем баланс счета
-	account, err := s.accountRepo.GetByID(context.Background(), credit.AccountID)
+	return nil
+}
+
+// penaltyPolicy возвращает политику неустойки кредита. Для кредитов без политики
+// применяется политика по умолчанию, а если ее нет — неустойка не начисляется
+func (s *creditService) penaltyPolicy(credit *domain.Credit) (*domain.PenaltyPolicy, error) {
+	var policy *domain.PenaltyPolicy
+	var err error
+	if credit.PenaltyPolicyID != nil {
+		policy, err = s.penaltyPolicyRepo.GetByID(context.Background(), *credit.PenaltyPolicyID)
+	} else {
+		policy, err = s.penaltyPolicyRepo.GetDefault(context.Background())
+	}
+	if errors.Is(err, dbaccess.ErrNotFound) {
+		return &domain.PenaltyPolicy{}, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to get account: %v", err)
+		return nil, fmt.Errorf("failed to get penalty policy: %w", err)
 	}
+	return policy, nil
+}
 
-	if account.Balance < payment.TotalAmount {
-		// Если средств недостаточно, начисляем штраф
-		penalty := payment.TotalAmount * 0.1
-		payment.TotalAmount += penalty
-		credit.Status = domain.CreditStatusOverdue
-		if err := s.creditRepo.Update(context.Background(), credit); err != nil {
-			return fmt.Errorf("failed to update credit status: %v", err)
+// changedPayments возвращает платежи графика, изменившиеся после пересчета
+func changedPayments(before, after []domain.PaymentSchedule) []domain.PaymentSchedule {
+	var changed []domain.PaymentSchedule
+	for i := range after {
+		if i >= len(before) || before[i] != after[i] {
+			changed = append(changed, after[i])
 		}
-		return errors.New("insufficient funds, penalty applied")
 	}
+	return changed
+}
 
-	// Списываем средства со счета
-	account.Balance -= payment.TotalAmount
-	if err := s.accountRepo.Update(context.Background(), account); err != nil {
-		return fmt.Errorf("failed to update account balance: %v", err)
+// EarlyRepayment частично погашает кредит досрочно и перестраивает оставшийся график
+func (s *creditService) EarlyRepayment(creditID, userID uint, amount float64, mode domain.PrepaymentMode) (*domain.Credit, *domain.Prepayment, error) {
+	credit, schedule, err := s.userCreditSchedule(creditID, userID)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Создаем транзакцию о платеже
-	transaction := &domain.Transaction{
-		Type:          domain.TransactionTypePayment,
-		FromAccountID: credit.AccountID,
-		Amount:        payment.TotalAmount,
-		Description:   fmt.Sprintf("Платеж по кредиту #%d, платеж #%d", credit.ID, paymentNumber),
-		Status:        domain.TransactionStatusCompleted,
+	prepayment, err := credit.PlanPrepayment(schedule, amount, mode, s.clock.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := s.applyPrepayment(credit, schedule, prepayment, false); err != nil {
+		return nil, nil, err
 	}
-	if err := s.transactionRepo.Create(context.Background(), transaction); err != nil {
-		return fmt.Errorf("failed to create transaction: %v", err)
+	return credit, prepayment, nil
+}
+
+// FullRepayment полностью погашает кредит досрочно, закрывает его и выдает справку о закрытии
+func (s *creditService) FullRepayment(creditID, userID uint) (*domain.Credit, *domain.CreditClosingCertificate, error) {
+	credit, schedule, err := s.userCreditSchedule(creditID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prepayment, err := credit.PlanFullRepayment(schedule, s.clock.Now())
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Обновляем статус платежа
-	payment.Status = domain.PaymentStatusPaid
-	now := time.Now()
-	payment.PaidAt = &now
+	certificate, err := s.applyPrepayment(credit, schedule, prepayment, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return credit, certificate, nil
+}
 
-	// Обновляем кредит
-	credit.TotalPaid += payment.TotalAmount
-	credit.RemainingDebt = credit.CalculateRemainingDebt()
-	credit.LastPayment = now
-	credit.NextPayment = credit.CalculateNextPaymentDate()
+// GetClosingCertificate возвращает справку о полном погашении кредита
+func (s *creditService) GetClosingCertificate(creditID, userID uint) (*domain.CreditClosingCertificate, error) {
+	credit, err := s.creditRepo.GetByID(context.Background(), creditID)
+	if err != nil {
+		return nil, err
+	}
+	if credit.UserID != userID {
+		return nil, domain.ErrCreditNotOwned
+	}
 
-	// Если это последний платеж, закрываем кредит
-	if paymentNumber == credit.Term {
-		credit.Status = domain.CreditStatusPaid
+	certificate, err := s.creditRepo.GetClosingCertificate(context.Background(), creditID)
+	if errors.Is(err, dbaccess.ErrNotFound) {
+		return nil, domain.ErrCertificateNotFound
 	}
+	return certificate, err
+}
 
-	if err := s.creditRepo.Update(context.Background(), credit); err != nil {
-		return fmt.Errorf("failed to update credit: %v", err)
+// GetAgreement возвращает кредитный договор
+func (s *creditService) GetAgreement(creditID, userID uint) (*domain.CreditAgreement, error) {
+	credit, err := s.creditRepo.GetByID(context.Background(), creditID)
+	if err != nil {
+		return nil, err
+	}
+	if credit.UserID != userID {
+		return nil, domain.ErrCreditNotOwned
 	}
 
-	return nil
+	agreement, err := s.creditRepo.GetAgreement(context.Background(), creditID)
+	if errors.Is(err, dbaccess.ErrNotFound) {
+		return nil, domain.ErrAgreementNotFound
+	}
+	return agreement, err
 }
 
-func (s *creditService) ProcessOverduePayments() error {
-	// Получаем просроченные кредиты
-	overdueCredits, err := s.creditRepo.GetOverdueCredits(context.Background())
+// userCreditSchedule возвращает кредит пользователя вместе с сохраненным графиком
+func (s *creditService) userCreditSchedule(creditID, userID uint) (*domain.Credit, []domain.PaymentSchedule, error) {
+	credit, err := s.creditRepo.GetByID(context.Background(), creditID)
 	if err != nil {
-		return fmt.Errorf("failed to get overdue credits: %v", err)
+		return nil, nil, err
+	}
+	if credit.UserID != userID {
+		return nil, nil, domain.ErrCreditNotOwned
 	}
 
-	for _, credit := range overdueCredits {
-		// Получаем график платежей
-		schedule, err := s.GetPaymentSchedule(credit.ID)
-		if err != nil {
-			return fmt.Errorf("failed to get payment schedule for credit %d: %v", credit.ID, err)
-		}
+	schedule, err := s.loadSchedule(credit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get payment schedule: %w", err)
+	}
+	// Даты перестроенного графика переносятся по производственному календарю
+	if err := s.useCalendar(credit); err != nil {
+		return nil, nil, err
+	}
+	return credit, schedule, nil
+}
 
-		// Находим просроченный платеж
-		for _, payment := range schedule {
-			if payment.Status == domain.PaymentStatusPending && payment.DueDate.Before(time.Now()) {
-				// Пытаемся обработать платеж
-				if err := s.ProcessPayment(credit.ID, payment.PaymentNumber); err != nil {
-					// Если не удалось обработать платеж, обновляем статус кредита
-					credit.Status = domain.CreditStatusOverdue
-					if err := s.creditRepo.Update(context.Background(), &credit); err != nil {
-						return fmt.Errorf("failed to update credit status: %v", err)
-					}
-				}
-				break
-			}
-		}
+// applyPrepayment списывает сумму досрочного погашения со счета и сохраняет новый график.
+// При полном погашении вместе с графиком сохраняется справка о закрытии кредита
+func (s *creditService) applyPrepayment(credit *domain.Credit, schedule []domain.PaymentSchedule, prepayment *domain.Prepayment, full bool) (*domain.CreditClosingCertificate, error) {
+	description := fmt.Sprintf("Частичное досрочное погашение кредита #%d", credit.ID)
+	if full {
+		description = fmt.Sprintf("Полное досрочное погашение кредита #%d", credit.ID)
+	}
+	transaction := &domain.Transaction{
+		Type:          domain.TransactionTypePayment,
+		FromAccountID: credit.AccountID,
+		Amount:        prepayment.Amount,
+		Description:   description,
+		Status:        domain.TransactionStatusCompleted,
 	}
 
-	return nil
+	result := prepayment.Apply(credit, schedule)
+	var certificate *domain.CreditClosingCertificate
+	if full {
+		certificate = domain.NewClosingCertificate(credit, result, prepayment.Payment.DueDate)
+	}
+	if err := s.creditRepo.ApplyPrepayment(context.Background(), credit, prepayment, transaction, certificate); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInsufficientFunds), errors.Is(err, domain.ErrCreditNotActive),
+			errors.Is(err, domain.ErrPrepaymentOutdated):
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save prepayment: %w", err)
+	}
+	return certificate, nil
 }
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"FinanceGolang/core/domain"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CurrencyRateProvider источник официальных курсов валют
+type CurrencyRateProvider interface {
+	// Rates возвращает курсы, действующие на дату date. Дата в курсах - дата их
+	// установления, она может быть раньше date, если на date курсы не устанавливались
+	Rates(ctx context.Context, date time.Time) ([]domain.CurrencyRate, error)
+}
+
+// Структуры для работы с XML-ответом GetCursOnDateXML
+type CursOnDateEnvelope struct {
+	XMLName xml.Name       `xml:"Envelope"`
+	Body    CursOnDateBody `xml:"Body"`
+}
+
+type CursOnDateBody struct {
+	Response CursOnDateResponse `xml:"GetCursOnDateXMLResponse"`
+}
+
+type CursOnDateResponse struct {
+	Result CursOnDateResult `xml:"GetCursOnDateXMLResult"`
+}
+
+type CursOnDateResult struct {
+	Data ValuteData `xml:"ValuteData"`
+}
+
+type ValuteData struct {
+	OnDate string             `xml:"OnDate,attr"` // ГГГГММДД
+	Rows   []ValuteCursOnDate `xml:"ValuteCursOnDate"`
+}
+
+type ValuteCursOnDate struct {
+	Name     string `xml:"Vname"`
+	Nominal  string `xml:"Vnom"`
+	Curs     string `xml:"Vcurs"`
+	NumCode  string `xml:"Vcode"`
+	CharCode string `xml:"VchCode"`
+	UnitRate string `xml:"VunitRate"`
+}
+
+// Структура для SOAP-запроса GetCursOnDateXML
+type GetCursOnDateXMLRequest struct {
+	XMLName xml.Name `xml:"GetCursOnDateXML"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	OnDate  string   `xml:"On_date"`
+}
+
+// CBRCurrencyRateProvider получает официальные курсы валют от веб-сервиса Банка России по SOAP
+type CBRCurrencyRateProvider struct {
+	client *cbrClient
+}
+
+// NewCBRCurrencyRateProvider создает клиент курсов валют веб-сервиса Банка России
+func NewCBRCurrencyRateProvider(url string, timeout time.Duration) *CBRCurrencyRateProvider {
+	return &CBRCurrencyRateProvider{client: newCBRClient(url, timeout)}
+}
+
+// Rates запрашивает курсы валют на дату
+func (p *CBRCurrencyRateProvider) Rates(ctx context.Context, date time.Time) ([]domain.CurrencyRate, error) {
+	request := GetCursOnDateXMLRequest{
+		Xmlns:  cbrNamespace,
+		OnDate: date.Format(domain.CurrencyRateDateLayout),
+	}
+	body, err := p.client.call(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var data CursOnDateEnvelope
+	if err := xml.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("ошибка при парсинге XML: %v", err)
+	}
+	valutes := data.Body.Response.Result.Data
+	if len(valutes.Rows) == 0 {
+		return nil, nil
+	}
+	onDate, err := time.Parse("20060102", strings.TrimSpace(valutes.OnDate))
+	if err != nil {
+		return nil, fmt.Errorf("некорректная дата курсов: %q", valutes.OnDate)
+	}
+
+	rates := make([]domain.CurrencyRate, 0, len(valutes.Rows))
+	for _, row := range valutes.Rows {
+		rate, err := parseValuteCurs(row, onDate)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].CharCode < rates[j].CharCode })
+	return rates, nil
+}
+
+// parseValuteCurs разбирает курс одной валюты. В старых ответах нет курса
+// за единицу, тогда он считается по номиналу
+func parseValuteCurs(row ValuteCursOnDate, onDate time.Time) (domain.CurrencyRate, error) {
+	nominal, err := strconv.Atoi(strings.TrimSpace(row.Nominal))
+	if err != nil {
+		return domain.CurrencyRate{}, fmt.Errorf("ошибка при конвертации номинала %s: %v", row.CharCode, err)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(row.Curs), 64)
+	if err != nil {
+		return domain.CurrencyRate{}, fmt.Errorf("ошибка при конвертации курса %s: %v", row.CharCode, err)
+	}
+	unitRate := value / float64(nominal)
+	if unit := strings.TrimSpace(row.UnitRate); unit != "" {
+		if unitRate, err = strconv.ParseFloat(unit, 64); err != nil {
+			return domain.CurrencyRate{}, fmt.Errorf("ошибка при конвертации курса %s: %v", row.CharCode, err)
+		}
+	}
+
+	rate := domain.CurrencyRate{
+		Date:     onDate.Format(domain.CurrencyRateDateLayout),
+		CharCode: strings.TrimSpace(row.CharCode),
+		NumCode:  strings.TrimSpace(row.NumCode),
+		Name:     strings.TrimSpace(row.Name),
+		Nominal:  nominal,
+		Value:    value,
+		UnitRate: unitRate,
+	}
+	if err := rate.Validate(); err != nil {
+		return domain.CurrencyRate{}, err
+	}
+	return rate, nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+type CurrencyRateService interface {
+	GetRates(date time.Time, charCode string) ([]domain.CurrencyRate, error)
+	Sync() error
+}
+
+type currencyRateService struct {
+	provider         CurrencyRateProvider
+	currencyRateRepo dbaccess.CurrencyRateRepository
+	clock            domain.Clock
+
+	// Параллельные запросы курсов на одну дату ждут один запрос к источнику
+	fetches singleflight.Group
+
+	mu          sync.Mutex
+	days        map[string]currencyRateDay
+	lastFailure time.Time
+}
+
+// currencyRateDay что известно о загрузке курсов на дату
+type currencyRateDay struct {
+	loaded    bool      // курсы на дату окончательно получены от источника
+	checkedAt time.Time // последний запрос к источнику
+}
+
+// currencyRateDaysLimit сколько дат сервис помнит. При переполнении память очищается:
+// загруженные курсы остаются в базе, а повторная проверка даты стоит одного запроса к источнику
+const currencyRateDaysLimit = 1024
+
+func CurrencyRateServiceInstance(provider CurrencyRateProvider, currencyRateRepo dbaccess.CurrencyRateRepository, clock domain.Clock) CurrencyRateService {
+	return &currencyRateService{
+		provider:         provider,
+		currencyRateRepo: currencyRateRepo,
+		clock:            clock,
+		days:             make(map[string]currencyRateDay),
+	}
+}
+
+// GetRates возвращает курсы, действующие на дату, с charCode - курс одной валюты.
+// Курсы, еще не полученные от источника, сначала загружаются; если источник
+// недоступен, отдаются последние сохраненные на эту дату
+func (s *currencyRateService) GetRates(date time.Time, charCode string) ([]domain.CurrencyRate, error) {
+	if charCode != "" && !domain.IsCurrencyCode(charCode) {
+		return nil, fmt.Errorf("%w: currency code must be 3 latin letters", domain.ErrInvalidCurrencyRate)
+	}
+	ctx := context.Background()
+	day := date.Format(domain.CurrencyRateDateLayout)
+
+	if s.needsLoad(day) {
+		if err := s.fetch(ctx, date); err != nil {
+			logrus.WithError(err).WithField("date", day).Warn("Не удалось загрузить курсы валют")
+		}
+	}
+
+	rates, err := s.currencyRateRepo.GetOnDate(ctx, day, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency rates: %w", err)
+	}
+	if len(rates) == 0 {
+		return nil, domain.ErrCurrencyRatesUnavailable
+	}
+	if charCode == "" {
+		return rates, nil
+	}
+	for i := range rates {
+		if rates[i].CharCode == charCode {
+			return rates[i : i+1], nil
+		}
+	}
+	return nil, domain.ErrCurrencyRateNotFound
+}
+
+// Sync загружает курсы на сегодня и на завтра: Банк России устанавливает
+// курсы накануне дня, с которого они действуют
+func (s *currencyRateService) Sync() error {
+	now := s.clock.Now()
+	for _, date := range []time.Time{now, now.AddDate(0, 0, 1)} {
+		if err := s.fetch(context.Background(), date); err != nil {
+			return fmt.Errorf("failed to sync currency rates: %w", err)
+		}
+	}
+	return nil
+}
+
+// needsLoad проверяет, нужно ли запрашивать курсы на дату у источника: курсы еще
+// не загружены окончательно, а источник не отказывал и не опрашивался недавно
+func (s *currencyRateService) needsLoad(day string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	state := s.days[day]
+	return !state.loaded && now.Sub(s.lastFailure) >= cbrRetryDelay && now.Sub(state.checkedAt) >= cbrRetryDelay
+}
+
+// fetch загружает курсы на дату. Блокировка на время запроса к источнику не держится,
+// а параллельные запросы на ту же дату получают результат одного обращения
+func (s *currencyRateService) fetch(ctx context.Context, date time.Time) error {
+	_, err, _ := s.fetches.Do(date.Format(domain.CurrencyRateDateLayout), func() (interface{}, error) {
+		return nil, s.load(ctx, date)
+	})
+	return err
+}
+
+// load запрашивает у источника курсы на дату и сохраняет их. Дата считается
+// загруженной, если курсы установлены на нее саму или она уже прошла: курсы
+// на прошедшие дни не меняются
+func (s *currencyRateService) load(ctx context.Context, date time.Time) error {
+	day := date.Format(domain.CurrencyRateDateLayout)
+	rates, err := s.provider.Rates(ctx, date)
+	if err != nil {
+		s.mu.Lock()
+		s.lastFailure = s.clock.Now()
+		s.mu.Unlock()
+		return err
+	}
+	if len(rates) > 0 {
+		if err := s.currencyRateRepo.SaveRates(ctx, rates); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if len(s.days) >= currencyRateDaysLimit {
+		clear(s.days)
+	}
+	if len(rates) > 0 {
+		if effective := s.days[rates[0].Date]; !effective.loaded {
+			logrus.WithFields(logrus.Fields{
+				"date":       rates[0].Date,
+				"currencies": len(rates),
+			}).Info("Загружены курсы валют ЦБ РФ")
+			effective.loaded = true
+			s.days[rates[0].Date] = effective
+		}
+	}
+
+	state := s.days[day]
+	state.checkedAt = now
+	if len(rates) > 0 && day < now.Format(domain.CurrencyRateDateLayout) {
+		state.loaded = true
+	}
+	s.days[day] = state
+	return nil
+}
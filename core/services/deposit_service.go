@@ -0,0 +1,311 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type DepositService interface {
+	// Продукты вкладов
+	GetActiveProducts() ([]domain.DepositProduct, error)
+	GetAllProducts() ([]domain.DepositProduct, error)
+	CreateProduct(product *domain.DepositProduct) error
+	UpdateProduct(id uint, product *domain.DepositProduct) (*domain.DepositProduct, error)
+
+	// Действия клиента
+	OpenDeposit(userID uint, req *payloads.OpenTermDepositRequest) (*domain.TermDeposit, error)
+	GetUserDeposits(userID uint) ([]domain.TermDeposit, error)
+	GetUserDeposit(depositID, userID uint) (*domain.TermDeposit, error)
+	CloseDeposit(depositID, userID uint) (*domain.TermDeposit, float64, error)
+
+	// Ежедневная обработка: начисление, капитализация и выплата процентов, окончание срока
+	ProcessDeposits() error
+}
+
+type depositService struct {
+	depositProductRepo dbaccess.DepositProductRepository
+	termDepositRepo    dbaccess.TermDepositRepository
+	accountRepo        dbaccess.AccountRepository
+	keyRateService     KeyRateService
+	calendarRepo       dbaccess.CalendarRepository
+	clock              domain.Clock
+}
+
+func DepositServiceInstance(
+	depositProductRepo dbaccess.DepositProductRepository,
+	termDepositRepo dbaccess.TermDepositRepository,
+	accountRepo dbaccess.AccountRepository,
+	keyRateService KeyRateService,
+	calendarRepo dbaccess.CalendarRepository,
+	clock domain.Clock,
+) DepositService {
+	return &depositService{
+		depositProductRepo: depositProductRepo,
+		termDepositRepo:    termDepositRepo,
+		accountRepo:        accountRepo,
+		keyRateService:     keyRateService,
+		calendarRepo:       calendarRepo,
+		clock:              clock,
+	}
+}
+
+// GetActiveProducts возвращает вклады, доступные для открытия
+func (s *depositService) GetActiveProducts() ([]domain.DepositProduct, error) {
+	return s.depositProductRepo.GetActive(context.Background())
+}
+
+// GetAllProducts возвращает все продукты вкладов, включая отключенные
+func (s *depositService) GetAllProducts() ([]domain.DepositProduct, error) {
+	return s.depositProductRepo.List(context.Background(), 0, -1)
+}
+
+// CreateProduct создает новый продукт вклада
+func (s *depositService) CreateProduct(product *domain.DepositProduct) error {
+	if err := product.Validate(); err != nil {
+		return err
+	}
+	if err := s.depositProductRepo.Create(context.Background(), product); err != nil {
+		return fmt.Errorf("failed to create deposit product: %w", err)
+	}
+	return nil
+}
+
+// UpdateProduct изменяет настройки продукта. Условия открытых вкладов не меняются
+// до пролонгации
+func (s *depositService) UpdateProduct(id uint, update *domain.DepositProduct) (*domain.DepositProduct, error) {
+	product, err := s.depositProductRepo.GetByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Name = update.Name
+	product.MinAmount = update.MinAmount
+	product.MaxAmount = update.MaxAmount
+	product.MinTerm = update.MinTerm
+	product.MaxTerm = update.MaxTerm
+	product.RateType = update.RateType
+	product.Margin = update.Margin
+	product.FixedRate = update.FixedRate
+	product.EarlyWithdrawalRate = update.EarlyWithdrawalRate
+	product.BusinessDayRule = update.BusinessDayRule
+	product.IsActive = update.IsActive
+
+	if err := product.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.depositProductRepo.Update(context.Background(), product); err != nil {
+		return nil, fmt.Errorf("failed to update deposit product: %w", err)
+	}
+	return product, nil
+}
+
+// OpenDeposit открывает вклад, списывая сумму с дебетового счета клиента
+func (s *depositService) OpenDeposit(userID uint, req *payloads.OpenTermDepositRequest) (*domain.TermDeposit, error) {
+	account, err := s.accountRepo.GetByID(context.Background(), req.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if account.UserID != userID {
+		return nil, domain.ErrTermDepositNotOwned
+	}
+	if account.Type != domain.AccountTypeDebit {
+		return nil, domain.ErrTermDepositSourceType
+	}
+	if err := account.CanWithdraw(req.Amount); err != nil {
+		return nil, err
+	}
+
+	product, err := s.depositProductRepo.GetByID(context.Background(), req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	keyRate, err := s.keyRate(product)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := s.calendarRepo.GetCalendar(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get business calendar: %w", err)
+	}
+
+	deposit, err := domain.NewTermDeposit(product, userID, account.ID, req.Amount, req.TermMonths,
+		domain.InterestPayout(req.InterestPayout), req.AutoRollover, keyRate, calendar, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	err = s.termDepositRepo.Open(context.Background(), deposit, func(deposit *domain.TermDeposit) *domain.Transaction {
+		return &domain.Transaction{
+			Type:          domain.TransactionTypeWithdrawal,
+			FromAccountID: deposit.AccountID,
+			Amount:        deposit.Amount,
+			Description:   fmt.Sprintf("Открытие вклада #%d", deposit.ID),
+			Status:        domain.TransactionStatusCompleted,
+		}
+	})
+	if errors.Is(err, domain.ErrInsufficientFunds) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open term deposit: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"deposit_id":    deposit.ID,
+		"user_id":       userID,
+		"amount":        deposit.Amount,
+		"interest_rate": deposit.InterestRate,
+		"end_date":      deposit.EndDate,
+	}).Info("Открыт срочный вклад")
+	return deposit, nil
+}
+
+// GetUserDeposits возвращает вклады пользователя
+func (s *depositService) GetUserDeposits(userID uint) ([]domain.TermDeposit, error) {
+	return s.termDepositRepo.GetByUserID(context.Background(), userID)
+}
+
+// GetUserDeposit возвращает вклад, проверяя, что он принадлежит пользователю
+func (s *depositService) GetUserDeposit(depositID, userID uint) (*domain.TermDeposit, error) {
+	deposit, err := s.termDepositRepo.GetByID(context.Background(), depositID)
+	if err != nil {
+		return nil, err
+	}
+	if deposit.UserID != userID {
+		return nil, domain.ErrTermDepositNotOwned
+	}
+	return deposit, nil
+}
+
+// CloseDeposit досрочно расторгает вклад по ставке досрочного расторжения
+// и возвращает сумму на счет
+func (s *depositService) CloseDeposit(depositID, userID uint) (*domain.TermDeposit, float64, error) {
+	deposit, err := s.GetUserDeposit(depositID, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	amount, err := deposit.CloseEarly(s.clock.Now())
+	if err != nil {
+		return nil, 0, err
+	}
+	credits := s.credit(nil, deposit, amount, fmt.Sprintf("Досрочное расторжение вклада #%d", deposit.ID))
+	err = s.termDepositRepo.Settle(context.Background(), deposit, credits)
+	if errors.Is(err, domain.ErrTermDepositClosed) {
+		return nil, 0, err
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to close term deposit: %w", err)
+	}
+	return deposit, amount, nil
+}
+
+// ProcessDeposits начисляет проценты по действующим вкладам, капитализирует или выплачивает
+// их в ежемесячную дату и пролонгирует или возвращает вклады с истекшим сроком
+func (s *depositService) ProcessDeposits() error {
+	deposits, err := s.termDepositRepo.GetActive(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get term deposits: %w", err)
+	}
+
+	now := s.clock.Now()
+	for i := range deposits {
+		if err := s.process(&deposits[i], now); err != nil {
+			return fmt.Errorf("failed to process term deposit %d: %w", deposits[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// process выполняет ежедневную обработку одного вклада. Выплаты сохраняются вместе
+// с начислением и статусом вклада
+func (s *depositService) process(deposit *domain.TermDeposit, now time.Time) error {
+	var credits []domain.Transaction
+	for deposit.InterestDue(now) {
+		credits = s.credit(credits, deposit, deposit.TakeInterest(), fmt.Sprintf("Выплата процентов по вкладу #%d", deposit.ID))
+	}
+
+	if deposit.Matured(now) {
+		amount, err := s.mature(deposit, now)
+		if err != nil {
+			return err
+		}
+		credits = s.credit(credits, deposit, amount, fmt.Sprintf("Возврат вклада #%d по окончании срока", deposit.ID))
+	} else {
+		deposit.Accrue(now)
+	}
+
+	err := s.termDepositRepo.Settle(context.Background(), deposit, credits)
+	if errors.Is(err, domain.ErrTermDepositClosed) {
+		// Клиент расторг вклад во время обработки
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update term deposit: %w", err)
+	}
+	return nil
+}
+
+// mature пролонгирует вклад по действующей ставке продукта или закрывает его.
+// Возвращает сумму, которую нужно вернуть на счет при закрытии
+func (s *depositService) mature(deposit *domain.TermDeposit, now time.Time) (float64, error) {
+	if deposit.AutoRollover {
+		product, err := s.depositProductRepo.GetByID(context.Background(), deposit.ProductID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get deposit product: %w", err)
+		}
+		// Пролонгация возможна, пока продукт открыт и сумма вклада в его лимитах
+		if product.ValidateTerms(deposit.Principal, deposit.Term) == nil {
+			keyRate, err := s.keyRate(product)
+			if err != nil {
+				return 0, err
+			}
+			calendar, err := s.calendarRepo.GetCalendar(context.Background())
+			if err != nil {
+				return 0, fmt.Errorf("failed to get business calendar: %w", err)
+			}
+			deposit.Rollover(product.InterestRate(keyRate), calendar)
+			logrus.WithFields(logrus.Fields{
+				"deposit_id":    deposit.ID,
+				"amount":        deposit.Amount,
+				"interest_rate": deposit.InterestRate,
+				"end_date":      deposit.EndDate,
+			}).Info("Вклад пролонгирован")
+			return 0, nil
+		}
+	}
+
+	return deposit.Close(now), nil
+}
+
+// credit добавляет к зачислениям выплату процентов или суммы вклада на счет клиента
+func (s *depositService) credit(credits []domain.Transaction, deposit *domain.TermDeposit, amount float64, description string) []domain.Transaction {
+	if amount <= 0 {
+		return credits
+	}
+	return append(credits, domain.Transaction{
+		Type:        domain.TransactionTypeDeposit,
+		ToAccountID: deposit.AccountID,
+		Amount:      amount,
+		Description: description,
+		Status:      domain.TransactionStatusCompleted,
+	})
+}
+
+// keyRate возвращает ключевую ставку, если от нее зависит ставка продукта
+func (s *depositService) keyRate(product *domain.DepositProduct) (float64, error) {
+	if !product.UsesKeyRate() {
+		return 0, nil
+	}
+	keyRate, err := s.keyRateService.GetKeyRate()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get key rate: %w", err)
+	}
+	return keyRate, nil
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"FinanceGolang/core/payloads"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+type DisputeService interface {
+	// Действия клиента
+	OpenDispute(userID uint, req *payloads.OpenDisputeRequest) (*domain.Dispute, error)
+	GetUserDisputes(userID uint) ([]domain.Dispute, error)
+	GetUserDispute(disputeID, userID uint) (*domain.Dispute, error)
+	AddAttachment(disputeID, userID uint, req *payloads.DisputeAttachmentRequest) (*domain.Dispute, error)
+
+	// Действия оператора
+	GetDisputes(status domain.DisputeStatus) ([]domain.Dispute, error)
+	GetDispute(disputeID uint) (*domain.Dispute, error)
+	StartReview(disputeID, operatorID uint, comment string) (*domain.Dispute, error)
+	PostProvisionalCredit(disputeID, operatorID uint, comment string) (*domain.Dispute, error)
+	ResolveDispute(disputeID, operatorID uint, req *payloads.ResolveDisputeRequest) (*domain.Dispute, error)
+
+	// Контроль сроков рассмотрения
+	ProcessSLA() (int, error)
+}
+
+type disputeService struct {
+	disputeRepo     dbaccess.DisputeRepository
+	transactionRepo dbaccess.TransactionRepository
+	cardRepo        dbaccess.CardRepository
+	clock           domain.Clock
+}
+
+func DisputeServiceInstance(
+	disputeRepo dbaccess.DisputeRepository,
+	transactionRepo dbaccess.TransactionRepository,
+	cardRepo dbaccess.CardRepository,
+	clock domain.Clock,
+) DisputeService {
+	return &disputeService{
+		disputeRepo:     disputeRepo,
+		transactionRepo: transactionRepo,
+		cardRepo:        cardRepo,
+		clock:           clock,
+	}
+}
+
+// OpenDispute открывает спор по операции по карте пользователя
+func (s *disputeService) OpenDispute(userID uint, req *payloads.OpenDisputeRequest) (*domain.Dispute, error) {
+	transaction, err := s.transactionRepo.GetByID(context.Background(), req.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.CardID == 0 {
+		return nil, domain.ErrDisputeNotAllowed
+	}
+
+	card, err := s.cardRepo.GetByID(context.Background(), transaction.CardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card: %v", err)
+	}
+	if card.UserID != userID {
+		return nil, domain.ErrCardNotOwned
+	}
+
+	now := s.clock.Now()
+	if err := domain.CanDisputeTransaction(transaction, now); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.disputeRepo.GetByTransactionID(context.Background(), transaction.ID); err == nil {
+		return nil, domain.ErrDisputeExists
+	} else if !errors.Is(err, dbaccess.ErrNotFound) {
+		return nil, fmt.Errorf("failed to check existing dispute: %v", err)
+	}
+
+	dispute, err := domain.NewDispute(transaction, userID, domain.DisputeReason(req.Reason), req.Comment, req.Amount, now)
+	if err != nil {
+		return nil, err
+	}
+
+	event := dispute.NewEvent(domain.DisputeEventOpened, userID, req.Comment)
+	if err := s.disputeRepo.CreateWithEvent(context.Background(), dispute, event); err != nil {
+		return nil, fmt.Errorf("failed to create dispute: %v", err)
+	}
+
+	return s.disputeRepo.GetByID(context.Background(), dispute.ID)
+}
+
+// GetUserDisputes возвращает споры пользователя
+func (s *disputeService) GetUserDisputes(userID uint) ([]domain.Dispute, error) {
+	return s.disputeRepo.GetByUserID(context.Background(), userID)
+}
+
+// GetUserDispute возвращает спор пользователя с историей и вложениями
+func (s *disputeService) GetUserDispute(disputeID, userID uint) (*domain.Dispute, error) {
+	dispute, err := s.disputeRepo.GetByID(context.Background(), disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.UserID != userID {
+		return nil, domain.ErrDisputeNotOwned
+	}
+	return dispute, nil
+}
+
+// AddAttachment сохраняет описание файла, приложенного клиентом к спору
+func (s *disputeService) AddAttachment(disputeID, userID uint, req *payloads.DisputeAttachmentRequest) (*domain.Dispute, error) {
+	dispute, err := s.GetUserDispute(disputeID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.IsResolved() {
+		return nil, domain.ErrDisputeClosed
+	}
+
+	count, err := s.disputeRepo.CountAttachments(context.Background(), dispute.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count attachments: %v", err)
+	}
+	if count >= domain.MaxDisputeAttachments {
+		return nil, domain.ErrDisputeAttachmentsExceeded
+	}
+
+	attachment := &domain.DisputeAttachment{
+		DisputeID:   dispute.ID,
+		FileName:    req.FileName,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		Checksum:    req.Checksum,
+		StorageURL:  req.StorageURL,
+		UploadedBy:  userID,
+	}
+	if err := attachment.Validate(); err != nil {
+		return nil, err
+	}
+
+	event := dispute.NewEvent(domain.DisputeEventAttachmentAdded, userID, req.FileName)
+	if err := s.disputeRepo.AddAttachment(context.Background(), attachment, event); err != nil {
+		return nil, fmt.Errorf("failed to add attachment: %v", err)
+	}
+
+	return s.disputeRepo.GetByID(context.Background(), dispute.ID)
+}
+
+// GetDisputes возвращает споры в указанном статусе или все нерешенные споры
+func (s *disputeService) GetDisputes(status domain.DisputeStatus) ([]domain.Dispute, error) {
+	if status == "" {
+		return s.disputeRepo.GetUnresolved(context.Background())
+	}
+	return s.disputeRepo.GetByStatus(context.Background(), status)
+}
+
+// GetDispute возвращает спор с историей и вложениями
+func (s *disputeService) GetDispute(disputeID uint) (*domain.Dispute, error) {
+	return s.disputeRepo.GetByID(context.Background(), disputeID)
+}
+
+// StartReview назначает спор оператору и переводит его в работу
+func (s *disputeService) StartReview(disputeID, operatorID uint, comment string) (*domain.Dispute, error) {
+	dispute, err := s.disputeRepo.GetByID(context.Background(), disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := dispute.StartReview(operatorID); err != nil {
+		return nil, err
+	}
+
+	event := dispute.NewEvent(domain.DisputeEventReviewStarted, operatorID, comment)
+	if err := s.disputeRepo.UpdateWithEvent(context.Background(), dispute, event); err != nil {
+		return nil, fmt.Errorf("failed to update dispute: %v", err)
+	}
+
+	return s.disputeRepo.GetByID(context.Background(), dispute.ID)
+}
+
+// PostProvisionalCredit зачисляет клиенту оспариваемую сумму до принятия решения по спору
+func (s *disputeService) PostProvisionalCredit(disputeID, operatorID uint, comment string) (*domain.Dispute, error) {
+	dispute, err := s.disputeRepo.GetByID(context.Background(), disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.IsResolved() {
+		return nil, domain.ErrDisputeClosed
+	}
+	if dispute.HasProvisionalCredit() {
+		return nil, domain.ErrProvisionalCreditPosted
+	}
+
+	credit := s.newCredit(dispute, fmt.Sprintf("Предварительное зачисление по спору #%d", dispute.ID))
+	event := dispute.NewEvent(domain.DisputeEventProvisionalCredit, operatorID, comment)
+	if err := s.disputeRepo.PostProvisionalCredit(context.Background(), dispute, credit, event); err != nil {
+		return nil, disputeUpdateError(err)
+	}
+
+	return s.disputeRepo.GetByID(context.Background(), dispute.ID)
+}
+
+// ResolveDispute фиксирует решение по спору. При выигрыше предварительное зачисление
+// становится окончательным (если его не было, сумма зачисляется сейчас), при проигрыше
+// предварительное зачисление списывается
+func (s *disputeService) ResolveDispute(disputeID, operatorID uint, req *payloads.ResolveDisputeRequest) (*domain.Dispute, error) {
+	dispute, err := s.disputeRepo.GetByID(context.Background(), disputeID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.DisputeStatus(req.Outcome)
+	if err := dispute.Resolve(status, operatorID, req.Comment, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	var posting *domain.Transaction
+	var events []*domain.DisputeEvent
+	eventType := domain.DisputeEventWon
+	switch status {
+	case domain.DisputeStatusWon:
+		if !dispute.HasProvisionalCredit() {
+			posting = s.newCredit(dispute, fmt.Sprintf("Возврат средств по спору #%d", dispute.ID))
+		}
+	case domain.DisputeStatusLost:
+		eventType = domain.DisputeEventLost
+		if dispute.HasProvisionalCredit() {
+			posting = s.newCreditReversal(dispute)
+			events = append(events, dispute.NewEvent(domain.DisputeEventProvisionalCreditReverse, operatorID, posting.Description))
+		}
+	}
+	events = append(events, dispute.NewEvent(eventType, operatorID, req.Comment))
+
+	if err := s.disputeRepo.Resolve(context.Background(), dispute, posting, events...); err != nil {
+		return nil, disputeUpdateError(err)
+	}
+
+	return s.disputeRepo.GetByID(context.Background(), dispute.ID)
+}
+
+// newCredit готовит зачисление оспариваемой суммы на счет карты
+func (s *disputeService) newCredit(dispute *domain.Dispute, description string) *domain.Transaction {
+	now := s.clock.Now()
+	return &domain.Transaction{
+		Type:        domain.TransactionTypeDeposit,
+		ToAccountID: dispute.AccountID,
+		Amount:      dispute.Amount,
+		Description: description,
+		Status:      domain.TransactionStatusCompleted,
+		CompletedAt: &now,
+	}
+}
+
+// newCreditReversal готовит списание предварительного зачисления. Списание проводится
+// и при недостаточном остатке: образовавшаяся задолженность погашается из поступлений
+func (s *disputeService) newCreditReversal(dispute *domain.Dispute) *domain.Transaction {
+	now := s.clock.Now()
+	return &domain.Transaction{
+		Type:          domain.TransactionTypeWithdrawal,
+		FromAccountID: dispute.AccountID,
+		Amount:        dispute.Amount,
+		Description:   fmt.Sprintf("Отмена предварительного зачисления по спору #%d", dispute.ID),
+		Status:        domain.TransactionStatusCompleted,
+		CompletedAt:   &now,
+	}
+}
+
+// disputeUpdateError оставляет доменные ошибки как есть, чтобы контроллер подобрал для них статус
+func disputeUpdateError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrDisputeClosed), errors.Is(err, domain.ErrProvisionalCreditPosted),
+		errors.Is(err, domain.ErrDisputedTransactionVoided):
+		return err
+	default:
+		return fmt.Errorf("failed to update dispute: %v", err)
+	}
+}
+
+// ProcessSLA отмечает споры, не взятые в работу или не решенные в срок.
+// Возвращает количество новых нарушений сроков
+func (s *disputeService) ProcessSLA() (int, error) {
+	disputes, err := s.disputeRepo.GetUnresolved(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unresolved disputes: %v", err)
+	}
+
+	now := s.clock.Now()
+	breaches := 0
+	for i := range disputes {
+		dispute := &disputes[i]
+		if dispute.IsReviewOverdue(now) {
+			dispute.ReviewOverdueAt = &now
+			if s.markOverdue(dispute, domain.DisputeEventReviewOverdue, "Спор не взят в работу в срок") {
+				breaches++
+			}
+		}
+		if dispute.IsResolutionOverdue(now) {
+			dispute.ResolutionOverdueAt = &now
+			if s.markOverdue(dispute, domain.DisputeEventResolutionOverdue, "Решение по спору не принято в срок") {
+				breaches++
+			}
+		}
+	}
+	return breaches, nil
+}
+
+// markOverdue сохраняет нарушение срока, ошибки только логируются
+func (s *disputeService) markOverdue(dispute *domain.Dispute, eventType domain.DisputeEventType, comment string) bool {
+	logrus.WithFields(logrus.Fields{
+		"dispute_id": dispute.ID,
+		"event":      eventType,
+	}).Warn(comment)
+
+	event := dispute.NewEvent(eventType, 0, comment)
+	if err := s.disputeRepo.UpdateWithEvent(context.Background(), dispute, event); err != nil {
+		logrus.WithError(err).WithField("dispute_id", dispute.ID).Error("Ошибка сохранения нарушения срока по спору")
+		return false
+	}
+	return true
+}
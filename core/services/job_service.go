@@ -0,0 +1,395 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JobHistoryLimit сколько последних запусков возвращается вместе с задачей
+const JobHistoryLimit = 50
+
+// JobDefinition фоновая задача, которую приложение умеет выполнять.
+// Schedule задает расписание cron по умолчанию
+type JobDefinition struct {
+	Name        string
+	Description string
+	Schedule    string
+	Run         func() error
+}
+
+// JobOptions настройки выполнения фоновых задач
+type JobOptions struct {
+	Instance     string            // идентификатор экземпляра для блокировок
+	PollInterval time.Duration     // как часто проверять сроки запуска
+	LeaseTTL     time.Duration     // на сколько захватывается задача; продлевается, пока она выполняется
+	Schedules    map[string]string // расписания из конфигурации, заменяющие расписания по умолчанию
+	Clock        domain.Clock      // часы, по которым наступают сроки запуска; блокировки идут по системному времени
+}
+
+type JobService interface {
+	// Register создает или обновляет записи задач и их расписания
+	Register() error
+	// Start запускает задачи по расписанию, пока не отменен ctx
+	Start(ctx context.Context)
+	// RunDue выполняет задачи, срок которых наступил, и ждет их завершения
+	RunDue() []domain.JobRun
+
+	GetJobs() ([]domain.Job, error)
+	GetJob(name string) (*domain.Job, []domain.JobRun, error)
+	Pause(name string) (*domain.Job, error)
+	Resume(name string) (*domain.Job, error)
+	Trigger(name string, actorID uint) (*domain.JobRun, error)
+}
+
+// registeredJob задача вместе с разобранным расписанием
+type registeredJob struct {
+	JobDefinition
+	schedule *domain.CronSchedule
+}
+
+// dueJob задача, срок запуска которой наступил
+type dueJob struct {
+	job        *domain.Job
+	definition *registeredJob
+	trigger    domain.JobTrigger
+	missed     int
+}
+
+type jobService struct {
+	jobRepo     dbaccess.JobRepository
+	definitions map[string]*registeredJob
+	order       []string
+	options     JobOptions
+	err         error // ошибка разбора расписаний, возвращается из Register
+}
+
+func JobServiceInstance(jobRepo dbaccess.JobRepository, definitions []JobDefinition, options JobOptions) JobService {
+	if options.Clock == nil {
+		options.Clock = domain.SystemClock
+	}
+	s := &jobService{
+		jobRepo:     jobRepo,
+		definitions: make(map[string]*registeredJob, len(definitions)),
+		options:     options,
+	}
+	for _, definition := range definitions {
+		expr := definition.Schedule
+		if configured, ok := options.Schedules[definition.Name]; ok {
+			expr = configured
+		}
+		schedule, err := domain.ParseCron(expr)
+		if err != nil {
+			s.err = errors.Join(s.err, fmt.Errorf("invalid schedule for job %s: %w", definition.Name, err))
+			continue
+		}
+		definition.Schedule = schedule.String()
+		s.definitions[definition.Name] = &registeredJob{JobDefinition: definition, schedule: schedule}
+		s.order = append(s.order, definition.Name)
+	}
+	return s
+}
+
+// Register создает записи новых задач и обновляет расписание существующих.
+// При смене расписания следующий запуск пересчитывается от текущего момента,
+// как и в случае, когда срок запуска дальше ближайшего по расписанию: так бывает,
+// если часы вернули назад после моделирования времени. Вызывается при старте
+// приложения, даже если выполнение задач отключено
+func (s *jobService) Register() error {
+	if s.err != nil {
+		return s.err
+	}
+	for name := range s.options.Schedules {
+		if _, ok := s.definitions[name]; !ok {
+			logrus.WithField("job", name).Warn("Расписание задано для неизвестной фоновой задачи")
+		}
+	}
+
+	now := s.options.Clock.Now()
+	for _, name := range s.order {
+		definition := s.definitions[name]
+		job, err := s.jobRepo.GetByName(context.Background(), name)
+		if errors.Is(err, dbaccess.ErrNotFound) {
+			job = &domain.Job{
+				Name:        name,
+				Description: definition.Description,
+				Schedule:    definition.Schedule,
+				Status:      domain.JobStatusActive,
+			}
+			job.Reschedule(definition.schedule, now)
+			if err := s.jobRepo.Create(context.Background(), job); err != nil {
+				return fmt.Errorf("failed to create job %s: %w", name, err)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get job %s: %w", name, err)
+		}
+
+		if !job.IsPaused() && (job.Schedule != definition.Schedule ||
+			job.NextRunAt == nil || job.NextRunAt.After(definition.schedule.Next(now))) {
+			job.Reschedule(definition.schedule, now)
+		}
+		job.Schedule = definition.Schedule
+		job.Description = definition.Description
+		if err := s.jobRepo.UpdateSchedule(context.Background(), job); err != nil {
+			return fmt.Errorf("failed to update job %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Start периодически запускает задачи, срок которых наступил. Пропущенные
+// за время остановки приложения сроки догоняются одним запуском
+func (s *jobService) Start(ctx context.Context) {
+	logrus.WithFields(logrus.Fields{
+		"instance": s.options.Instance,
+		"jobs":     len(s.order),
+	}).Info("Запущен планировщик фоновых задач")
+
+	ticker := time.NewTicker(s.options.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Каждая задача выполняется в своей горутине, чтобы долгая задача не задерживала остальные
+		for _, due := range s.dueJobs() {
+			go func() {
+				if _, err := s.execute(due.job, due.definition, due.trigger, due.missed, 0); err != nil && !errors.Is(err, domain.ErrJobRunning) {
+					logrus.WithError(err).WithField("job", due.job.Name).Error("Ошибка при запуске фоновой задачи")
+				}
+			}()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue выполняет задачи, срок которых наступил, по очереди в порядке сроков
+// и возвращает завершенные запуски. Используется при переводе смоделированных
+// часов, чтобы задачи отработали каждый пройденный операционный день
+func (s *jobService) RunDue() []domain.JobRun {
+	var runs []domain.JobRun
+	for _, due := range s.dueJobs() {
+		run, err := s.execute(due.job, due.definition, due.trigger, due.missed, 0)
+		if err != nil {
+			if !errors.Is(err, domain.ErrJobRunning) {
+				logrus.WithError(err).WithField("job", due.job.Name).Error("Ошибка при запуске фоновой задачи")
+			}
+			continue
+		}
+		runs = append(runs, *run)
+	}
+	return runs
+}
+
+// dueJobs возвращает задачи, срок которых наступил, в порядке сроков запуска
+func (s *jobService) dueJobs() []dueJob {
+	jobs, err := s.jobRepo.List(context.Background(), 0, -1)
+	if err != nil {
+		logrus.WithError(err).Error("Ошибка при получении фоновых задач")
+		return nil
+	}
+
+	now := s.options.Clock.Now()
+	var due []dueJob
+	for i := range jobs {
+		job := &jobs[i]
+		definition, ok := s.definitions[job.Name]
+		if !ok || !job.IsDue(now) || job.IsRunning(time.Now()) {
+			continue
+		}
+
+		trigger := domain.JobTriggerSchedule
+		if now.Sub(*job.NextRunAt) > s.options.PollInterval {
+			trigger = domain.JobTriggerCatchUp
+		}
+		due = append(due, dueJob{
+			job:        job,
+			definition: definition,
+			trigger:    trigger,
+			missed:     domain.MissedRuns(definition.schedule, *job.NextRunAt, now),
+		})
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].job.NextRunAt.Before(*due[j].job.NextRunAt)
+	})
+	return due
+}
+
+// GetJobs возвращает все задачи
+func (s *jobService) GetJobs() ([]domain.Job, error) {
+	return s.jobRepo.List(context.Background(), 0, -1)
+}
+
+// GetJob возвращает задачу и последние запуски
+func (s *jobService) GetJob(name string) (*domain.Job, []domain.JobRun, error) {
+	job, err := s.jobRepo.GetByName(context.Background(), name)
+	if err != nil {
+		return nil, nil, err
+	}
+	runs, err := s.jobRepo.GetRuns(context.Background(), job.ID, JobHistoryLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, runs, nil
+}
+
+// Pause приостанавливает запуски задачи по расписанию. Уже начатый запуск
+// доводится до конца
+func (s *jobService) Pause(name string) (*domain.Job, error) {
+	job, err := s.jobRepo.GetByName(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+	if err := job.Pause(); err != nil {
+		return nil, err
+	}
+	if err := s.jobRepo.UpdateSchedule(context.Background(), job); err != nil {
+		return nil, fmt.Errorf("failed to pause job: %w", err)
+	}
+	logrus.WithField("job", name).Info("Фоновая задача приостановлена")
+	return job, nil
+}
+
+// Resume возобновляет запуски задачи по расписанию
+func (s *jobService) Resume(name string) (*domain.Job, error) {
+	definition, ok := s.definitions[name]
+	if !ok {
+		return nil, dbaccess.ErrNotFound
+	}
+	job, err := s.jobRepo.GetByName(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+	if err := job.Resume(definition.schedule, s.options.Clock.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.jobRepo.UpdateSchedule(context.Background(), job); err != nil {
+		return nil, fmt.Errorf("failed to resume job: %w", err)
+	}
+	logrus.WithField("job", name).Info("Фоновая задача возобновлена")
+	return job, nil
+}
+
+// Trigger выполняет задачу немедленно, в том числе приостановленную.
+// Срок следующего запуска по расписанию не меняется
+func (s *jobService) Trigger(name string, actorID uint) (*domain.JobRun, error) {
+	definition, ok := s.definitions[name]
+	if !ok {
+		return nil, dbaccess.ErrNotFound
+	}
+	job, err := s.jobRepo.GetByName(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+	return s.execute(job, definition, domain.JobTriggerManual, 0, actorID)
+}
+
+// execute захватывает задачу, выполняет ее и сохраняет результат в истории.
+// Если задачу уже выполняет этот или другой экземпляр, возвращает ErrJobRunning.
+// Сроки и история запусков ведутся по часам сервиса, блокировка - по системному времени
+func (s *jobService) execute(job *domain.Job, definition *registeredJob, trigger domain.JobTrigger, missed int, actorID uint) (*domain.JobRun, error) {
+	now := s.options.Clock.Now()
+	token, err := leaseToken()
+	if err != nil {
+		return nil, err
+	}
+	var dueBy time.Time
+	if trigger != domain.JobTriggerManual {
+		dueBy = now
+	}
+	acquired, err := s.jobRepo.AcquireLease(context.Background(), job, s.options.Instance, token,
+		time.Now().Add(s.options.LeaseTTL), time.Now(), dueBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire job lease: %w", err)
+	}
+	if !acquired {
+		return nil, domain.ErrJobRunning
+	}
+
+	if err := s.jobRepo.AbandonRuns(context.Background(), job.ID, now); err != nil {
+		logrus.WithError(err).WithField("job", job.Name).Error("Ошибка при закрытии прерванных запусков")
+	}
+	run := domain.NewJobRun(job, trigger, actorID, s.options.Instance, now)
+	run.MissedRuns = missed
+	if err := s.jobRepo.CreateRun(context.Background(), run); err != nil {
+		logrus.WithError(err).WithField("job", job.Name).Error("Ошибка при сохранении запуска фоновой задачи")
+	}
+
+	stop := s.keepLease(job.ID, token)
+	runErr := runJob(definition.Run)
+	stop()
+
+	finished := s.options.Clock.Now()
+	run.Finish(runErr, finished)
+	job.Record(run)
+	if trigger != domain.JobTriggerManual {
+		job.Reschedule(definition.schedule, finished)
+	}
+	if err := s.jobRepo.FinishRun(context.Background(), job, run); err != nil {
+		return nil, fmt.Errorf("failed to save job run: %w", err)
+	}
+
+	entry := logrus.WithFields(logrus.Fields{
+		"job":         job.Name,
+		"run_id":      run.ID,
+		"trigger":     run.Trigger,
+		"missed_runs": run.MissedRuns,
+		"duration_ms": run.DurationMs,
+	})
+	if runErr != nil {
+		entry.WithError(runErr).Error("Фоновая задача завершилась с ошибкой")
+	} else {
+		entry.Info("Фоновая задача выполнена")
+	}
+	return run, nil
+}
+
+// keepLease продлевает блокировку, пока задача выполняется. Возвращает функцию остановки
+func (s *jobService) keepLease(jobID uint, token string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.options.LeaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.jobRepo.RenewLease(context.Background(), jobID, token, time.Now().Add(s.options.LeaseTTL)); err != nil {
+					logrus.WithError(err).WithField("job_id", jobID).Error("Ошибка при продлении блокировки фоновой задачи")
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// runJob выполняет задачу, превращая панику в ошибку запуска
+func runJob(run func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return run()
+}
+
+// leaseToken создает случайный идентификатор блокировки
+func leaseToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate lease token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
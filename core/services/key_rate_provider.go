@@ -0,0 +1,169 @@
+package services
+
+import (
+	"FinanceGolang/core/domain"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyRateProvider источник значений ключевой ставки
+type KeyRateProvider interface {
+	// Source возвращает вид источника для истории ставки
+	Source() domain.KeyRateSource
+	// Rates возвращает значения ставки за период с from по to в порядке дат
+	Rates(ctx context.Context, from, to time.Time) ([]domain.KeyRate, error)
+}
+
+// NewKeyRateProvider создает источник ключевой ставки по настройке: "stub" - локальный
+// файл (без пути - история, поставляемая с приложением), иначе веб-сервис Банка России
+func NewKeyRateProvider(kind, cbrURL, stubPath string, timeout time.Duration) KeyRateProvider {
+	if strings.EqualFold(kind, "stub") {
+		return NewStubKeyRateProvider(stubPath)
+	}
+	return NewCBRKeyRateProvider(cbrURL, timeout)
+}
+
+// Структуры для работы с XML-ответом от ЦБ РФ
+type KeyRateEnvelope struct {
+	XMLName xml.Name    `xml:"Envelope"`
+	Body    KeyRateBody `xml:"Body"`
+}
+
+type KeyRateBody struct {
+	Response KeyRateResponse `xml:"KeyRateXMLResponse"`
+}
+
+type KeyRateResponse struct {
+	Result KeyRateResult `xml:"KeyRateXMLResult"`
+}
+
+type KeyRateResult struct {
+	Rows []KeyRateRows `xml:"KeyRate"`
+}
+
+type KeyRateRows struct {
+	KeyRates []KeyRates `xml:"KR"`
+}
+
+type KeyRates struct {
+	Date string `xml:"DT" json:"date"`
+	Rate string `xml:"Rate" json:"rate"`
+}
+
+// Структура для SOAP-запроса
+type GetKeyRateXMLRequest struct {
+	XMLName  xml.Name `xml:"KeyRateXML"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	FromDate string   `xml:"fromDate"`
+	ToDate   string   `xml:"ToDate"`
+}
+
+// CBRKeyRateProvider получает ключевую ставку от веб-сервиса Банка России по SOAP
+type CBRKeyRateProvider struct {
+	client *cbrClient
+}
+
+// NewCBRKeyRateProvider создает клиент веб-сервиса Банка России. Сертификат сервера
+// проверяется, запрос ограничен таймаутом
+func NewCBRKeyRateProvider(url string, timeout time.Duration) *CBRKeyRateProvider {
+	return &CBRKeyRateProvider{client: newCBRClient(url, timeout)}
+}
+
+// Source возвращает вид источника
+func (p *CBRKeyRateProvider) Source() domain.KeyRateSource {
+	return domain.KeyRateSourceCBR
+}
+
+// Rates запрашивает значения ключевой ставки за период
+func (p *CBRKeyRateProvider) Rates(ctx context.Context, from, to time.Time) ([]domain.KeyRate, error) {
+	request := GetKeyRateXMLRequest{
+		Xmlns:    cbrNamespace,
+		FromDate: from.Format(domain.KeyRateDateLayout),
+		ToDate:   to.Format(domain.KeyRateDateLayout),
+	}
+	body, err := p.client.call(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var data KeyRateEnvelope
+	if err := xml.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("ошибка при парсинге XML: %v", err)
+	}
+
+	var rates []domain.KeyRate
+	for _, row := range data.Body.Response.Result.Rows {
+		for _, item := range row.KeyRates {
+			if len(item.Date) < len(domain.KeyRateDateLayout) {
+				return nil, fmt.Errorf("некорректная дата ключевой ставки: %q", item.Date)
+			}
+			value, err := strconv.ParseFloat(strings.TrimSpace(item.Rate), 64)
+			if err != nil {
+				return nil, fmt.Errorf("ошибка при конвертации ставки: %v", err)
+			}
+			rates = append(rates, domain.KeyRate{
+				Date:   item.Date[:len(domain.KeyRateDateLayout)],
+				Rate:   value,
+				Source: domain.KeyRateSourceCBR,
+			})
+		}
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Date < rates[j].Date })
+	return rates, nil
+}
+
+// StubKeyRateProvider берет ключевую ставку из локального файла, без обращения к ЦБ РФ.
+// Используется в изолированных и тестовых окружениях
+type StubKeyRateProvider struct {
+	path string
+}
+
+// NewStubKeyRateProvider создает заглушку по файлу path. Без пути используется
+// история, поставляемая с приложением. Файл читается при каждом запросе,
+// поэтому ставку можно поменять без перезапуска
+func NewStubKeyRateProvider(path string) *StubKeyRateProvider {
+	return &StubKeyRateProvider{path: path}
+}
+
+// Source возвращает вид источника
+func (p *StubKeyRateProvider) Source() domain.KeyRateSource {
+	return domain.KeyRateSourceStub
+}
+
+// Rates возвращает изменения ставки за период вместе со ставкой, действовавшей на его начало
+func (p *StubKeyRateProvider) Rates(ctx context.Context, from, to time.Time) ([]domain.KeyRate, error) {
+	var all []domain.KeyRate
+	var err error
+	if p.path == "" {
+		all, err = domain.DefaultStubKeyRates()
+	} else {
+		var data []byte
+		if data, err = os.ReadFile(p.path); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении файла ключевой ставки: %v", err)
+		}
+		all, err = domain.ParseKeyRates(data, domain.KeyRateSourceStub)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Date < all[j].Date })
+
+	start := from.Format(domain.KeyRateDateLayout)
+	end := to.Format(domain.KeyRateDateLayout)
+	var rates []domain.KeyRate
+	for i, rate := range all {
+		if rate.Date > end {
+			break
+		}
+		if rate.Date >= start || i == len(all)-1 || all[i+1].Date > start {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
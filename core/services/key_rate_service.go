@@ -0,0 +1,246 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// keyRateSyncWindow период, за который запрашивается ставка при обновлении
+const keyRateSyncWindow = 30 * 24 * time.Hour
+
+type KeyRateService interface {
+	GetKeyRate() (float64, error)
+	Current() (domain.KeyRateQuote, error)
+	History(from, to time.Time) ([]domain.KeyRate, error)
+	Sync() error
+}
+
+// KeyRateOptions политика кэширования ключевой ставки
+type KeyRateOptions struct {
+	CacheTTL     time.Duration // как долго значение считается актуальным без обращения к источнику
+	MaxStaleness time.Duration // как долго можно отдавать последнее известное значение, если источник недоступен
+}
+
+type keyRateService struct {
+	provider    KeyRateProvider
+	keyRateRepo dbaccess.KeyRateRepository
+	options     KeyRateOptions
+	clock       domain.Clock
+
+	mu          sync.Mutex
+	cached      *domain.KeyRateQuote
+	lastFailure time.Time
+	loaded      keyRateSpan // период, история за который полностью получена от источника
+}
+
+// keyRateSpan период дат ГГГГ-ММ-ДД
+type keyRateSpan struct {
+	from, to string
+}
+
+// covers проверяет, входит ли период from..to в span
+func (span keyRateSpan) covers(from, to string) bool {
+	return span.from != "" && span.from <= from && to <= span.to
+}
+
+// merge объединяет пересекающиеся периоды. Если периоды не пересекаются,
+// остается новый: история между ними не загружена
+func (span keyRateSpan) merge(from, to string) keyRateSpan {
+	if span.from == "" || to < span.from || from > span.to {
+		return keyRateSpan{from: from, to: to}
+	}
+	if from < span.from {
+		span.from = from
+	}
+	if to > span.to {
+		span.to = to
+	}
+	return span
+}
+
+func KeyRateServiceInstance(provider KeyRateProvider, keyRateRepo dbaccess.KeyRateRepository, options KeyRateOptions, clock domain.Clock) KeyRateService {
+	if options.CacheTTL <= 0 {
+		options.CacheTTL = time.Hour
+	}
+	if options.MaxStaleness < options.CacheTTL {
+		options.MaxStaleness = options.CacheTTL
+	}
+	return &keyRateService{
+		provider:    provider,
+		keyRateRepo: keyRateRepo,
+		options:     options,
+		clock:       clock,
+	}
+}
+
+// GetKeyRate возвращает текущее значение ключевой ставки
+func (s *keyRateService) GetKeyRate() (float64, error) {
+	quote, err := s.Current()
+	if err != nil {
+		return 0, err
+	}
+	return quote.Rate, nil
+}
+
+// Current возвращает текущую ключевую ставку. Источник опрашивается не чаще раза
+// за CacheTTL; если он недоступен, отдается последнее известное значение
+// не старше MaxStaleness с признаком Stale
+func (s *keyRateService) Current() (domain.KeyRateQuote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if s.cached != nil && now.Sub(s.cached.FetchedAt) < s.options.CacheTTL {
+		return *s.cached, nil
+	}
+
+	var refreshErr error
+	if now.Sub(s.lastFailure) >= cbrRetryDelay {
+		quote, err := s.refresh(now)
+		if err == nil {
+			return quote, nil
+		}
+		refreshErr = err
+	}
+	return s.fallback(now, refreshErr)
+}
+
+// Sync принудительно обновляет ставку из источника и сохраняет ее в историю
+func (s *keyRateService) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.refresh(s.clock.Now())
+	return err
+}
+
+// History возвращает историю ключевой ставки за период с from по to. Первой
+// записью идет ставка, действовавшая на начало периода. История, еще не полученная
+// от источника, сначала загружается; если источник недоступен, отдается сохраненная
+func (s *keyRateService) History(from, to time.Time) ([]domain.KeyRate, error) {
+	if from.After(to) {
+		return nil, fmt.Errorf("%w: from must not be after to", domain.ErrInvalidDateRange)
+	}
+	ctx := context.Background()
+	start := from.Format(domain.KeyRateDateLayout)
+	end := to.Format(domain.KeyRateDateLayout)
+
+	s.load(ctx, from, to)
+
+	rates, err := s.keyRateRepo.GetRange(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key rate history: %w", err)
+	}
+	if len(rates) == 0 || rates[0].Date > start {
+		effective, err := s.keyRateRepo.GetEffective(ctx, start)
+		switch {
+		case err == nil:
+			rates = append([]domain.KeyRate{*effective}, rates...)
+		case !errors.Is(err, dbaccess.ErrNotFound):
+			return nil, fmt.Errorf("failed to get key rate history: %w", err)
+		}
+	}
+	return rates, nil
+}
+
+// refresh запрашивает ставку у источника, сохраняет историю и обновляет кэш
+func (s *keyRateService) refresh(now time.Time) (domain.KeyRateQuote, error) {
+	rates, err := s.provider.Rates(context.Background(), now.Add(-keyRateSyncWindow), now)
+	if err == nil && len(rates) == 0 {
+		err = errors.New("источник не вернул значений ставки")
+	}
+	if err != nil {
+		s.lastFailure = now
+		return domain.KeyRateQuote{}, err
+	}
+	if err := s.keyRateRepo.SaveRates(context.Background(), rates); err != nil {
+		logrus.WithError(err).Warn("Не удалось сохранить историю ключевой ставки")
+	} else {
+		s.loaded = s.loaded.merge(now.Add(-keyRateSyncWindow).Format(domain.KeyRateDateLayout), now.Format(domain.KeyRateDateLayout))
+	}
+
+	latest := rates[len(rates)-1]
+	if s.cached == nil || s.cached.Rate != latest.Rate {
+		logrus.WithFields(logrus.Fields{
+			"rate":   latest.Rate,
+			"date":   latest.Date,
+			"source": latest.Source,
+		}).Info("Обновлена ключевая ставка")
+	}
+	s.cached = &domain.KeyRateQuote{
+		Rate:      latest.Rate,
+		Date:      latest.Date,
+		Source:    latest.Source,
+		FetchedAt: now,
+	}
+	s.lastFailure = time.Time{}
+	return *s.cached, nil
+}
+
+// fallback возвращает последнее известное значение из кэша или истории,
+// если оно не старше MaxStaleness
+func (s *keyRateService) fallback(now time.Time, cause error) (domain.KeyRateQuote, error) {
+	quote := s.cached
+	if quote == nil {
+		if latest, err := s.keyRateRepo.GetLatest(context.Background()); err == nil {
+			quote = &domain.KeyRateQuote{
+				Rate:      latest.Rate,
+				Date:      latest.Date,
+				Source:    latest.Source,
+				FetchedAt: latest.UpdatedAt,
+			}
+		}
+	}
+	if quote == nil || now.Sub(quote.FetchedAt) > s.options.MaxStaleness {
+		if cause != nil {
+			return domain.KeyRateQuote{}, fmt.Errorf("%w: %v", domain.ErrKeyRateUnavailable, cause)
+		}
+		return domain.KeyRateQuote{}, domain.ErrKeyRateUnavailable
+	}
+
+	entry := logrus.WithField("fetched_at", quote.FetchedAt)
+	if cause != nil {
+		entry = entry.WithError(cause)
+	}
+	entry.Warn("Источник ключевой ставки недоступен, используется последнее известное значение")
+
+	result := *quote
+	result.Stale = true
+	return result, nil
+}
+
+// load загружает от источника историю за период, если она еще не загружена.
+// Будущие даты не считаются загруженными: ставка на них появится позже
+func (s *keyRateService) load(ctx context.Context, from, to time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if to.After(now) {
+		to = now
+	}
+	start := from.Format(domain.KeyRateDateLayout)
+	end := to.Format(domain.KeyRateDateLayout)
+	if start > end || s.loaded.covers(start, end) || now.Sub(s.lastFailure) < cbrRetryDelay {
+		return
+	}
+
+	rates, err := s.provider.Rates(ctx, from, to)
+	if err == nil {
+		err = s.keyRateRepo.SaveRates(ctx, rates)
+	} else {
+		s.lastFailure = now
+	}
+	if err != nil {
+		logrus.WithError(err).Warn("Не удалось загрузить историю ключевой ставки")
+		return
+	}
+	s.loaded = s.loaded.merge(start, end)
+}
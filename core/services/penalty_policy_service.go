@@ -0,0 +1,75 @@
+package services
+
+import (
+	"FinanceGolang/core/dbaccess"
+	"FinanceGolang/core/domain"
+	"context"
+	"fmt"
+)
+
+type PenaltyPolicyService interface {
+	GetPolicies() ([]domain.PenaltyPolicy, error)
+	CreatePolicy(policy *domain.PenaltyPolicy) error
+	UpdatePolicy(id uint, policy *domain.PenaltyPolicy) (*domain.PenaltyPolicy, error)
+}
+
+type penaltyPolicyService struct {
+	penaltyPolicyRepo dbaccess.PenaltyPolicyRepository
+}
+
+func PenaltyPolicyServiceInstance(penaltyPolicyRepo dbaccess.PenaltyPolicyRepository) PenaltyPolicyService {
+	return &penaltyPolicyService{penaltyPolicyRepo: penaltyPolicyRepo}
+}
+
+// GetPolicies возвращает все политики неустойки
+func (s *penaltyPolicyService) GetPolicies() ([]domain.PenaltyPolicy, error) {
+	return s.penaltyPolicyRepo.List(context.Background(), 0, -1)
+}
+
+// CreatePolicy создает новую политику неустойки
+func (s *penaltyPolicyService) CreatePolicy(policy *domain.PenaltyPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if err := s.penaltyPolicyRepo.Create(context.Background(), policy); err != nil {
+		return fmt.Errorf("failed to create penalty policy: %w", err)
+	}
+	return s.applyDefault(policy)
+}
+
+// UpdatePolicy изменяет политику. Новые условия применяются к дальнейшему
+// начислению, уже начисленная неустойка не пересчитывается
+func (s *penaltyPolicyService) UpdatePolicy(id uint, update *domain.PenaltyPolicy) (*domain.PenaltyPolicy, error) {
+	policy, err := s.penaltyPolicyRepo.GetByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.Name = update.Name
+	policy.DailyRate = update.DailyRate
+	policy.LateFee = update.LateFee
+	policy.GraceDays = update.GraceDays
+	policy.IsDefault = update.IsDefault
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.penaltyPolicyRepo.Update(context.Background(), policy); err != nil {
+		return nil, fmt.Errorf("failed to update penalty policy: %w", err)
+	}
+	if err := s.applyDefault(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// applyDefault оставляет признак политики по умолчанию только у одной политики
+func (s *penaltyPolicyService) applyDefault(policy *domain.PenaltyPolicy) error {
+	if !policy.IsDefault {
+		return nil
+	}
+	if err := s.penaltyPolicyRepo.ClearDefault(context.Background(), policy.ID); err != nil {
+		return fmt.Errorf("failed to update default penalty policy: %w", err)
+	}
+	return nil
+}
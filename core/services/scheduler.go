@@ -2,8 +2,8 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
 
 	"FinanceGolang/core/dbaccess"
 	"FinanceGolang/core/dbcore"
@@ -11,319 +11,289 @@ import (
 )
 
 type Scheduler struct {
-	creditRepo      dbaccess.CreditRepository
-	accountRepo     dbaccess.AccountRepository
-	transactionRepo dbaccess.TransactionRepository
-	userRepo        dbaccess.UserRepository
-	keyRateService  *ExternalService
+	creditRepo        dbaccess.CreditRepository
+	accountRepo       dbaccess.AccountRepository
+	transactionRepo   dbaccess.TransactionRepository
+	userRepo          dbaccess.UserRepository
+	externalService   *ExternalService
+	keyRateService    KeyRateService
+	currencyRates     CurrencyRateService
+	creditService     CreditService
+	creditLineService CreditLineService
+	depositService    DepositService
+	cardService       CardService
+	disputeService    DisputeService
+	collectionService CollectionService
+	clock             domain.Clock
 }
 
 func NewScheduler(
 	creditRepo dbaccess.CreditRepository,
 	accountRepo dbaccess.AccountRepository,
 	transactionRepo dbaccess.TransactionRepository,
-	keyRateService *ExternalService,
+	externalService *ExternalService,
+	keyRateService KeyRateService,
+	currencyRates CurrencyRateService,
+	cardService CardService,
+	disputeService DisputeService,
+	clock domain.Clock,
 ) *Scheduler {
+	creditProductRepo := dbaccess.CreditProductRepositoryInstance(dbcore.DB)
+	penaltyPolicyRepo := dbaccess.PenaltyPolicyRepositoryInstance(dbcore.DB)
+	userRepo := dbaccess.UserRepositoryInstance(dbcore.DB)
+	creditLineRepo := dbaccess.CreditLineRepositoryInstance(dbcore.DB)
+	calendarRepo := dbaccess.CalendarRepositoryInstance(dbcore.DB)
+	creditService := CreditServiceInstance(creditRepo, accountRepo, transactionRepo, creditProductRepo, penaltyPolicyRepo, userRepo, keyRateService, calendarRepo, clock)
 	return &Scheduler{
-		creditRepo:      creditRepo,
-		accountRepo:     accountRepo,
-		transactionRepo: transactionRepo,
-		userRepo:        dbaccess.UserRepositoryInstance(dbcore.DB),
-		keyRateService:  keyRateService,
+		creditRepo:        creditRepo,
+		accountRepo:       accountRepo,
+		transactionRepo:   transactionRepo,
+		userRepo:          userRepo,
+		externalService:   externalService,
+		keyRateService:    keyRateService,
+		currencyRates:     currencyRates,
+		creditService:     creditService,
+		creditLineService: CreditLineServiceInstance(creditLineRepo, accountRepo, transactionRepo, userRepo, clock),
+		depositService: DepositServiceInstance(dbaccess.DepositProductRepositoryInstance(dbcore.DB),
+			dbaccess.TermDepositRepositoryInstance(dbcore.DB), accountRepo, keyRateService, calendarRepo, clock),
+		cardService:    cardService,
+		disputeService: disputeService,
+		collectionService: CollectionServiceInstance(dbaccess.DunningStageRepositoryInstance(dbcore.DB),
+			dbaccess.CollectionCaseRepositoryInstance(dbcore.DB), creditRepo, userRepo, creditService, externalService, clock),
+		clock: clock,
 	}
 }
 
-// Start запускает шедулер
-func (s *Scheduler) Start() {
-	// Проверка платежей каждые 12 часов
-	go s.checkPayments()
-}
+// Имена фоновых задач. Расписание по умолчанию можно переопределить
+// переменной окружения JOB_SCHEDULE_<ИМЯ>, например JOB_SCHEDULE_CHECK_PAYMENTS
+const (
+	JobCheckPayments      = "check-payments"
+	JobAccruePenalties    = "accrue-penalties"
+	JobProcessDunning     = "process-dunning"
+	JobProcessCreditLines = "process-credit-lines"
+	JobProcessDeposits    = "process-deposits"
+	JobProcessCards       = "process-cards"
+	JobProcessDisputes    = "process-disputes"
+	JobSyncKeyRate        = "sync-key-rate"
+	JobSyncCurrencyRates  = "sync-currency-rates"
+)
 
-// checkPayments проверяет и обрабатывает платежи по кредитам
-func (s *Scheduler) checkPayments() {
-	ticker := time.NewTicker(12 * time.Hour)
-	defer ticker.Stop()
+// Jobs возвращает фоновые задачи шедулера с расписаниями по умолчанию.
+// Неустойка начисляется до прохода по просрочке, чтобы письма взыскания
+// содержали актуальную сумму
+func (s *Scheduler) Jobs() []JobDefinition {
+	return []JobDefinition{
+		{
+			Name:        JobCheckPayments,
+			Description: "Списание наступивших платежей по кредитам и перевод неоплаченных в просрочку",
+			Schedule:    "0 */12 * * *",
+			Run:         s.CheckPayments,
+		},
+		{
+			Name:        JobAccruePenalties,
+			Description: "Начисление пеней и штрафов по просроченным платежам",
+			Schedule:    "30 0 * * *",
+			Run:         s.AccruePenalties,
+		},
+		{
+			Name:        JobProcessDunning,
+			Description: "Стадии взыскания по просроченным кредитам",
+			Schedule:    "0 10 * * *",
+			Run:         s.ProcessDunning,
+		},
+		{
+			Name:        JobProcessCreditLines,
+			Description: "Проценты и выписки по кредитным линиям",
+			Schedule:    "0 1 * * *",
+			Run:         s.ProcessCreditLines,
+		},
+		{
+			Name:        JobProcessDeposits,
+			Description: "Проценты и окончание срока вкладов",
+			Schedule:    "0 2 * * *",
+			Run:         s.ProcessDeposits,
+		},
+		{
+			Name:        JobProcessCards,
+			Description: "Закрытие и перевыпуск карт с истекающим сроком, снятие просроченных холдов",
+			Schedule:    "5 * * * *",
+			Run:         s.ProcessCards,
+		},
+		{
+			Name:        JobProcessDisputes,
+			Description: "Контроль сроков рассмотрения споров по операциям",
+			Schedule:    "15 * * * *",
+			Run:         s.ProcessDisputes,
+		},
+		{
+			Name:        JobSyncKeyRate,
+			Description: "Обновление ключевой ставки и ее истории из источника",
+			Schedule:    "0 */6 * * *",
+			Run:         s.SyncKeyRate,
+		},
+		{
+			Name:        JobSyncCurrencyRates,
+			Description: "Загрузка официальных курсов валют ЦБ РФ на сегодня и завтра",
+			Schedule:    "20 */3 * * *",
+			Run:         s.SyncCurrencyRates,
+		},
+	}
+}
 
-	for range ticker.C {
-		credits, err := s.creditRepo.GetActiveCredits(context.Background())
-		if err != nil {
-			fmt.Printf("Ошибка при получении кредитов: %v\n", err)
-			continue
-		}
+// SyncCurrencyRates загружает курсы валют, установленные ЦБ РФ
+func (s *Scheduler) SyncCurrencyRates() error {
+	return s.currencyRates.Sync()
+}
 
-		for _, credit := range credits {
-			if credit.Status == domain.CreditStatusActive {
-				s.processCreditPayment(&credit)
-			}
-		}
-	}
+// SyncKeyRate обновляет ключевую ставку, чтобы выдача кредитов не ждала источник
+func (s *Scheduler) SyncKeyRate() error {
+	return s.keyRateService.Sync()
 }
 
-// processCreditPayment обрабатывает платеж по кредиту
-func (s *Scheduler) processCreditPayment(credit *domain.Credit) {
-	// Получаем график платежей
-	schedule, err := s.creditRepo.GetCreditsByDateRange(context.Background(), credit.StartDate, credit.EndDate)
+// ProcessDisputes отмечает споры, не взятые в работу или не решенные в срок
+func (s *Scheduler) ProcessDisputes() error {
+	breaches, err := s.disputeService.ProcessSLA()
 	if err != nil {
-		fmt.Printf("Ошибка при получении графика платежей: %v\n", err)
-		return
+		return err
 	}
-
-	now := time.Now()
-	for _, payment := range schedule {
-		// Если платеж просрочен и не оплачен
-		if payment.NextPayment.Before(now) && payment.Status == domain.CreditStatusActive {
-			// Проверяем баланс счета
-			account, err := s.accountRepo.GetByID(context.Background(), credit.AccountID)
-			if err != nil {
-				fmt.Printf("Ошибка при получении счета: %v\n", err)
-				continue
-			}
-
-			monthlyPayment := payment.CalculateMonthlyPayment()
-
-			// Если на счету достаточно средств
-			if account.Balance >= monthlyPayment {
-				// Списание средств
-				account.Balance -= monthlyPayment
-				if err := s.accountRepo.Update(context.Background(), account); err != nil {
-					fmt.Printf("Ошибка при списании средств: %v\n", err)
-					continue
-				}
-
-				// Обновление статуса платежа
-				payment.Status = domain.CreditStatusPaid
-				now := time.Now()
-				payment.LastPayment = now
-
-				// Создаем транзакцию о платеже
-				transaction := &domain.Transaction{
-					Type:          domain.TransactionTypePayment,
-					FromAccountID: credit.AccountID,
-					Amount:        monthlyPayment,
-					Description:   fmt.Sprintf("Платеж по кредиту #%d", credit.ID),
-					Status:        domain.TransactionStatusCompleted,
-				}
-				if err := s.transactionRepo.Create(context.Background(), transaction); err != nil {
-					fmt.Printf("Ошибка при создании транзакции: %v\n", err)
-					continue
-				}
-
-				// Отправка уведомления
-				user, err := s.userRepo.GetByID(context.Background(), account.UserID)
-				if err != nil {
-					fmt.Printf("Ошибка при получении пользователя: %v\n", err)
-					continue
-				}
-
-				if user == nil {
-					fmt.Printf("Пользователь не найден\n")
-					continue
-				}
-
-				if err := s.keyRateService.SendPaymentNotification(
-					user.Email,
-					"Платеж по кредиту",
-					monthlyPayment,
-				); err != nil {
-					fmt.Printf("Ошибка при отправке уведомления: %v\n", err)
-				}
-			} else {
-				// Начисление штрафа за просрочку
-				penalty := monthlyPayment * 0.1
-				payment.OverdueAmount += penalty
-				payment.Status = domain.CreditStatusOverdue
-
-				// Создаем транзакцию о штрафе
-				transaction := &domain.Transaction{
-					Type:          domain.TransactionTypePayment,
-					FromAccountID: credit.AccountID,
-					Amount:        penalty,
-					Description:   fmt.Sprintf("Штраф за просрочку платежа по кредиту #%d", credit.ID),
-					Status:        domain.TransactionStatusCompleted,
-				}
-				if err := s.transactionRepo.Create(context.Background(), transaction); err != nil {
-					fmt.Printf("Ошибка при создании транзакции: %v\n", err)
-					continue
-				}
-
-				// Отправка уведомления о просрочке
-				user, err := s.userRepo.GetByID(context.Background(), account.UserID)
-				if err != nil {
-					fmt.Printf("Ошибка при получении пользователя: %v\n", err)
-					continue
-				}
-
-				if user == nil {
-					fmt.Printf("Пользователь не найден\n")
-					continue
-				}
-
-				if err := s.keyRateService.SendPaymentNotification(
-					user.Email,
-					"Просрочка платежа по кредиту",
-					monthlyPayment+penalty,
-				); err != nil {
-					fmt.Printf("Ошибка при отправке уведомления: %v\n", err)
-				}
-			}
-		}
+	if breaches > 0 {
+		fmt.Printf("Нарушены сроки рассмотрения споров: %d\n", breaches)
 	}
+	return nil
 }
 
-// ProcessPayment обрабатывает платеж по кредиту
-func (s *Scheduler) ProcessPayment(creditID uint, paymentNumber int) error {
-	credit, err := s.creditRepo.GetByID(context.Background(), creditID)
+// ProcessCards закрывает виртуальные карты с истекшим сроком действия,
+// перевыпускает карты с заканчивающимся сроком, закрывает истекшие карты
+// и снимает неподтвержденные холды
+func (s *Scheduler) ProcessCards() error {
+	closed, err := s.cardService.CloseExpiredVirtualCards()
 	if err != nil {
-		return fmt.Errorf("failed to get credit: %v", err)
+		return err
+	}
+	if closed > 0 {
+		fmt.Printf("Закрыто виртуальных карт с истекшим сроком: %d\n", closed)
 	}
 
-	account, err := s.accountRepo.GetByID(context.Background(), credit.AccountID)
+	renewals, err := s.cardService.RenewExpiringCards()
 	if err != nil {
-		return fmt.Errorf("failed to get account: %v", err)
+		return err
+	}
+	for _, renewal := range renewals {
+		s.sendCardRenewalNotification(&renewal)
 	}
 
-	// Получаем график платежей
-	schedule, err := s.creditRepo.GetPaymentSchedule(context.Background(), creditID)
+	closed, err = s.cardService.CloseExpiredCards()
 	if err != nil {
-		return fmt.Errorf("failed to get payment schedule: %v", err)
+		return err
 	}
-
-	// Находим нужный платеж
-	var payment *domain.PaymentSchedule
-	for _, p := range schedule {
-		if p.PaymentNumber == paymentNumber {
-			payment = &p
-			break
-		}
+	if closed > 0 {
+		fmt.Printf("Закрыто карт с истекшим сроком действия: %d\n", closed)
 	}
 
-	if payment == nil {
-		return fmt.Errorf("payment not found")
+	released, err := s.cardService.ReleaseExpiredHolds()
+	if err != nil {
+		return err
 	}
-
-	// Проверяем достаточно ли средств
-	if account.Balance < payment.Amount {
-		return fmt.Errorf("insufficient funds")
+	if released > 0 {
+		fmt.Printf("Снято холдов по картам с истекшим сроком: %d\n", released)
 	}
+	return nil
+}
 
-	// Списываем платеж
-	account.Balance -= payment.Amount
-	if err := s.accountRepo.Update(context.Background(), account); err != nil {
-		return fmt.Errorf("failed to update account: %v", err)
+// sendCardRenewalNotification отправляет клиенту уведомление о перевыпуске карты
+func (s *Scheduler) sendCardRenewalNotification(renewal *CardRenewal) {
+	user, err := s.userRepo.GetByID(context.Background(), renewal.OldCard.UserID)
+	if err != nil {
+		fmt.Printf("Ошибка при получении пользователя: %v\n", err)
+		return
 	}
 
-	// Обновляем статус платежа
-	payment.Status = "COMPLETED"
-	if err := s.creditRepo.UpdatePaymentSchedule(context.Background(), payment); err != nil {
-		return fmt.Errorf("failed to update payment schedule: %v", err)
+	if err := s.externalService.SendCardRenewalNotification(
+		user.Email,
+		renewal.OldCard.LastFour,
+		renewal.NewCard.LastFour,
+		*renewal.OldCard.ExpiresAt,
+	); err != nil {
+		fmt.Printf("Ошибка при отправке уведомления: %v\n", err)
 	}
+}
+
+// AccruePenalties начисляет неустойку по просроченным платежам
+func (s *Scheduler) AccruePenalties() error {
+	return s.creditService.AccruePenalties()
+}
 
-	// Создаем транзакцию
-	transaction := &domain.Transaction{
-		FromAccountID: credit.AccountID,
-		Amount:        payment.Amount,
-		Type:          "CREDIT_PAYMENT",
-		Status:        "COMPLETED",
-		Description:   fmt.Sprintf("Платеж по кредиту #%d", credit.ID),
+// ProcessDunning открывает и закрывает дела о просрочке и выполняет очередные
+// стадии взыскания: письма заемщикам, задачи менеджерам, передачу во взыскание
+func (s *Scheduler) ProcessDunning() error {
+	executed, err := s.collectionService.ProcessDunning()
+	if err != nil {
+		return err
 	}
+	if executed > 0 {
+		fmt.Printf("Выполнено стадий взыскания: %d\n", executed)
+	}
+	return nil
+}
+
+// ProcessCreditLines начисляет проценты и формирует выписки по кредитным линиям
+func (s *Scheduler) ProcessCreditLines() error {
+	return s.creditLineService.ProcessCreditLines()
+}
 
-	if err := s.transactionRepo.Create(context.Background(), transaction); err != nil {
-		return fmt.Errorf("failed to create transaction: %v", err)
+// ProcessDeposits начисляет проценты и обрабатывает окончание срока вкладов
+func (s *Scheduler) ProcessDeposits() error {
+	return s.depositService.ProcessDeposits()
+}
+
+// notifyCreditOwner отправляет владельцу кредита уведомление о платеже
+func (s *Scheduler) notifyCreditOwner(creditID uint, paymentType string, amount float64) error {
+	credit, err := s.creditRepo.GetByID(context.Background(), creditID)
+	if err != nil {
+		return fmt.Errorf("failed to get credit: %w", err)
 	}
 
-	// Получаем пользователя для уведомления
-	user, err := s.userRepo.GetByID(context.Background(), account.UserID)
+	user, err := s.userRepo.GetByID(context.Background(), credit.UserID)
 	if err != nil {
-		return fmt.Errorf("failed to get user: %v", err)
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 
 	if user == nil {
 		return fmt.Errorf("user not found")
 	}
 
-	// Отправляем уведомление
-	if err := s.keyRateService.SendPaymentNotification(
-		user.Email,
-		"Платеж по кредиту",
-		payment.Amount,
-	); err != nil {
-		return fmt.Errorf("failed to send notification: %v", err)
-	}
-
-	return nil
+	return s.externalService.SendPaymentNotification(user.Email, paymentType, amount, s.clock.Now())
 }
 
-// sendPaymentOverdueNotification отправляет уведомление о просрочке платежа
-func (s *Scheduler) sendPaymentOverdueNotification(email string, creditID uint, amount float64) error {
-	return s.keyRateService.SendPaymentNotification(
-		email,
-		"Просрочка платежа по кредиту",
-		amount,
-	)
-}
-
-// CheckPayments запускает проверку платежей вручную
+// CheckPayments списывает наступившие платежи
+// по графику вместе с неустойкой, а неоплаченные из-за нехватки средств переводит в просрочку
 func (s *Scheduler) CheckPayments() error {
-	// Получаем все активные кредиты
-	credits, err := s.creditRepo.GetActiveCredits(context.Background())
+	duePayments, err := s.creditRepo.GetDuePayments(context.Background(), s.clock.Now())
 	if err != nil {
-		return fmt.Errorf("failed to get active credits: %v", err)
+		return fmt.Errorf("failed to get due payments: %w", err)
 	}
 
-	for _, credit := range credits {
-		// Получаем счет
-		account, err := s.accountRepo.GetByID(context.Background(), credit.AccountID)
-		if err != nil {
-			return fmt.Errorf("failed to get account: %v", err)
-		}
-
-		// Получаем пользователя
-		user, err := s.userRepo.GetByID(context.Background(), account.UserID)
-		if err != nil {
-			return fmt.Errorf("failed to get user: %v", err)
-		}
-
-		if user == nil {
-			return fmt.Errorf("user not found")
+	// Платежи идут по порядку: если один не удалось списать, следующие по тому же кредиту пропускаем
+	failed := make(map[uint]bool)
+	for _, payment := range duePayments {
+		if failed[payment.CreditID] {
+			continue
 		}
 
-		// Проверяем, нужно ли списать платеж
-		if time.Now().After(credit.NextPayment) {
-			// Получаем график платежей
-			schedule, err := s.creditRepo.GetPaymentSchedule(context.Background(), credit.ID)
-			if err != nil {
-				return fmt.Errorf("failed to get payment schedule: %v", err)
-			}
-
-			// Находим следующий платеж
-			var nextPayment *domain.PaymentSchedule
-			for _, payment := range schedule {
-				if payment.Status == "PENDING" {
-					nextPayment = &payment
-					break
-				}
-			}
-
-			if nextPayment != nil {
-				// Проверяем достаточно ли средств
-				if account.Balance >= nextPayment.Amount {
-					// Списываем платеж
-					if err := s.ProcessPayment(credit.ID, nextPayment.PaymentNumber); err != nil {
-						return fmt.Errorf("failed to process payment: %v", err)
-					}
-				} else {
-					// Отмечаем платеж как просроченный
-					nextPayment.Status = "OVERDUE"
-					if err := s.creditRepo.UpdatePaymentSchedule(context.Background(), nextPayment); err != nil {
-						return fmt.Errorf("failed to update payment schedule: %v", err)
-					}
-
-					// Отправляем уведомление пользователю
-					if err := s.sendPaymentOverdueNotification(user.Email, credit.ID, nextPayment.Amount); err != nil {
-						return fmt.Errorf("failed to send notification: %v", err)
-					}
-				}
+		allocation, err := s.creditService.ProcessPayment(payment.CreditID, payment.PaymentNumber)
+		switch {
+		case err == nil:
+			if err := s.notifyCreditOwner(payment.CreditID, "Платеж по кредиту", allocation.Amount); err != nil {
+				fmt.Printf("Ошибка при отправке уведомления: %v\n", err)
 			}
+		case errors.Is(err, domain.ErrPaymentAlreadyPaid):
+			// Платеж уже погашен вместе с более ранней просроченной задолженностью
+		case errors.Is(err, domain.ErrInsufficientFunds):
+			// Уведомления о просрочке отправляются по стадиям взыскания, см. ProcessDunning
+			failed[payment.CreditID] = true
+		default:
+			failed[payment.CreditID] = true
+			fmt.Printf("Ошибка при обработке платежа #%d по кредиту %d: %v\n", payment.PaymentNumber, payment.CreditID, err)
 		}
 	}
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -22,9 +23,38 @@ type Config struct {
 	JWTSecret     string
 	JWTExpiration int
 
+	PGPPublicKeyPath  string
+	PGPPrivateKeyPath string
+	PGPPassphrase     string
+
+	CardHMACSecret string
+
 	ServerPort int
 	ServerHost string
 
+	ISO8583Enabled  bool
+	ISO8583Host     string
+	ISO8583Port     int
+	ISO8583SpecPath string
+
+	JobsEnabled      bool
+	JobsInstanceID   string
+	JobsPollInterval time.Duration
+	JobsLeaseTTL     time.Duration
+	JobSchedules     map[string]string // имя задачи -> выражение cron
+
+	ClockSimulation bool // моделирование времени для тестовых окружений, в production не включается
+
+	KeyRateProvider     string // cbr - веб-сервис Банка России, stub - локальный файл
+	KeyRateCBRURL       string
+	KeyRateTimeout      time.Duration
+	KeyRateStubFile     string // пустой путь - история, поставляемая с приложением
+	KeyRateCacheTTL     time.Duration
+	KeyRateMaxStaleness time.Duration
+
+	CurrencyRatesCBRURL  string
+	CurrencyRatesTimeout time.Duration
+
 	LogLevel  string
 	LogFormat string
 
@@ -35,6 +65,9 @@ type Config struct {
 
 var cfg *Config
 
+// developmentCardHMACSecret ключ HMAC номеров карт для разработки, когда CARD_HMAC_SECRET не задан
+const developmentCardHMACSecret = "development-card-hmac-secret"
+
 func Init() error {
 	if err := godotenv.Load(); err != nil {
 		return fmt.Errorf("error loading .env file: %v", err)
@@ -53,9 +86,38 @@ func Init() error {
 		JWTSecret:     getEnv("JWT_SECRET", "your-secret-key"),
 		JWTExpiration: getEnvAsInt("JWT_EXPIRATION", 24),
 
+		PGPPublicKeyPath:  getEnv("PGP_PUBLIC_KEY_PATH", "public_key.asc"),
+		PGPPrivateKeyPath: getEnv("PGP_PRIVATE_KEY_PATH", "private_key.asc"),
+		PGPPassphrase:     getEnv("PGP_PASSPHRASE", ""),
+
+		CardHMACSecret: getEnv("CARD_HMAC_SECRET", ""),
+
 		ServerPort: getEnvAsInt("SERVER_PORT", 8080),
 		ServerHost: getEnv("SERVER_HOST", "localhost"),
 
+		ISO8583Enabled:  getEnvAsBool("ISO8583_ENABLED", false),
+		ISO8583Host:     getEnv("ISO8583_HOST", "localhost"),
+		ISO8583Port:     getEnvAsInt("ISO8583_PORT", 8583),
+		ISO8583SpecPath: getEnv("ISO8583_SPEC_PATH", ""),
+
+		JobsEnabled:      getEnvAsBool("JOBS_ENABLED", true),
+		JobsInstanceID:   getEnv("JOBS_INSTANCE_ID", defaultInstanceID()),
+		JobsPollInterval: getEnvAsDuration("JOBS_POLL_INTERVAL", time.Minute),
+		JobsLeaseTTL:     getEnvAsDuration("JOBS_LEASE_TTL", 30*time.Minute),
+		JobSchedules:     getJobSchedules(),
+
+		ClockSimulation: getEnvAsBool("CLOCK_SIMULATION", false),
+
+		KeyRateProvider:     getEnv("KEY_RATE_PROVIDER", "cbr"),
+		KeyRateCBRURL:       getEnv("KEY_RATE_CBR_URL", "https://www.cbr.ru/DailyInfoWebServ/DailyInfo.asmx"),
+		KeyRateTimeout:      getEnvAsDuration("KEY_RATE_TIMEOUT", 10*time.Second),
+		KeyRateStubFile:     getEnv("KEY_RATE_STUB_FILE", ""),
+		KeyRateCacheTTL:     getEnvAsDuration("KEY_RATE_CACHE_TTL", time.Hour),
+		KeyRateMaxStaleness: getEnvAsDuration("KEY_RATE_MAX_STALENESS", 72*time.Hour),
+
+		CurrencyRatesCBRURL:  getEnv("CURRENCY_RATES_CBR_URL", "https://www.cbr.ru/DailyInfoWebServ/DailyInfo.asmx"),
+		CurrencyRatesTimeout: getEnvAsDuration("CURRENCY_RATES_TIMEOUT", 10*time.Second),
+
 		LogLevel:  getEnv("LOG_LEVEL", "debug"),
 		LogFormat: getEnv("LOG_FORMAT", "json"),
 
@@ -64,6 +126,15 @@ func Init() error {
 		AppDebug: getEnvAsBool("APP_DEBUG", true),
 	}
 
+	// Отпечатки номеров карт с известным ключом подбираются перебором, поэтому
+	// ключ по умолчанию допускается только при разработке
+	if cfg.CardHMACSecret == "" {
+		if cfg.AppEnv != "development" {
+			return fmt.Errorf("CARD_HMAC_SECRET must be set when APP_ENV=%s", cfg.AppEnv)
+		}
+		cfg.CardHMACSecret = developmentCardHMACSecret
+	}
+
 	return nil
 }
 
@@ -104,3 +175,28 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getJobSchedules собирает расписания задач из переменных JOB_SCHEDULE_<ИМЯ>:
+// JOB_SCHEDULE_CHECK_PAYMENTS задает расписание задачи check-payments
+func getJobSchedules() map[string]string {
+	const prefix = "JOB_SCHEDULE_"
+	schedules := make(map[string]string)
+	for _, env := range os.Environ() {
+		key, value, ok := strings.Cut(env, "=")
+		if !ok || !strings.HasPrefix(key, prefix) || value == "" {
+			continue
+		}
+		name := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(key, prefix), "_", "-"))
+		schedules[name] = value
+	}
+	return schedules
+}
+
+// defaultInstanceID идентифицирует экземпляр приложения для блокировок фоновых задач
+func defaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}